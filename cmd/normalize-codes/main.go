@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"coupon-issuance/internal/database"
+	"coupon-issuance/internal/server"
+)
+
+// normalize-codes rewrites the coupon codes stored before codes were
+// normalized. Run it once after migration 018, while no server is running.
+func main() {
+	ctx := context.Background()
+
+	pool, err := database.NewPool(ctx)
+	if err != nil {
+		log.Fatalf("Failed to create database pool: %v", err)
+	}
+	defer pool.Close()
+
+	result, err := server.NormalizeStoredCodes(ctx, pool)
+	if err != nil {
+		log.Fatalf("Failed to normalize codes: %v", err)
+	}
+
+	log.Printf("Normalized %d codes", result.Renamed)
+	if result.Conflicts > 0 {
+		log.Printf(
+			"%d codes could not be normalized, "+
+				"see code_normalization_conflicts",
+			result.Conflicts,
+		)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	couponConnect "coupon-issuance/gen/coupon/v1/v1connect"
+	"coupon-issuance/internal/codeimage"
 	"coupon-issuance/internal/server"
 
 	"golang.org/x/net/http2"
@@ -18,6 +19,7 @@ func main() {
 	path, handler := couponConnect.NewCouponServiceHandler(couponService)
 	mux := http.NewServeMux()
 	mux.Handle(path, handler)
+	mux.Handle(codeimage.Path, couponService.ImageHandler())
 
 	// HTTP server with H2C support
 	h2s := &http2.Server{}
@@ -0,0 +1,40 @@
+ALTER TABLE coupons ADD COLUMN IF NOT EXISTS user_id VARCHAR(255);
+
+CREATE TYPE job_status AS ENUM ('pending', 'running', 'completed', 'failed');
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(50) NOT NULL,
+    campaign_id UUID NOT NULL REFERENCES campaigns(id),
+    status job_status NOT NULL DEFAULT 'pending',
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    next_batch INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status);
+
+CREATE TYPE push_recipient_status AS ENUM ('pending', 'issued', 'failed');
+
+CREATE TABLE IF NOT EXISTS push_issuance_recipients (
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    status push_recipient_status NOT NULL DEFAULT 'pending',
+    coupon_code VARCHAR(50),
+    error TEXT,
+    PRIMARY KEY (job_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_issuance_recipients_status
+    ON push_issuance_recipients(job_id, status);
+
+CREATE TRIGGER update_jobs_updated_at
+    BEFORE UPDATE ON jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
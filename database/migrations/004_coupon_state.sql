@@ -0,0 +1,19 @@
+CREATE TYPE coupon_state AS ENUM (
+    'available',
+    'issued',
+    'redeemed',
+    'void',
+    'expired'
+);
+
+ALTER TABLE coupons
+    ADD COLUMN IF NOT EXISTS state coupon_state NOT NULL DEFAULT 'available',
+    ADD COLUMN IF NOT EXISTS redeemed_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS order_ref VARCHAR(255);
+
+UPDATE coupons SET state = 'issued' WHERE issued;
+
+DROP INDEX IF EXISTS idx_coupons_issued;
+ALTER TABLE coupons DROP COLUMN IF EXISTS issued;
+
+CREATE INDEX IF NOT EXISTS idx_coupons_state ON coupons(state);
@@ -0,0 +1,13 @@
+ALTER TABLE campaigns
+    ADD COLUMN IF NOT EXISTS valid_until TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS validity_seconds BIGINT
+        CHECK (validity_seconds > 0);
+
+ALTER TABLE coupons
+    ADD COLUMN IF NOT EXISTS issued_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE coupons SET issued_at = updated_at WHERE state <> 'available';
+
+CREATE INDEX IF NOT EXISTS idx_coupons_expires_at
+    ON coupons(expires_at) WHERE state = 'issued';
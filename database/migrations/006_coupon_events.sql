@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS coupon_events (
+    id BIGSERIAL PRIMARY KEY,
+    coupon_id UUID NOT NULL REFERENCES coupons(id),
+    event VARCHAR(50) NOT NULL,
+    from_state coupon_state,
+    to_state coupon_state,
+    reason TEXT,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_coupon_events_coupon_id
+    ON coupon_events(coupon_id);
@@ -0,0 +1 @@
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS benefit JSONB;
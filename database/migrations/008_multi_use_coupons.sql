@@ -0,0 +1,26 @@
+ALTER TABLE campaigns
+    ADD COLUMN IF NOT EXISTS max_redemptions INTEGER NOT NULL DEFAULT 1
+        CHECK (max_redemptions > 0);
+
+-- A NULL max_redemptions falls back to the campaign's
+ALTER TABLE coupons
+    ADD COLUMN IF NOT EXISTS max_redemptions INTEGER
+        CHECK (max_redemptions > 0),
+    ADD COLUMN IF NOT EXISTS redemption_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    coupon_id UUID NOT NULL REFERENCES coupons(id),
+    order_ref VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255),
+    redeemed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (coupon_id, order_ref)
+);
+
+INSERT INTO coupon_redemptions (coupon_id, order_ref, user_id, redeemed_at)
+SELECT id, order_ref, user_id, COALESCE(redeemed_at, updated_at)
+FROM coupons
+WHERE state = 'redeemed' AND order_ref IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+UPDATE coupons SET redemption_count = 1 WHERE state = 'redeemed';
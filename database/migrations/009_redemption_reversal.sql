@@ -0,0 +1,2 @@
+ALTER TABLE coupon_redemptions
+    ADD COLUMN IF NOT EXISTS reversed_at TIMESTAMP WITH TIME ZONE;
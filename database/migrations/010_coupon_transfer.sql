@@ -0,0 +1,7 @@
+ALTER TABLE campaigns
+    ADD COLUMN IF NOT EXISTS transferable BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS max_held_per_user INTEGER
+        CHECK (max_held_per_user > 0);
+
+CREATE INDEX IF NOT EXISTS idx_coupons_campaign_user
+    ON coupons(campaign_id, user_id) WHERE state = 'issued';
@@ -0,0 +1,7 @@
+CREATE TYPE stacking_policy AS ENUM ('stackable', 'exclusive');
+
+ALTER TABLE campaigns
+    ADD COLUMN IF NOT EXISTS exclusivity_group VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS stacking_policy stacking_policy NOT NULL
+        DEFAULT 'stackable',
+    ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
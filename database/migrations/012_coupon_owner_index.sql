@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_coupons_user_issued_at
+    ON coupons(user_id, issued_at DESC, id DESC)
+    WHERE user_id IS NOT NULL;
@@ -0,0 +1,16 @@
+ALTER TABLE campaigns
+    ADD COLUMN IF NOT EXISTS signed_codes BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Key IDs are embedded in signed codes, so they are unique across campaigns
+-- and never reused. Retired keys no longer sign codes but still verify them.
+CREATE TABLE IF NOT EXISTS campaign_signing_keys (
+    key_id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    secret BYTEA NOT NULL,
+    next_serial BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    retired_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_campaign_signing_keys_active
+    ON campaign_signing_keys(campaign_id) WHERE retired_at IS NULL;
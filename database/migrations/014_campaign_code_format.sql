@@ -0,0 +1,2 @@
+-- NULL keeps the original 10 character Hangul and digit codes
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS code_format JSONB;
@@ -0,0 +1,12 @@
+ALTER TABLE campaigns
+    ADD COLUMN IF NOT EXISTS sequenced_codes BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Sequenced codes are derived from a number counted per code format, so
+-- campaigns with the same format never derive the same code. A format is
+-- used either for sequenced or for random codes, never both.
+CREATE TABLE IF NOT EXISTS code_spaces (
+    format_key TEXT PRIMARY KEY,
+    secret BYTEA NOT NULL,
+    next_sequence BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,19 @@
+ALTER TABLE campaigns
+    ADD COLUMN IF NOT EXISTS imported_codes BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Campaigns with imported codes start without coupons
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS campaigns_coupon_limit_check;
+ALTER TABLE campaigns ADD CONSTRAINT campaigns_coupon_limit_check
+    CHECK (coupon_limit > 0 OR imported_codes);
+
+-- Imported codes also have a row in coupons, as available codes, from the
+-- import on. claimed_at is set once a server has taken the code into its
+-- pool.
+CREATE TABLE IF NOT EXISTS imported_codes (
+    code VARCHAR(50) PRIMARY KEY REFERENCES coupons(code) ON DELETE CASCADE,
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    claimed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_imported_codes_unclaimed
+    ON imported_codes(campaign_id) WHERE claimed_at IS NULL;
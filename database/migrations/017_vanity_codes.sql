@@ -0,0 +1,17 @@
+-- A vanity code also has a row in coupons, issued without an owner, so that
+-- no other code can take it
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS vanity_code VARCHAR(50);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_campaigns_vanity_code
+    ON campaigns(vanity_code) WHERE vanity_code IS NOT NULL;
+
+-- Each user's claim of a campaign's vanity code, and its redemption
+CREATE TABLE IF NOT EXISTS vanity_claims (
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    claimed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    redeemed_at TIMESTAMP WITH TIME ZONE,
+    order_ref VARCHAR(255),
+    PRIMARY KEY (campaign_id, user_id)
+);
@@ -0,0 +1,21 @@
+-- Codes are stored in their normalized form: full-width and half-width
+-- characters folded, NFC, separators removed and Latin letters upper-cased.
+-- Existing rows are rewritten by cmd/normalize-codes, which uses the same
+-- codeformat.Normalize as the server.
+
+-- Imported codes follow their coupon's code
+ALTER TABLE imported_codes DROP CONSTRAINT IF EXISTS imported_codes_code_fkey;
+ALTER TABLE imported_codes ADD CONSTRAINT imported_codes_code_fkey
+    FOREIGN KEY (code) REFERENCES coupons(code)
+    ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- Coupons whose code cannot be normalized because the normalized form is
+-- empty or belongs to another coupon. They cannot be looked up until they
+-- are reissued or deleted.
+CREATE TABLE IF NOT EXISTS code_normalization_conflicts (
+    coupon_id UUID PRIMARY KEY REFERENCES coupons(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    normalized_code VARCHAR(50) NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('empty', 'taken')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
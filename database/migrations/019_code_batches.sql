@@ -0,0 +1,15 @@
+-- Pre-issued codes are issued without an owner by a code batch job, to be
+-- handed out outside the service, e.g. printed on flyers
+ALTER TABLE coupons
+    ADD COLUMN IF NOT EXISTS pre_issued BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The codes of a code batch job in the order they were generated, as they
+-- are printed
+CREATE TABLE IF NOT EXISTS code_batch_codes (
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    code VARCHAR(50) NOT NULL REFERENCES coupons(code)
+        ON DELETE CASCADE ON UPDATE CASCADE,
+    printed_code VARCHAR(50) NOT NULL,
+    PRIMARY KEY (job_id, position)
+);
@@ -0,0 +1,13 @@
+-- A server's claim on imported codes in its pool is a lease, so codes held
+-- by a server that stopped are claimed again once it expires. claimed_at is
+-- now only set when the code is issued.
+ALTER TABLE imported_codes
+    ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP WITH TIME ZONE;
+
+-- Codes claimed into a pool but never issued were lost; free them again
+UPDATE imported_codes i SET claimed_at = NULL
+FROM coupons c
+WHERE c.code = i.code
+AND i.claimed_at IS NOT NULL
+AND c.campaign_id IS NULL
+AND c.state = 'available';
@@ -0,0 +1,7 @@
+-- The checkout holding a coupon's lock. Redis hands out and expires locks;
+-- redemptions, transfers and revocations are conditioned on these columns,
+-- so a lock that expired or changed hands since it was checked cannot be
+-- overtaken.
+ALTER TABLE coupons ADD COLUMN IF NOT EXISTS locked_by TEXT;
+ALTER TABLE coupons
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,4 @@
+-- Coupons a code batch job has taken from the campaign counter for its
+-- next block, kept until the block commits so that a retried block does not
+-- take them again
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS reserved INTEGER;
@@ -0,0 +1,4 @@
+-- Failed attempts at a job's current batch, reset when a batch commits. A
+-- job whose batch keeps failing is marked failed instead of being retried
+-- forever.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
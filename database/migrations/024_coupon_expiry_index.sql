@@ -0,0 +1,5 @@
+-- The expiry sweep selects coupons by state = ANY(...) rather than a fixed
+-- state, which the partial index on issued coupons cannot serve.
+DROP INDEX IF EXISTS idx_coupons_expires_at;
+CREATE INDEX IF NOT EXISTS idx_coupons_state_expires_at
+    ON coupons(state, expires_at) WHERE expires_at IS NOT NULL;
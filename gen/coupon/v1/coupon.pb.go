@@ -21,30 +21,4831 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type CodeAlphabet int32
+
+const (
+	// Hangul syllables and digits, each character picked from either with
+	// equal probability.
+	CodeAlphabet_CODE_ALPHABET_UNSPECIFIED     CodeAlphabet = 0
+	CodeAlphabet_CODE_ALPHABET_HANGUL          CodeAlphabet = 1
+	CodeAlphabet_CODE_ALPHABET_DIGITS          CodeAlphabet = 2
+	CodeAlphabet_CODE_ALPHABET_LATIN_UPPERCASE CodeAlphabet = 3
+	// 0-9 and A-Z without I, L, O and U.
+	CodeAlphabet_CODE_ALPHABET_CROCKFORD_BASE32 CodeAlphabet = 4
+	// Digits and capitals without characters easily mistaken for one
+	// another: 34679ACEFGHJKMNPRTWXY.
+	CodeAlphabet_CODE_ALPHABET_READABLE CodeAlphabet = 5
+)
+
+// Enum value maps for CodeAlphabet.
+var (
+	CodeAlphabet_name = map[int32]string{
+		0: "CODE_ALPHABET_UNSPECIFIED",
+		1: "CODE_ALPHABET_HANGUL",
+		2: "CODE_ALPHABET_DIGITS",
+		3: "CODE_ALPHABET_LATIN_UPPERCASE",
+		4: "CODE_ALPHABET_CROCKFORD_BASE32",
+		5: "CODE_ALPHABET_READABLE",
+	}
+	CodeAlphabet_value = map[string]int32{
+		"CODE_ALPHABET_UNSPECIFIED":      0,
+		"CODE_ALPHABET_HANGUL":           1,
+		"CODE_ALPHABET_DIGITS":           2,
+		"CODE_ALPHABET_LATIN_UPPERCASE":  3,
+		"CODE_ALPHABET_CROCKFORD_BASE32": 4,
+		"CODE_ALPHABET_READABLE":         5,
+	}
+)
+
+func (x CodeAlphabet) Enum() *CodeAlphabet {
+	p := new(CodeAlphabet)
+	*p = x
+	return p
+}
+
+func (x CodeAlphabet) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CodeAlphabet) Descriptor() protoreflect.EnumDescriptor {
+	return file_coupon_v1_coupon_proto_enumTypes[0].Descriptor()
+}
+
+func (CodeAlphabet) Type() protoreflect.EnumType {
+	return &file_coupon_v1_coupon_proto_enumTypes[0]
+}
+
+func (x CodeAlphabet) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CodeAlphabet.Descriptor instead.
+func (CodeAlphabet) EnumDescriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{0}
+}
+
+type CheckCharacter int32
+
+const (
+	CheckCharacter_CHECK_CHARACTER_UNSPECIFIED CheckCharacter = 0
+	// Luhn mod N over the code's alphabet, for any alphabet.
+	CheckCharacter_CHECK_CHARACTER_LUHN CheckCharacter = 1
+	// Damm check digit, for the digits alphabet.
+	CheckCharacter_CHECK_CHARACTER_DAMM CheckCharacter = 2
+)
+
+// Enum value maps for CheckCharacter.
+var (
+	CheckCharacter_name = map[int32]string{
+		0: "CHECK_CHARACTER_UNSPECIFIED",
+		1: "CHECK_CHARACTER_LUHN",
+		2: "CHECK_CHARACTER_DAMM",
+	}
+	CheckCharacter_value = map[string]int32{
+		"CHECK_CHARACTER_UNSPECIFIED": 0,
+		"CHECK_CHARACTER_LUHN":        1,
+		"CHECK_CHARACTER_DAMM":        2,
+	}
+)
+
+func (x CheckCharacter) Enum() *CheckCharacter {
+	p := new(CheckCharacter)
+	*p = x
+	return p
+}
+
+func (x CheckCharacter) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CheckCharacter) Descriptor() protoreflect.EnumDescriptor {
+	return file_coupon_v1_coupon_proto_enumTypes[1].Descriptor()
+}
+
+func (CheckCharacter) Type() protoreflect.EnumType {
+	return &file_coupon_v1_coupon_proto_enumTypes[1]
+}
+
+func (x CheckCharacter) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CheckCharacter.Descriptor instead.
+func (CheckCharacter) EnumDescriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{1}
+}
+
+type StackingPolicy int32
+
+const (
+	// Treated as STACKABLE.
+	StackingPolicy_STACKING_POLICY_UNSPECIFIED StackingPolicy = 0
+	// Combines with other stackable coupons.
+	StackingPolicy_STACKING_POLICY_STACKABLE StackingPolicy = 1
+	// Cannot be combined with any other coupon.
+	StackingPolicy_STACKING_POLICY_EXCLUSIVE StackingPolicy = 2
+)
+
+// Enum value maps for StackingPolicy.
+var (
+	StackingPolicy_name = map[int32]string{
+		0: "STACKING_POLICY_UNSPECIFIED",
+		1: "STACKING_POLICY_STACKABLE",
+		2: "STACKING_POLICY_EXCLUSIVE",
+	}
+	StackingPolicy_value = map[string]int32{
+		"STACKING_POLICY_UNSPECIFIED": 0,
+		"STACKING_POLICY_STACKABLE":   1,
+		"STACKING_POLICY_EXCLUSIVE":   2,
+	}
+)
+
+func (x StackingPolicy) Enum() *StackingPolicy {
+	p := new(StackingPolicy)
+	*p = x
+	return p
+}
+
+func (x StackingPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StackingPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_coupon_v1_coupon_proto_enumTypes[2].Descriptor()
+}
+
+func (StackingPolicy) Type() protoreflect.EnumType {
+	return &file_coupon_v1_coupon_proto_enumTypes[2]
+}
+
+func (x StackingPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StackingPolicy.Descriptor instead.
+func (StackingPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{2}
+}
+
+type BasketRejectionReason int32
+
+const (
+	BasketRejectionReason_BASKET_REJECTION_REASON_UNSPECIFIED        BasketRejectionReason = 0
+	BasketRejectionReason_BASKET_REJECTION_REASON_NOT_FOUND          BasketRejectionReason = 1
+	BasketRejectionReason_BASKET_REJECTION_REASON_NOT_REDEEMABLE     BasketRejectionReason = 2
+	BasketRejectionReason_BASKET_REJECTION_REASON_NOT_OWNER          BasketRejectionReason = 3
+	BasketRejectionReason_BASKET_REJECTION_REASON_DUPLICATE          BasketRejectionReason = 4
+	BasketRejectionReason_BASKET_REJECTION_REASON_NO_BENEFIT         BasketRejectionReason = 5
+	BasketRejectionReason_BASKET_REJECTION_REASON_NOT_APPLICABLE     BasketRejectionReason = 6
+	BasketRejectionReason_BASKET_REJECTION_REASON_EXCLUSIVITY_GROUP  BasketRejectionReason = 7
+	BasketRejectionReason_BASKET_REJECTION_REASON_NOT_STACKABLE      BasketRejectionReason = 8
+	BasketRejectionReason_BASKET_REJECTION_REASON_NO_REMAINING_VALUE BasketRejectionReason = 9
+)
+
+// Enum value maps for BasketRejectionReason.
+var (
+	BasketRejectionReason_name = map[int32]string{
+		0: "BASKET_REJECTION_REASON_UNSPECIFIED",
+		1: "BASKET_REJECTION_REASON_NOT_FOUND",
+		2: "BASKET_REJECTION_REASON_NOT_REDEEMABLE",
+		3: "BASKET_REJECTION_REASON_NOT_OWNER",
+		4: "BASKET_REJECTION_REASON_DUPLICATE",
+		5: "BASKET_REJECTION_REASON_NO_BENEFIT",
+		6: "BASKET_REJECTION_REASON_NOT_APPLICABLE",
+		7: "BASKET_REJECTION_REASON_EXCLUSIVITY_GROUP",
+		8: "BASKET_REJECTION_REASON_NOT_STACKABLE",
+		9: "BASKET_REJECTION_REASON_NO_REMAINING_VALUE",
+	}
+	BasketRejectionReason_value = map[string]int32{
+		"BASKET_REJECTION_REASON_UNSPECIFIED":        0,
+		"BASKET_REJECTION_REASON_NOT_FOUND":          1,
+		"BASKET_REJECTION_REASON_NOT_REDEEMABLE":     2,
+		"BASKET_REJECTION_REASON_NOT_OWNER":          3,
+		"BASKET_REJECTION_REASON_DUPLICATE":          4,
+		"BASKET_REJECTION_REASON_NO_BENEFIT":         5,
+		"BASKET_REJECTION_REASON_NOT_APPLICABLE":     6,
+		"BASKET_REJECTION_REASON_EXCLUSIVITY_GROUP":  7,
+		"BASKET_REJECTION_REASON_NOT_STACKABLE":      8,
+		"BASKET_REJECTION_REASON_NO_REMAINING_VALUE": 9,
+	}
+)
+
+func (x BasketRejectionReason) Enum() *BasketRejectionReason {
+	p := new(BasketRejectionReason)
+	*p = x
+	return p
+}
+
+func (x BasketRejectionReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BasketRejectionReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_coupon_v1_coupon_proto_enumTypes[3].Descriptor()
+}
+
+func (BasketRejectionReason) Type() protoreflect.EnumType {
+	return &file_coupon_v1_coupon_proto_enumTypes[3]
+}
+
+func (x BasketRejectionReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BasketRejectionReason.Descriptor instead.
+func (BasketRejectionReason) EnumDescriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{3}
+}
+
+type BenefitNotApplicableReason int32
+
+const (
+	BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_UNSPECIFIED       BenefitNotApplicableReason = 0
+	BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_CURRENCY_MISMATCH BenefitNotApplicableReason = 1
+	BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_MIN_ORDER_NOT_MET BenefitNotApplicableReason = 2
+	BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_NO_ELIGIBLE_ITEMS BenefitNotApplicableReason = 3
+	BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_FREE_ITEM_MISSING BenefitNotApplicableReason = 4
+)
+
+// Enum value maps for BenefitNotApplicableReason.
+var (
+	BenefitNotApplicableReason_name = map[int32]string{
+		0: "BENEFIT_NOT_APPLICABLE_REASON_UNSPECIFIED",
+		1: "BENEFIT_NOT_APPLICABLE_REASON_CURRENCY_MISMATCH",
+		2: "BENEFIT_NOT_APPLICABLE_REASON_MIN_ORDER_NOT_MET",
+		3: "BENEFIT_NOT_APPLICABLE_REASON_NO_ELIGIBLE_ITEMS",
+		4: "BENEFIT_NOT_APPLICABLE_REASON_FREE_ITEM_MISSING",
+	}
+	BenefitNotApplicableReason_value = map[string]int32{
+		"BENEFIT_NOT_APPLICABLE_REASON_UNSPECIFIED":       0,
+		"BENEFIT_NOT_APPLICABLE_REASON_CURRENCY_MISMATCH": 1,
+		"BENEFIT_NOT_APPLICABLE_REASON_MIN_ORDER_NOT_MET": 2,
+		"BENEFIT_NOT_APPLICABLE_REASON_NO_ELIGIBLE_ITEMS": 3,
+		"BENEFIT_NOT_APPLICABLE_REASON_FREE_ITEM_MISSING": 4,
+	}
+)
+
+func (x BenefitNotApplicableReason) Enum() *BenefitNotApplicableReason {
+	p := new(BenefitNotApplicableReason)
+	*p = x
+	return p
+}
+
+func (x BenefitNotApplicableReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BenefitNotApplicableReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_coupon_v1_coupon_proto_enumTypes[4].Descriptor()
+}
+
+func (BenefitNotApplicableReason) Type() protoreflect.EnumType {
+	return &file_coupon_v1_coupon_proto_enumTypes[4]
+}
+
+func (x BenefitNotApplicableReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BenefitNotApplicableReason.Descriptor instead.
+func (BenefitNotApplicableReason) EnumDescriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{4}
+}
+
+type CouponInvalidReason int32
+
+const (
+	CouponInvalidReason_COUPON_INVALID_REASON_UNSPECIFIED CouponInvalidReason = 0
+	CouponInvalidReason_COUPON_INVALID_REASON_NOT_FOUND   CouponInvalidReason = 1
+	CouponInvalidReason_COUPON_INVALID_REASON_NOT_ISSUED  CouponInvalidReason = 2
+	CouponInvalidReason_COUPON_INVALID_REASON_REDEEMED    CouponInvalidReason = 3
+	CouponInvalidReason_COUPON_INVALID_REASON_VOID        CouponInvalidReason = 4
+	CouponInvalidReason_COUPON_INVALID_REASON_EXPIRED     CouponInvalidReason = 5
+	// The code has the shape of a campaign's codes but a wrong check
+	// character, so it was most likely mistyped.
+	CouponInvalidReason_COUPON_INVALID_REASON_PROBABLE_TYPO CouponInvalidReason = 6
+)
+
+// Enum value maps for CouponInvalidReason.
+var (
+	CouponInvalidReason_name = map[int32]string{
+		0: "COUPON_INVALID_REASON_UNSPECIFIED",
+		1: "COUPON_INVALID_REASON_NOT_FOUND",
+		2: "COUPON_INVALID_REASON_NOT_ISSUED",
+		3: "COUPON_INVALID_REASON_REDEEMED",
+		4: "COUPON_INVALID_REASON_VOID",
+		5: "COUPON_INVALID_REASON_EXPIRED",
+		6: "COUPON_INVALID_REASON_PROBABLE_TYPO",
+	}
+	CouponInvalidReason_value = map[string]int32{
+		"COUPON_INVALID_REASON_UNSPECIFIED":   0,
+		"COUPON_INVALID_REASON_NOT_FOUND":     1,
+		"COUPON_INVALID_REASON_NOT_ISSUED":    2,
+		"COUPON_INVALID_REASON_REDEEMED":      3,
+		"COUPON_INVALID_REASON_VOID":          4,
+		"COUPON_INVALID_REASON_EXPIRED":       5,
+		"COUPON_INVALID_REASON_PROBABLE_TYPO": 6,
+	}
+)
+
+func (x CouponInvalidReason) Enum() *CouponInvalidReason {
+	p := new(CouponInvalidReason)
+	*p = x
+	return p
+}
+
+func (x CouponInvalidReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CouponInvalidReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_coupon_v1_coupon_proto_enumTypes[5].Descriptor()
+}
+
+func (CouponInvalidReason) Type() protoreflect.EnumType {
+	return &file_coupon_v1_coupon_proto_enumTypes[5]
+}
+
+func (x CouponInvalidReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CouponInvalidReason.Descriptor instead.
+func (CouponInvalidReason) EnumDescriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{5}
+}
+
+type CodeListFormat int32
+
+const (
+	// One code per line.
+	CodeListFormat_CODE_LIST_FORMAT_UNSPECIFIED CodeListFormat = 0
+	// The code is the first column. A first row whose first column is "code"
+	// is a header and skipped.
+	CodeListFormat_CODE_LIST_FORMAT_CSV CodeListFormat = 1
+)
+
+// Enum value maps for CodeListFormat.
+var (
+	CodeListFormat_name = map[int32]string{
+		0: "CODE_LIST_FORMAT_UNSPECIFIED",
+		1: "CODE_LIST_FORMAT_CSV",
+	}
+	CodeListFormat_value = map[string]int32{
+		"CODE_LIST_FORMAT_UNSPECIFIED": 0,
+		"CODE_LIST_FORMAT_CSV":         1,
+	}
+)
+
+func (x CodeListFormat) Enum() *CodeListFormat {
+	p := new(CodeListFormat)
+	*p = x
+	return p
+}
+
+func (x CodeListFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CodeListFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_coupon_v1_coupon_proto_enumTypes[6].Descriptor()
+}
+
+func (CodeListFormat) Type() protoreflect.EnumType {
+	return &file_coupon_v1_coupon_proto_enumTypes[6]
+}
+
+func (x CodeListFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CodeListFormat.Descriptor instead.
+func (CodeListFormat) EnumDescriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{6}
+}
+
+type CodeBatchFormat int32
+
+const (
+	// CSV with a header row.
+	CodeBatchFormat_CODE_BATCH_FORMAT_UNSPECIFIED CodeBatchFormat = 0
+	// One JSON object per line.
+	CodeBatchFormat_CODE_BATCH_FORMAT_JSONL CodeBatchFormat = 1
+)
+
+// Enum value maps for CodeBatchFormat.
+var (
+	CodeBatchFormat_name = map[int32]string{
+		0: "CODE_BATCH_FORMAT_UNSPECIFIED",
+		1: "CODE_BATCH_FORMAT_JSONL",
+	}
+	CodeBatchFormat_value = map[string]int32{
+		"CODE_BATCH_FORMAT_UNSPECIFIED": 0,
+		"CODE_BATCH_FORMAT_JSONL":       1,
+	}
+)
+
+func (x CodeBatchFormat) Enum() *CodeBatchFormat {
+	p := new(CodeBatchFormat)
+	*p = x
+	return p
+}
+
+func (x CodeBatchFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CodeBatchFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_coupon_v1_coupon_proto_enumTypes[7].Descriptor()
+}
+
+func (CodeBatchFormat) Type() protoreflect.EnumType {
+	return &file_coupon_v1_coupon_proto_enumTypes[7]
+}
+
+func (x CodeBatchFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CodeBatchFormat.Descriptor instead.
+func (CodeBatchFormat) EnumDescriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{7}
+}
+
+type EligibilityDenialReason int32
+
+const (
+	EligibilityDenialReason_ELIGIBILITY_DENIAL_REASON_UNSPECIFIED        EligibilityDenialReason = 0
+	EligibilityDenialReason_ELIGIBILITY_DENIAL_REASON_RULE_NOT_SATISFIED EligibilityDenialReason = 1
+	EligibilityDenialReason_ELIGIBILITY_DENIAL_REASON_MISSING_ATTRIBUTE  EligibilityDenialReason = 2
+	EligibilityDenialReason_ELIGIBILITY_DENIAL_REASON_INVALID_ATTRIBUTE  EligibilityDenialReason = 3
+)
+
+// Enum value maps for EligibilityDenialReason.
+var (
+	EligibilityDenialReason_name = map[int32]string{
+		0: "ELIGIBILITY_DENIAL_REASON_UNSPECIFIED",
+		1: "ELIGIBILITY_DENIAL_REASON_RULE_NOT_SATISFIED",
+		2: "ELIGIBILITY_DENIAL_REASON_MISSING_ATTRIBUTE",
+		3: "ELIGIBILITY_DENIAL_REASON_INVALID_ATTRIBUTE",
+	}
+	EligibilityDenialReason_value = map[string]int32{
+		"ELIGIBILITY_DENIAL_REASON_UNSPECIFIED":        0,
+		"ELIGIBILITY_DENIAL_REASON_RULE_NOT_SATISFIED": 1,
+		"ELIGIBILITY_DENIAL_REASON_MISSING_ATTRIBUTE":  2,
+		"ELIGIBILITY_DENIAL_REASON_INVALID_ATTRIBUTE":  3,
+	}
+)
+
+func (x EligibilityDenialReason) Enum() *EligibilityDenialReason {
+	p := new(EligibilityDenialReason)
+	*p = x
+	return p
+}
+
+func (x EligibilityDenialReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EligibilityDenialReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_coupon_v1_coupon_proto_enumTypes[8].Descriptor()
+}
+
+func (EligibilityDenialReason) Type() protoreflect.EnumType {
+	return &file_coupon_v1_coupon_proto_enumTypes[8]
+}
+
+func (x EligibilityDenialReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EligibilityDenialReason.Descriptor instead.
+func (EligibilityDenialReason) EnumDescriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{8}
+}
+
+type BarcodeSymbology int32
+
+const (
+	// A QR code, which can hold any code.
+	BarcodeSymbology_BARCODE_SYMBOLOGY_UNSPECIFIED BarcodeSymbology = 0
+	// Code 128, for codes of ASCII characters.
+	BarcodeSymbology_BARCODE_SYMBOLOGY_CODE128 BarcodeSymbology = 1
+	// EAN-13, for codes of 12 digits, or 13 ending in the EAN check digit.
+	BarcodeSymbology_BARCODE_SYMBOLOGY_EAN13 BarcodeSymbology = 2
+)
+
+// Enum value maps for BarcodeSymbology.
+var (
+	BarcodeSymbology_name = map[int32]string{
+		0: "BARCODE_SYMBOLOGY_UNSPECIFIED",
+		1: "BARCODE_SYMBOLOGY_CODE128",
+		2: "BARCODE_SYMBOLOGY_EAN13",
+	}
+	BarcodeSymbology_value = map[string]int32{
+		"BARCODE_SYMBOLOGY_UNSPECIFIED": 0,
+		"BARCODE_SYMBOLOGY_CODE128":     1,
+		"BARCODE_SYMBOLOGY_EAN13":       2,
+	}
+)
+
+func (x BarcodeSymbology) Enum() *BarcodeSymbology {
+	p := new(BarcodeSymbology)
+	*p = x
+	return p
+}
+
+func (x BarcodeSymbology) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BarcodeSymbology) Descriptor() protoreflect.EnumDescriptor {
+	return file_coupon_v1_coupon_proto_enumTypes[9].Descriptor()
+}
+
+func (BarcodeSymbology) Type() protoreflect.EnumType {
+	return &file_coupon_v1_coupon_proto_enumTypes[9]
+}
+
+func (x BarcodeSymbology) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BarcodeSymbology.Descriptor instead.
+func (BarcodeSymbology) EnumDescriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{9}
+}
+
+type ImageFormat int32
+
+const (
+	// PNG.
+	ImageFormat_IMAGE_FORMAT_UNSPECIFIED ImageFormat = 0
+	ImageFormat_IMAGE_FORMAT_SVG         ImageFormat = 1
+)
+
+// Enum value maps for ImageFormat.
+var (
+	ImageFormat_name = map[int32]string{
+		0: "IMAGE_FORMAT_UNSPECIFIED",
+		1: "IMAGE_FORMAT_SVG",
+	}
+	ImageFormat_value = map[string]int32{
+		"IMAGE_FORMAT_UNSPECIFIED": 0,
+		"IMAGE_FORMAT_SVG":         1,
+	}
+)
+
+func (x ImageFormat) Enum() *ImageFormat {
+	p := new(ImageFormat)
+	*p = x
+	return p
+}
+
+func (x ImageFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImageFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_coupon_v1_coupon_proto_enumTypes[10].Descriptor()
+}
+
+func (ImageFormat) Type() protoreflect.EnumType {
+	return &file_coupon_v1_coupon_proto_enumTypes[10]
+}
+
+func (x ImageFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImageFormat.Descriptor instead.
+func (ImageFormat) EnumDescriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{10}
+}
+
 type CreateCampaignRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	StartTime   string                 `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	CouponLimit int32                  `protobuf:"varint,3,opt,name=coupon_limit,json=couponLimit,proto3" json:"coupon_limit,omitempty"`
+	// Optional eligibility expression, e.g.
+	// user.tier in ["gold", "vip"] && user.region == "KR"
+	Eligibility string `protobuf:"bytes,4,opt,name=eligibility,proto3" json:"eligibility,omitempty"`
+	// Optional absolute end of validity for issued coupons (RFC3339).
+	ValidUntil string `protobuf:"bytes,5,opt,name=valid_until,json=validUntil,proto3" json:"valid_until,omitempty"`
+	// Optional validity after issuance, e.g. "720h". When both are set a
+	// coupon expires at whichever comes first.
+	ValidityDuration string `protobuf:"bytes,6,opt,name=validity_duration,json=validityDuration,proto3" json:"validity_duration,omitempty"`
+	// Optional value of the campaign's coupons, used by ApplyCoupon.
+	Benefit *Benefit `protobuf:"bytes,7,opt,name=benefit,proto3" json:"benefit,omitempty"`
+	// How many times each coupon can be redeemed. Defaults to 1.
+	MaxRedemptions int32 `protobuf:"varint,8,opt,name=max_redemptions,json=maxRedemptions,proto3" json:"max_redemptions,omitempty"`
+	// Whether owners may transfer coupons to other users.
+	Transferable bool `protobuf:"varint,9,opt,name=transferable,proto3" json:"transferable,omitempty"`
+	// Optional cap on the unused coupons of the campaign a user may hold.
+	// Issuance and transfers that would exceed it are refused.
+	MaxHeldPerUser int32 `protobuf:"varint,10,opt,name=max_held_per_user,json=maxHeldPerUser,proto3" json:"max_held_per_user,omitempty"`
+	// Optional group of mutually exclusive campaigns; a basket can use one
+	// coupon per group.
+	ExclusivityGroup string         `protobuf:"bytes,11,opt,name=exclusivity_group,json=exclusivityGroup,proto3" json:"exclusivity_group,omitempty"`
+	StackingPolicy   StackingPolicy `protobuf:"varint,12,opt,name=stacking_policy,json=stackingPolicy,proto3,enum=coupon.v1.StackingPolicy" json:"stacking_policy,omitempty"`
+	// Higher priority coupons are applied first in a basket.
+	Priority int32 `protobuf:"varint,13,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Issue codes that carry an HMAC signature, so that they can be checked
+	// offline with the campaign's verification keys.
+	SignedCodes bool `protobuf:"varint,14,opt,name=signed_codes,json=signedCodes,proto3" json:"signed_codes,omitempty"`
+	// Optional shape of the campaign's codes; 10 random Hangul syllables and
+	// digits by default.
+	CodeFormat *CodeFormat `protobuf:"bytes,15,opt,name=code_format,json=codeFormat,proto3" json:"code_format,omitempty"`
+	// Derive codes from a sequence number with a keyed permutation of the
+	// code format's codes instead of drawing them at random. They are unique
+	// without being reserved in advance. Not combinable with signed_codes.
+	SequencedCodes bool `protobuf:"varint,16,opt,name=sequenced_codes,json=sequencedCodes,proto3" json:"sequenced_codes,omitempty"`
+	// Issue only codes supplied through ImportCodes. The coupon limit must be
+	// left unset; each import raises it by the number of codes imported.
+	// Not combinable with other kinds of codes or a code format.
+	ImportedCodes bool `protobuf:"varint,17,opt,name=imported_codes,json=importedCodes,proto3" json:"imported_codes,omitempty"`
+	// Optional single public code, such as "SUMMER24", shared by every user
+	// of the campaign. Issuing records a claim for the user instead of a new
+	// code; each user can claim and redeem it once, up to coupon_limit users.
+	// Not combinable with other kinds of codes, a code format or transfers.
+	VanityCode    string `protobuf:"bytes,18,opt,name=vanity_code,json=vanityCode,proto3" json:"vanity_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCampaignRequest) Reset() {
+	*x = CreateCampaignRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCampaignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCampaignRequest) ProtoMessage() {}
+
+func (x *CreateCampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCampaignRequest.ProtoReflect.Descriptor instead.
+func (*CreateCampaignRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateCampaignRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetCouponLimit() int32 {
+	if x != nil {
+		return x.CouponLimit
+	}
+	return 0
+}
+
+func (x *CreateCampaignRequest) GetEligibility() string {
+	if x != nil {
+		return x.Eligibility
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetValidUntil() string {
+	if x != nil {
+		return x.ValidUntil
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetValidityDuration() string {
+	if x != nil {
+		return x.ValidityDuration
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetBenefit() *Benefit {
+	if x != nil {
+		return x.Benefit
+	}
+	return nil
+}
+
+func (x *CreateCampaignRequest) GetMaxRedemptions() int32 {
+	if x != nil {
+		return x.MaxRedemptions
+	}
+	return 0
+}
+
+func (x *CreateCampaignRequest) GetTransferable() bool {
+	if x != nil {
+		return x.Transferable
+	}
+	return false
+}
+
+func (x *CreateCampaignRequest) GetMaxHeldPerUser() int32 {
+	if x != nil {
+		return x.MaxHeldPerUser
+	}
+	return 0
+}
+
+func (x *CreateCampaignRequest) GetExclusivityGroup() string {
+	if x != nil {
+		return x.ExclusivityGroup
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetStackingPolicy() StackingPolicy {
+	if x != nil {
+		return x.StackingPolicy
+	}
+	return StackingPolicy_STACKING_POLICY_UNSPECIFIED
+}
+
+func (x *CreateCampaignRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *CreateCampaignRequest) GetSignedCodes() bool {
+	if x != nil {
+		return x.SignedCodes
+	}
+	return false
+}
+
+func (x *CreateCampaignRequest) GetCodeFormat() *CodeFormat {
+	if x != nil {
+		return x.CodeFormat
+	}
+	return nil
+}
+
+func (x *CreateCampaignRequest) GetSequencedCodes() bool {
+	if x != nil {
+		return x.SequencedCodes
+	}
+	return false
+}
+
+func (x *CreateCampaignRequest) GetImportedCodes() bool {
+	if x != nil {
+		return x.ImportedCodes
+	}
+	return false
+}
+
+func (x *CreateCampaignRequest) GetVanityCode() string {
+	if x != nil {
+		return x.VanityCode
+	}
+	return ""
+}
+
+type CreateCampaignResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CampaignId    string                 `protobuf:"bytes,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCampaignResponse) Reset() {
+	*x = CreateCampaignResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCampaignResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCampaignResponse) ProtoMessage() {}
+
+func (x *CreateCampaignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCampaignResponse.ProtoReflect.Descriptor instead.
+func (*CreateCampaignResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateCampaignResponse) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+type GetCampaignRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CampaignId    string                 `protobuf:"bytes,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCampaignRequest) Reset() {
+	*x = GetCampaignRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCampaignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCampaignRequest) ProtoMessage() {}
+
+func (x *GetCampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCampaignRequest.ProtoReflect.Descriptor instead.
+func (*GetCampaignRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetCampaignRequest) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+type GetCampaignResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	StartTime        string                 `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	Status           string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	IssuedCoupons    []string               `protobuf:"bytes,4,rep,name=issued_coupons,json=issuedCoupons,proto3" json:"issued_coupons,omitempty"`
+	Eligibility      string                 `protobuf:"bytes,5,opt,name=eligibility,proto3" json:"eligibility,omitempty"`
+	ValidUntil       string                 `protobuf:"bytes,6,opt,name=valid_until,json=validUntil,proto3" json:"valid_until,omitempty"`
+	ValidityDuration string                 `protobuf:"bytes,7,opt,name=validity_duration,json=validityDuration,proto3" json:"validity_duration,omitempty"`
+	Benefit          *Benefit               `protobuf:"bytes,8,opt,name=benefit,proto3" json:"benefit,omitempty"`
+	MaxRedemptions   int32                  `protobuf:"varint,9,opt,name=max_redemptions,json=maxRedemptions,proto3" json:"max_redemptions,omitempty"`
+	Transferable     bool                   `protobuf:"varint,10,opt,name=transferable,proto3" json:"transferable,omitempty"`
+	MaxHeldPerUser   int32                  `protobuf:"varint,11,opt,name=max_held_per_user,json=maxHeldPerUser,proto3" json:"max_held_per_user,omitempty"`
+	ExclusivityGroup string                 `protobuf:"bytes,12,opt,name=exclusivity_group,json=exclusivityGroup,proto3" json:"exclusivity_group,omitempty"`
+	StackingPolicy   StackingPolicy         `protobuf:"varint,13,opt,name=stacking_policy,json=stackingPolicy,proto3,enum=coupon.v1.StackingPolicy" json:"stacking_policy,omitempty"`
+	Priority         int32                  `protobuf:"varint,14,opt,name=priority,proto3" json:"priority,omitempty"`
+	SignedCodes      bool                   `protobuf:"varint,15,opt,name=signed_codes,json=signedCodes,proto3" json:"signed_codes,omitempty"`
+	CodeFormat       *CodeFormat            `protobuf:"bytes,16,opt,name=code_format,json=codeFormat,proto3" json:"code_format,omitempty"`
+	SequencedCodes   bool                   `protobuf:"varint,17,opt,name=sequenced_codes,json=sequencedCodes,proto3" json:"sequenced_codes,omitempty"`
+	ImportedCodes    bool                   `protobuf:"varint,18,opt,name=imported_codes,json=importedCodes,proto3" json:"imported_codes,omitempty"`
+	CouponLimit      int32                  `protobuf:"varint,19,opt,name=coupon_limit,json=couponLimit,proto3" json:"coupon_limit,omitempty"`
+	VanityCode       string                 `protobuf:"bytes,20,opt,name=vanity_code,json=vanityCode,proto3" json:"vanity_code,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetCampaignResponse) Reset() {
+	*x = GetCampaignResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCampaignResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCampaignResponse) ProtoMessage() {}
+
+func (x *GetCampaignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCampaignResponse.ProtoReflect.Descriptor instead.
+func (*GetCampaignResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetCampaignResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GetCampaignResponse) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *GetCampaignResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GetCampaignResponse) GetIssuedCoupons() []string {
+	if x != nil {
+		return x.IssuedCoupons
+	}
+	return nil
+}
+
+func (x *GetCampaignResponse) GetEligibility() string {
+	if x != nil {
+		return x.Eligibility
+	}
+	return ""
+}
+
+func (x *GetCampaignResponse) GetValidUntil() string {
+	if x != nil {
+		return x.ValidUntil
+	}
+	return ""
+}
+
+func (x *GetCampaignResponse) GetValidityDuration() string {
+	if x != nil {
+		return x.ValidityDuration
+	}
+	return ""
+}
+
+func (x *GetCampaignResponse) GetBenefit() *Benefit {
+	if x != nil {
+		return x.Benefit
+	}
+	return nil
+}
+
+func (x *GetCampaignResponse) GetMaxRedemptions() int32 {
+	if x != nil {
+		return x.MaxRedemptions
+	}
+	return 0
+}
+
+func (x *GetCampaignResponse) GetTransferable() bool {
+	if x != nil {
+		return x.Transferable
+	}
+	return false
+}
+
+func (x *GetCampaignResponse) GetMaxHeldPerUser() int32 {
+	if x != nil {
+		return x.MaxHeldPerUser
+	}
+	return 0
+}
+
+func (x *GetCampaignResponse) GetExclusivityGroup() string {
+	if x != nil {
+		return x.ExclusivityGroup
+	}
+	return ""
+}
+
+func (x *GetCampaignResponse) GetStackingPolicy() StackingPolicy {
+	if x != nil {
+		return x.StackingPolicy
+	}
+	return StackingPolicy_STACKING_POLICY_UNSPECIFIED
+}
+
+func (x *GetCampaignResponse) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *GetCampaignResponse) GetSignedCodes() bool {
+	if x != nil {
+		return x.SignedCodes
+	}
+	return false
+}
+
+func (x *GetCampaignResponse) GetCodeFormat() *CodeFormat {
+	if x != nil {
+		return x.CodeFormat
+	}
+	return nil
+}
+
+func (x *GetCampaignResponse) GetSequencedCodes() bool {
+	if x != nil {
+		return x.SequencedCodes
+	}
+	return false
+}
+
+func (x *GetCampaignResponse) GetImportedCodes() bool {
+	if x != nil {
+		return x.ImportedCodes
+	}
+	return false
+}
+
+func (x *GetCampaignResponse) GetCouponLimit() int32 {
+	if x != nil {
+		return x.CouponLimit
+	}
+	return 0
+}
+
+func (x *GetCampaignResponse) GetVanityCode() string {
+	if x != nil {
+		return x.VanityCode
+	}
+	return ""
+}
+
+type CodeFormat struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Alphabet CodeAlphabet           `protobuf:"varint,1,opt,name=alphabet,proto3,enum=coupon.v1.CodeAlphabet" json:"alphabet,omitempty"`
+	// Replaces alphabet when set.
+	CustomAlphabet string `protobuf:"bytes,2,opt,name=custom_alphabet,json=customAlphabet,proto3" json:"custom_alphabet,omitempty"`
+	// Number of random characters, 10 by default. Not used with pattern.
+	Length int32 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	// Prepended to every code.
+	Prefix string `protobuf:"bytes,4,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Template such as "SALE-####-@@@@": # is a digit, @ a letter and * any
+	// character of the alphabet; \ makes the next character literal.
+	Pattern string `protobuf:"bytes,5,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// Optional check character appended to every code.
+	CheckCharacter CheckCharacter `protobuf:"varint,6,opt,name=check_character,json=checkCharacter,proto3,enum=coupon.v1.CheckCharacter" json:"check_character,omitempty"`
+	// Restricts Hangul syllables to the 2,350 of KS X 1001, for the default
+	// and HANGUL alphabets.
+	CommonHangul  bool `protobuf:"varint,7,opt,name=common_hangul,json=commonHangul,proto3" json:"common_hangul,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CodeFormat) Reset() {
+	*x = CodeFormat{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CodeFormat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CodeFormat) ProtoMessage() {}
+
+func (x *CodeFormat) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CodeFormat.ProtoReflect.Descriptor instead.
+func (*CodeFormat) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CodeFormat) GetAlphabet() CodeAlphabet {
+	if x != nil {
+		return x.Alphabet
+	}
+	return CodeAlphabet_CODE_ALPHABET_UNSPECIFIED
+}
+
+func (x *CodeFormat) GetCustomAlphabet() string {
+	if x != nil {
+		return x.CustomAlphabet
+	}
+	return ""
+}
+
+func (x *CodeFormat) GetLength() int32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *CodeFormat) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *CodeFormat) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *CodeFormat) GetCheckCharacter() CheckCharacter {
+	if x != nil {
+		return x.CheckCharacter
+	}
+	return CheckCharacter_CHECK_CHARACTER_UNSPECIFIED
+}
+
+func (x *CodeFormat) GetCommonHangul() bool {
+	if x != nil {
+		return x.CommonHangul
+	}
+	return false
+}
+
+// Amounts are decimal strings such as "12.50" and are never floats.
+type Benefit struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Benefit_PercentOff
+	//	*Benefit_FixedAmount
+	//	*Benefit_FreeItem
+	Kind isBenefit_Kind `protobuf_oneof:"kind"`
+	// ISO 4217 currency of every amount in the benefit. Required when any
+	// amount is set.
+	Currency string `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	// Optional minimum cart subtotal.
+	MinOrderAmount string `protobuf:"bytes,5,opt,name=min_order_amount,json=minOrderAmount,proto3" json:"min_order_amount,omitempty"`
+	// Restricts percent_off and fixed_amount to items in these categories.
+	EligibleCategories []string `protobuf:"bytes,6,rep,name=eligible_categories,json=eligibleCategories,proto3" json:"eligible_categories,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Benefit) Reset() {
+	*x = Benefit{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Benefit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Benefit) ProtoMessage() {}
+
+func (x *Benefit) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Benefit.ProtoReflect.Descriptor instead.
+func (*Benefit) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Benefit) GetKind() isBenefit_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Benefit) GetPercentOff() *PercentOff {
+	if x != nil {
+		if x, ok := x.Kind.(*Benefit_PercentOff); ok {
+			return x.PercentOff
+		}
+	}
+	return nil
+}
+
+func (x *Benefit) GetFixedAmount() *FixedAmount {
+	if x != nil {
+		if x, ok := x.Kind.(*Benefit_FixedAmount); ok {
+			return x.FixedAmount
+		}
+	}
+	return nil
+}
+
+func (x *Benefit) GetFreeItem() *FreeItem {
+	if x != nil {
+		if x, ok := x.Kind.(*Benefit_FreeItem); ok {
+			return x.FreeItem
+		}
+	}
+	return nil
+}
+
+func (x *Benefit) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Benefit) GetMinOrderAmount() string {
+	if x != nil {
+		return x.MinOrderAmount
+	}
+	return ""
+}
+
+func (x *Benefit) GetEligibleCategories() []string {
+	if x != nil {
+		return x.EligibleCategories
+	}
+	return nil
+}
+
+type isBenefit_Kind interface {
+	isBenefit_Kind()
+}
+
+type Benefit_PercentOff struct {
+	PercentOff *PercentOff `protobuf:"bytes,1,opt,name=percent_off,json=percentOff,proto3,oneof"`
+}
+
+type Benefit_FixedAmount struct {
+	FixedAmount *FixedAmount `protobuf:"bytes,2,opt,name=fixed_amount,json=fixedAmount,proto3,oneof"`
+}
+
+type Benefit_FreeItem struct {
+	FreeItem *FreeItem `protobuf:"bytes,3,opt,name=free_item,json=freeItem,proto3,oneof"`
+}
+
+func (*Benefit_PercentOff) isBenefit_Kind() {}
+
+func (*Benefit_FixedAmount) isBenefit_Kind() {}
+
+func (*Benefit_FreeItem) isBenefit_Kind() {}
+
+type PercentOff struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// e.g. "15" or "12.5"
+	Percent string `protobuf:"bytes,1,opt,name=percent,proto3" json:"percent,omitempty"`
+	// Optional cap on the discount.
+	MaxDiscount   string `protobuf:"bytes,2,opt,name=max_discount,json=maxDiscount,proto3" json:"max_discount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PercentOff) Reset() {
+	*x = PercentOff{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PercentOff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PercentOff) ProtoMessage() {}
+
+func (x *PercentOff) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PercentOff.ProtoReflect.Descriptor instead.
+func (*PercentOff) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PercentOff) GetPercent() string {
+	if x != nil {
+		return x.Percent
+	}
+	return ""
+}
+
+func (x *PercentOff) GetMaxDiscount() string {
+	if x != nil {
+		return x.MaxDiscount
+	}
+	return ""
+}
+
+type FixedAmount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amount        string                 `protobuf:"bytes,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FixedAmount) Reset() {
+	*x = FixedAmount{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FixedAmount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FixedAmount) ProtoMessage() {}
+
+func (x *FixedAmount) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FixedAmount.ProtoReflect.Descriptor instead.
+func (*FixedAmount) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FixedAmount) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+type FreeItem struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One unit of this SKU is free.
+	Sku           string `protobuf:"bytes,1,opt,name=sku,proto3" json:"sku,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FreeItem) Reset() {
+	*x = FreeItem{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FreeItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FreeItem) ProtoMessage() {}
+
+func (x *FreeItem) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FreeItem.ProtoReflect.Descriptor instead.
+func (*FreeItem) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FreeItem) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+type UserAttributes struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Tier   string                 `protobuf:"bytes,1,opt,name=tier,proto3" json:"tier,omitempty"`
+	Region string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	// RFC3339 timestamp or YYYY-MM-DD
+	SignupDate    string `protobuf:"bytes,3,opt,name=signup_date,json=signupDate,proto3" json:"signup_date,omitempty"`
+	AppVersion    string `protobuf:"bytes,4,opt,name=app_version,json=appVersion,proto3" json:"app_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserAttributes) Reset() {
+	*x = UserAttributes{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserAttributes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserAttributes) ProtoMessage() {}
+
+func (x *UserAttributes) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserAttributes.ProtoReflect.Descriptor instead.
+func (*UserAttributes) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UserAttributes) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+func (x *UserAttributes) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *UserAttributes) GetSignupDate() string {
+	if x != nil {
+		return x.SignupDate
+	}
+	return ""
+}
+
+func (x *UserAttributes) GetAppVersion() string {
+	if x != nil {
+		return x.AppVersion
+	}
+	return ""
+}
+
+type IssueCouponRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	CampaignId string                 `protobuf:"bytes,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	Attributes *UserAttributes        `protobuf:"bytes,2,opt,name=attributes,proto3" json:"attributes,omitempty"`
+	// Optional owner of the issued coupon.
+	UserId        string `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueCouponRequest) Reset() {
+	*x = IssueCouponRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueCouponRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueCouponRequest) ProtoMessage() {}
+
+func (x *IssueCouponRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueCouponRequest.ProtoReflect.Descriptor instead.
+func (*IssueCouponRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *IssueCouponRequest) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *IssueCouponRequest) GetAttributes() *UserAttributes {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *IssueCouponRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type IssueCouponResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CouponCode    string                 `protobuf:"bytes,1,opt,name=coupon_code,json=couponCode,proto3" json:"coupon_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueCouponResponse) Reset() {
+	*x = IssueCouponResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueCouponResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueCouponResponse) ProtoMessage() {}
+
+func (x *IssueCouponResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueCouponResponse.ProtoReflect.Descriptor instead.
+func (*IssueCouponResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *IssueCouponResponse) GetCouponCode() string {
+	if x != nil {
+		return x.CouponCode
+	}
+	return ""
+}
+
+type IssueCouponStreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Echoed back on the matching response. Responses may arrive out of order.
+	CorrelationId string              `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	Request       *IssueCouponRequest `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueCouponStreamRequest) Reset() {
+	*x = IssueCouponStreamRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueCouponStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueCouponStreamRequest) ProtoMessage() {}
+
+func (x *IssueCouponStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueCouponStreamRequest.ProtoReflect.Descriptor instead.
+func (*IssueCouponStreamRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *IssueCouponStreamRequest) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *IssueCouponStreamRequest) GetRequest() *IssueCouponRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+type IssueCouponStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*IssueCouponStreamResponse_CouponCode
+	//	*IssueCouponStreamResponse_Error
+	Result        isIssueCouponStreamResponse_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueCouponStreamResponse) Reset() {
+	*x = IssueCouponStreamResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueCouponStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueCouponStreamResponse) ProtoMessage() {}
+
+func (x *IssueCouponStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueCouponStreamResponse.ProtoReflect.Descriptor instead.
+func (*IssueCouponStreamResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *IssueCouponStreamResponse) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *IssueCouponStreamResponse) GetResult() isIssueCouponStreamResponse_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *IssueCouponStreamResponse) GetCouponCode() string {
+	if x != nil {
+		if x, ok := x.Result.(*IssueCouponStreamResponse_CouponCode); ok {
+			return x.CouponCode
+		}
+	}
+	return ""
+}
+
+func (x *IssueCouponStreamResponse) GetError() *IssueCouponError {
+	if x != nil {
+		if x, ok := x.Result.(*IssueCouponStreamResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isIssueCouponStreamResponse_Result interface {
+	isIssueCouponStreamResponse_Result()
+}
+
+type IssueCouponStreamResponse_CouponCode struct {
+	CouponCode string `protobuf:"bytes,2,opt,name=coupon_code,json=couponCode,proto3,oneof"`
+}
+
+type IssueCouponStreamResponse_Error struct {
+	Error *IssueCouponError `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*IssueCouponStreamResponse_CouponCode) isIssueCouponStreamResponse_Result() {}
+
+func (*IssueCouponStreamResponse_Error) isIssueCouponStreamResponse_Result() {}
+
+type IssueCouponError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Connect error code, e.g. "resource_exhausted".
+	Code    string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Set when the request was denied by the campaign's eligibility rule.
+	EligibilityDenial *EligibilityDenial `protobuf:"bytes,3,opt,name=eligibility_denial,json=eligibilityDenial,proto3" json:"eligibility_denial,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *IssueCouponError) Reset() {
+	*x = IssueCouponError{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueCouponError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueCouponError) ProtoMessage() {}
+
+func (x *IssueCouponError) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueCouponError.ProtoReflect.Descriptor instead.
+func (*IssueCouponError) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *IssueCouponError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *IssueCouponError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *IssueCouponError) GetEligibilityDenial() *EligibilityDenial {
+	if x != nil {
+		return x.EligibilityDenial
+	}
+	return nil
+}
+
+type RedeemCouponRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Must match the coupon's owner when the coupon has one.
+	UserId        string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	OrderRef      string `protobuf:"bytes,3,opt,name=order_ref,json=orderRef,proto3" json:"order_ref,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedeemCouponRequest) Reset() {
+	*x = RedeemCouponRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedeemCouponRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedeemCouponRequest) ProtoMessage() {}
+
+func (x *RedeemCouponRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedeemCouponRequest.ProtoReflect.Descriptor instead.
+func (*RedeemCouponRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RedeemCouponRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *RedeemCouponRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RedeemCouponRequest) GetOrderRef() string {
+	if x != nil {
+		return x.OrderRef
+	}
+	return ""
+}
+
+type RedeemCouponResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Code       string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	CampaignId string                 `protobuf:"bytes,2,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	State      string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	RedeemedAt string                 `protobuf:"bytes,4,opt,name=redeemed_at,json=redeemedAt,proto3" json:"redeemed_at,omitempty"`
+	OrderRef   string                 `protobuf:"bytes,5,opt,name=order_ref,json=orderRef,proto3" json:"order_ref,omitempty"`
+	// Redemptions made so far, including this one.
+	RedemptionCount int32 `protobuf:"varint,6,opt,name=redemption_count,json=redemptionCount,proto3" json:"redemption_count,omitempty"`
+	MaxRedemptions  int32 `protobuf:"varint,7,opt,name=max_redemptions,json=maxRedemptions,proto3" json:"max_redemptions,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RedeemCouponResponse) Reset() {
+	*x = RedeemCouponResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedeemCouponResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedeemCouponResponse) ProtoMessage() {}
+
+func (x *RedeemCouponResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedeemCouponResponse.ProtoReflect.Descriptor instead.
+func (*RedeemCouponResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RedeemCouponResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *RedeemCouponResponse) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *RedeemCouponResponse) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *RedeemCouponResponse) GetRedeemedAt() string {
+	if x != nil {
+		return x.RedeemedAt
+	}
+	return ""
+}
+
+func (x *RedeemCouponResponse) GetOrderRef() string {
+	if x != nil {
+		return x.OrderRef
+	}
+	return ""
+}
+
+func (x *RedeemCouponResponse) GetRedemptionCount() int32 {
+	if x != nil {
+		return x.RedemptionCount
+	}
+	return 0
+}
+
+func (x *RedeemCouponResponse) GetMaxRedemptions() int32 {
+	if x != nil {
+		return x.MaxRedemptions
+	}
+	return 0
+}
+
+type LockCouponRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Identifies the checkout holding the lock. Locking again with the same
+	// checkout extends the lock.
+	CheckoutId string `protobuf:"bytes,2,opt,name=checkout_id,json=checkoutId,proto3" json:"checkout_id,omitempty"`
+	// How long the lock is held unless committed or released, e.g. "5m".
+	// Defaults to 5m, at most 1h.
+	Ttl string `protobuf:"bytes,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// Must match the coupon's owner when the coupon has one.
+	UserId        string `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockCouponRequest) Reset() {
+	*x = LockCouponRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockCouponRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockCouponRequest) ProtoMessage() {}
+
+func (x *LockCouponRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockCouponRequest.ProtoReflect.Descriptor instead.
+func (*LockCouponRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *LockCouponRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *LockCouponRequest) GetCheckoutId() string {
+	if x != nil {
+		return x.CheckoutId
+	}
+	return ""
+}
+
+func (x *LockCouponRequest) GetTtl() string {
+	if x != nil {
+		return x.Ttl
+	}
+	return ""
+}
+
+func (x *LockCouponRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type LockCouponResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	CheckoutId    string                 `protobuf:"bytes,2,opt,name=checkout_id,json=checkoutId,proto3" json:"checkout_id,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockCouponResponse) Reset() {
+	*x = LockCouponResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockCouponResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockCouponResponse) ProtoMessage() {}
+
+func (x *LockCouponResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockCouponResponse.ProtoReflect.Descriptor instead.
+func (*LockCouponResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *LockCouponResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *LockCouponResponse) GetCheckoutId() string {
+	if x != nil {
+		return x.CheckoutId
+	}
+	return ""
+}
+
+func (x *LockCouponResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type CommitRedemptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	CheckoutId    string                 `protobuf:"bytes,2,opt,name=checkout_id,json=checkoutId,proto3" json:"checkout_id,omitempty"`
+	OrderRef      string                 `protobuf:"bytes,3,opt,name=order_ref,json=orderRef,proto3" json:"order_ref,omitempty"`
+	UserId        string                 `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitRedemptionRequest) Reset() {
+	*x = CommitRedemptionRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitRedemptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitRedemptionRequest) ProtoMessage() {}
+
+func (x *CommitRedemptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitRedemptionRequest.ProtoReflect.Descriptor instead.
+func (*CommitRedemptionRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CommitRedemptionRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *CommitRedemptionRequest) GetCheckoutId() string {
+	if x != nil {
+		return x.CheckoutId
+	}
+	return ""
+}
+
+func (x *CommitRedemptionRequest) GetOrderRef() string {
+	if x != nil {
+		return x.OrderRef
+	}
+	return ""
+}
+
+func (x *CommitRedemptionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ReleaseLockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	CheckoutId    string                 `protobuf:"bytes,2,opt,name=checkout_id,json=checkoutId,proto3" json:"checkout_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseLockRequest) Reset() {
+	*x = ReleaseLockRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseLockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseLockRequest) ProtoMessage() {}
+
+func (x *ReleaseLockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseLockRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseLockRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ReleaseLockRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ReleaseLockRequest) GetCheckoutId() string {
+	if x != nil {
+		return x.CheckoutId
+	}
+	return ""
+}
+
+type ReleaseLockResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False if the checkout did not hold the lock, e.g. because it expired.
+	Released      bool `protobuf:"varint,1,opt,name=released,proto3" json:"released,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseLockResponse) Reset() {
+	*x = ReleaseLockResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseLockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseLockResponse) ProtoMessage() {}
+
+func (x *ReleaseLockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseLockResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseLockResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ReleaseLockResponse) GetReleased() bool {
+	if x != nil {
+		return x.Released
+	}
+	return false
+}
+
+type SetCouponRedemptionLimitRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Overrides the campaign's max_redemptions for this code.
+	MaxRedemptions int32 `protobuf:"varint,2,opt,name=max_redemptions,json=maxRedemptions,proto3" json:"max_redemptions,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SetCouponRedemptionLimitRequest) Reset() {
+	*x = SetCouponRedemptionLimitRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetCouponRedemptionLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetCouponRedemptionLimitRequest) ProtoMessage() {}
+
+func (x *SetCouponRedemptionLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetCouponRedemptionLimitRequest.ProtoReflect.Descriptor instead.
+func (*SetCouponRedemptionLimitRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SetCouponRedemptionLimitRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *SetCouponRedemptionLimitRequest) GetMaxRedemptions() int32 {
+	if x != nil {
+		return x.MaxRedemptions
+	}
+	return 0
+}
+
+type SetCouponRedemptionLimitResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Code            string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	RedemptionCount int32                  `protobuf:"varint,2,opt,name=redemption_count,json=redemptionCount,proto3" json:"redemption_count,omitempty"`
+	MaxRedemptions  int32                  `protobuf:"varint,3,opt,name=max_redemptions,json=maxRedemptions,proto3" json:"max_redemptions,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SetCouponRedemptionLimitResponse) Reset() {
+	*x = SetCouponRedemptionLimitResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetCouponRedemptionLimitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetCouponRedemptionLimitResponse) ProtoMessage() {}
+
+func (x *SetCouponRedemptionLimitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetCouponRedemptionLimitResponse.ProtoReflect.Descriptor instead.
+func (*SetCouponRedemptionLimitResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SetCouponRedemptionLimitResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *SetCouponRedemptionLimitResponse) GetRedemptionCount() int32 {
+	if x != nil {
+		return x.RedemptionCount
+	}
+	return 0
+}
+
+func (x *SetCouponRedemptionLimitResponse) GetMaxRedemptions() int32 {
+	if x != nil {
+		return x.MaxRedemptions
+	}
+	return 0
+}
+
+type ReverseRedemptionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// The order whose redemption is reversed, e.g. because it was refunded.
+	OrderRef      string `protobuf:"bytes,2,opt,name=order_ref,json=orderRef,proto3" json:"order_ref,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReverseRedemptionRequest) Reset() {
+	*x = ReverseRedemptionRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseRedemptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseRedemptionRequest) ProtoMessage() {}
+
+func (x *ReverseRedemptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseRedemptionRequest.ProtoReflect.Descriptor instead.
+func (*ReverseRedemptionRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ReverseRedemptionRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ReverseRedemptionRequest) GetOrderRef() string {
+	if x != nil {
+		return x.OrderRef
+	}
+	return ""
+}
+
+type ReverseRedemptionResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Code            string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	CampaignId      string                 `protobuf:"bytes,2,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	State           string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	OrderRef        string                 `protobuf:"bytes,4,opt,name=order_ref,json=orderRef,proto3" json:"order_ref,omitempty"`
+	ReversedAt      string                 `protobuf:"bytes,5,opt,name=reversed_at,json=reversedAt,proto3" json:"reversed_at,omitempty"`
+	RedemptionCount int32                  `protobuf:"varint,6,opt,name=redemption_count,json=redemptionCount,proto3" json:"redemption_count,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ReverseRedemptionResponse) Reset() {
+	*x = ReverseRedemptionResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseRedemptionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseRedemptionResponse) ProtoMessage() {}
+
+func (x *ReverseRedemptionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseRedemptionResponse.ProtoReflect.Descriptor instead.
+func (*ReverseRedemptionResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ReverseRedemptionResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ReverseRedemptionResponse) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *ReverseRedemptionResponse) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ReverseRedemptionResponse) GetOrderRef() string {
+	if x != nil {
+		return x.OrderRef
+	}
+	return ""
+}
+
+func (x *ReverseRedemptionResponse) GetReversedAt() string {
+	if x != nil {
+		return x.ReversedAt
+	}
+	return ""
+}
+
+func (x *ReverseRedemptionResponse) GetRedemptionCount() int32 {
+	if x != nil {
+		return x.RedemptionCount
+	}
+	return 0
+}
+
+type TransferCouponRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Must be the coupon's current owner.
+	FromUserId    string `protobuf:"bytes,2,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`
+	ToUserId      string `protobuf:"bytes,3,opt,name=to_user_id,json=toUserId,proto3" json:"to_user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferCouponRequest) Reset() {
+	*x = TransferCouponRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferCouponRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferCouponRequest) ProtoMessage() {}
+
+func (x *TransferCouponRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferCouponRequest.ProtoReflect.Descriptor instead.
+func (*TransferCouponRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *TransferCouponRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *TransferCouponRequest) GetFromUserId() string {
+	if x != nil {
+		return x.FromUserId
+	}
+	return ""
+}
+
+func (x *TransferCouponRequest) GetToUserId() string {
+	if x != nil {
+		return x.ToUserId
+	}
+	return ""
+}
+
+type TransferCouponResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	CampaignId    string                 `protobuf:"bytes,2,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	OwnerUserId   string                 `protobuf:"bytes,3,opt,name=owner_user_id,json=ownerUserId,proto3" json:"owner_user_id,omitempty"`
+	TransferredAt string                 `protobuf:"bytes,4,opt,name=transferred_at,json=transferredAt,proto3" json:"transferred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferCouponResponse) Reset() {
+	*x = TransferCouponResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferCouponResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferCouponResponse) ProtoMessage() {}
+
+func (x *TransferCouponResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferCouponResponse.ProtoReflect.Descriptor instead.
+func (*TransferCouponResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *TransferCouponResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *TransferCouponResponse) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *TransferCouponResponse) GetOwnerUserId() string {
+	if x != nil {
+		return x.OwnerUserId
+	}
+	return ""
+}
+
+func (x *TransferCouponResponse) GetTransferredAt() string {
+	if x != nil {
+		return x.TransferredAt
+	}
+	return ""
+}
+
+type ListUserCouponsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Only return coupons in these states, e.g. ["issued"]. Coupons past their
+	// expiry are in the expired state. Empty returns all states.
+	States []string `protobuf:"bytes,2,rep,name=states,proto3" json:"states,omitempty"`
+	// Defaults to 20, at most 100.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// From a previous response's next_page_token.
+	PageToken     string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUserCouponsRequest) Reset() {
+	*x = ListUserCouponsRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUserCouponsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUserCouponsRequest) ProtoMessage() {}
+
+func (x *ListUserCouponsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUserCouponsRequest.ProtoReflect.Descriptor instead.
+func (*ListUserCouponsRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListUserCouponsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListUserCouponsRequest) GetStates() []string {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+func (x *ListUserCouponsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListUserCouponsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type UserCoupon struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Code         string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	CampaignId   string                 `protobuf:"bytes,2,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	CampaignName string                 `protobuf:"bytes,3,opt,name=campaign_name,json=campaignName,proto3" json:"campaign_name,omitempty"`
+	State        string                 `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
+	IssuedAt     string                 `protobuf:"bytes,5,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	// RFC3339; empty when the coupon does not expire.
+	ExpiresAt string   `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Benefit   *Benefit `protobuf:"bytes,7,opt,name=benefit,proto3" json:"benefit,omitempty"`
+	// Human readable, e.g. "10% off up to 5000 KRW on drinks".
+	BenefitSummary  string `protobuf:"bytes,8,opt,name=benefit_summary,json=benefitSummary,proto3" json:"benefit_summary,omitempty"`
+	RedemptionCount int32  `protobuf:"varint,9,opt,name=redemption_count,json=redemptionCount,proto3" json:"redemption_count,omitempty"`
+	MaxRedemptions  int32  `protobuf:"varint,10,opt,name=max_redemptions,json=maxRedemptions,proto3" json:"max_redemptions,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UserCoupon) Reset() {
+	*x = UserCoupon{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserCoupon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserCoupon) ProtoMessage() {}
+
+func (x *UserCoupon) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserCoupon.ProtoReflect.Descriptor instead.
+func (*UserCoupon) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *UserCoupon) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *UserCoupon) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *UserCoupon) GetCampaignName() string {
+	if x != nil {
+		return x.CampaignName
+	}
+	return ""
+}
+
+func (x *UserCoupon) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *UserCoupon) GetIssuedAt() string {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return ""
+}
+
+func (x *UserCoupon) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *UserCoupon) GetBenefit() *Benefit {
+	if x != nil {
+		return x.Benefit
+	}
+	return nil
+}
+
+func (x *UserCoupon) GetBenefitSummary() string {
+	if x != nil {
+		return x.BenefitSummary
+	}
+	return ""
+}
+
+func (x *UserCoupon) GetRedemptionCount() int32 {
+	if x != nil {
+		return x.RedemptionCount
+	}
+	return 0
+}
+
+func (x *UserCoupon) GetMaxRedemptions() int32 {
+	if x != nil {
+		return x.MaxRedemptions
+	}
+	return 0
+}
+
+type ListUserCouponsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Most recently issued first.
+	Coupons []*UserCoupon `protobuf:"bytes,1,rep,name=coupons,proto3" json:"coupons,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUserCouponsResponse) Reset() {
+	*x = ListUserCouponsResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUserCouponsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUserCouponsResponse) ProtoMessage() {}
+
+func (x *ListUserCouponsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUserCouponsResponse.ProtoReflect.Descriptor instead.
+func (*ListUserCouponsResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListUserCouponsResponse) GetCoupons() []*UserCoupon {
+	if x != nil {
+		return x.Coupons
+	}
+	return nil
+}
+
+func (x *ListUserCouponsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetCouponHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCouponHistoryRequest) Reset() {
+	*x = GetCouponHistoryRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCouponHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCouponHistoryRequest) ProtoMessage() {}
+
+func (x *GetCouponHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCouponHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetCouponHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetCouponHistoryRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type CouponEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// e.g. "revoked", "redemption_reversed" or "transferred"
+	Event     string `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	FromState string `protobuf:"bytes,2,opt,name=from_state,json=fromState,proto3" json:"from_state,omitempty"`
+	ToState   string `protobuf:"bytes,3,opt,name=to_state,json=toState,proto3" json:"to_state,omitempty"`
+	Reason    string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Event specific data as a JSON object.
+	Details       string `protobuf:"bytes,5,opt,name=details,proto3" json:"details,omitempty"`
+	CreatedAt     string `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CouponEvent) Reset() {
+	*x = CouponEvent{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CouponEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CouponEvent) ProtoMessage() {}
+
+func (x *CouponEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CouponEvent.ProtoReflect.Descriptor instead.
+func (*CouponEvent) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *CouponEvent) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *CouponEvent) GetFromState() string {
+	if x != nil {
+		return x.FromState
+	}
+	return ""
+}
+
+func (x *CouponEvent) GetToState() string {
+	if x != nil {
+		return x.ToState
+	}
+	return ""
+}
+
+func (x *CouponEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CouponEvent) GetDetails() string {
+	if x != nil {
+		return x.Details
+	}
+	return ""
+}
+
+func (x *CouponEvent) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type GetCouponHistoryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Oldest first.
+	Events        []*CouponEvent `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCouponHistoryResponse) Reset() {
+	*x = GetCouponHistoryResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCouponHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCouponHistoryResponse) ProtoMessage() {}
+
+func (x *GetCouponHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCouponHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetCouponHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetCouponHistoryResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *GetCouponHistoryResponse) GetEvents() []*CouponEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type ListCouponRedemptionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCouponRedemptionsRequest) Reset() {
+	*x = ListCouponRedemptionsRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCouponRedemptionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCouponRedemptionsRequest) ProtoMessage() {}
+
+func (x *ListCouponRedemptionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCouponRedemptionsRequest.ProtoReflect.Descriptor instead.
+func (*ListCouponRedemptionsRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListCouponRedemptionsRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type CouponRedemption struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	OrderRef   string                 `protobuf:"bytes,1,opt,name=order_ref,json=orderRef,proto3" json:"order_ref,omitempty"`
+	UserId     string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	RedeemedAt string                 `protobuf:"bytes,3,opt,name=redeemed_at,json=redeemedAt,proto3" json:"redeemed_at,omitempty"`
+	// Set when the redemption has been reversed.
+	ReversedAt    string `protobuf:"bytes,4,opt,name=reversed_at,json=reversedAt,proto3" json:"reversed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CouponRedemption) Reset() {
+	*x = CouponRedemption{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CouponRedemption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CouponRedemption) ProtoMessage() {}
+
+func (x *CouponRedemption) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CouponRedemption.ProtoReflect.Descriptor instead.
+func (*CouponRedemption) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *CouponRedemption) GetOrderRef() string {
+	if x != nil {
+		return x.OrderRef
+	}
+	return ""
+}
+
+func (x *CouponRedemption) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CouponRedemption) GetRedeemedAt() string {
+	if x != nil {
+		return x.RedeemedAt
+	}
+	return ""
+}
+
+func (x *CouponRedemption) GetReversedAt() string {
+	if x != nil {
+		return x.ReversedAt
+	}
+	return ""
+}
+
+type ListCouponRedemptionsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Code           string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	MaxRedemptions int32                  `protobuf:"varint,2,opt,name=max_redemptions,json=maxRedemptions,proto3" json:"max_redemptions,omitempty"`
+	// Oldest first.
+	Redemptions   []*CouponRedemption `protobuf:"bytes,3,rep,name=redemptions,proto3" json:"redemptions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCouponRedemptionsResponse) Reset() {
+	*x = ListCouponRedemptionsResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCouponRedemptionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCouponRedemptionsResponse) ProtoMessage() {}
+
+func (x *ListCouponRedemptionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCouponRedemptionsResponse.ProtoReflect.Descriptor instead.
+func (*ListCouponRedemptionsResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ListCouponRedemptionsResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ListCouponRedemptionsResponse) GetMaxRedemptions() int32 {
+	if x != nil {
+		return x.MaxRedemptions
+	}
+	return 0
+}
+
+func (x *ListCouponRedemptionsResponse) GetRedemptions() []*CouponRedemption {
+	if x != nil {
+		return x.Redemptions
+	}
+	return nil
+}
+
+type RevokeCouponRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Why the coupon is being revoked; recorded in the coupon's audit trail.
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Give the slot back to the campaign so another coupon can be issued.
+	// Campaigns with imported codes reject it.
+	ReturnToPool  bool `protobuf:"varint,3,opt,name=return_to_pool,json=returnToPool,proto3" json:"return_to_pool,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeCouponRequest) Reset() {
+	*x = RevokeCouponRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeCouponRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeCouponRequest) ProtoMessage() {}
+
+func (x *RevokeCouponRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeCouponRequest.ProtoReflect.Descriptor instead.
+func (*RevokeCouponRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RevokeCouponRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *RevokeCouponRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RevokeCouponRequest) GetReturnToPool() bool {
+	if x != nil {
+		return x.ReturnToPool
+	}
+	return false
+}
+
+type RevokeCouponResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Code           string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	CampaignId     string                 `protobuf:"bytes,2,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	State          string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	RevokedAt      string                 `protobuf:"bytes,4,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	ReturnedToPool bool                   `protobuf:"varint,5,opt,name=returned_to_pool,json=returnedToPool,proto3" json:"returned_to_pool,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RevokeCouponResponse) Reset() {
+	*x = RevokeCouponResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeCouponResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeCouponResponse) ProtoMessage() {}
+
+func (x *RevokeCouponResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeCouponResponse.ProtoReflect.Descriptor instead.
+func (*RevokeCouponResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *RevokeCouponResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *RevokeCouponResponse) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *RevokeCouponResponse) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *RevokeCouponResponse) GetRevokedAt() string {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return ""
+}
+
+func (x *RevokeCouponResponse) GetReturnedToPool() bool {
+	if x != nil {
+		return x.ReturnedToPool
+	}
+	return false
+}
+
+type CartItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sku           string                 `protobuf:"bytes,1,opt,name=sku,proto3" json:"sku,omitempty"`
+	Category      string                 `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`
+	Quantity      int64                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	UnitPrice     string                 `protobuf:"bytes,4,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CartItem) Reset() {
+	*x = CartItem{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CartItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CartItem) ProtoMessage() {}
+
+func (x *CartItem) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CartItem.ProtoReflect.Descriptor instead.
+func (*CartItem) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *CartItem) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *CartItem) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *CartItem) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *CartItem) GetUnitPrice() string {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return ""
+}
+
+type Cart struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Currency      string                 `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	Items         []*CartItem            `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cart) Reset() {
+	*x = Cart{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cart) ProtoMessage() {}
+
+func (x *Cart) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cart.ProtoReflect.Descriptor instead.
+func (*Cart) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *Cart) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Cart) GetItems() []*CartItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ApplyCouponRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Must match the coupon's owner when the coupon has one.
+	UserId        string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Cart          *Cart  `protobuf:"bytes,3,opt,name=cart,proto3" json:"cart,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyCouponRequest) Reset() {
+	*x = ApplyCouponRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyCouponRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyCouponRequest) ProtoMessage() {}
+
+func (x *ApplyCouponRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyCouponRequest.ProtoReflect.Descriptor instead.
+func (*ApplyCouponRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ApplyCouponRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ApplyCouponRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ApplyCouponRequest) GetCart() *Cart {
+	if x != nil {
+		return x.Cart
+	}
+	return nil
+}
+
+// ApplyCouponResponse amounts are in the cart's currency.
+type ApplyCouponResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Code       string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	CampaignId string                 `protobuf:"bytes,2,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	// Whether the benefit applies to the cart. When false, discount is zero
+	// and reason says why.
+	Applicable bool                       `protobuf:"varint,3,opt,name=applicable,proto3" json:"applicable,omitempty"`
+	Reason     BenefitNotApplicableReason `protobuf:"varint,4,opt,name=reason,proto3,enum=coupon.v1.BenefitNotApplicableReason" json:"reason,omitempty"`
+	Subtotal   string                     `protobuf:"bytes,5,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	Discount   string                     `protobuf:"bytes,6,opt,name=discount,proto3" json:"discount,omitempty"`
+	Total      string                     `protobuf:"bytes,7,opt,name=total,proto3" json:"total,omitempty"`
+	// Set for free item benefits.
+	FreeItemSku   string `protobuf:"bytes,8,opt,name=free_item_sku,json=freeItemSku,proto3" json:"free_item_sku,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyCouponResponse) Reset() {
+	*x = ApplyCouponResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyCouponResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyCouponResponse) ProtoMessage() {}
+
+func (x *ApplyCouponResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyCouponResponse.ProtoReflect.Descriptor instead.
+func (*ApplyCouponResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ApplyCouponResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ApplyCouponResponse) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *ApplyCouponResponse) GetApplicable() bool {
+	if x != nil {
+		return x.Applicable
+	}
+	return false
+}
+
+func (x *ApplyCouponResponse) GetReason() BenefitNotApplicableReason {
+	if x != nil {
+		return x.Reason
+	}
+	return BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_UNSPECIFIED
+}
+
+func (x *ApplyCouponResponse) GetSubtotal() string {
+	if x != nil {
+		return x.Subtotal
+	}
+	return ""
+}
+
+func (x *ApplyCouponResponse) GetDiscount() string {
+	if x != nil {
+		return x.Discount
+	}
+	return ""
+}
+
+func (x *ApplyCouponResponse) GetTotal() string {
+	if x != nil {
+		return x.Total
+	}
+	return ""
+}
+
+func (x *ApplyCouponResponse) GetFreeItemSku() string {
+	if x != nil {
+		return x.FreeItemSku
+	}
+	return ""
+}
+
+type ValidateBasketRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Codes []string               `protobuf:"bytes,1,rep,name=codes,proto3" json:"codes,omitempty"`
+	// Must match the owner of each coupon that has one.
+	UserId        string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Cart          *Cart  `protobuf:"bytes,3,opt,name=cart,proto3" json:"cart,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateBasketRequest) Reset() {
+	*x = ValidateBasketRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateBasketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateBasketRequest) ProtoMessage() {}
+
+func (x *ValidateBasketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateBasketRequest.ProtoReflect.Descriptor instead.
+func (*ValidateBasketRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ValidateBasketRequest) GetCodes() []string {
+	if x != nil {
+		return x.Codes
+	}
+	return nil
+}
+
+func (x *ValidateBasketRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ValidateBasketRequest) GetCart() *Cart {
+	if x != nil {
+		return x.Cart
+	}
+	return nil
+}
+
+type BasketCoupon struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	CampaignId    string                 `protobuf:"bytes,2,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	Discount      string                 `protobuf:"bytes,3,opt,name=discount,proto3" json:"discount,omitempty"`
+	FreeItemSku   string                 `protobuf:"bytes,4,opt,name=free_item_sku,json=freeItemSku,proto3" json:"free_item_sku,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BasketCoupon) Reset() {
+	*x = BasketCoupon{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BasketCoupon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BasketCoupon) ProtoMessage() {}
+
+func (x *BasketCoupon) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BasketCoupon.ProtoReflect.Descriptor instead.
+func (*BasketCoupon) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *BasketCoupon) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *BasketCoupon) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *BasketCoupon) GetDiscount() string {
+	if x != nil {
+		return x.Discount
+	}
+	return ""
+}
+
+func (x *BasketCoupon) GetFreeItemSku() string {
+	if x != nil {
+		return x.FreeItemSku
+	}
+	return ""
+}
+
+type RejectedCoupon struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Code   string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Reason BasketRejectionReason  `protobuf:"varint,2,opt,name=reason,proto3,enum=coupon.v1.BasketRejectionReason" json:"reason,omitempty"`
+	// The applied coupon this one conflicts with, for exclusivity group and
+	// stacking rejections.
+	ConflictsWith string `protobuf:"bytes,3,opt,name=conflicts_with,json=conflictsWith,proto3" json:"conflicts_with,omitempty"`
+	// Set when reason is NOT_APPLICABLE.
+	BenefitReason BenefitNotApplicableReason `protobuf:"varint,4,opt,name=benefit_reason,json=benefitReason,proto3,enum=coupon.v1.BenefitNotApplicableReason" json:"benefit_reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectedCoupon) Reset() {
+	*x = RejectedCoupon{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectedCoupon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectedCoupon) ProtoMessage() {}
+
+func (x *RejectedCoupon) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectedCoupon.ProtoReflect.Descriptor instead.
+func (*RejectedCoupon) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *RejectedCoupon) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *RejectedCoupon) GetReason() BasketRejectionReason {
+	if x != nil {
+		return x.Reason
+	}
+	return BasketRejectionReason_BASKET_REJECTION_REASON_UNSPECIFIED
+}
+
+func (x *RejectedCoupon) GetConflictsWith() string {
+	if x != nil {
+		return x.ConflictsWith
+	}
+	return ""
+}
+
+func (x *RejectedCoupon) GetBenefitReason() BenefitNotApplicableReason {
+	if x != nil {
+		return x.BenefitReason
+	}
+	return BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_UNSPECIFIED
+}
+
+// ValidateBasketResponse amounts are in the cart's currency.
+type ValidateBasketResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// In the order the coupons are applied.
+	Applied       []*BasketCoupon   `protobuf:"bytes,1,rep,name=applied,proto3" json:"applied,omitempty"`
+	Rejected      []*RejectedCoupon `protobuf:"bytes,2,rep,name=rejected,proto3" json:"rejected,omitempty"`
+	Subtotal      string            `protobuf:"bytes,3,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	Discount      string            `protobuf:"bytes,4,opt,name=discount,proto3" json:"discount,omitempty"`
+	Total         string            `protobuf:"bytes,5,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateBasketResponse) Reset() {
+	*x = ValidateBasketResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateBasketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateBasketResponse) ProtoMessage() {}
+
+func (x *ValidateBasketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateBasketResponse.ProtoReflect.Descriptor instead.
+func (*ValidateBasketResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ValidateBasketResponse) GetApplied() []*BasketCoupon {
+	if x != nil {
+		return x.Applied
+	}
+	return nil
+}
+
+func (x *ValidateBasketResponse) GetRejected() []*RejectedCoupon {
+	if x != nil {
+		return x.Rejected
+	}
+	return nil
+}
+
+func (x *ValidateBasketResponse) GetSubtotal() string {
+	if x != nil {
+		return x.Subtotal
+	}
+	return ""
+}
+
+func (x *ValidateBasketResponse) GetDiscount() string {
+	if x != nil {
+		return x.Discount
+	}
+	return ""
+}
+
+func (x *ValidateBasketResponse) GetTotal() string {
+	if x != nil {
+		return x.Total
+	}
+	return ""
+}
+
+type ValidateCouponRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Optional customer the code is checked for. Corrections of a probable
+	// typo are only suggested from this user's coupons, so that validation
+	// cannot be used to discover other users' codes.
+	UserId        string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCouponRequest) Reset() {
+	*x = ValidateCouponRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCouponRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCouponRequest) ProtoMessage() {}
+
+func (x *ValidateCouponRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCouponRequest.ProtoReflect.Descriptor instead.
+func (*ValidateCouponRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ValidateCouponRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ValidateCouponRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ValidateCouponResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether the code can currently be redeemed.
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	// Set when valid is false.
+	Reason       CouponInvalidReason `protobuf:"varint,2,opt,name=reason,proto3,enum=coupon.v1.CouponInvalidReason" json:"reason,omitempty"`
+	Code         string              `protobuf:"bytes,3,opt,name=code,proto3" json:"code,omitempty"`
+	CampaignId   string              `protobuf:"bytes,4,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	CampaignName string              `protobuf:"bytes,5,opt,name=campaign_name,json=campaignName,proto3" json:"campaign_name,omitempty"`
+	OwnerUserId  string              `protobuf:"bytes,6,opt,name=owner_user_id,json=ownerUserId,proto3" json:"owner_user_id,omitempty"`
+	State        string              `protobuf:"bytes,7,opt,name=state,proto3" json:"state,omitempty"`
+	// RFC3339; empty when the coupon does not expire.
+	ExpiresAt string `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// For a probable typo, the user's coupons the code differs from by one
+	// character or by two swapped adjacent characters.
+	Suggestions   []string `protobuf:"bytes,9,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCouponResponse) Reset() {
+	*x = ValidateCouponResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCouponResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCouponResponse) ProtoMessage() {}
+
+func (x *ValidateCouponResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCouponResponse.ProtoReflect.Descriptor instead.
+func (*ValidateCouponResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ValidateCouponResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateCouponResponse) GetReason() CouponInvalidReason {
+	if x != nil {
+		return x.Reason
+	}
+	return CouponInvalidReason_COUPON_INVALID_REASON_UNSPECIFIED
+}
+
+func (x *ValidateCouponResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ValidateCouponResponse) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *ValidateCouponResponse) GetCampaignName() string {
+	if x != nil {
+		return x.CampaignName
+	}
+	return ""
+}
+
+func (x *ValidateCouponResponse) GetOwnerUserId() string {
+	if x != nil {
+		return x.OwnerUserId
+	}
+	return ""
+}
+
+func (x *ValidateCouponResponse) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ValidateCouponResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *ValidateCouponResponse) GetSuggestions() []string {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+type StartPushIssuanceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only read from the first message of the stream.
+	CampaignId    string   `protobuf:"bytes,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	UserIds       []string `protobuf:"bytes,2,rep,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartPushIssuanceRequest) Reset() {
+	*x = StartPushIssuanceRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartPushIssuanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartPushIssuanceRequest) ProtoMessage() {}
+
+func (x *StartPushIssuanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartPushIssuanceRequest.ProtoReflect.Descriptor instead.
+func (*StartPushIssuanceRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *StartPushIssuanceRequest) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *StartPushIssuanceRequest) GetUserIds() []string {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+type StartPushIssuanceResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	JobId string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	// Number of distinct users queued for issuance.
+	TotalUsers    int32 `protobuf:"varint,2,opt,name=total_users,json=totalUsers,proto3" json:"total_users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartPushIssuanceResponse) Reset() {
+	*x = StartPushIssuanceResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartPushIssuanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartPushIssuanceResponse) ProtoMessage() {}
+
+func (x *StartPushIssuanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartPushIssuanceResponse.ProtoReflect.Descriptor instead.
+func (*StartPushIssuanceResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *StartPushIssuanceResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *StartPushIssuanceResponse) GetTotalUsers() int32 {
+	if x != nil {
+		return x.TotalUsers
+	}
+	return 0
+}
+
+type ImportCodesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only read from the first message of the stream.
+	CampaignId string `protobuf:"bytes,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	// Only read from the first message of the stream.
+	Format CodeListFormat `protobuf:"varint,2,opt,name=format,proto3,enum=coupon.v1.CodeListFormat" json:"format,omitempty"`
+	// The next part of the code list. Parts may end anywhere in a line.
+	Data          []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportCodesRequest) Reset() {
+	*x = ImportCodesRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportCodesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportCodesRequest) ProtoMessage() {}
+
+func (x *ImportCodesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportCodesRequest.ProtoReflect.Descriptor instead.
+func (*ImportCodesRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *ImportCodesRequest) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *ImportCodesRequest) GetFormat() CodeListFormat {
+	if x != nil {
+		return x.Format
+	}
+	return CodeListFormat_CODE_LIST_FORMAT_UNSPECIFIED
+}
+
+func (x *ImportCodesRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type ImportCodesResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Imported int32                  `protobuf:"varint,1,opt,name=imported,proto3" json:"imported,omitempty"`
+	// Codes skipped because they were repeated in the list or already exist.
+	Duplicates int32 `protobuf:"varint,2,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	// The campaign's coupon limit after the import.
+	CouponLimit   int32 `protobuf:"varint,3,opt,name=coupon_limit,json=couponLimit,proto3" json:"coupon_limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportCodesResponse) Reset() {
+	*x = ImportCodesResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportCodesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportCodesResponse) ProtoMessage() {}
+
+func (x *ImportCodesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportCodesResponse.ProtoReflect.Descriptor instead.
+func (*ImportCodesResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *ImportCodesResponse) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportCodesResponse) GetDuplicates() int32 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+func (x *ImportCodesResponse) GetCouponLimit() int32 {
+	if x != nil {
+		return x.CouponLimit
+	}
+	return 0
+}
+
+type GenerateCodeBatchRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	CampaignId string                 `protobuf:"bytes,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	// Number of codes to pre-issue, at most 1,000,000.
+	Count         int32 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateCodeBatchRequest) Reset() {
+	*x = GenerateCodeBatchRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateCodeBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateCodeBatchRequest) ProtoMessage() {}
+
+func (x *GenerateCodeBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateCodeBatchRequest.ProtoReflect.Descriptor instead.
+func (*GenerateCodeBatchRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *GenerateCodeBatchRequest) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *GenerateCodeBatchRequest) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type GenerateCodeBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateCodeBatchResponse) Reset() {
+	*x = GenerateCodeBatchResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateCodeBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateCodeBatchResponse) ProtoMessage() {}
+
+func (x *GenerateCodeBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateCodeBatchResponse.ProtoReflect.Descriptor instead.
+func (*GenerateCodeBatchResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *GenerateCodeBatchResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type ExportCodeBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Format        CodeBatchFormat        `protobuf:"varint,2,opt,name=format,proto3,enum=coupon.v1.CodeBatchFormat" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportCodeBatchRequest) Reset() {
+	*x = ExportCodeBatchRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportCodeBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportCodeBatchRequest) ProtoMessage() {}
+
+func (x *ExportCodeBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportCodeBatchRequest.ProtoReflect.Descriptor instead.
+func (*ExportCodeBatchRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ExportCodeBatchRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *ExportCodeBatchRequest) GetFormat() CodeBatchFormat {
+	if x != nil {
+		return x.Format
+	}
+	return CodeBatchFormat_CODE_BATCH_FORMAT_UNSPECIFIED
+}
+
+type ExportCodeBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The next part of the file. Parts may end anywhere in a line.
+	Data          []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportCodeBatchResponse) Reset() {
+	*x = ExportCodeBatchResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportCodeBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportCodeBatchResponse) ProtoMessage() {}
+
+func (x *ExportCodeBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportCodeBatchResponse.ProtoReflect.Descriptor instead.
+func (*ExportCodeBatchResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *ExportCodeBatchResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *GetJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type JobFailure struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	StartTime     string                 `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
-	CouponLimit   int32                  `protobuf:"varint,3,opt,name=coupon_limit,json=couponLimit,proto3" json:"coupon_limit,omitempty"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobFailure) Reset() {
+	*x = JobFailure{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobFailure) ProtoMessage() {}
+
+func (x *JobFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobFailure.ProtoReflect.Descriptor instead.
+func (*JobFailure) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *JobFailure) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *JobFailure) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetJobResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	JobId      string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Type       string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	CampaignId string                 `protobuf:"bytes,3,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	Status     string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Total      int32                  `protobuf:"varint,5,opt,name=total,proto3" json:"total,omitempty"`
+	Processed  int32                  `protobuf:"varint,6,opt,name=processed,proto3" json:"processed,omitempty"`
+	Succeeded  int32                  `protobuf:"varint,7,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed     int32                  `protobuf:"varint,8,opt,name=failed,proto3" json:"failed,omitempty"`
+	// Up to the first 100 failures.
+	Failures      []*JobFailure `protobuf:"bytes,9,rep,name=failures,proto3" json:"failures,omitempty"`
+	Error         string        `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	CreatedAt     string        `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     string        `protobuf:"bytes,12,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobResponse) Reset() {
+	*x = GetJobResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobResponse) ProtoMessage() {}
+
+func (x *GetJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobResponse.ProtoReflect.Descriptor instead.
+func (*GetJobResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *GetJobResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *GetJobResponse) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *GetJobResponse) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
+	}
+	return ""
+}
+
+func (x *GetJobResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GetJobResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetJobResponse) GetProcessed() int32 {
+	if x != nil {
+		return x.Processed
+	}
+	return 0
+}
+
+func (x *GetJobResponse) GetSucceeded() int32 {
+	if x != nil {
+		return x.Succeeded
+	}
+	return 0
+}
+
+func (x *GetJobResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *GetJobResponse) GetFailures() []*JobFailure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+func (x *GetJobResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetJobResponse) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *GetJobResponse) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+// Attached as an error detail when IssueCoupon denies a request.
+type EligibilityDenial struct {
+	state  protoimpl.MessageState  `protogen:"open.v1"`
+	Reason EligibilityDenialReason `protobuf:"varint,1,opt,name=reason,proto3,enum=coupon.v1.EligibilityDenialReason" json:"reason,omitempty"`
+	// The condition of the campaign's expression that was not met.
+	Clause string `protobuf:"bytes,2,opt,name=clause,proto3" json:"clause,omitempty"`
+	// The attribute that was missing or invalid, if any.
+	Attribute     string `protobuf:"bytes,3,opt,name=attribute,proto3" json:"attribute,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateCampaignRequest) Reset() {
-	*x = CreateCampaignRequest{}
-	mi := &file_coupon_v1_coupon_proto_msgTypes[0]
+func (x *EligibilityDenial) Reset() {
+	*x = EligibilityDenial{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateCampaignRequest) String() string {
+func (x *EligibilityDenial) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateCampaignRequest) ProtoMessage() {}
+func (*EligibilityDenial) ProtoMessage() {}
 
-func (x *CreateCampaignRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_coupon_v1_coupon_proto_msgTypes[0]
+func (x *EligibilityDenial) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -55,54 +4856,54 @@ func (x *CreateCampaignRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateCampaignRequest.ProtoReflect.Descriptor instead.
-func (*CreateCampaignRequest) Descriptor() ([]byte, []int) {
-	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use EligibilityDenial.ProtoReflect.Descriptor instead.
+func (*EligibilityDenial) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{60}
 }
 
-func (x *CreateCampaignRequest) GetName() string {
+func (x *EligibilityDenial) GetReason() EligibilityDenialReason {
 	if x != nil {
-		return x.Name
+		return x.Reason
 	}
-	return ""
+	return EligibilityDenialReason_ELIGIBILITY_DENIAL_REASON_UNSPECIFIED
 }
 
-func (x *CreateCampaignRequest) GetStartTime() string {
+func (x *EligibilityDenial) GetClause() string {
 	if x != nil {
-		return x.StartTime
+		return x.Clause
 	}
 	return ""
 }
 
-func (x *CreateCampaignRequest) GetCouponLimit() int32 {
+func (x *EligibilityDenial) GetAttribute() string {
 	if x != nil {
-		return x.CouponLimit
+		return x.Attribute
 	}
-	return 0
+	return ""
 }
 
-type CreateCampaignResponse struct {
+type RotateSigningKeyRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	CampaignId    string                 `protobuf:"bytes,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateCampaignResponse) Reset() {
-	*x = CreateCampaignResponse{}
-	mi := &file_coupon_v1_coupon_proto_msgTypes[1]
+func (x *RotateSigningKeyRequest) Reset() {
+	*x = RotateSigningKeyRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateCampaignResponse) String() string {
+func (x *RotateSigningKeyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateCampaignResponse) ProtoMessage() {}
+func (*RotateSigningKeyRequest) ProtoMessage() {}
 
-func (x *CreateCampaignResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_coupon_v1_coupon_proto_msgTypes[1]
+func (x *RotateSigningKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -113,40 +4914,44 @@ func (x *CreateCampaignResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateCampaignResponse.ProtoReflect.Descriptor instead.
-func (*CreateCampaignResponse) Descriptor() ([]byte, []int) {
-	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use RotateSigningKeyRequest.ProtoReflect.Descriptor instead.
+func (*RotateSigningKeyRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{61}
 }
 
-func (x *CreateCampaignResponse) GetCampaignId() string {
+func (x *RotateSigningKeyRequest) GetCampaignId() string {
 	if x != nil {
 		return x.CampaignId
 	}
 	return ""
 }
 
-type GetCampaignRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	CampaignId    string                 `protobuf:"bytes,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+type RotateSigningKeyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	KeyId uint32                 `protobuf:"varint,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	// The key that stopped signing codes, if any. It still verifies the codes
+	// it signed.
+	RetiredKeyId  uint32 `protobuf:"varint,2,opt,name=retired_key_id,json=retiredKeyId,proto3" json:"retired_key_id,omitempty"`
+	CreatedAt     string `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetCampaignRequest) Reset() {
-	*x = GetCampaignRequest{}
-	mi := &file_coupon_v1_coupon_proto_msgTypes[2]
+func (x *RotateSigningKeyResponse) Reset() {
+	*x = RotateSigningKeyResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[62]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetCampaignRequest) String() string {
+func (x *RotateSigningKeyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetCampaignRequest) ProtoMessage() {}
+func (*RotateSigningKeyResponse) ProtoMessage() {}
 
-func (x *GetCampaignRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_coupon_v1_coupon_proto_msgTypes[2]
+func (x *RotateSigningKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[62]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -157,43 +4962,108 @@ func (x *GetCampaignRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetCampaignRequest.ProtoReflect.Descriptor instead.
-func (*GetCampaignRequest) Descriptor() ([]byte, []int) {
-	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use RotateSigningKeyResponse.ProtoReflect.Descriptor instead.
+func (*RotateSigningKeyResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{62}
 }
 
-func (x *GetCampaignRequest) GetCampaignId() string {
+func (x *RotateSigningKeyResponse) GetKeyId() uint32 {
+	if x != nil {
+		return x.KeyId
+	}
+	return 0
+}
+
+func (x *RotateSigningKeyResponse) GetRetiredKeyId() uint32 {
+	if x != nil {
+		return x.RetiredKeyId
+	}
+	return 0
+}
+
+func (x *RotateSigningKeyResponse) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type GetVerificationKeysRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional; the keys of every campaign with signed codes when empty.
+	CampaignId    string `protobuf:"bytes,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVerificationKeysRequest) Reset() {
+	*x = GetVerificationKeysRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVerificationKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVerificationKeysRequest) ProtoMessage() {}
+
+func (x *GetVerificationKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVerificationKeysRequest.ProtoReflect.Descriptor instead.
+func (*GetVerificationKeysRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *GetVerificationKeysRequest) GetCampaignId() string {
 	if x != nil {
 		return x.CampaignId
 	}
 	return ""
 }
 
-type GetCampaignResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	StartTime     string                 `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
-	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
-	IssuedCoupons []string               `protobuf:"bytes,4,rep,name=issued_coupons,json=issuedCoupons,proto3" json:"issued_coupons,omitempty"`
+type VerificationKey struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	KeyId      uint32                 `protobuf:"varint,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	CampaignId string                 `protobuf:"bytes,2,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	Secret     []byte                 `protobuf:"bytes,3,opt,name=secret,proto3" json:"secret,omitempty"`
+	CreatedAt  string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Set once the key no longer signs new codes.
+	RetiredAt string `protobuf:"bytes,5,opt,name=retired_at,json=retiredAt,proto3" json:"retired_at,omitempty"`
+	// The characters the key's codes are written in, in digit order.
+	Alphabet string `protobuf:"bytes,6,opt,name=alphabet,proto3" json:"alphabet,omitempty"`
+	// Prepended to the key's codes.
+	Prefix        string `protobuf:"bytes,7,opt,name=prefix,proto3" json:"prefix,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetCampaignResponse) Reset() {
-	*x = GetCampaignResponse{}
-	mi := &file_coupon_v1_coupon_proto_msgTypes[3]
+func (x *VerificationKey) Reset() {
+	*x = VerificationKey{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[64]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetCampaignResponse) String() string {
+func (x *VerificationKey) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetCampaignResponse) ProtoMessage() {}
+func (*VerificationKey) ProtoMessage() {}
 
-func (x *GetCampaignResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_coupon_v1_coupon_proto_msgTypes[3]
+func (x *VerificationKey) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[64]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -204,61 +5074,82 @@ func (x *GetCampaignResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetCampaignResponse.ProtoReflect.Descriptor instead.
-func (*GetCampaignResponse) Descriptor() ([]byte, []int) {
-	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use VerificationKey.ProtoReflect.Descriptor instead.
+func (*VerificationKey) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{64}
 }
 
-func (x *GetCampaignResponse) GetName() string {
+func (x *VerificationKey) GetKeyId() uint32 {
 	if x != nil {
-		return x.Name
+		return x.KeyId
+	}
+	return 0
+}
+
+func (x *VerificationKey) GetCampaignId() string {
+	if x != nil {
+		return x.CampaignId
 	}
 	return ""
 }
 
-func (x *GetCampaignResponse) GetStartTime() string {
+func (x *VerificationKey) GetSecret() []byte {
 	if x != nil {
-		return x.StartTime
+		return x.Secret
+	}
+	return nil
+}
+
+func (x *VerificationKey) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
 	}
 	return ""
 }
 
-func (x *GetCampaignResponse) GetStatus() string {
+func (x *VerificationKey) GetRetiredAt() string {
 	if x != nil {
-		return x.Status
+		return x.RetiredAt
 	}
 	return ""
 }
 
-func (x *GetCampaignResponse) GetIssuedCoupons() []string {
+func (x *VerificationKey) GetAlphabet() string {
 	if x != nil {
-		return x.IssuedCoupons
+		return x.Alphabet
 	}
-	return nil
+	return ""
 }
 
-type IssueCouponRequest struct {
+func (x *VerificationKey) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type GetVerificationKeysResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CampaignId    string                 `protobuf:"bytes,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	Keys          []*VerificationKey     `protobuf:"bytes,2,rep,name=keys,proto3" json:"keys,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *IssueCouponRequest) Reset() {
-	*x = IssueCouponRequest{}
-	mi := &file_coupon_v1_coupon_proto_msgTypes[4]
+func (x *GetVerificationKeysResponse) Reset() {
+	*x = GetVerificationKeysResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[65]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *IssueCouponRequest) String() string {
+func (x *GetVerificationKeysResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IssueCouponRequest) ProtoMessage() {}
+func (*GetVerificationKeysResponse) ProtoMessage() {}
 
-func (x *IssueCouponRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_coupon_v1_coupon_proto_msgTypes[4]
+func (x *GetVerificationKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -269,40 +5160,131 @@ func (x *IssueCouponRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IssueCouponRequest.ProtoReflect.Descriptor instead.
-func (*IssueCouponRequest) Descriptor() ([]byte, []int) {
-	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use GetVerificationKeysResponse.ProtoReflect.Descriptor instead.
+func (*GetVerificationKeysResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{65}
 }
 
-func (x *IssueCouponRequest) GetCampaignId() string {
+func (x *GetVerificationKeysResponse) GetKeys() []*VerificationKey {
 	if x != nil {
-		return x.CampaignId
+		return x.Keys
+	}
+	return nil
+}
+
+type CreateCouponImageUrlRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// The coupon's owner. Coupons that belong to a user only get images for
+	// that user.
+	UserId    string           `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Symbology BarcodeSymbology `protobuf:"varint,3,opt,name=symbology,proto3,enum=coupon.v1.BarcodeSymbology" json:"symbology,omitempty"`
+	Format    ImageFormat      `protobuf:"varint,4,opt,name=format,proto3,enum=coupon.v1.ImageFormat" json:"format,omitempty"`
+	// Optional branding text drawn under the code, at most 40 characters.
+	// PNG images can only draw ASCII text.
+	Text string `protobuf:"bytes,5,opt,name=text,proto3" json:"text,omitempty"`
+	// How long the URL works. Defaults to an hour, at most a day.
+	TtlSeconds    int32 `protobuf:"varint,6,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCouponImageUrlRequest) Reset() {
+	*x = CreateCouponImageUrlRequest{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCouponImageUrlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCouponImageUrlRequest) ProtoMessage() {}
+
+func (x *CreateCouponImageUrlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCouponImageUrlRequest.ProtoReflect.Descriptor instead.
+func (*CreateCouponImageUrlRequest) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *CreateCouponImageUrlRequest) GetCode() string {
+	if x != nil {
+		return x.Code
 	}
 	return ""
 }
 
-type IssueCouponResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	CouponCode    string                 `protobuf:"bytes,1,opt,name=coupon_code,json=couponCode,proto3" json:"coupon_code,omitempty"`
+func (x *CreateCouponImageUrlRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateCouponImageUrlRequest) GetSymbology() BarcodeSymbology {
+	if x != nil {
+		return x.Symbology
+	}
+	return BarcodeSymbology_BARCODE_SYMBOLOGY_UNSPECIFIED
+}
+
+func (x *CreateCouponImageUrlRequest) GetFormat() ImageFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ImageFormat_IMAGE_FORMAT_UNSPECIFIED
+}
+
+func (x *CreateCouponImageUrlRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *CreateCouponImageUrlRequest) GetTtlSeconds() int32 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type CreateCouponImageUrlResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Signed URL of the image. It cannot be changed to show another code.
+	Url           string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	ExpiresAt     string `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *IssueCouponResponse) Reset() {
-	*x = IssueCouponResponse{}
-	mi := &file_coupon_v1_coupon_proto_msgTypes[5]
+func (x *CreateCouponImageUrlResponse) Reset() {
+	*x = CreateCouponImageUrlResponse{}
+	mi := &file_coupon_v1_coupon_proto_msgTypes[67]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *IssueCouponResponse) String() string {
+func (x *CreateCouponImageUrlResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*IssueCouponResponse) ProtoMessage() {}
+func (*CreateCouponImageUrlResponse) ProtoMessage() {}
 
-func (x *IssueCouponResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_coupon_v1_coupon_proto_msgTypes[5]
+func (x *CreateCouponImageUrlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_coupon_v1_coupon_proto_msgTypes[67]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -313,14 +5295,21 @@ func (x *IssueCouponResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use IssueCouponResponse.ProtoReflect.Descriptor instead.
-func (*IssueCouponResponse) Descriptor() ([]byte, []int) {
-	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use CreateCouponImageUrlResponse.ProtoReflect.Descriptor instead.
+func (*CreateCouponImageUrlResponse) Descriptor() ([]byte, []int) {
+	return file_coupon_v1_coupon_proto_rawDescGZIP(), []int{67}
 }
 
-func (x *IssueCouponResponse) GetCouponCode() string {
+func (x *CreateCouponImageUrlResponse) GetUrl() string {
 	if x != nil {
-		return x.CouponCode
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CreateCouponImageUrlResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
 	}
 	return ""
 }
@@ -329,34 +5318,491 @@ var File_coupon_v1_coupon_proto protoreflect.FileDescriptor
 
 const file_coupon_v1_coupon_proto_rawDesc = "" +
 	"\n" +
-	"\x16coupon/v1/coupon.proto\x12\tcoupon.v1\"m\n" +
+	"\x16coupon/v1/coupon.proto\x12\tcoupon.v1\"\xdc\x05\n" +
 	"\x15CreateCampaignRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
 	"\n" +
 	"start_time\x18\x02 \x01(\tR\tstartTime\x12!\n" +
-	"\fcoupon_limit\x18\x03 \x01(\x05R\vcouponLimit\"9\n" +
+	"\fcoupon_limit\x18\x03 \x01(\x05R\vcouponLimit\x12 \n" +
+	"\veligibility\x18\x04 \x01(\tR\veligibility\x12\x1f\n" +
+	"\vvalid_until\x18\x05 \x01(\tR\n" +
+	"validUntil\x12+\n" +
+	"\x11validity_duration\x18\x06 \x01(\tR\x10validityDuration\x12,\n" +
+	"\abenefit\x18\a \x01(\v2\x12.coupon.v1.BenefitR\abenefit\x12'\n" +
+	"\x0fmax_redemptions\x18\b \x01(\x05R\x0emaxRedemptions\x12\"\n" +
+	"\ftransferable\x18\t \x01(\bR\ftransferable\x12)\n" +
+	"\x11max_held_per_user\x18\n" +
+	" \x01(\x05R\x0emaxHeldPerUser\x12+\n" +
+	"\x11exclusivity_group\x18\v \x01(\tR\x10exclusivityGroup\x12B\n" +
+	"\x0fstacking_policy\x18\f \x01(\x0e2\x19.coupon.v1.StackingPolicyR\x0estackingPolicy\x12\x1a\n" +
+	"\bpriority\x18\r \x01(\x05R\bpriority\x12!\n" +
+	"\fsigned_codes\x18\x0e \x01(\bR\vsignedCodes\x126\n" +
+	"\vcode_format\x18\x0f \x01(\v2\x15.coupon.v1.CodeFormatR\n" +
+	"codeFormat\x12'\n" +
+	"\x0fsequenced_codes\x18\x10 \x01(\bR\x0esequencedCodes\x12%\n" +
+	"\x0eimported_codes\x18\x11 \x01(\bR\rimportedCodes\x12\x1f\n" +
+	"\vvanity_code\x18\x12 \x01(\tR\n" +
+	"vanityCode\"9\n" +
 	"\x16CreateCampaignResponse\x12\x1f\n" +
 	"\vcampaign_id\x18\x01 \x01(\tR\n" +
 	"campaignId\"5\n" +
 	"\x12GetCampaignRequest\x12\x1f\n" +
 	"\vcampaign_id\x18\x01 \x01(\tR\n" +
-	"campaignId\"\x87\x01\n" +
+	"campaignId\"\x99\x06\n" +
 	"\x13GetCampaignResponse\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
 	"\n" +
 	"start_time\x18\x02 \x01(\tR\tstartTime\x12\x16\n" +
 	"\x06status\x18\x03 \x01(\tR\x06status\x12%\n" +
-	"\x0eissued_coupons\x18\x04 \x03(\tR\rissuedCoupons\"5\n" +
+	"\x0eissued_coupons\x18\x04 \x03(\tR\rissuedCoupons\x12 \n" +
+	"\veligibility\x18\x05 \x01(\tR\veligibility\x12\x1f\n" +
+	"\vvalid_until\x18\x06 \x01(\tR\n" +
+	"validUntil\x12+\n" +
+	"\x11validity_duration\x18\a \x01(\tR\x10validityDuration\x12,\n" +
+	"\abenefit\x18\b \x01(\v2\x12.coupon.v1.BenefitR\abenefit\x12'\n" +
+	"\x0fmax_redemptions\x18\t \x01(\x05R\x0emaxRedemptions\x12\"\n" +
+	"\ftransferable\x18\n" +
+	" \x01(\bR\ftransferable\x12)\n" +
+	"\x11max_held_per_user\x18\v \x01(\x05R\x0emaxHeldPerUser\x12+\n" +
+	"\x11exclusivity_group\x18\f \x01(\tR\x10exclusivityGroup\x12B\n" +
+	"\x0fstacking_policy\x18\r \x01(\x0e2\x19.coupon.v1.StackingPolicyR\x0estackingPolicy\x12\x1a\n" +
+	"\bpriority\x18\x0e \x01(\x05R\bpriority\x12!\n" +
+	"\fsigned_codes\x18\x0f \x01(\bR\vsignedCodes\x126\n" +
+	"\vcode_format\x18\x10 \x01(\v2\x15.coupon.v1.CodeFormatR\n" +
+	"codeFormat\x12'\n" +
+	"\x0fsequenced_codes\x18\x11 \x01(\bR\x0esequencedCodes\x12%\n" +
+	"\x0eimported_codes\x18\x12 \x01(\bR\rimportedCodes\x12!\n" +
+	"\fcoupon_limit\x18\x13 \x01(\x05R\vcouponLimit\x12\x1f\n" +
+	"\vvanity_code\x18\x14 \x01(\tR\n" +
+	"vanityCode\"\x9d\x02\n" +
+	"\n" +
+	"CodeFormat\x123\n" +
+	"\balphabet\x18\x01 \x01(\x0e2\x17.coupon.v1.CodeAlphabetR\balphabet\x12'\n" +
+	"\x0fcustom_alphabet\x18\x02 \x01(\tR\x0ecustomAlphabet\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x05R\x06length\x12\x16\n" +
+	"\x06prefix\x18\x04 \x01(\tR\x06prefix\x12\x18\n" +
+	"\apattern\x18\x05 \x01(\tR\apattern\x12B\n" +
+	"\x0fcheck_character\x18\x06 \x01(\x0e2\x19.coupon.v1.CheckCharacterR\x0echeckCharacter\x12#\n" +
+	"\rcommon_hangul\x18\a \x01(\bR\fcommonHangul\"\xb3\x02\n" +
+	"\aBenefit\x128\n" +
+	"\vpercent_off\x18\x01 \x01(\v2\x15.coupon.v1.PercentOffH\x00R\n" +
+	"percentOff\x12;\n" +
+	"\ffixed_amount\x18\x02 \x01(\v2\x16.coupon.v1.FixedAmountH\x00R\vfixedAmount\x122\n" +
+	"\tfree_item\x18\x03 \x01(\v2\x13.coupon.v1.FreeItemH\x00R\bfreeItem\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12(\n" +
+	"\x10min_order_amount\x18\x05 \x01(\tR\x0eminOrderAmount\x12/\n" +
+	"\x13eligible_categories\x18\x06 \x03(\tR\x12eligibleCategoriesB\x06\n" +
+	"\x04kind\"I\n" +
+	"\n" +
+	"PercentOff\x12\x18\n" +
+	"\apercent\x18\x01 \x01(\tR\apercent\x12!\n" +
+	"\fmax_discount\x18\x02 \x01(\tR\vmaxDiscount\"%\n" +
+	"\vFixedAmount\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\tR\x06amount\"\x1c\n" +
+	"\bFreeItem\x12\x10\n" +
+	"\x03sku\x18\x01 \x01(\tR\x03sku\"~\n" +
+	"\x0eUserAttributes\x12\x12\n" +
+	"\x04tier\x18\x01 \x01(\tR\x04tier\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12\x1f\n" +
+	"\vsignup_date\x18\x03 \x01(\tR\n" +
+	"signupDate\x12\x1f\n" +
+	"\vapp_version\x18\x04 \x01(\tR\n" +
+	"appVersion\"\x89\x01\n" +
 	"\x12IssueCouponRequest\x12\x1f\n" +
 	"\vcampaign_id\x18\x01 \x01(\tR\n" +
-	"campaignId\"6\n" +
+	"campaignId\x129\n" +
+	"\n" +
+	"attributes\x18\x02 \x01(\v2\x19.coupon.v1.UserAttributesR\n" +
+	"attributes\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\"6\n" +
 	"\x13IssueCouponResponse\x12\x1f\n" +
 	"\vcoupon_code\x18\x01 \x01(\tR\n" +
-	"couponCode2\x82\x02\n" +
+	"couponCode\"z\n" +
+	"\x18IssueCouponStreamRequest\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x127\n" +
+	"\arequest\x18\x02 \x01(\v2\x1d.coupon.v1.IssueCouponRequestR\arequest\"\xa4\x01\n" +
+	"\x19IssueCouponStreamResponse\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12!\n" +
+	"\vcoupon_code\x18\x02 \x01(\tH\x00R\n" +
+	"couponCode\x123\n" +
+	"\x05error\x18\x03 \x01(\v2\x1b.coupon.v1.IssueCouponErrorH\x00R\x05errorB\b\n" +
+	"\x06result\"\x8d\x01\n" +
+	"\x10IssueCouponError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12K\n" +
+	"\x12eligibility_denial\x18\x03 \x01(\v2\x1c.coupon.v1.EligibilityDenialR\x11eligibilityDenial\"_\n" +
+	"\x13RedeemCouponRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1b\n" +
+	"\torder_ref\x18\x03 \x01(\tR\borderRef\"\xf3\x01\n" +
+	"\x14RedeemCouponResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcampaign_id\x18\x02 \x01(\tR\n" +
+	"campaignId\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x1f\n" +
+	"\vredeemed_at\x18\x04 \x01(\tR\n" +
+	"redeemedAt\x12\x1b\n" +
+	"\torder_ref\x18\x05 \x01(\tR\borderRef\x12)\n" +
+	"\x10redemption_count\x18\x06 \x01(\x05R\x0fredemptionCount\x12'\n" +
+	"\x0fmax_redemptions\x18\a \x01(\x05R\x0emaxRedemptions\"s\n" +
+	"\x11LockCouponRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcheckout_id\x18\x02 \x01(\tR\n" +
+	"checkoutId\x12\x10\n" +
+	"\x03ttl\x18\x03 \x01(\tR\x03ttl\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\"h\n" +
+	"\x12LockCouponResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcheckout_id\x18\x02 \x01(\tR\n" +
+	"checkoutId\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\tR\texpiresAt\"\x84\x01\n" +
+	"\x17CommitRedemptionRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcheckout_id\x18\x02 \x01(\tR\n" +
+	"checkoutId\x12\x1b\n" +
+	"\torder_ref\x18\x03 \x01(\tR\borderRef\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\tR\x06userId\"I\n" +
+	"\x12ReleaseLockRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcheckout_id\x18\x02 \x01(\tR\n" +
+	"checkoutId\"1\n" +
+	"\x13ReleaseLockResponse\x12\x1a\n" +
+	"\breleased\x18\x01 \x01(\bR\breleased\"^\n" +
+	"\x1fSetCouponRedemptionLimitRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12'\n" +
+	"\x0fmax_redemptions\x18\x02 \x01(\x05R\x0emaxRedemptions\"\x8a\x01\n" +
+	" SetCouponRedemptionLimitResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12)\n" +
+	"\x10redemption_count\x18\x02 \x01(\x05R\x0fredemptionCount\x12'\n" +
+	"\x0fmax_redemptions\x18\x03 \x01(\x05R\x0emaxRedemptions\"K\n" +
+	"\x18ReverseRedemptionRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1b\n" +
+	"\torder_ref\x18\x02 \x01(\tR\borderRef\"\xcf\x01\n" +
+	"\x19ReverseRedemptionResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcampaign_id\x18\x02 \x01(\tR\n" +
+	"campaignId\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x1b\n" +
+	"\torder_ref\x18\x04 \x01(\tR\borderRef\x12\x1f\n" +
+	"\vreversed_at\x18\x05 \x01(\tR\n" +
+	"reversedAt\x12)\n" +
+	"\x10redemption_count\x18\x06 \x01(\x05R\x0fredemptionCount\"k\n" +
+	"\x15TransferCouponRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12 \n" +
+	"\ffrom_user_id\x18\x02 \x01(\tR\n" +
+	"fromUserId\x12\x1c\n" +
+	"\n" +
+	"to_user_id\x18\x03 \x01(\tR\btoUserId\"\x98\x01\n" +
+	"\x16TransferCouponResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcampaign_id\x18\x02 \x01(\tR\n" +
+	"campaignId\x12\"\n" +
+	"\rowner_user_id\x18\x03 \x01(\tR\vownerUserId\x12%\n" +
+	"\x0etransferred_at\x18\x04 \x01(\tR\rtransferredAt\"\x85\x01\n" +
+	"\x16ListUserCouponsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06states\x18\x02 \x03(\tR\x06states\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"\xe3\x02\n" +
+	"\n" +
+	"UserCoupon\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcampaign_id\x18\x02 \x01(\tR\n" +
+	"campaignId\x12#\n" +
+	"\rcampaign_name\x18\x03 \x01(\tR\fcampaignName\x12\x14\n" +
+	"\x05state\x18\x04 \x01(\tR\x05state\x12\x1b\n" +
+	"\tissued_at\x18\x05 \x01(\tR\bissuedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\tR\texpiresAt\x12,\n" +
+	"\abenefit\x18\a \x01(\v2\x12.coupon.v1.BenefitR\abenefit\x12'\n" +
+	"\x0fbenefit_summary\x18\b \x01(\tR\x0ebenefitSummary\x12)\n" +
+	"\x10redemption_count\x18\t \x01(\x05R\x0fredemptionCount\x12'\n" +
+	"\x0fmax_redemptions\x18\n" +
+	" \x01(\x05R\x0emaxRedemptions\"r\n" +
+	"\x17ListUserCouponsResponse\x12/\n" +
+	"\acoupons\x18\x01 \x03(\v2\x15.coupon.v1.UserCouponR\acoupons\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"-\n" +
+	"\x17GetCouponHistoryRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\"\xae\x01\n" +
+	"\vCouponEvent\x12\x14\n" +
+	"\x05event\x18\x01 \x01(\tR\x05event\x12\x1d\n" +
+	"\n" +
+	"from_state\x18\x02 \x01(\tR\tfromState\x12\x19\n" +
+	"\bto_state\x18\x03 \x01(\tR\atoState\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x18\n" +
+	"\adetails\x18\x05 \x01(\tR\adetails\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\"^\n" +
+	"\x18GetCouponHistoryResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12.\n" +
+	"\x06events\x18\x02 \x03(\v2\x16.coupon.v1.CouponEventR\x06events\"2\n" +
+	"\x1cListCouponRedemptionsRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\"\x8a\x01\n" +
+	"\x10CouponRedemption\x12\x1b\n" +
+	"\torder_ref\x18\x01 \x01(\tR\borderRef\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1f\n" +
+	"\vredeemed_at\x18\x03 \x01(\tR\n" +
+	"redeemedAt\x12\x1f\n" +
+	"\vreversed_at\x18\x04 \x01(\tR\n" +
+	"reversedAt\"\x9b\x01\n" +
+	"\x1dListCouponRedemptionsResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12'\n" +
+	"\x0fmax_redemptions\x18\x02 \x01(\x05R\x0emaxRedemptions\x12=\n" +
+	"\vredemptions\x18\x03 \x03(\v2\x1b.coupon.v1.CouponRedemptionR\vredemptions\"g\n" +
+	"\x13RevokeCouponRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12$\n" +
+	"\x0ereturn_to_pool\x18\x03 \x01(\bR\freturnToPool\"\xaa\x01\n" +
+	"\x14RevokeCouponResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcampaign_id\x18\x02 \x01(\tR\n" +
+	"campaignId\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x1d\n" +
+	"\n" +
+	"revoked_at\x18\x04 \x01(\tR\trevokedAt\x12(\n" +
+	"\x10returned_to_pool\x18\x05 \x01(\bR\x0ereturnedToPool\"s\n" +
+	"\bCartItem\x12\x10\n" +
+	"\x03sku\x18\x01 \x01(\tR\x03sku\x12\x1a\n" +
+	"\bcategory\x18\x02 \x01(\tR\bcategory\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x03R\bquantity\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x04 \x01(\tR\tunitPrice\"M\n" +
+	"\x04Cart\x12\x1a\n" +
+	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12)\n" +
+	"\x05items\x18\x02 \x03(\v2\x13.coupon.v1.CartItemR\x05items\"f\n" +
+	"\x12ApplyCouponRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12#\n" +
+	"\x04cart\x18\x03 \x01(\v2\x0f.coupon.v1.CartR\x04cart\"\x9b\x02\n" +
+	"\x13ApplyCouponResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcampaign_id\x18\x02 \x01(\tR\n" +
+	"campaignId\x12\x1e\n" +
+	"\n" +
+	"applicable\x18\x03 \x01(\bR\n" +
+	"applicable\x12=\n" +
+	"\x06reason\x18\x04 \x01(\x0e2%.coupon.v1.BenefitNotApplicableReasonR\x06reason\x12\x1a\n" +
+	"\bsubtotal\x18\x05 \x01(\tR\bsubtotal\x12\x1a\n" +
+	"\bdiscount\x18\x06 \x01(\tR\bdiscount\x12\x14\n" +
+	"\x05total\x18\a \x01(\tR\x05total\x12\"\n" +
+	"\rfree_item_sku\x18\b \x01(\tR\vfreeItemSku\"k\n" +
+	"\x15ValidateBasketRequest\x12\x14\n" +
+	"\x05codes\x18\x01 \x03(\tR\x05codes\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12#\n" +
+	"\x04cart\x18\x03 \x01(\v2\x0f.coupon.v1.CartR\x04cart\"\x83\x01\n" +
+	"\fBasketCoupon\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcampaign_id\x18\x02 \x01(\tR\n" +
+	"campaignId\x12\x1a\n" +
+	"\bdiscount\x18\x03 \x01(\tR\bdiscount\x12\"\n" +
+	"\rfree_item_sku\x18\x04 \x01(\tR\vfreeItemSku\"\xd3\x01\n" +
+	"\x0eRejectedCoupon\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x128\n" +
+	"\x06reason\x18\x02 \x01(\x0e2 .coupon.v1.BasketRejectionReasonR\x06reason\x12%\n" +
+	"\x0econflicts_with\x18\x03 \x01(\tR\rconflictsWith\x12L\n" +
+	"\x0ebenefit_reason\x18\x04 \x01(\x0e2%.coupon.v1.BenefitNotApplicableReasonR\rbenefitReason\"\xd0\x01\n" +
+	"\x16ValidateBasketResponse\x121\n" +
+	"\aapplied\x18\x01 \x03(\v2\x17.coupon.v1.BasketCouponR\aapplied\x125\n" +
+	"\brejected\x18\x02 \x03(\v2\x19.coupon.v1.RejectedCouponR\brejected\x12\x1a\n" +
+	"\bsubtotal\x18\x03 \x01(\tR\bsubtotal\x12\x1a\n" +
+	"\bdiscount\x18\x04 \x01(\tR\bdiscount\x12\x14\n" +
+	"\x05total\x18\x05 \x01(\tR\x05total\"D\n" +
+	"\x15ValidateCouponRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\xbb\x02\n" +
+	"\x16ValidateCouponResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x126\n" +
+	"\x06reason\x18\x02 \x01(\x0e2\x1e.coupon.v1.CouponInvalidReasonR\x06reason\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcampaign_id\x18\x04 \x01(\tR\n" +
+	"campaignId\x12#\n" +
+	"\rcampaign_name\x18\x05 \x01(\tR\fcampaignName\x12\"\n" +
+	"\rowner_user_id\x18\x06 \x01(\tR\vownerUserId\x12\x14\n" +
+	"\x05state\x18\a \x01(\tR\x05state\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\tR\texpiresAt\x12 \n" +
+	"\vsuggestions\x18\t \x03(\tR\vsuggestions\"V\n" +
+	"\x18StartPushIssuanceRequest\x12\x1f\n" +
+	"\vcampaign_id\x18\x01 \x01(\tR\n" +
+	"campaignId\x12\x19\n" +
+	"\buser_ids\x18\x02 \x03(\tR\auserIds\"S\n" +
+	"\x19StartPushIssuanceResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x1f\n" +
+	"\vtotal_users\x18\x02 \x01(\x05R\n" +
+	"totalUsers\"|\n" +
+	"\x12ImportCodesRequest\x12\x1f\n" +
+	"\vcampaign_id\x18\x01 \x01(\tR\n" +
+	"campaignId\x121\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x19.coupon.v1.CodeListFormatR\x06format\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"t\n" +
+	"\x13ImportCodesResponse\x12\x1a\n" +
+	"\bimported\x18\x01 \x01(\x05R\bimported\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\x02 \x01(\x05R\n" +
+	"duplicates\x12!\n" +
+	"\fcoupon_limit\x18\x03 \x01(\x05R\vcouponLimit\"Q\n" +
+	"\x18GenerateCodeBatchRequest\x12\x1f\n" +
+	"\vcampaign_id\x18\x01 \x01(\tR\n" +
+	"campaignId\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"2\n" +
+	"\x19GenerateCodeBatchResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"c\n" +
+	"\x16ExportCodeBatchRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x122\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x1a.coupon.v1.CodeBatchFormatR\x06format\"-\n" +
+	"\x17ExportCodeBatchResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"&\n" +
+	"\rGetJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\";\n" +
+	"\n" +
+	"JobFailure\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xe5\x02\n" +
+	"\x0eGetJobResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1f\n" +
+	"\vcampaign_id\x18\x03 \x01(\tR\n" +
+	"campaignId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x14\n" +
+	"\x05total\x18\x05 \x01(\x05R\x05total\x12\x1c\n" +
+	"\tprocessed\x18\x06 \x01(\x05R\tprocessed\x12\x1c\n" +
+	"\tsucceeded\x18\a \x01(\x05R\tsucceeded\x12\x16\n" +
+	"\x06failed\x18\b \x01(\x05R\x06failed\x121\n" +
+	"\bfailures\x18\t \x03(\v2\x15.coupon.v1.JobFailureR\bfailures\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\v \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\f \x01(\tR\tupdatedAt\"\x85\x01\n" +
+	"\x11EligibilityDenial\x12:\n" +
+	"\x06reason\x18\x01 \x01(\x0e2\".coupon.v1.EligibilityDenialReasonR\x06reason\x12\x16\n" +
+	"\x06clause\x18\x02 \x01(\tR\x06clause\x12\x1c\n" +
+	"\tattribute\x18\x03 \x01(\tR\tattribute\":\n" +
+	"\x17RotateSigningKeyRequest\x12\x1f\n" +
+	"\vcampaign_id\x18\x01 \x01(\tR\n" +
+	"campaignId\"v\n" +
+	"\x18RotateSigningKeyResponse\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\rR\x05keyId\x12$\n" +
+	"\x0eretired_key_id\x18\x02 \x01(\rR\fretiredKeyId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\tR\tcreatedAt\"=\n" +
+	"\x1aGetVerificationKeysRequest\x12\x1f\n" +
+	"\vcampaign_id\x18\x01 \x01(\tR\n" +
+	"campaignId\"\xd3\x01\n" +
+	"\x0fVerificationKey\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\rR\x05keyId\x12\x1f\n" +
+	"\vcampaign_id\x18\x02 \x01(\tR\n" +
+	"campaignId\x12\x16\n" +
+	"\x06secret\x18\x03 \x01(\fR\x06secret\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"retired_at\x18\x05 \x01(\tR\tretiredAt\x12\x1a\n" +
+	"\balphabet\x18\x06 \x01(\tR\balphabet\x12\x16\n" +
+	"\x06prefix\x18\a \x01(\tR\x06prefix\"]\n" +
+	"\x1bGetVerificationKeysResponse\x12.\n" +
+	"\x04keys\x18\x02 \x03(\v2\x1a.coupon.v1.VerificationKeyR\x04keysJ\x04\b\x01\x10\x02R\balphabet\"\xea\x01\n" +
+	"\x1bCreateCouponImageUrlRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x129\n" +
+	"\tsymbology\x18\x03 \x01(\x0e2\x1b.coupon.v1.BarcodeSymbologyR\tsymbology\x12.\n" +
+	"\x06format\x18\x04 \x01(\x0e2\x16.coupon.v1.ImageFormatR\x06format\x12\x12\n" +
+	"\x04text\x18\x05 \x01(\tR\x04text\x12\x1f\n" +
+	"\vttl_seconds\x18\x06 \x01(\x05R\n" +
+	"ttlSeconds\"O\n" +
+	"\x1cCreateCouponImageUrlResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\tR\texpiresAt*\xc4\x01\n" +
+	"\fCodeAlphabet\x12\x1d\n" +
+	"\x19CODE_ALPHABET_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14CODE_ALPHABET_HANGUL\x10\x01\x12\x18\n" +
+	"\x14CODE_ALPHABET_DIGITS\x10\x02\x12!\n" +
+	"\x1dCODE_ALPHABET_LATIN_UPPERCASE\x10\x03\x12\"\n" +
+	"\x1eCODE_ALPHABET_CROCKFORD_BASE32\x10\x04\x12\x1a\n" +
+	"\x16CODE_ALPHABET_READABLE\x10\x05*e\n" +
+	"\x0eCheckCharacter\x12\x1f\n" +
+	"\x1bCHECK_CHARACTER_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14CHECK_CHARACTER_LUHN\x10\x01\x12\x18\n" +
+	"\x14CHECK_CHARACTER_DAMM\x10\x02*o\n" +
+	"\x0eStackingPolicy\x12\x1f\n" +
+	"\x1bSTACKING_POLICY_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19STACKING_POLICY_STACKABLE\x10\x01\x12\x1d\n" +
+	"\x19STACKING_POLICY_EXCLUSIVE\x10\x02*\xbf\x03\n" +
+	"\x15BasketRejectionReason\x12'\n" +
+	"#BASKET_REJECTION_REASON_UNSPECIFIED\x10\x00\x12%\n" +
+	"!BASKET_REJECTION_REASON_NOT_FOUND\x10\x01\x12*\n" +
+	"&BASKET_REJECTION_REASON_NOT_REDEEMABLE\x10\x02\x12%\n" +
+	"!BASKET_REJECTION_REASON_NOT_OWNER\x10\x03\x12%\n" +
+	"!BASKET_REJECTION_REASON_DUPLICATE\x10\x04\x12&\n" +
+	"\"BASKET_REJECTION_REASON_NO_BENEFIT\x10\x05\x12*\n" +
+	"&BASKET_REJECTION_REASON_NOT_APPLICABLE\x10\x06\x12-\n" +
+	")BASKET_REJECTION_REASON_EXCLUSIVITY_GROUP\x10\a\x12)\n" +
+	"%BASKET_REJECTION_REASON_NOT_STACKABLE\x10\b\x12.\n" +
+	"*BASKET_REJECTION_REASON_NO_REMAINING_VALUE\x10\t*\x9f\x02\n" +
+	"\x1aBenefitNotApplicableReason\x12-\n" +
+	")BENEFIT_NOT_APPLICABLE_REASON_UNSPECIFIED\x10\x00\x123\n" +
+	"/BENEFIT_NOT_APPLICABLE_REASON_CURRENCY_MISMATCH\x10\x01\x123\n" +
+	"/BENEFIT_NOT_APPLICABLE_REASON_MIN_ORDER_NOT_MET\x10\x02\x123\n" +
+	"/BENEFIT_NOT_APPLICABLE_REASON_NO_ELIGIBLE_ITEMS\x10\x03\x123\n" +
+	"/BENEFIT_NOT_APPLICABLE_REASON_FREE_ITEM_MISSING\x10\x04*\x97\x02\n" +
+	"\x13CouponInvalidReason\x12%\n" +
+	"!COUPON_INVALID_REASON_UNSPECIFIED\x10\x00\x12#\n" +
+	"\x1fCOUPON_INVALID_REASON_NOT_FOUND\x10\x01\x12$\n" +
+	" COUPON_INVALID_REASON_NOT_ISSUED\x10\x02\x12\"\n" +
+	"\x1eCOUPON_INVALID_REASON_REDEEMED\x10\x03\x12\x1e\n" +
+	"\x1aCOUPON_INVALID_REASON_VOID\x10\x04\x12!\n" +
+	"\x1dCOUPON_INVALID_REASON_EXPIRED\x10\x05\x12'\n" +
+	"#COUPON_INVALID_REASON_PROBABLE_TYPO\x10\x06*L\n" +
+	"\x0eCodeListFormat\x12 \n" +
+	"\x1cCODE_LIST_FORMAT_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14CODE_LIST_FORMAT_CSV\x10\x01*Q\n" +
+	"\x0fCodeBatchFormat\x12!\n" +
+	"\x1dCODE_BATCH_FORMAT_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17CODE_BATCH_FORMAT_JSONL\x10\x01*\xd8\x01\n" +
+	"\x17EligibilityDenialReason\x12)\n" +
+	"%ELIGIBILITY_DENIAL_REASON_UNSPECIFIED\x10\x00\x120\n" +
+	",ELIGIBILITY_DENIAL_REASON_RULE_NOT_SATISFIED\x10\x01\x12/\n" +
+	"+ELIGIBILITY_DENIAL_REASON_MISSING_ATTRIBUTE\x10\x02\x12/\n" +
+	"+ELIGIBILITY_DENIAL_REASON_INVALID_ATTRIBUTE\x10\x03*q\n" +
+	"\x10BarcodeSymbology\x12!\n" +
+	"\x1dBARCODE_SYMBOLOGY_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19BARCODE_SYMBOLOGY_CODE128\x10\x01\x12\x1b\n" +
+	"\x17BARCODE_SYMBOLOGY_EAN13\x10\x02*A\n" +
+	"\vImageFormat\x12\x1c\n" +
+	"\x18IMAGE_FORMAT_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10IMAGE_FORMAT_SVG\x10\x012\x9e\x12\n" +
 	"\rCouponService\x12U\n" +
 	"\x0eCreateCampaign\x12 .coupon.v1.CreateCampaignRequest\x1a!.coupon.v1.CreateCampaignResponse\x12L\n" +
 	"\vGetCampaign\x12\x1d.coupon.v1.GetCampaignRequest\x1a\x1e.coupon.v1.GetCampaignResponse\x12L\n" +
-	"\vIssueCoupon\x12\x1d.coupon.v1.IssueCouponRequest\x1a\x1e.coupon.v1.IssueCouponResponseB\x1fZ\x1dcoupon-issuance/gen/coupon/v1b\x06proto3"
+	"\vIssueCoupon\x12\x1d.coupon.v1.IssueCouponRequest\x1a\x1e.coupon.v1.IssueCouponResponse\x12b\n" +
+	"\x11IssueCouponStream\x12#.coupon.v1.IssueCouponStreamRequest\x1a$.coupon.v1.IssueCouponStreamResponse(\x010\x01\x12`\n" +
+	"\x11StartPushIssuance\x12#.coupon.v1.StartPushIssuanceRequest\x1a$.coupon.v1.StartPushIssuanceResponse(\x01\x12N\n" +
+	"\vImportCodes\x12\x1d.coupon.v1.ImportCodesRequest\x1a\x1e.coupon.v1.ImportCodesResponse(\x01\x12=\n" +
+	"\x06GetJob\x12\x18.coupon.v1.GetJobRequest\x1a\x19.coupon.v1.GetJobResponse\x12^\n" +
+	"\x11GenerateCodeBatch\x12#.coupon.v1.GenerateCodeBatchRequest\x1a$.coupon.v1.GenerateCodeBatchResponse\x12Z\n" +
+	"\x0fExportCodeBatch\x12!.coupon.v1.ExportCodeBatchRequest\x1a\".coupon.v1.ExportCodeBatchResponse0\x01\x12O\n" +
+	"\fRedeemCoupon\x12\x1e.coupon.v1.RedeemCouponRequest\x1a\x1f.coupon.v1.RedeemCouponResponse\x12I\n" +
+	"\n" +
+	"LockCoupon\x12\x1c.coupon.v1.LockCouponRequest\x1a\x1d.coupon.v1.LockCouponResponse\x12W\n" +
+	"\x10CommitRedemption\x12\".coupon.v1.CommitRedemptionRequest\x1a\x1f.coupon.v1.RedeemCouponResponse\x12L\n" +
+	"\vReleaseLock\x12\x1d.coupon.v1.ReleaseLockRequest\x1a\x1e.coupon.v1.ReleaseLockResponse\x12U\n" +
+	"\x0eValidateCoupon\x12 .coupon.v1.ValidateCouponRequest\x1a!.coupon.v1.ValidateCouponResponse\x12O\n" +
+	"\fRevokeCoupon\x12\x1e.coupon.v1.RevokeCouponRequest\x1a\x1f.coupon.v1.RevokeCouponResponse\x12L\n" +
+	"\vApplyCoupon\x12\x1d.coupon.v1.ApplyCouponRequest\x1a\x1e.coupon.v1.ApplyCouponResponse\x12U\n" +
+	"\x0eValidateBasket\x12 .coupon.v1.ValidateBasketRequest\x1a!.coupon.v1.ValidateBasketResponse\x12s\n" +
+	"\x18SetCouponRedemptionLimit\x12*.coupon.v1.SetCouponRedemptionLimitRequest\x1a+.coupon.v1.SetCouponRedemptionLimitResponse\x12j\n" +
+	"\x15ListCouponRedemptions\x12'.coupon.v1.ListCouponRedemptionsRequest\x1a(.coupon.v1.ListCouponRedemptionsResponse\x12^\n" +
+	"\x11ReverseRedemption\x12#.coupon.v1.ReverseRedemptionRequest\x1a$.coupon.v1.ReverseRedemptionResponse\x12U\n" +
+	"\x0eTransferCoupon\x12 .coupon.v1.TransferCouponRequest\x1a!.coupon.v1.TransferCouponResponse\x12[\n" +
+	"\x10GetCouponHistory\x12\".coupon.v1.GetCouponHistoryRequest\x1a#.coupon.v1.GetCouponHistoryResponse\x12X\n" +
+	"\x0fListUserCoupons\x12!.coupon.v1.ListUserCouponsRequest\x1a\".coupon.v1.ListUserCouponsResponse\x12[\n" +
+	"\x10RotateSigningKey\x12\".coupon.v1.RotateSigningKeyRequest\x1a#.coupon.v1.RotateSigningKeyResponse\x12d\n" +
+	"\x13GetVerificationKeys\x12%.coupon.v1.GetVerificationKeysRequest\x1a&.coupon.v1.GetVerificationKeysResponse\x12g\n" +
+	"\x14CreateCouponImageUrl\x12&.coupon.v1.CreateCouponImageUrlRequest\x1a'.coupon.v1.CreateCouponImageUrlResponseB\x1fZ\x1dcoupon-issuance/gen/coupon/v1b\x06proto3"
 
 var (
 	file_coupon_v1_coupon_proto_rawDescOnce sync.Once
@@ -370,27 +5816,182 @@ func file_coupon_v1_coupon_proto_rawDescGZIP() []byte {
 	return file_coupon_v1_coupon_proto_rawDescData
 }
 
-var file_coupon_v1_coupon_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_coupon_v1_coupon_proto_enumTypes = make([]protoimpl.EnumInfo, 11)
+var file_coupon_v1_coupon_proto_msgTypes = make([]protoimpl.MessageInfo, 68)
 var file_coupon_v1_coupon_proto_goTypes = []any{
-	(*CreateCampaignRequest)(nil),  // 0: coupon.v1.CreateCampaignRequest
-	(*CreateCampaignResponse)(nil), // 1: coupon.v1.CreateCampaignResponse
-	(*GetCampaignRequest)(nil),     // 2: coupon.v1.GetCampaignRequest
-	(*GetCampaignResponse)(nil),    // 3: coupon.v1.GetCampaignResponse
-	(*IssueCouponRequest)(nil),     // 4: coupon.v1.IssueCouponRequest
-	(*IssueCouponResponse)(nil),    // 5: coupon.v1.IssueCouponResponse
+	(CodeAlphabet)(0),                        // 0: coupon.v1.CodeAlphabet
+	(CheckCharacter)(0),                      // 1: coupon.v1.CheckCharacter
+	(StackingPolicy)(0),                      // 2: coupon.v1.StackingPolicy
+	(BasketRejectionReason)(0),               // 3: coupon.v1.BasketRejectionReason
+	(BenefitNotApplicableReason)(0),          // 4: coupon.v1.BenefitNotApplicableReason
+	(CouponInvalidReason)(0),                 // 5: coupon.v1.CouponInvalidReason
+	(CodeListFormat)(0),                      // 6: coupon.v1.CodeListFormat
+	(CodeBatchFormat)(0),                     // 7: coupon.v1.CodeBatchFormat
+	(EligibilityDenialReason)(0),             // 8: coupon.v1.EligibilityDenialReason
+	(BarcodeSymbology)(0),                    // 9: coupon.v1.BarcodeSymbology
+	(ImageFormat)(0),                         // 10: coupon.v1.ImageFormat
+	(*CreateCampaignRequest)(nil),            // 11: coupon.v1.CreateCampaignRequest
+	(*CreateCampaignResponse)(nil),           // 12: coupon.v1.CreateCampaignResponse
+	(*GetCampaignRequest)(nil),               // 13: coupon.v1.GetCampaignRequest
+	(*GetCampaignResponse)(nil),              // 14: coupon.v1.GetCampaignResponse
+	(*CodeFormat)(nil),                       // 15: coupon.v1.CodeFormat
+	(*Benefit)(nil),                          // 16: coupon.v1.Benefit
+	(*PercentOff)(nil),                       // 17: coupon.v1.PercentOff
+	(*FixedAmount)(nil),                      // 18: coupon.v1.FixedAmount
+	(*FreeItem)(nil),                         // 19: coupon.v1.FreeItem
+	(*UserAttributes)(nil),                   // 20: coupon.v1.UserAttributes
+	(*IssueCouponRequest)(nil),               // 21: coupon.v1.IssueCouponRequest
+	(*IssueCouponResponse)(nil),              // 22: coupon.v1.IssueCouponResponse
+	(*IssueCouponStreamRequest)(nil),         // 23: coupon.v1.IssueCouponStreamRequest
+	(*IssueCouponStreamResponse)(nil),        // 24: coupon.v1.IssueCouponStreamResponse
+	(*IssueCouponError)(nil),                 // 25: coupon.v1.IssueCouponError
+	(*RedeemCouponRequest)(nil),              // 26: coupon.v1.RedeemCouponRequest
+	(*RedeemCouponResponse)(nil),             // 27: coupon.v1.RedeemCouponResponse
+	(*LockCouponRequest)(nil),                // 28: coupon.v1.LockCouponRequest
+	(*LockCouponResponse)(nil),               // 29: coupon.v1.LockCouponResponse
+	(*CommitRedemptionRequest)(nil),          // 30: coupon.v1.CommitRedemptionRequest
+	(*ReleaseLockRequest)(nil),               // 31: coupon.v1.ReleaseLockRequest
+	(*ReleaseLockResponse)(nil),              // 32: coupon.v1.ReleaseLockResponse
+	(*SetCouponRedemptionLimitRequest)(nil),  // 33: coupon.v1.SetCouponRedemptionLimitRequest
+	(*SetCouponRedemptionLimitResponse)(nil), // 34: coupon.v1.SetCouponRedemptionLimitResponse
+	(*ReverseRedemptionRequest)(nil),         // 35: coupon.v1.ReverseRedemptionRequest
+	(*ReverseRedemptionResponse)(nil),        // 36: coupon.v1.ReverseRedemptionResponse
+	(*TransferCouponRequest)(nil),            // 37: coupon.v1.TransferCouponRequest
+	(*TransferCouponResponse)(nil),           // 38: coupon.v1.TransferCouponResponse
+	(*ListUserCouponsRequest)(nil),           // 39: coupon.v1.ListUserCouponsRequest
+	(*UserCoupon)(nil),                       // 40: coupon.v1.UserCoupon
+	(*ListUserCouponsResponse)(nil),          // 41: coupon.v1.ListUserCouponsResponse
+	(*GetCouponHistoryRequest)(nil),          // 42: coupon.v1.GetCouponHistoryRequest
+	(*CouponEvent)(nil),                      // 43: coupon.v1.CouponEvent
+	(*GetCouponHistoryResponse)(nil),         // 44: coupon.v1.GetCouponHistoryResponse
+	(*ListCouponRedemptionsRequest)(nil),     // 45: coupon.v1.ListCouponRedemptionsRequest
+	(*CouponRedemption)(nil),                 // 46: coupon.v1.CouponRedemption
+	(*ListCouponRedemptionsResponse)(nil),    // 47: coupon.v1.ListCouponRedemptionsResponse
+	(*RevokeCouponRequest)(nil),              // 48: coupon.v1.RevokeCouponRequest
+	(*RevokeCouponResponse)(nil),             // 49: coupon.v1.RevokeCouponResponse
+	(*CartItem)(nil),                         // 50: coupon.v1.CartItem
+	(*Cart)(nil),                             // 51: coupon.v1.Cart
+	(*ApplyCouponRequest)(nil),               // 52: coupon.v1.ApplyCouponRequest
+	(*ApplyCouponResponse)(nil),              // 53: coupon.v1.ApplyCouponResponse
+	(*ValidateBasketRequest)(nil),            // 54: coupon.v1.ValidateBasketRequest
+	(*BasketCoupon)(nil),                     // 55: coupon.v1.BasketCoupon
+	(*RejectedCoupon)(nil),                   // 56: coupon.v1.RejectedCoupon
+	(*ValidateBasketResponse)(nil),           // 57: coupon.v1.ValidateBasketResponse
+	(*ValidateCouponRequest)(nil),            // 58: coupon.v1.ValidateCouponRequest
+	(*ValidateCouponResponse)(nil),           // 59: coupon.v1.ValidateCouponResponse
+	(*StartPushIssuanceRequest)(nil),         // 60: coupon.v1.StartPushIssuanceRequest
+	(*StartPushIssuanceResponse)(nil),        // 61: coupon.v1.StartPushIssuanceResponse
+	(*ImportCodesRequest)(nil),               // 62: coupon.v1.ImportCodesRequest
+	(*ImportCodesResponse)(nil),              // 63: coupon.v1.ImportCodesResponse
+	(*GenerateCodeBatchRequest)(nil),         // 64: coupon.v1.GenerateCodeBatchRequest
+	(*GenerateCodeBatchResponse)(nil),        // 65: coupon.v1.GenerateCodeBatchResponse
+	(*ExportCodeBatchRequest)(nil),           // 66: coupon.v1.ExportCodeBatchRequest
+	(*ExportCodeBatchResponse)(nil),          // 67: coupon.v1.ExportCodeBatchResponse
+	(*GetJobRequest)(nil),                    // 68: coupon.v1.GetJobRequest
+	(*JobFailure)(nil),                       // 69: coupon.v1.JobFailure
+	(*GetJobResponse)(nil),                   // 70: coupon.v1.GetJobResponse
+	(*EligibilityDenial)(nil),                // 71: coupon.v1.EligibilityDenial
+	(*RotateSigningKeyRequest)(nil),          // 72: coupon.v1.RotateSigningKeyRequest
+	(*RotateSigningKeyResponse)(nil),         // 73: coupon.v1.RotateSigningKeyResponse
+	(*GetVerificationKeysRequest)(nil),       // 74: coupon.v1.GetVerificationKeysRequest
+	(*VerificationKey)(nil),                  // 75: coupon.v1.VerificationKey
+	(*GetVerificationKeysResponse)(nil),      // 76: coupon.v1.GetVerificationKeysResponse
+	(*CreateCouponImageUrlRequest)(nil),      // 77: coupon.v1.CreateCouponImageUrlRequest
+	(*CreateCouponImageUrlResponse)(nil),     // 78: coupon.v1.CreateCouponImageUrlResponse
 }
 var file_coupon_v1_coupon_proto_depIdxs = []int32{
-	0, // 0: coupon.v1.CouponService.CreateCampaign:input_type -> coupon.v1.CreateCampaignRequest
-	2, // 1: coupon.v1.CouponService.GetCampaign:input_type -> coupon.v1.GetCampaignRequest
-	4, // 2: coupon.v1.CouponService.IssueCoupon:input_type -> coupon.v1.IssueCouponRequest
-	1, // 3: coupon.v1.CouponService.CreateCampaign:output_type -> coupon.v1.CreateCampaignResponse
-	3, // 4: coupon.v1.CouponService.GetCampaign:output_type -> coupon.v1.GetCampaignResponse
-	5, // 5: coupon.v1.CouponService.IssueCoupon:output_type -> coupon.v1.IssueCouponResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	16, // 0: coupon.v1.CreateCampaignRequest.benefit:type_name -> coupon.v1.Benefit
+	2,  // 1: coupon.v1.CreateCampaignRequest.stacking_policy:type_name -> coupon.v1.StackingPolicy
+	15, // 2: coupon.v1.CreateCampaignRequest.code_format:type_name -> coupon.v1.CodeFormat
+	16, // 3: coupon.v1.GetCampaignResponse.benefit:type_name -> coupon.v1.Benefit
+	2,  // 4: coupon.v1.GetCampaignResponse.stacking_policy:type_name -> coupon.v1.StackingPolicy
+	15, // 5: coupon.v1.GetCampaignResponse.code_format:type_name -> coupon.v1.CodeFormat
+	0,  // 6: coupon.v1.CodeFormat.alphabet:type_name -> coupon.v1.CodeAlphabet
+	1,  // 7: coupon.v1.CodeFormat.check_character:type_name -> coupon.v1.CheckCharacter
+	17, // 8: coupon.v1.Benefit.percent_off:type_name -> coupon.v1.PercentOff
+	18, // 9: coupon.v1.Benefit.fixed_amount:type_name -> coupon.v1.FixedAmount
+	19, // 10: coupon.v1.Benefit.free_item:type_name -> coupon.v1.FreeItem
+	20, // 11: coupon.v1.IssueCouponRequest.attributes:type_name -> coupon.v1.UserAttributes
+	21, // 12: coupon.v1.IssueCouponStreamRequest.request:type_name -> coupon.v1.IssueCouponRequest
+	25, // 13: coupon.v1.IssueCouponStreamResponse.error:type_name -> coupon.v1.IssueCouponError
+	71, // 14: coupon.v1.IssueCouponError.eligibility_denial:type_name -> coupon.v1.EligibilityDenial
+	16, // 15: coupon.v1.UserCoupon.benefit:type_name -> coupon.v1.Benefit
+	40, // 16: coupon.v1.ListUserCouponsResponse.coupons:type_name -> coupon.v1.UserCoupon
+	43, // 17: coupon.v1.GetCouponHistoryResponse.events:type_name -> coupon.v1.CouponEvent
+	46, // 18: coupon.v1.ListCouponRedemptionsResponse.redemptions:type_name -> coupon.v1.CouponRedemption
+	50, // 19: coupon.v1.Cart.items:type_name -> coupon.v1.CartItem
+	51, // 20: coupon.v1.ApplyCouponRequest.cart:type_name -> coupon.v1.Cart
+	4,  // 21: coupon.v1.ApplyCouponResponse.reason:type_name -> coupon.v1.BenefitNotApplicableReason
+	51, // 22: coupon.v1.ValidateBasketRequest.cart:type_name -> coupon.v1.Cart
+	3,  // 23: coupon.v1.RejectedCoupon.reason:type_name -> coupon.v1.BasketRejectionReason
+	4,  // 24: coupon.v1.RejectedCoupon.benefit_reason:type_name -> coupon.v1.BenefitNotApplicableReason
+	55, // 25: coupon.v1.ValidateBasketResponse.applied:type_name -> coupon.v1.BasketCoupon
+	56, // 26: coupon.v1.ValidateBasketResponse.rejected:type_name -> coupon.v1.RejectedCoupon
+	5,  // 27: coupon.v1.ValidateCouponResponse.reason:type_name -> coupon.v1.CouponInvalidReason
+	6,  // 28: coupon.v1.ImportCodesRequest.format:type_name -> coupon.v1.CodeListFormat
+	7,  // 29: coupon.v1.ExportCodeBatchRequest.format:type_name -> coupon.v1.CodeBatchFormat
+	69, // 30: coupon.v1.GetJobResponse.failures:type_name -> coupon.v1.JobFailure
+	8,  // 31: coupon.v1.EligibilityDenial.reason:type_name -> coupon.v1.EligibilityDenialReason
+	75, // 32: coupon.v1.GetVerificationKeysResponse.keys:type_name -> coupon.v1.VerificationKey
+	9,  // 33: coupon.v1.CreateCouponImageUrlRequest.symbology:type_name -> coupon.v1.BarcodeSymbology
+	10, // 34: coupon.v1.CreateCouponImageUrlRequest.format:type_name -> coupon.v1.ImageFormat
+	11, // 35: coupon.v1.CouponService.CreateCampaign:input_type -> coupon.v1.CreateCampaignRequest
+	13, // 36: coupon.v1.CouponService.GetCampaign:input_type -> coupon.v1.GetCampaignRequest
+	21, // 37: coupon.v1.CouponService.IssueCoupon:input_type -> coupon.v1.IssueCouponRequest
+	23, // 38: coupon.v1.CouponService.IssueCouponStream:input_type -> coupon.v1.IssueCouponStreamRequest
+	60, // 39: coupon.v1.CouponService.StartPushIssuance:input_type -> coupon.v1.StartPushIssuanceRequest
+	62, // 40: coupon.v1.CouponService.ImportCodes:input_type -> coupon.v1.ImportCodesRequest
+	68, // 41: coupon.v1.CouponService.GetJob:input_type -> coupon.v1.GetJobRequest
+	64, // 42: coupon.v1.CouponService.GenerateCodeBatch:input_type -> coupon.v1.GenerateCodeBatchRequest
+	66, // 43: coupon.v1.CouponService.ExportCodeBatch:input_type -> coupon.v1.ExportCodeBatchRequest
+	26, // 44: coupon.v1.CouponService.RedeemCoupon:input_type -> coupon.v1.RedeemCouponRequest
+	28, // 45: coupon.v1.CouponService.LockCoupon:input_type -> coupon.v1.LockCouponRequest
+	30, // 46: coupon.v1.CouponService.CommitRedemption:input_type -> coupon.v1.CommitRedemptionRequest
+	31, // 47: coupon.v1.CouponService.ReleaseLock:input_type -> coupon.v1.ReleaseLockRequest
+	58, // 48: coupon.v1.CouponService.ValidateCoupon:input_type -> coupon.v1.ValidateCouponRequest
+	48, // 49: coupon.v1.CouponService.RevokeCoupon:input_type -> coupon.v1.RevokeCouponRequest
+	52, // 50: coupon.v1.CouponService.ApplyCoupon:input_type -> coupon.v1.ApplyCouponRequest
+	54, // 51: coupon.v1.CouponService.ValidateBasket:input_type -> coupon.v1.ValidateBasketRequest
+	33, // 52: coupon.v1.CouponService.SetCouponRedemptionLimit:input_type -> coupon.v1.SetCouponRedemptionLimitRequest
+	45, // 53: coupon.v1.CouponService.ListCouponRedemptions:input_type -> coupon.v1.ListCouponRedemptionsRequest
+	35, // 54: coupon.v1.CouponService.ReverseRedemption:input_type -> coupon.v1.ReverseRedemptionRequest
+	37, // 55: coupon.v1.CouponService.TransferCoupon:input_type -> coupon.v1.TransferCouponRequest
+	42, // 56: coupon.v1.CouponService.GetCouponHistory:input_type -> coupon.v1.GetCouponHistoryRequest
+	39, // 57: coupon.v1.CouponService.ListUserCoupons:input_type -> coupon.v1.ListUserCouponsRequest
+	72, // 58: coupon.v1.CouponService.RotateSigningKey:input_type -> coupon.v1.RotateSigningKeyRequest
+	74, // 59: coupon.v1.CouponService.GetVerificationKeys:input_type -> coupon.v1.GetVerificationKeysRequest
+	77, // 60: coupon.v1.CouponService.CreateCouponImageUrl:input_type -> coupon.v1.CreateCouponImageUrlRequest
+	12, // 61: coupon.v1.CouponService.CreateCampaign:output_type -> coupon.v1.CreateCampaignResponse
+	14, // 62: coupon.v1.CouponService.GetCampaign:output_type -> coupon.v1.GetCampaignResponse
+	22, // 63: coupon.v1.CouponService.IssueCoupon:output_type -> coupon.v1.IssueCouponResponse
+	24, // 64: coupon.v1.CouponService.IssueCouponStream:output_type -> coupon.v1.IssueCouponStreamResponse
+	61, // 65: coupon.v1.CouponService.StartPushIssuance:output_type -> coupon.v1.StartPushIssuanceResponse
+	63, // 66: coupon.v1.CouponService.ImportCodes:output_type -> coupon.v1.ImportCodesResponse
+	70, // 67: coupon.v1.CouponService.GetJob:output_type -> coupon.v1.GetJobResponse
+	65, // 68: coupon.v1.CouponService.GenerateCodeBatch:output_type -> coupon.v1.GenerateCodeBatchResponse
+	67, // 69: coupon.v1.CouponService.ExportCodeBatch:output_type -> coupon.v1.ExportCodeBatchResponse
+	27, // 70: coupon.v1.CouponService.RedeemCoupon:output_type -> coupon.v1.RedeemCouponResponse
+	29, // 71: coupon.v1.CouponService.LockCoupon:output_type -> coupon.v1.LockCouponResponse
+	27, // 72: coupon.v1.CouponService.CommitRedemption:output_type -> coupon.v1.RedeemCouponResponse
+	32, // 73: coupon.v1.CouponService.ReleaseLock:output_type -> coupon.v1.ReleaseLockResponse
+	59, // 74: coupon.v1.CouponService.ValidateCoupon:output_type -> coupon.v1.ValidateCouponResponse
+	49, // 75: coupon.v1.CouponService.RevokeCoupon:output_type -> coupon.v1.RevokeCouponResponse
+	53, // 76: coupon.v1.CouponService.ApplyCoupon:output_type -> coupon.v1.ApplyCouponResponse
+	57, // 77: coupon.v1.CouponService.ValidateBasket:output_type -> coupon.v1.ValidateBasketResponse
+	34, // 78: coupon.v1.CouponService.SetCouponRedemptionLimit:output_type -> coupon.v1.SetCouponRedemptionLimitResponse
+	47, // 79: coupon.v1.CouponService.ListCouponRedemptions:output_type -> coupon.v1.ListCouponRedemptionsResponse
+	36, // 80: coupon.v1.CouponService.ReverseRedemption:output_type -> coupon.v1.ReverseRedemptionResponse
+	38, // 81: coupon.v1.CouponService.TransferCoupon:output_type -> coupon.v1.TransferCouponResponse
+	44, // 82: coupon.v1.CouponService.GetCouponHistory:output_type -> coupon.v1.GetCouponHistoryResponse
+	41, // 83: coupon.v1.CouponService.ListUserCoupons:output_type -> coupon.v1.ListUserCouponsResponse
+	73, // 84: coupon.v1.CouponService.RotateSigningKey:output_type -> coupon.v1.RotateSigningKeyResponse
+	76, // 85: coupon.v1.CouponService.GetVerificationKeys:output_type -> coupon.v1.GetVerificationKeysResponse
+	78, // 86: coupon.v1.CouponService.CreateCouponImageUrl:output_type -> coupon.v1.CreateCouponImageUrlResponse
+	61, // [61:87] is the sub-list for method output_type
+	35, // [35:61] is the sub-list for method input_type
+	35, // [35:35] is the sub-list for extension type_name
+	35, // [35:35] is the sub-list for extension extendee
+	0,  // [0:35] is the sub-list for field type_name
 }
 
 func init() { file_coupon_v1_coupon_proto_init() }
@@ -398,18 +5999,28 @@ func file_coupon_v1_coupon_proto_init() {
 	if File_coupon_v1_coupon_proto != nil {
 		return
 	}
+	file_coupon_v1_coupon_proto_msgTypes[5].OneofWrappers = []any{
+		(*Benefit_PercentOff)(nil),
+		(*Benefit_FixedAmount)(nil),
+		(*Benefit_FreeItem)(nil),
+	}
+	file_coupon_v1_coupon_proto_msgTypes[13].OneofWrappers = []any{
+		(*IssueCouponStreamResponse_CouponCode)(nil),
+		(*IssueCouponStreamResponse_Error)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_coupon_v1_coupon_proto_rawDesc), len(file_coupon_v1_coupon_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      11,
+			NumMessages:   68,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_coupon_v1_coupon_proto_goTypes,
 		DependencyIndexes: file_coupon_v1_coupon_proto_depIdxs,
+		EnumInfos:         file_coupon_v1_coupon_proto_enumTypes,
 		MessageInfos:      file_coupon_v1_coupon_proto_msgTypes,
 	}.Build()
 	File_coupon_v1_coupon_proto = out.File
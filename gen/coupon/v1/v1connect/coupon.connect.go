@@ -42,6 +42,74 @@ const (
 	// CouponServiceIssueCouponProcedure is the fully-qualified name of the CouponService's IssueCoupon
 	// RPC.
 	CouponServiceIssueCouponProcedure = "/coupon.v1.CouponService/IssueCoupon"
+	// CouponServiceIssueCouponStreamProcedure is the fully-qualified name of the CouponService's
+	// IssueCouponStream RPC.
+	CouponServiceIssueCouponStreamProcedure = "/coupon.v1.CouponService/IssueCouponStream"
+	// CouponServiceStartPushIssuanceProcedure is the fully-qualified name of the CouponService's
+	// StartPushIssuance RPC.
+	CouponServiceStartPushIssuanceProcedure = "/coupon.v1.CouponService/StartPushIssuance"
+	// CouponServiceImportCodesProcedure is the fully-qualified name of the CouponService's ImportCodes
+	// RPC.
+	CouponServiceImportCodesProcedure = "/coupon.v1.CouponService/ImportCodes"
+	// CouponServiceGetJobProcedure is the fully-qualified name of the CouponService's GetJob RPC.
+	CouponServiceGetJobProcedure = "/coupon.v1.CouponService/GetJob"
+	// CouponServiceGenerateCodeBatchProcedure is the fully-qualified name of the CouponService's
+	// GenerateCodeBatch RPC.
+	CouponServiceGenerateCodeBatchProcedure = "/coupon.v1.CouponService/GenerateCodeBatch"
+	// CouponServiceExportCodeBatchProcedure is the fully-qualified name of the CouponService's
+	// ExportCodeBatch RPC.
+	CouponServiceExportCodeBatchProcedure = "/coupon.v1.CouponService/ExportCodeBatch"
+	// CouponServiceRedeemCouponProcedure is the fully-qualified name of the CouponService's
+	// RedeemCoupon RPC.
+	CouponServiceRedeemCouponProcedure = "/coupon.v1.CouponService/RedeemCoupon"
+	// CouponServiceLockCouponProcedure is the fully-qualified name of the CouponService's LockCoupon
+	// RPC.
+	CouponServiceLockCouponProcedure = "/coupon.v1.CouponService/LockCoupon"
+	// CouponServiceCommitRedemptionProcedure is the fully-qualified name of the CouponService's
+	// CommitRedemption RPC.
+	CouponServiceCommitRedemptionProcedure = "/coupon.v1.CouponService/CommitRedemption"
+	// CouponServiceReleaseLockProcedure is the fully-qualified name of the CouponService's ReleaseLock
+	// RPC.
+	CouponServiceReleaseLockProcedure = "/coupon.v1.CouponService/ReleaseLock"
+	// CouponServiceValidateCouponProcedure is the fully-qualified name of the CouponService's
+	// ValidateCoupon RPC.
+	CouponServiceValidateCouponProcedure = "/coupon.v1.CouponService/ValidateCoupon"
+	// CouponServiceRevokeCouponProcedure is the fully-qualified name of the CouponService's
+	// RevokeCoupon RPC.
+	CouponServiceRevokeCouponProcedure = "/coupon.v1.CouponService/RevokeCoupon"
+	// CouponServiceApplyCouponProcedure is the fully-qualified name of the CouponService's ApplyCoupon
+	// RPC.
+	CouponServiceApplyCouponProcedure = "/coupon.v1.CouponService/ApplyCoupon"
+	// CouponServiceValidateBasketProcedure is the fully-qualified name of the CouponService's
+	// ValidateBasket RPC.
+	CouponServiceValidateBasketProcedure = "/coupon.v1.CouponService/ValidateBasket"
+	// CouponServiceSetCouponRedemptionLimitProcedure is the fully-qualified name of the CouponService's
+	// SetCouponRedemptionLimit RPC.
+	CouponServiceSetCouponRedemptionLimitProcedure = "/coupon.v1.CouponService/SetCouponRedemptionLimit"
+	// CouponServiceListCouponRedemptionsProcedure is the fully-qualified name of the CouponService's
+	// ListCouponRedemptions RPC.
+	CouponServiceListCouponRedemptionsProcedure = "/coupon.v1.CouponService/ListCouponRedemptions"
+	// CouponServiceReverseRedemptionProcedure is the fully-qualified name of the CouponService's
+	// ReverseRedemption RPC.
+	CouponServiceReverseRedemptionProcedure = "/coupon.v1.CouponService/ReverseRedemption"
+	// CouponServiceTransferCouponProcedure is the fully-qualified name of the CouponService's
+	// TransferCoupon RPC.
+	CouponServiceTransferCouponProcedure = "/coupon.v1.CouponService/TransferCoupon"
+	// CouponServiceGetCouponHistoryProcedure is the fully-qualified name of the CouponService's
+	// GetCouponHistory RPC.
+	CouponServiceGetCouponHistoryProcedure = "/coupon.v1.CouponService/GetCouponHistory"
+	// CouponServiceListUserCouponsProcedure is the fully-qualified name of the CouponService's
+	// ListUserCoupons RPC.
+	CouponServiceListUserCouponsProcedure = "/coupon.v1.CouponService/ListUserCoupons"
+	// CouponServiceRotateSigningKeyProcedure is the fully-qualified name of the CouponService's
+	// RotateSigningKey RPC.
+	CouponServiceRotateSigningKeyProcedure = "/coupon.v1.CouponService/RotateSigningKey"
+	// CouponServiceGetVerificationKeysProcedure is the fully-qualified name of the CouponService's
+	// GetVerificationKeys RPC.
+	CouponServiceGetVerificationKeysProcedure = "/coupon.v1.CouponService/GetVerificationKeys"
+	// CouponServiceCreateCouponImageUrlProcedure is the fully-qualified name of the CouponService's
+	// CreateCouponImageUrl RPC.
+	CouponServiceCreateCouponImageUrlProcedure = "/coupon.v1.CouponService/CreateCouponImageUrl"
 )
 
 // CouponServiceClient is a client for the coupon.v1.CouponService service.
@@ -49,6 +117,29 @@ type CouponServiceClient interface {
 	CreateCampaign(context.Context, *connect.Request[v1.CreateCampaignRequest]) (*connect.Response[v1.CreateCampaignResponse], error)
 	GetCampaign(context.Context, *connect.Request[v1.GetCampaignRequest]) (*connect.Response[v1.GetCampaignResponse], error)
 	IssueCoupon(context.Context, *connect.Request[v1.IssueCouponRequest]) (*connect.Response[v1.IssueCouponResponse], error)
+	IssueCouponStream(context.Context) *connect.BidiStreamForClient[v1.IssueCouponStreamRequest, v1.IssueCouponStreamResponse]
+	StartPushIssuance(context.Context) *connect.ClientStreamForClient[v1.StartPushIssuanceRequest, v1.StartPushIssuanceResponse]
+	ImportCodes(context.Context) *connect.ClientStreamForClient[v1.ImportCodesRequest, v1.ImportCodesResponse]
+	GetJob(context.Context, *connect.Request[v1.GetJobRequest]) (*connect.Response[v1.GetJobResponse], error)
+	GenerateCodeBatch(context.Context, *connect.Request[v1.GenerateCodeBatchRequest]) (*connect.Response[v1.GenerateCodeBatchResponse], error)
+	ExportCodeBatch(context.Context, *connect.Request[v1.ExportCodeBatchRequest]) (*connect.ServerStreamForClient[v1.ExportCodeBatchResponse], error)
+	RedeemCoupon(context.Context, *connect.Request[v1.RedeemCouponRequest]) (*connect.Response[v1.RedeemCouponResponse], error)
+	LockCoupon(context.Context, *connect.Request[v1.LockCouponRequest]) (*connect.Response[v1.LockCouponResponse], error)
+	CommitRedemption(context.Context, *connect.Request[v1.CommitRedemptionRequest]) (*connect.Response[v1.RedeemCouponResponse], error)
+	ReleaseLock(context.Context, *connect.Request[v1.ReleaseLockRequest]) (*connect.Response[v1.ReleaseLockResponse], error)
+	ValidateCoupon(context.Context, *connect.Request[v1.ValidateCouponRequest]) (*connect.Response[v1.ValidateCouponResponse], error)
+	RevokeCoupon(context.Context, *connect.Request[v1.RevokeCouponRequest]) (*connect.Response[v1.RevokeCouponResponse], error)
+	ApplyCoupon(context.Context, *connect.Request[v1.ApplyCouponRequest]) (*connect.Response[v1.ApplyCouponResponse], error)
+	ValidateBasket(context.Context, *connect.Request[v1.ValidateBasketRequest]) (*connect.Response[v1.ValidateBasketResponse], error)
+	SetCouponRedemptionLimit(context.Context, *connect.Request[v1.SetCouponRedemptionLimitRequest]) (*connect.Response[v1.SetCouponRedemptionLimitResponse], error)
+	ListCouponRedemptions(context.Context, *connect.Request[v1.ListCouponRedemptionsRequest]) (*connect.Response[v1.ListCouponRedemptionsResponse], error)
+	ReverseRedemption(context.Context, *connect.Request[v1.ReverseRedemptionRequest]) (*connect.Response[v1.ReverseRedemptionResponse], error)
+	TransferCoupon(context.Context, *connect.Request[v1.TransferCouponRequest]) (*connect.Response[v1.TransferCouponResponse], error)
+	GetCouponHistory(context.Context, *connect.Request[v1.GetCouponHistoryRequest]) (*connect.Response[v1.GetCouponHistoryResponse], error)
+	ListUserCoupons(context.Context, *connect.Request[v1.ListUserCouponsRequest]) (*connect.Response[v1.ListUserCouponsResponse], error)
+	RotateSigningKey(context.Context, *connect.Request[v1.RotateSigningKeyRequest]) (*connect.Response[v1.RotateSigningKeyResponse], error)
+	GetVerificationKeys(context.Context, *connect.Request[v1.GetVerificationKeysRequest]) (*connect.Response[v1.GetVerificationKeysResponse], error)
+	CreateCouponImageUrl(context.Context, *connect.Request[v1.CreateCouponImageUrlRequest]) (*connect.Response[v1.CreateCouponImageUrlResponse], error)
 }
 
 // NewCouponServiceClient constructs a client for the coupon.v1.CouponService service. By default,
@@ -80,14 +171,175 @@ func NewCouponServiceClient(httpClient connect.HTTPClient, baseURL string, opts
 			connect.WithSchema(couponServiceMethods.ByName("IssueCoupon")),
 			connect.WithClientOptions(opts...),
 		),
+		issueCouponStream: connect.NewClient[v1.IssueCouponStreamRequest, v1.IssueCouponStreamResponse](
+			httpClient,
+			baseURL+CouponServiceIssueCouponStreamProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("IssueCouponStream")),
+			connect.WithClientOptions(opts...),
+		),
+		startPushIssuance: connect.NewClient[v1.StartPushIssuanceRequest, v1.StartPushIssuanceResponse](
+			httpClient,
+			baseURL+CouponServiceStartPushIssuanceProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("StartPushIssuance")),
+			connect.WithClientOptions(opts...),
+		),
+		importCodes: connect.NewClient[v1.ImportCodesRequest, v1.ImportCodesResponse](
+			httpClient,
+			baseURL+CouponServiceImportCodesProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("ImportCodes")),
+			connect.WithClientOptions(opts...),
+		),
+		getJob: connect.NewClient[v1.GetJobRequest, v1.GetJobResponse](
+			httpClient,
+			baseURL+CouponServiceGetJobProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("GetJob")),
+			connect.WithClientOptions(opts...),
+		),
+		generateCodeBatch: connect.NewClient[v1.GenerateCodeBatchRequest, v1.GenerateCodeBatchResponse](
+			httpClient,
+			baseURL+CouponServiceGenerateCodeBatchProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("GenerateCodeBatch")),
+			connect.WithClientOptions(opts...),
+		),
+		exportCodeBatch: connect.NewClient[v1.ExportCodeBatchRequest, v1.ExportCodeBatchResponse](
+			httpClient,
+			baseURL+CouponServiceExportCodeBatchProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("ExportCodeBatch")),
+			connect.WithClientOptions(opts...),
+		),
+		redeemCoupon: connect.NewClient[v1.RedeemCouponRequest, v1.RedeemCouponResponse](
+			httpClient,
+			baseURL+CouponServiceRedeemCouponProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("RedeemCoupon")),
+			connect.WithClientOptions(opts...),
+		),
+		lockCoupon: connect.NewClient[v1.LockCouponRequest, v1.LockCouponResponse](
+			httpClient,
+			baseURL+CouponServiceLockCouponProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("LockCoupon")),
+			connect.WithClientOptions(opts...),
+		),
+		commitRedemption: connect.NewClient[v1.CommitRedemptionRequest, v1.RedeemCouponResponse](
+			httpClient,
+			baseURL+CouponServiceCommitRedemptionProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("CommitRedemption")),
+			connect.WithClientOptions(opts...),
+		),
+		releaseLock: connect.NewClient[v1.ReleaseLockRequest, v1.ReleaseLockResponse](
+			httpClient,
+			baseURL+CouponServiceReleaseLockProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("ReleaseLock")),
+			connect.WithClientOptions(opts...),
+		),
+		validateCoupon: connect.NewClient[v1.ValidateCouponRequest, v1.ValidateCouponResponse](
+			httpClient,
+			baseURL+CouponServiceValidateCouponProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("ValidateCoupon")),
+			connect.WithClientOptions(opts...),
+		),
+		revokeCoupon: connect.NewClient[v1.RevokeCouponRequest, v1.RevokeCouponResponse](
+			httpClient,
+			baseURL+CouponServiceRevokeCouponProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("RevokeCoupon")),
+			connect.WithClientOptions(opts...),
+		),
+		applyCoupon: connect.NewClient[v1.ApplyCouponRequest, v1.ApplyCouponResponse](
+			httpClient,
+			baseURL+CouponServiceApplyCouponProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("ApplyCoupon")),
+			connect.WithClientOptions(opts...),
+		),
+		validateBasket: connect.NewClient[v1.ValidateBasketRequest, v1.ValidateBasketResponse](
+			httpClient,
+			baseURL+CouponServiceValidateBasketProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("ValidateBasket")),
+			connect.WithClientOptions(opts...),
+		),
+		setCouponRedemptionLimit: connect.NewClient[v1.SetCouponRedemptionLimitRequest, v1.SetCouponRedemptionLimitResponse](
+			httpClient,
+			baseURL+CouponServiceSetCouponRedemptionLimitProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("SetCouponRedemptionLimit")),
+			connect.WithClientOptions(opts...),
+		),
+		listCouponRedemptions: connect.NewClient[v1.ListCouponRedemptionsRequest, v1.ListCouponRedemptionsResponse](
+			httpClient,
+			baseURL+CouponServiceListCouponRedemptionsProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("ListCouponRedemptions")),
+			connect.WithClientOptions(opts...),
+		),
+		reverseRedemption: connect.NewClient[v1.ReverseRedemptionRequest, v1.ReverseRedemptionResponse](
+			httpClient,
+			baseURL+CouponServiceReverseRedemptionProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("ReverseRedemption")),
+			connect.WithClientOptions(opts...),
+		),
+		transferCoupon: connect.NewClient[v1.TransferCouponRequest, v1.TransferCouponResponse](
+			httpClient,
+			baseURL+CouponServiceTransferCouponProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("TransferCoupon")),
+			connect.WithClientOptions(opts...),
+		),
+		getCouponHistory: connect.NewClient[v1.GetCouponHistoryRequest, v1.GetCouponHistoryResponse](
+			httpClient,
+			baseURL+CouponServiceGetCouponHistoryProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("GetCouponHistory")),
+			connect.WithClientOptions(opts...),
+		),
+		listUserCoupons: connect.NewClient[v1.ListUserCouponsRequest, v1.ListUserCouponsResponse](
+			httpClient,
+			baseURL+CouponServiceListUserCouponsProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("ListUserCoupons")),
+			connect.WithClientOptions(opts...),
+		),
+		rotateSigningKey: connect.NewClient[v1.RotateSigningKeyRequest, v1.RotateSigningKeyResponse](
+			httpClient,
+			baseURL+CouponServiceRotateSigningKeyProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("RotateSigningKey")),
+			connect.WithClientOptions(opts...),
+		),
+		getVerificationKeys: connect.NewClient[v1.GetVerificationKeysRequest, v1.GetVerificationKeysResponse](
+			httpClient,
+			baseURL+CouponServiceGetVerificationKeysProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("GetVerificationKeys")),
+			connect.WithClientOptions(opts...),
+		),
+		createCouponImageUrl: connect.NewClient[v1.CreateCouponImageUrlRequest, v1.CreateCouponImageUrlResponse](
+			httpClient,
+			baseURL+CouponServiceCreateCouponImageUrlProcedure,
+			connect.WithSchema(couponServiceMethods.ByName("CreateCouponImageUrl")),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // couponServiceClient implements CouponServiceClient.
 type couponServiceClient struct {
-	createCampaign *connect.Client[v1.CreateCampaignRequest, v1.CreateCampaignResponse]
-	getCampaign    *connect.Client[v1.GetCampaignRequest, v1.GetCampaignResponse]
-	issueCoupon    *connect.Client[v1.IssueCouponRequest, v1.IssueCouponResponse]
+	createCampaign           *connect.Client[v1.CreateCampaignRequest, v1.CreateCampaignResponse]
+	getCampaign              *connect.Client[v1.GetCampaignRequest, v1.GetCampaignResponse]
+	issueCoupon              *connect.Client[v1.IssueCouponRequest, v1.IssueCouponResponse]
+	issueCouponStream        *connect.Client[v1.IssueCouponStreamRequest, v1.IssueCouponStreamResponse]
+	startPushIssuance        *connect.Client[v1.StartPushIssuanceRequest, v1.StartPushIssuanceResponse]
+	importCodes              *connect.Client[v1.ImportCodesRequest, v1.ImportCodesResponse]
+	getJob                   *connect.Client[v1.GetJobRequest, v1.GetJobResponse]
+	generateCodeBatch        *connect.Client[v1.GenerateCodeBatchRequest, v1.GenerateCodeBatchResponse]
+	exportCodeBatch          *connect.Client[v1.ExportCodeBatchRequest, v1.ExportCodeBatchResponse]
+	redeemCoupon             *connect.Client[v1.RedeemCouponRequest, v1.RedeemCouponResponse]
+	lockCoupon               *connect.Client[v1.LockCouponRequest, v1.LockCouponResponse]
+	commitRedemption         *connect.Client[v1.CommitRedemptionRequest, v1.RedeemCouponResponse]
+	releaseLock              *connect.Client[v1.ReleaseLockRequest, v1.ReleaseLockResponse]
+	validateCoupon           *connect.Client[v1.ValidateCouponRequest, v1.ValidateCouponResponse]
+	revokeCoupon             *connect.Client[v1.RevokeCouponRequest, v1.RevokeCouponResponse]
+	applyCoupon              *connect.Client[v1.ApplyCouponRequest, v1.ApplyCouponResponse]
+	validateBasket           *connect.Client[v1.ValidateBasketRequest, v1.ValidateBasketResponse]
+	setCouponRedemptionLimit *connect.Client[v1.SetCouponRedemptionLimitRequest, v1.SetCouponRedemptionLimitResponse]
+	listCouponRedemptions    *connect.Client[v1.ListCouponRedemptionsRequest, v1.ListCouponRedemptionsResponse]
+	reverseRedemption        *connect.Client[v1.ReverseRedemptionRequest, v1.ReverseRedemptionResponse]
+	transferCoupon           *connect.Client[v1.TransferCouponRequest, v1.TransferCouponResponse]
+	getCouponHistory         *connect.Client[v1.GetCouponHistoryRequest, v1.GetCouponHistoryResponse]
+	listUserCoupons          *connect.Client[v1.ListUserCouponsRequest, v1.ListUserCouponsResponse]
+	rotateSigningKey         *connect.Client[v1.RotateSigningKeyRequest, v1.RotateSigningKeyResponse]
+	getVerificationKeys      *connect.Client[v1.GetVerificationKeysRequest, v1.GetVerificationKeysResponse]
+	createCouponImageUrl     *connect.Client[v1.CreateCouponImageUrlRequest, v1.CreateCouponImageUrlResponse]
 }
 
 // CreateCampaign calls coupon.v1.CouponService.CreateCampaign.
@@ -105,11 +357,149 @@ func (c *couponServiceClient) IssueCoupon(ctx context.Context, req *connect.Requ
 	return c.issueCoupon.CallUnary(ctx, req)
 }
 
+// IssueCouponStream calls coupon.v1.CouponService.IssueCouponStream.
+func (c *couponServiceClient) IssueCouponStream(ctx context.Context) *connect.BidiStreamForClient[v1.IssueCouponStreamRequest, v1.IssueCouponStreamResponse] {
+	return c.issueCouponStream.CallBidiStream(ctx)
+}
+
+// StartPushIssuance calls coupon.v1.CouponService.StartPushIssuance.
+func (c *couponServiceClient) StartPushIssuance(ctx context.Context) *connect.ClientStreamForClient[v1.StartPushIssuanceRequest, v1.StartPushIssuanceResponse] {
+	return c.startPushIssuance.CallClientStream(ctx)
+}
+
+// ImportCodes calls coupon.v1.CouponService.ImportCodes.
+func (c *couponServiceClient) ImportCodes(ctx context.Context) *connect.ClientStreamForClient[v1.ImportCodesRequest, v1.ImportCodesResponse] {
+	return c.importCodes.CallClientStream(ctx)
+}
+
+// GetJob calls coupon.v1.CouponService.GetJob.
+func (c *couponServiceClient) GetJob(ctx context.Context, req *connect.Request[v1.GetJobRequest]) (*connect.Response[v1.GetJobResponse], error) {
+	return c.getJob.CallUnary(ctx, req)
+}
+
+// GenerateCodeBatch calls coupon.v1.CouponService.GenerateCodeBatch.
+func (c *couponServiceClient) GenerateCodeBatch(ctx context.Context, req *connect.Request[v1.GenerateCodeBatchRequest]) (*connect.Response[v1.GenerateCodeBatchResponse], error) {
+	return c.generateCodeBatch.CallUnary(ctx, req)
+}
+
+// ExportCodeBatch calls coupon.v1.CouponService.ExportCodeBatch.
+func (c *couponServiceClient) ExportCodeBatch(ctx context.Context, req *connect.Request[v1.ExportCodeBatchRequest]) (*connect.ServerStreamForClient[v1.ExportCodeBatchResponse], error) {
+	return c.exportCodeBatch.CallServerStream(ctx, req)
+}
+
+// RedeemCoupon calls coupon.v1.CouponService.RedeemCoupon.
+func (c *couponServiceClient) RedeemCoupon(ctx context.Context, req *connect.Request[v1.RedeemCouponRequest]) (*connect.Response[v1.RedeemCouponResponse], error) {
+	return c.redeemCoupon.CallUnary(ctx, req)
+}
+
+// LockCoupon calls coupon.v1.CouponService.LockCoupon.
+func (c *couponServiceClient) LockCoupon(ctx context.Context, req *connect.Request[v1.LockCouponRequest]) (*connect.Response[v1.LockCouponResponse], error) {
+	return c.lockCoupon.CallUnary(ctx, req)
+}
+
+// CommitRedemption calls coupon.v1.CouponService.CommitRedemption.
+func (c *couponServiceClient) CommitRedemption(ctx context.Context, req *connect.Request[v1.CommitRedemptionRequest]) (*connect.Response[v1.RedeemCouponResponse], error) {
+	return c.commitRedemption.CallUnary(ctx, req)
+}
+
+// ReleaseLock calls coupon.v1.CouponService.ReleaseLock.
+func (c *couponServiceClient) ReleaseLock(ctx context.Context, req *connect.Request[v1.ReleaseLockRequest]) (*connect.Response[v1.ReleaseLockResponse], error) {
+	return c.releaseLock.CallUnary(ctx, req)
+}
+
+// ValidateCoupon calls coupon.v1.CouponService.ValidateCoupon.
+func (c *couponServiceClient) ValidateCoupon(ctx context.Context, req *connect.Request[v1.ValidateCouponRequest]) (*connect.Response[v1.ValidateCouponResponse], error) {
+	return c.validateCoupon.CallUnary(ctx, req)
+}
+
+// RevokeCoupon calls coupon.v1.CouponService.RevokeCoupon.
+func (c *couponServiceClient) RevokeCoupon(ctx context.Context, req *connect.Request[v1.RevokeCouponRequest]) (*connect.Response[v1.RevokeCouponResponse], error) {
+	return c.revokeCoupon.CallUnary(ctx, req)
+}
+
+// ApplyCoupon calls coupon.v1.CouponService.ApplyCoupon.
+func (c *couponServiceClient) ApplyCoupon(ctx context.Context, req *connect.Request[v1.ApplyCouponRequest]) (*connect.Response[v1.ApplyCouponResponse], error) {
+	return c.applyCoupon.CallUnary(ctx, req)
+}
+
+// ValidateBasket calls coupon.v1.CouponService.ValidateBasket.
+func (c *couponServiceClient) ValidateBasket(ctx context.Context, req *connect.Request[v1.ValidateBasketRequest]) (*connect.Response[v1.ValidateBasketResponse], error) {
+	return c.validateBasket.CallUnary(ctx, req)
+}
+
+// SetCouponRedemptionLimit calls coupon.v1.CouponService.SetCouponRedemptionLimit.
+func (c *couponServiceClient) SetCouponRedemptionLimit(ctx context.Context, req *connect.Request[v1.SetCouponRedemptionLimitRequest]) (*connect.Response[v1.SetCouponRedemptionLimitResponse], error) {
+	return c.setCouponRedemptionLimit.CallUnary(ctx, req)
+}
+
+// ListCouponRedemptions calls coupon.v1.CouponService.ListCouponRedemptions.
+func (c *couponServiceClient) ListCouponRedemptions(ctx context.Context, req *connect.Request[v1.ListCouponRedemptionsRequest]) (*connect.Response[v1.ListCouponRedemptionsResponse], error) {
+	return c.listCouponRedemptions.CallUnary(ctx, req)
+}
+
+// ReverseRedemption calls coupon.v1.CouponService.ReverseRedemption.
+func (c *couponServiceClient) ReverseRedemption(ctx context.Context, req *connect.Request[v1.ReverseRedemptionRequest]) (*connect.Response[v1.ReverseRedemptionResponse], error) {
+	return c.reverseRedemption.CallUnary(ctx, req)
+}
+
+// TransferCoupon calls coupon.v1.CouponService.TransferCoupon.
+func (c *couponServiceClient) TransferCoupon(ctx context.Context, req *connect.Request[v1.TransferCouponRequest]) (*connect.Response[v1.TransferCouponResponse], error) {
+	return c.transferCoupon.CallUnary(ctx, req)
+}
+
+// GetCouponHistory calls coupon.v1.CouponService.GetCouponHistory.
+func (c *couponServiceClient) GetCouponHistory(ctx context.Context, req *connect.Request[v1.GetCouponHistoryRequest]) (*connect.Response[v1.GetCouponHistoryResponse], error) {
+	return c.getCouponHistory.CallUnary(ctx, req)
+}
+
+// ListUserCoupons calls coupon.v1.CouponService.ListUserCoupons.
+func (c *couponServiceClient) ListUserCoupons(ctx context.Context, req *connect.Request[v1.ListUserCouponsRequest]) (*connect.Response[v1.ListUserCouponsResponse], error) {
+	return c.listUserCoupons.CallUnary(ctx, req)
+}
+
+// RotateSigningKey calls coupon.v1.CouponService.RotateSigningKey.
+func (c *couponServiceClient) RotateSigningKey(ctx context.Context, req *connect.Request[v1.RotateSigningKeyRequest]) (*connect.Response[v1.RotateSigningKeyResponse], error) {
+	return c.rotateSigningKey.CallUnary(ctx, req)
+}
+
+// GetVerificationKeys calls coupon.v1.CouponService.GetVerificationKeys.
+func (c *couponServiceClient) GetVerificationKeys(ctx context.Context, req *connect.Request[v1.GetVerificationKeysRequest]) (*connect.Response[v1.GetVerificationKeysResponse], error) {
+	return c.getVerificationKeys.CallUnary(ctx, req)
+}
+
+// CreateCouponImageUrl calls coupon.v1.CouponService.CreateCouponImageUrl.
+func (c *couponServiceClient) CreateCouponImageUrl(ctx context.Context, req *connect.Request[v1.CreateCouponImageUrlRequest]) (*connect.Response[v1.CreateCouponImageUrlResponse], error) {
+	return c.createCouponImageUrl.CallUnary(ctx, req)
+}
+
 // CouponServiceHandler is an implementation of the coupon.v1.CouponService service.
 type CouponServiceHandler interface {
 	CreateCampaign(context.Context, *connect.Request[v1.CreateCampaignRequest]) (*connect.Response[v1.CreateCampaignResponse], error)
 	GetCampaign(context.Context, *connect.Request[v1.GetCampaignRequest]) (*connect.Response[v1.GetCampaignResponse], error)
 	IssueCoupon(context.Context, *connect.Request[v1.IssueCouponRequest]) (*connect.Response[v1.IssueCouponResponse], error)
+	IssueCouponStream(context.Context, *connect.BidiStream[v1.IssueCouponStreamRequest, v1.IssueCouponStreamResponse]) error
+	StartPushIssuance(context.Context, *connect.ClientStream[v1.StartPushIssuanceRequest]) (*connect.Response[v1.StartPushIssuanceResponse], error)
+	ImportCodes(context.Context, *connect.ClientStream[v1.ImportCodesRequest]) (*connect.Response[v1.ImportCodesResponse], error)
+	GetJob(context.Context, *connect.Request[v1.GetJobRequest]) (*connect.Response[v1.GetJobResponse], error)
+	GenerateCodeBatch(context.Context, *connect.Request[v1.GenerateCodeBatchRequest]) (*connect.Response[v1.GenerateCodeBatchResponse], error)
+	ExportCodeBatch(context.Context, *connect.Request[v1.ExportCodeBatchRequest], *connect.ServerStream[v1.ExportCodeBatchResponse]) error
+	RedeemCoupon(context.Context, *connect.Request[v1.RedeemCouponRequest]) (*connect.Response[v1.RedeemCouponResponse], error)
+	LockCoupon(context.Context, *connect.Request[v1.LockCouponRequest]) (*connect.Response[v1.LockCouponResponse], error)
+	CommitRedemption(context.Context, *connect.Request[v1.CommitRedemptionRequest]) (*connect.Response[v1.RedeemCouponResponse], error)
+	ReleaseLock(context.Context, *connect.Request[v1.ReleaseLockRequest]) (*connect.Response[v1.ReleaseLockResponse], error)
+	ValidateCoupon(context.Context, *connect.Request[v1.ValidateCouponRequest]) (*connect.Response[v1.ValidateCouponResponse], error)
+	RevokeCoupon(context.Context, *connect.Request[v1.RevokeCouponRequest]) (*connect.Response[v1.RevokeCouponResponse], error)
+	ApplyCoupon(context.Context, *connect.Request[v1.ApplyCouponRequest]) (*connect.Response[v1.ApplyCouponResponse], error)
+	ValidateBasket(context.Context, *connect.Request[v1.ValidateBasketRequest]) (*connect.Response[v1.ValidateBasketResponse], error)
+	SetCouponRedemptionLimit(context.Context, *connect.Request[v1.SetCouponRedemptionLimitRequest]) (*connect.Response[v1.SetCouponRedemptionLimitResponse], error)
+	ListCouponRedemptions(context.Context, *connect.Request[v1.ListCouponRedemptionsRequest]) (*connect.Response[v1.ListCouponRedemptionsResponse], error)
+	ReverseRedemption(context.Context, *connect.Request[v1.ReverseRedemptionRequest]) (*connect.Response[v1.ReverseRedemptionResponse], error)
+	TransferCoupon(context.Context, *connect.Request[v1.TransferCouponRequest]) (*connect.Response[v1.TransferCouponResponse], error)
+	GetCouponHistory(context.Context, *connect.Request[v1.GetCouponHistoryRequest]) (*connect.Response[v1.GetCouponHistoryResponse], error)
+	ListUserCoupons(context.Context, *connect.Request[v1.ListUserCouponsRequest]) (*connect.Response[v1.ListUserCouponsResponse], error)
+	RotateSigningKey(context.Context, *connect.Request[v1.RotateSigningKeyRequest]) (*connect.Response[v1.RotateSigningKeyResponse], error)
+	GetVerificationKeys(context.Context, *connect.Request[v1.GetVerificationKeysRequest]) (*connect.Response[v1.GetVerificationKeysResponse], error)
+	CreateCouponImageUrl(context.Context, *connect.Request[v1.CreateCouponImageUrlRequest]) (*connect.Response[v1.CreateCouponImageUrlResponse], error)
 }
 
 // NewCouponServiceHandler builds an HTTP handler from the service implementation. It returns the
@@ -137,6 +527,144 @@ func NewCouponServiceHandler(svc CouponServiceHandler, opts ...connect.HandlerOp
 		connect.WithSchema(couponServiceMethods.ByName("IssueCoupon")),
 		connect.WithHandlerOptions(opts...),
 	)
+	couponServiceIssueCouponStreamHandler := connect.NewBidiStreamHandler(
+		CouponServiceIssueCouponStreamProcedure,
+		svc.IssueCouponStream,
+		connect.WithSchema(couponServiceMethods.ByName("IssueCouponStream")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceStartPushIssuanceHandler := connect.NewClientStreamHandler(
+		CouponServiceStartPushIssuanceProcedure,
+		svc.StartPushIssuance,
+		connect.WithSchema(couponServiceMethods.ByName("StartPushIssuance")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceImportCodesHandler := connect.NewClientStreamHandler(
+		CouponServiceImportCodesProcedure,
+		svc.ImportCodes,
+		connect.WithSchema(couponServiceMethods.ByName("ImportCodes")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceGetJobHandler := connect.NewUnaryHandler(
+		CouponServiceGetJobProcedure,
+		svc.GetJob,
+		connect.WithSchema(couponServiceMethods.ByName("GetJob")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceGenerateCodeBatchHandler := connect.NewUnaryHandler(
+		CouponServiceGenerateCodeBatchProcedure,
+		svc.GenerateCodeBatch,
+		connect.WithSchema(couponServiceMethods.ByName("GenerateCodeBatch")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceExportCodeBatchHandler := connect.NewServerStreamHandler(
+		CouponServiceExportCodeBatchProcedure,
+		svc.ExportCodeBatch,
+		connect.WithSchema(couponServiceMethods.ByName("ExportCodeBatch")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceRedeemCouponHandler := connect.NewUnaryHandler(
+		CouponServiceRedeemCouponProcedure,
+		svc.RedeemCoupon,
+		connect.WithSchema(couponServiceMethods.ByName("RedeemCoupon")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceLockCouponHandler := connect.NewUnaryHandler(
+		CouponServiceLockCouponProcedure,
+		svc.LockCoupon,
+		connect.WithSchema(couponServiceMethods.ByName("LockCoupon")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceCommitRedemptionHandler := connect.NewUnaryHandler(
+		CouponServiceCommitRedemptionProcedure,
+		svc.CommitRedemption,
+		connect.WithSchema(couponServiceMethods.ByName("CommitRedemption")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceReleaseLockHandler := connect.NewUnaryHandler(
+		CouponServiceReleaseLockProcedure,
+		svc.ReleaseLock,
+		connect.WithSchema(couponServiceMethods.ByName("ReleaseLock")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceValidateCouponHandler := connect.NewUnaryHandler(
+		CouponServiceValidateCouponProcedure,
+		svc.ValidateCoupon,
+		connect.WithSchema(couponServiceMethods.ByName("ValidateCoupon")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceRevokeCouponHandler := connect.NewUnaryHandler(
+		CouponServiceRevokeCouponProcedure,
+		svc.RevokeCoupon,
+		connect.WithSchema(couponServiceMethods.ByName("RevokeCoupon")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceApplyCouponHandler := connect.NewUnaryHandler(
+		CouponServiceApplyCouponProcedure,
+		svc.ApplyCoupon,
+		connect.WithSchema(couponServiceMethods.ByName("ApplyCoupon")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceValidateBasketHandler := connect.NewUnaryHandler(
+		CouponServiceValidateBasketProcedure,
+		svc.ValidateBasket,
+		connect.WithSchema(couponServiceMethods.ByName("ValidateBasket")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceSetCouponRedemptionLimitHandler := connect.NewUnaryHandler(
+		CouponServiceSetCouponRedemptionLimitProcedure,
+		svc.SetCouponRedemptionLimit,
+		connect.WithSchema(couponServiceMethods.ByName("SetCouponRedemptionLimit")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceListCouponRedemptionsHandler := connect.NewUnaryHandler(
+		CouponServiceListCouponRedemptionsProcedure,
+		svc.ListCouponRedemptions,
+		connect.WithSchema(couponServiceMethods.ByName("ListCouponRedemptions")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceReverseRedemptionHandler := connect.NewUnaryHandler(
+		CouponServiceReverseRedemptionProcedure,
+		svc.ReverseRedemption,
+		connect.WithSchema(couponServiceMethods.ByName("ReverseRedemption")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceTransferCouponHandler := connect.NewUnaryHandler(
+		CouponServiceTransferCouponProcedure,
+		svc.TransferCoupon,
+		connect.WithSchema(couponServiceMethods.ByName("TransferCoupon")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceGetCouponHistoryHandler := connect.NewUnaryHandler(
+		CouponServiceGetCouponHistoryProcedure,
+		svc.GetCouponHistory,
+		connect.WithSchema(couponServiceMethods.ByName("GetCouponHistory")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceListUserCouponsHandler := connect.NewUnaryHandler(
+		CouponServiceListUserCouponsProcedure,
+		svc.ListUserCoupons,
+		connect.WithSchema(couponServiceMethods.ByName("ListUserCoupons")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceRotateSigningKeyHandler := connect.NewUnaryHandler(
+		CouponServiceRotateSigningKeyProcedure,
+		svc.RotateSigningKey,
+		connect.WithSchema(couponServiceMethods.ByName("RotateSigningKey")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceGetVerificationKeysHandler := connect.NewUnaryHandler(
+		CouponServiceGetVerificationKeysProcedure,
+		svc.GetVerificationKeys,
+		connect.WithSchema(couponServiceMethods.ByName("GetVerificationKeys")),
+		connect.WithHandlerOptions(opts...),
+	)
+	couponServiceCreateCouponImageUrlHandler := connect.NewUnaryHandler(
+		CouponServiceCreateCouponImageUrlProcedure,
+		svc.CreateCouponImageUrl,
+		connect.WithSchema(couponServiceMethods.ByName("CreateCouponImageUrl")),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/coupon.v1.CouponService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case CouponServiceCreateCampaignProcedure:
@@ -145,6 +673,52 @@ func NewCouponServiceHandler(svc CouponServiceHandler, opts ...connect.HandlerOp
 			couponServiceGetCampaignHandler.ServeHTTP(w, r)
 		case CouponServiceIssueCouponProcedure:
 			couponServiceIssueCouponHandler.ServeHTTP(w, r)
+		case CouponServiceIssueCouponStreamProcedure:
+			couponServiceIssueCouponStreamHandler.ServeHTTP(w, r)
+		case CouponServiceStartPushIssuanceProcedure:
+			couponServiceStartPushIssuanceHandler.ServeHTTP(w, r)
+		case CouponServiceImportCodesProcedure:
+			couponServiceImportCodesHandler.ServeHTTP(w, r)
+		case CouponServiceGetJobProcedure:
+			couponServiceGetJobHandler.ServeHTTP(w, r)
+		case CouponServiceGenerateCodeBatchProcedure:
+			couponServiceGenerateCodeBatchHandler.ServeHTTP(w, r)
+		case CouponServiceExportCodeBatchProcedure:
+			couponServiceExportCodeBatchHandler.ServeHTTP(w, r)
+		case CouponServiceRedeemCouponProcedure:
+			couponServiceRedeemCouponHandler.ServeHTTP(w, r)
+		case CouponServiceLockCouponProcedure:
+			couponServiceLockCouponHandler.ServeHTTP(w, r)
+		case CouponServiceCommitRedemptionProcedure:
+			couponServiceCommitRedemptionHandler.ServeHTTP(w, r)
+		case CouponServiceReleaseLockProcedure:
+			couponServiceReleaseLockHandler.ServeHTTP(w, r)
+		case CouponServiceValidateCouponProcedure:
+			couponServiceValidateCouponHandler.ServeHTTP(w, r)
+		case CouponServiceRevokeCouponProcedure:
+			couponServiceRevokeCouponHandler.ServeHTTP(w, r)
+		case CouponServiceApplyCouponProcedure:
+			couponServiceApplyCouponHandler.ServeHTTP(w, r)
+		case CouponServiceValidateBasketProcedure:
+			couponServiceValidateBasketHandler.ServeHTTP(w, r)
+		case CouponServiceSetCouponRedemptionLimitProcedure:
+			couponServiceSetCouponRedemptionLimitHandler.ServeHTTP(w, r)
+		case CouponServiceListCouponRedemptionsProcedure:
+			couponServiceListCouponRedemptionsHandler.ServeHTTP(w, r)
+		case CouponServiceReverseRedemptionProcedure:
+			couponServiceReverseRedemptionHandler.ServeHTTP(w, r)
+		case CouponServiceTransferCouponProcedure:
+			couponServiceTransferCouponHandler.ServeHTTP(w, r)
+		case CouponServiceGetCouponHistoryProcedure:
+			couponServiceGetCouponHistoryHandler.ServeHTTP(w, r)
+		case CouponServiceListUserCouponsProcedure:
+			couponServiceListUserCouponsHandler.ServeHTTP(w, r)
+		case CouponServiceRotateSigningKeyProcedure:
+			couponServiceRotateSigningKeyHandler.ServeHTTP(w, r)
+		case CouponServiceGetVerificationKeysProcedure:
+			couponServiceGetVerificationKeysHandler.ServeHTTP(w, r)
+		case CouponServiceCreateCouponImageUrlProcedure:
+			couponServiceCreateCouponImageUrlHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -165,3 +739,95 @@ func (UnimplementedCouponServiceHandler) GetCampaign(context.Context, *connect.R
 func (UnimplementedCouponServiceHandler) IssueCoupon(context.Context, *connect.Request[v1.IssueCouponRequest]) (*connect.Response[v1.IssueCouponResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.IssueCoupon is not implemented"))
 }
+
+func (UnimplementedCouponServiceHandler) IssueCouponStream(context.Context, *connect.BidiStream[v1.IssueCouponStreamRequest, v1.IssueCouponStreamResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.IssueCouponStream is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) StartPushIssuance(context.Context, *connect.ClientStream[v1.StartPushIssuanceRequest]) (*connect.Response[v1.StartPushIssuanceResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.StartPushIssuance is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) ImportCodes(context.Context, *connect.ClientStream[v1.ImportCodesRequest]) (*connect.Response[v1.ImportCodesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.ImportCodes is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) GetJob(context.Context, *connect.Request[v1.GetJobRequest]) (*connect.Response[v1.GetJobResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.GetJob is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) GenerateCodeBatch(context.Context, *connect.Request[v1.GenerateCodeBatchRequest]) (*connect.Response[v1.GenerateCodeBatchResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.GenerateCodeBatch is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) ExportCodeBatch(context.Context, *connect.Request[v1.ExportCodeBatchRequest], *connect.ServerStream[v1.ExportCodeBatchResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.ExportCodeBatch is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) RedeemCoupon(context.Context, *connect.Request[v1.RedeemCouponRequest]) (*connect.Response[v1.RedeemCouponResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.RedeemCoupon is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) LockCoupon(context.Context, *connect.Request[v1.LockCouponRequest]) (*connect.Response[v1.LockCouponResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.LockCoupon is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) CommitRedemption(context.Context, *connect.Request[v1.CommitRedemptionRequest]) (*connect.Response[v1.RedeemCouponResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.CommitRedemption is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) ReleaseLock(context.Context, *connect.Request[v1.ReleaseLockRequest]) (*connect.Response[v1.ReleaseLockResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.ReleaseLock is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) ValidateCoupon(context.Context, *connect.Request[v1.ValidateCouponRequest]) (*connect.Response[v1.ValidateCouponResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.ValidateCoupon is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) RevokeCoupon(context.Context, *connect.Request[v1.RevokeCouponRequest]) (*connect.Response[v1.RevokeCouponResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.RevokeCoupon is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) ApplyCoupon(context.Context, *connect.Request[v1.ApplyCouponRequest]) (*connect.Response[v1.ApplyCouponResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.ApplyCoupon is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) ValidateBasket(context.Context, *connect.Request[v1.ValidateBasketRequest]) (*connect.Response[v1.ValidateBasketResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.ValidateBasket is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) SetCouponRedemptionLimit(context.Context, *connect.Request[v1.SetCouponRedemptionLimitRequest]) (*connect.Response[v1.SetCouponRedemptionLimitResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.SetCouponRedemptionLimit is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) ListCouponRedemptions(context.Context, *connect.Request[v1.ListCouponRedemptionsRequest]) (*connect.Response[v1.ListCouponRedemptionsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.ListCouponRedemptions is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) ReverseRedemption(context.Context, *connect.Request[v1.ReverseRedemptionRequest]) (*connect.Response[v1.ReverseRedemptionResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.ReverseRedemption is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) TransferCoupon(context.Context, *connect.Request[v1.TransferCouponRequest]) (*connect.Response[v1.TransferCouponResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.TransferCoupon is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) GetCouponHistory(context.Context, *connect.Request[v1.GetCouponHistoryRequest]) (*connect.Response[v1.GetCouponHistoryResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.GetCouponHistory is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) ListUserCoupons(context.Context, *connect.Request[v1.ListUserCouponsRequest]) (*connect.Response[v1.ListUserCouponsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.ListUserCoupons is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) RotateSigningKey(context.Context, *connect.Request[v1.RotateSigningKeyRequest]) (*connect.Response[v1.RotateSigningKeyResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.RotateSigningKey is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) GetVerificationKeys(context.Context, *connect.Request[v1.GetVerificationKeysRequest]) (*connect.Response[v1.GetVerificationKeysResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.GetVerificationKeys is not implemented"))
+}
+
+func (UnimplementedCouponServiceHandler) CreateCouponImageUrl(context.Context, *connect.Request[v1.CreateCouponImageUrlRequest]) (*connect.Response[v1.CreateCouponImageUrlResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("coupon.v1.CouponService.CreateCouponImageUrl is not implemented"))
+}
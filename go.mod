@@ -4,10 +4,13 @@ go 1.23.0
 
 require (
 	connectrpc.com/connect v1.16.2
+	github.com/boombuler/barcode v1.1.0
 	github.com/jackc/pgx/v5 v5.7.5
 	github.com/redis/go-redis/v9 v9.10.0
 	github.com/stretchr/testify v1.8.1
+	golang.org/x/image v0.25.0
 	golang.org/x/net v0.25.0
+	golang.org/x/text v0.24.0
 	google.golang.org/protobuf v1.34.1
 )
 
@@ -23,6 +26,5 @@ require (
 	github.com/rogpeppe/go-internal v1.14.1 // indirect
 	golang.org/x/crypto v0.37.0 // indirect
 	golang.org/x/sync v0.13.0 // indirect
-	golang.org/x/text v0.24.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
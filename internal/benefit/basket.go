@@ -0,0 +1,154 @@
+package benefit
+
+import (
+	"cmp"
+	"slices"
+)
+
+type StackingPolicy string
+
+const (
+	// PolicyStackable coupons combine with other stackable coupons.
+	PolicyStackable StackingPolicy = "stackable"
+	// PolicyExclusive coupons cannot be combined with any other coupon.
+	PolicyExclusive StackingPolicy = "exclusive"
+)
+
+// Candidate is a coupon offered for a basket.
+type Candidate struct {
+	Code    string
+	Benefit *Benefit
+	// Group is the campaign's exclusivity group. At most one coupon per
+	// non-empty group is applied.
+	Group    string
+	Policy   StackingPolicy
+	Priority int32
+}
+
+type Rejection int
+
+const (
+	RejectNone Rejection = iota
+	// RejectNotApplicable means the benefit does not apply to the cart;
+	// BenefitReason says why.
+	RejectNotApplicable
+	// RejectExclusivityGroup means a coupon of the same exclusivity group
+	// was applied.
+	RejectExclusivityGroup
+	// RejectNotStackable means the coupon or an applied coupon is
+	// exclusive.
+	RejectNotStackable
+	// RejectNoRemainingValue means earlier coupons already discount the
+	// whole cart.
+	RejectNoRemainingValue
+)
+
+// AppliedCoupon is a coupon selected for the basket.
+type AppliedCoupon struct {
+	Code        string
+	Discount    Decimal
+	FreeItemSKU string
+}
+
+// RejectedCoupon is a coupon left out of the basket. ConflictsWith names
+// the applied coupon it clashes with for group and stacking rejections.
+type RejectedCoupon struct {
+	Code          string
+	Rejection     Rejection
+	BenefitReason Reason
+	ConflictsWith string
+}
+
+// BasketResult lists the applied coupons in application order.
+type BasketResult struct {
+	Applied  []AppliedCoupon
+	Rejected []RejectedCoupon
+	Subtotal Decimal
+	Discount Decimal
+	Total    Decimal
+}
+
+// ResolveBasket chooses which candidates can be applied together. Coupons
+// are considered by descending priority, then by the larger discount, then
+// in the order given, and each is applied if it does not conflict with the
+// ones already chosen. Every discount is computed on the original cart, so
+// stacked percentages do not compound, and is limited to what is left of
+// the cart total after the coupons before it.
+func ResolveBasket(cart Cart, candidates []Candidate) (BasketResult, error) {
+	type evaluated struct {
+		Candidate
+		index  int
+		result Result
+	}
+
+	if err := cart.validate(); err != nil {
+		return BasketResult{}, err
+	}
+
+	result := BasketResult{Subtotal: cart.subtotal()}
+	options := make([]evaluated, 0, len(candidates))
+	for i, c := range candidates {
+		r, err := c.Benefit.Apply(cart)
+		if err != nil {
+			return BasketResult{}, err
+		}
+		if !r.Applicable {
+			result.Rejected = append(result.Rejected, RejectedCoupon{
+				Code:          c.Code,
+				Rejection:     RejectNotApplicable,
+				BenefitReason: r.Reason,
+			})
+			continue
+		}
+		options = append(options, evaluated{c, i, r})
+	}
+	slices.SortFunc(options, func(a, b evaluated) int {
+		return cmp.Or(
+			cmp.Compare(b.Priority, a.Priority),
+			b.result.Discount.Cmp(a.result.Discount),
+			cmp.Compare(a.index, b.index),
+		)
+	})
+
+	remaining := result.Subtotal
+	groups := map[string]string{} // group to applied code
+	var exclusive string          // applied exclusive code
+	for _, o := range options {
+		rejected := RejectedCoupon{Code: o.Code}
+		switch {
+		case exclusive != "":
+			rejected.Rejection = RejectNotStackable
+			rejected.ConflictsWith = exclusive
+		case o.Policy == PolicyExclusive && len(result.Applied) > 0:
+			rejected.Rejection = RejectNotStackable
+			rejected.ConflictsWith = result.Applied[0].Code
+		case o.Group != "" && groups[o.Group] != "":
+			rejected.Rejection = RejectExclusivityGroup
+			rejected.ConflictsWith = groups[o.Group]
+		case remaining.Sign() == 0:
+			rejected.Rejection = RejectNoRemainingValue
+		}
+		if rejected.Rejection != RejectNone {
+			result.Rejected = append(result.Rejected, rejected)
+			continue
+		}
+
+		discount := o.result.Discount.Min(remaining)
+		remaining = remaining.Sub(discount)
+		result.Discount = result.Discount.Add(discount)
+		result.Applied = append(result.Applied, AppliedCoupon{
+			Code:        o.Code,
+			Discount:    discount,
+			FreeItemSKU: o.result.FreeItemSKU,
+		})
+		if o.Group != "" {
+			groups[o.Group] = o.Code
+		}
+		if o.Policy == PolicyExclusive {
+			exclusive = o.Code
+		}
+	}
+
+	result.Total = remaining
+	return result, nil
+}
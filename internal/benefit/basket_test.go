@@ -0,0 +1,119 @@
+package benefit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveBasket(t *testing.T) {
+	cart := Cart{
+		Currency: "KRW",
+		Items: []Item{
+			{
+				SKU:       "LATTE",
+				Category:  "drinks",
+				Quantity:  2,
+				UnitPrice: MustParseDecimal("5000"),
+			},
+			{
+				SKU:       "CAKE",
+				Category:  "food",
+				Quantity:  1,
+				UnitPrice: MustParseDecimal("8000"),
+			},
+		},
+	}
+	percent := func(p string) *Benefit {
+		return &Benefit{Kind: KindPercentOff, Percent: dec(p)}
+	}
+	fixed := func(amount string) *Benefit {
+		return &Benefit{
+			Kind:     KindFixedAmount,
+			Amount:   dec(amount),
+			Currency: "KRW",
+		}
+	}
+
+	applied := func(r BasketResult) []string {
+		codes := []string{}
+		for _, a := range r.Applied {
+			codes = append(codes, a.Code)
+		}
+		return codes
+	}
+	rejection := func(r BasketResult, code string) RejectedCoupon {
+		for _, rej := range r.Rejected {
+			if rej.Code == code {
+				return rej
+			}
+		}
+		t.Fatalf("%s was not rejected", code)
+		return RejectedCoupon{}
+	}
+
+	t.Run("stackable coupons apply by priority", func(t *testing.T) {
+		r, err := ResolveBasket(cart, []Candidate{
+			{Code: "A", Benefit: percent("10")},
+			{Code: "B", Benefit: fixed("3000"), Priority: 1},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"B", "A"}, applied(r))
+		assert.Equal(t, "18000", r.Subtotal.String())
+		assert.Equal(t, "4800", r.Discount.String())
+		assert.Equal(t, "13200", r.Total.String())
+	})
+
+	t.Run("larger discount wins within a group", func(t *testing.T) {
+		r, err := ResolveBasket(cart, []Candidate{
+			{Code: "A", Benefit: percent("10"), Group: "welcome"},
+			{Code: "B", Benefit: fixed("3000"), Group: "welcome"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"B"}, applied(r))
+		rej := rejection(r, "A")
+		assert.Equal(t, RejectExclusivityGroup, rej.Rejection)
+		assert.Equal(t, "B", rej.ConflictsWith)
+	})
+
+	t.Run("exclusive coupon", func(t *testing.T) {
+		r, err := ResolveBasket(cart, []Candidate{
+			{Code: "A", Benefit: percent("10"), Priority: 1},
+			{Code: "B", Benefit: fixed("5000"), Policy: PolicyExclusive},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A"}, applied(r))
+		assert.Equal(t, RejectNotStackable, rejection(r, "B").Rejection)
+
+		r, err = ResolveBasket(cart, []Candidate{
+			{Code: "A", Benefit: percent("10")},
+			{Code: "B", Benefit: fixed("5000"), Policy: PolicyExclusive},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"B"}, applied(r))
+		assert.Equal(t, "B", rejection(r, "A").ConflictsWith)
+	})
+
+	t.Run("discounts never exceed the cart", func(t *testing.T) {
+		r, err := ResolveBasket(cart, []Candidate{
+			{Code: "A", Benefit: percent("100"), Priority: 1},
+			{Code: "B", Benefit: fixed("1000")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A"}, applied(r))
+		assert.Equal(t, RejectNoRemainingValue, rejection(r, "B").Rejection)
+		assert.Equal(t, 0, r.Total.Sign())
+	})
+
+	t.Run("benefit not applicable", func(t *testing.T) {
+		r, err := ResolveBasket(cart, []Candidate{
+			{Code: "A", Benefit: &Benefit{Kind: KindFreeItem, SKU: "MUG"}},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, r.Applied)
+		rej := rejection(r, "A")
+		assert.Equal(t, RejectNotApplicable, rej.Rejection)
+		assert.Equal(t, ReasonFreeItemNotInCart, rej.BenefitReason)
+	})
+}
@@ -0,0 +1,280 @@
+// Package benefit defines what a coupon is worth and computes the discount
+// it gives on a cart. All amounts are exact decimals; discounts are rounded
+// down to the currency's minor unit only at the end of the calculation.
+package benefit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Kind string
+
+const (
+	// KindPercentOff takes a percentage off the eligible items, optionally
+	// capped at MaxDiscount.
+	KindPercentOff Kind = "percent_off"
+	// KindFixedAmount takes a fixed amount off the eligible items.
+	KindFixedAmount Kind = "fixed_amount"
+	// KindFreeItem makes one unit of SKU free.
+	KindFreeItem Kind = "free_item"
+)
+
+// Benefit is the value attached to a campaign's coupons. It is stored as
+// JSON on the campaign.
+type Benefit struct {
+	Kind        Kind     `json:"kind"`
+	Percent     *Decimal `json:"percent,omitempty"`
+	MaxDiscount *Decimal `json:"max_discount,omitempty"`
+	Amount      *Decimal `json:"amount,omitempty"`
+	SKU         string   `json:"sku,omitempty"`
+	// Currency of every amount in the benefit. Required when any amount is
+	// set; a percentage without amounts applies to carts in any currency.
+	Currency string `json:"currency,omitempty"`
+
+	// Conditions
+	MinOrderAmount *Decimal `json:"min_order_amount,omitempty"`
+	// Categories restricts the discount to items in these categories. An
+	// empty list makes every item eligible.
+	Categories []string `json:"categories,omitempty"`
+}
+
+// Validate checks that the benefit is complete and consistent.
+func (b *Benefit) Validate() error {
+	switch b.Kind {
+	case KindPercentOff:
+		if b.Percent == nil {
+			return errors.New("percent is required")
+		}
+		if b.Percent.Sign() <= 0 || b.Percent.Cmp(MustParseDecimal("100")) > 0 {
+			return fmt.Errorf("percent must be in (0, 100], got %s", b.Percent)
+		}
+		if b.MaxDiscount != nil && b.MaxDiscount.Sign() <= 0 {
+			return errors.New("max_discount must be positive")
+		}
+	case KindFixedAmount:
+		if b.Amount == nil || b.Amount.Sign() <= 0 {
+			return errors.New("amount must be positive")
+		}
+	case KindFreeItem:
+		if strings.TrimSpace(b.SKU) == "" {
+			return errors.New("sku is required")
+		}
+	default:
+		return fmt.Errorf("unknown benefit kind %q", b.Kind)
+	}
+
+	if b.Currency != "" {
+		if err := validateCurrency(b.Currency); err != nil {
+			return err
+		}
+	}
+	amounts := []struct {
+		name  string
+		value *Decimal
+	}{
+		{"max_discount", b.MaxDiscount},
+		{"amount", b.Amount},
+		{"min_order_amount", b.MinOrderAmount},
+	}
+	for _, a := range amounts {
+		if a.value == nil {
+			continue
+		}
+		if b.Currency == "" {
+			return fmt.Errorf("currency is required with %s", a.name)
+		}
+		if err := validateAmount(a.name, *a.value, b.Currency); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range b.Categories {
+		if strings.TrimSpace(c) == "" {
+			return errors.New("categories cannot contain empty values")
+		}
+	}
+	return nil
+}
+
+// Item is a cart line.
+type Item struct {
+	SKU       string
+	Category  string
+	Quantity  int64
+	UnitPrice Decimal
+}
+
+// Cart is the order a coupon is applied to.
+type Cart struct {
+	Currency string
+	Items    []Item
+}
+
+func (c Cart) validate() error {
+	if err := validateCurrency(c.Currency); err != nil {
+		return err
+	}
+	for i, item := range c.Items {
+		if item.SKU == "" {
+			return fmt.Errorf("item %d: sku is required", i)
+		}
+		if item.Quantity <= 0 {
+			return fmt.Errorf("item %d: quantity must be positive", i)
+		}
+		name := fmt.Sprintf("item %d: unit_price", i)
+		if err := validateAmount(name, item.UnitPrice, c.Currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c Cart) subtotal() Decimal {
+	var subtotal Decimal
+	for _, item := range c.Items {
+		subtotal = subtotal.Add(item.UnitPrice.MulInt(item.Quantity))
+	}
+	return subtotal
+}
+
+type Reason int
+
+const (
+	ReasonNone Reason = iota
+	// ReasonCurrencyMismatch means the cart is in a different currency
+	// from the benefit's amounts.
+	ReasonCurrencyMismatch
+	// ReasonMinOrderNotMet means the cart subtotal is below the minimum
+	// order amount.
+	ReasonMinOrderNotMet
+	// ReasonNoEligibleItems means no item is in an eligible category.
+	ReasonNoEligibleItems
+	// ReasonFreeItemNotInCart means the free item SKU is not in the cart.
+	ReasonFreeItemNotInCart
+)
+
+// Result is the outcome of applying a benefit to a cart. Amounts are in the
+// cart's currency; Discount is zero when the benefit does not apply.
+type Result struct {
+	Applicable  bool
+	Reason      Reason
+	Subtotal    Decimal
+	Discount    Decimal
+	Total       Decimal
+	FreeItemSKU string
+}
+
+// Apply computes the discount the benefit gives on a cart. It returns an
+// error only if the cart itself is invalid.
+func (b *Benefit) Apply(cart Cart) (Result, error) {
+	if err := cart.validate(); err != nil {
+		return Result{}, err
+	}
+
+	subtotal := cart.subtotal()
+	var eligible Decimal
+	hasEligible := false
+	for _, item := range cart.Items {
+		if b.eligible(item) {
+			eligible = eligible.Add(item.UnitPrice.MulInt(item.Quantity))
+			hasEligible = true
+		}
+	}
+	result := Result{Subtotal: subtotal, Total: subtotal}
+
+	if b.Currency != "" && b.Currency != cart.Currency {
+		result.Reason = ReasonCurrencyMismatch
+		return result, nil
+	}
+	if b.MinOrderAmount != nil && subtotal.Cmp(*b.MinOrderAmount) < 0 {
+		result.Reason = ReasonMinOrderNotMet
+		return result, nil
+	}
+
+	var discount Decimal
+	switch b.Kind {
+	case KindPercentOff, KindFixedAmount:
+		if !hasEligible {
+			result.Reason = ReasonNoEligibleItems
+			return result, nil
+		}
+		if b.Kind == KindPercentOff {
+			discount = eligible.Percent(*b.Percent)
+			discount = discount.Floor(MinorUnits(cart.Currency))
+			if b.MaxDiscount != nil {
+				discount = discount.Min(*b.MaxDiscount)
+			}
+		} else {
+			discount = b.Amount.Min(eligible)
+		}
+	case KindFreeItem:
+		found := false
+		for _, item := range cart.Items {
+			if item.SKU == b.SKU {
+				discount = item.UnitPrice
+				found = true
+				break
+			}
+		}
+		if !found {
+			result.Reason = ReasonFreeItemNotInCart
+			return result, nil
+		}
+		result.FreeItemSKU = b.SKU
+	}
+
+	result.Applicable = true
+	result.Discount = discount
+	result.Total = subtotal.Sub(discount)
+	return result, nil
+}
+
+// eligible reports whether an item counts towards a percentage or fixed
+// amount discount. The free item SKU is matched directly and ignores
+// categories.
+func (b *Benefit) eligible(item Item) bool {
+	if len(b.Categories) == 0 {
+		return true
+	}
+	for _, c := range b.Categories {
+		if c == item.Category {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary describes the benefit in one line for display, e.g.
+// "10% off up to 5000 KRW on drinks (min. order 20000 KRW)".
+func (b *Benefit) Summary() string {
+	amount := func(d *Decimal) string {
+		return fmt.Sprintf(
+			"%s %s",
+			d.StringFixed(MinorUnits(b.Currency)),
+			b.Currency,
+		)
+	}
+
+	var summary string
+	switch b.Kind {
+	case KindPercentOff:
+		summary = fmt.Sprintf("%s%% off", b.Percent)
+		if b.MaxDiscount != nil {
+			summary += " up to " + amount(b.MaxDiscount)
+		}
+	case KindFixedAmount:
+		summary = amount(b.Amount) + " off"
+	case KindFreeItem:
+		summary = "Free " + b.SKU
+	}
+
+	if len(b.Categories) > 0 && b.Kind != KindFreeItem {
+		summary += " on " + strings.Join(b.Categories, ", ")
+	}
+	if b.MinOrderAmount != nil {
+		summary += " (min. order " + amount(b.MinOrderAmount) + ")"
+	}
+	return summary
+}
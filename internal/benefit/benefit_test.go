@@ -0,0 +1,294 @@
+package benefit
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dec(s string) *Decimal {
+	d := MustParseDecimal(s)
+	return &d
+}
+
+func TestDecimal(t *testing.T) {
+	_, err := ParseDecimal("1e3")
+	assert.Error(t, err)
+	_, err = ParseDecimal("-1")
+	assert.Error(t, err)
+	_, err = ParseDecimal("1/3")
+	assert.Error(t, err)
+
+	// 0.1 + 0.2 is exact
+	sum := MustParseDecimal("0.1").Add(MustParseDecimal("0.2"))
+	assert.Equal(t, 0, sum.Cmp(MustParseDecimal("0.3")))
+
+	third := MustParseDecimal("10").Percent(MustParseDecimal("33.333"))
+	assert.Equal(t, "3.33", third.Floor(2).StringFixed(2))
+	assert.Equal(t, "3.3333", third.String())
+	assert.Equal(t, "12", MustParseDecimal("12.000").String())
+	assert.Equal(t, "0.333333333333333333",
+		Decimal{r: big.NewRat(1, 3)}.String())
+
+	data, err := json.Marshal(MustParseDecimal("12.50"))
+	require.NoError(t, err)
+	assert.Equal(t, `"12.5"`, string(data))
+}
+
+func TestBenefit_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		benefit   Benefit
+		expectErr bool
+	}{
+		{
+			name:    "percent off",
+			benefit: Benefit{Kind: KindPercentOff, Percent: dec("10")},
+		},
+		{
+			name: "percent off with cap",
+			benefit: Benefit{
+				Kind:        KindPercentOff,
+				Percent:     dec("12.5"),
+				MaxDiscount: dec("5000"),
+				Currency:    "KRW",
+			},
+		},
+		{
+			name: "fixed amount",
+			benefit: Benefit{
+				Kind:     KindFixedAmount,
+				Amount:   dec("3.50"),
+				Currency: "USD",
+			},
+		},
+		{
+			name:    "free item",
+			benefit: Benefit{Kind: KindFreeItem, SKU: "AMERICANO"},
+		},
+		{
+			name:      "unknown kind",
+			benefit:   Benefit{Kind: "bogo"},
+			expectErr: true,
+		},
+		{
+			name:      "percent over 100",
+			benefit:   Benefit{Kind: KindPercentOff, Percent: dec("120")},
+			expectErr: true,
+		},
+		{
+			name:      "amount without currency",
+			benefit:   Benefit{Kind: KindFixedAmount, Amount: dec("5")},
+			expectErr: true,
+		},
+		{
+			name: "amount finer than currency",
+			benefit: Benefit{
+				Kind:     KindFixedAmount,
+				Amount:   dec("100.5"),
+				Currency: "KRW",
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid currency",
+			benefit: Benefit{
+				Kind:     KindFixedAmount,
+				Amount:   dec("5"),
+				Currency: "usd",
+			},
+			expectErr: true,
+		},
+		{
+			name:      "free item without sku",
+			benefit:   Benefit{Kind: KindFreeItem},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.benefit.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestBenefit_Apply(t *testing.T) {
+	cart := Cart{
+		Currency: "USD",
+		Items: []Item{
+			{
+				SKU:       "LATTE",
+				Category:  "drinks",
+				Quantity:  3,
+				UnitPrice: MustParseDecimal("4.35"),
+			},
+			{
+				SKU:       "MUG",
+				Category:  "goods",
+				Quantity:  1,
+				UnitPrice: MustParseDecimal("12.00"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		benefit  Benefit
+		reason   Reason
+		discount string
+		freeItem string
+	}{
+		{
+			name:     "percent off rounds down",
+			benefit:  Benefit{Kind: KindPercentOff, Percent: dec("15")},
+			discount: "3.75", // 15% of 25.05 = 3.7575
+		},
+		{
+			name: "percent off capped",
+			benefit: Benefit{
+				Kind:        KindPercentOff,
+				Percent:     dec("50"),
+				MaxDiscount: dec("10"),
+				Currency:    "USD",
+			},
+			discount: "10.00",
+		},
+		{
+			name: "percent off eligible categories only",
+			benefit: Benefit{
+				Kind:       KindPercentOff,
+				Percent:    dec("10"),
+				Categories: []string{"drinks"},
+			},
+			discount: "1.30", // 10% of 13.05
+		},
+		{
+			name: "fixed amount limited to eligible subtotal",
+			benefit: Benefit{
+				Kind:       KindFixedAmount,
+				Amount:     dec("20"),
+				Currency:   "USD",
+				Categories: []string{"goods"},
+			},
+			discount: "12.00",
+		},
+		{
+			name:     "free item",
+			benefit:  Benefit{Kind: KindFreeItem, SKU: "LATTE"},
+			discount: "4.35",
+			freeItem: "LATTE",
+		},
+		{
+			name:    "free item not in cart",
+			benefit: Benefit{Kind: KindFreeItem, SKU: "SCONE"},
+			reason:  ReasonFreeItemNotInCart,
+		},
+		{
+			name: "minimum order not met",
+			benefit: Benefit{
+				Kind:           KindPercentOff,
+				Percent:        dec("10"),
+				MinOrderAmount: dec("30"),
+				Currency:       "USD",
+			},
+			reason: ReasonMinOrderNotMet,
+		},
+		{
+			name: "currency mismatch",
+			benefit: Benefit{
+				Kind:     KindFixedAmount,
+				Amount:   dec("1000"),
+				Currency: "KRW",
+			},
+			reason: ReasonCurrencyMismatch,
+		},
+		{
+			name: "no eligible items",
+			benefit: Benefit{
+				Kind:       KindPercentOff,
+				Percent:    dec("10"),
+				Categories: []string{"food"},
+			},
+			reason: ReasonNoEligibleItems,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.benefit.Validate())
+
+			result, err := tt.benefit.Apply(cart)
+			require.NoError(t, err)
+			assert.Equal(t, "25.05", result.Subtotal.StringFixed(2))
+			assert.Equal(t, tt.reason, result.Reason)
+			assert.Equal(t, tt.reason == ReasonNone, result.Applicable)
+			if tt.reason != ReasonNone {
+				assert.Equal(t, 0, result.Discount.Sign())
+				return
+			}
+			assert.Equal(t, tt.discount, result.Discount.StringFixed(2))
+			assert.Equal(t,
+				result.Subtotal.Sub(result.Discount).StringFixed(2),
+				result.Total.StringFixed(2),
+			)
+			assert.Equal(t, tt.freeItem, result.FreeItemSKU)
+		})
+	}
+
+	t.Run("invalid cart", func(t *testing.T) {
+		b := Benefit{Kind: KindPercentOff, Percent: dec("10")}
+		_, err := b.Apply(Cart{
+			Currency: "KRW",
+			Items: []Item{
+				{SKU: "A", Quantity: 1, UnitPrice: MustParseDecimal("0.5")},
+			},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestBenefit_Summary(t *testing.T) {
+	tests := []struct {
+		benefit  Benefit
+		expected string
+	}{
+		{
+			Benefit{Kind: KindPercentOff, Percent: dec("12.5")},
+			"12.5% off",
+		},
+		{
+			Benefit{
+				Kind:           KindPercentOff,
+				Percent:        dec("10"),
+				MaxDiscount:    dec("5000"),
+				Currency:       "KRW",
+				MinOrderAmount: dec("20000"),
+				Categories:     []string{"drinks", "food"},
+			},
+			"10% off up to 5000 KRW on drinks, food (min. order 20000 KRW)",
+		},
+		{
+			Benefit{Kind: KindFixedAmount, Amount: dec("3.5"), Currency: "USD"},
+			"3.50 USD off",
+		},
+		{
+			Benefit{Kind: KindFreeItem, SKU: "AMERICANO"},
+			"Free AMERICANO",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.benefit.Summary())
+		})
+	}
+}
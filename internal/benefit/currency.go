@@ -0,0 +1,53 @@
+package benefit
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// minorUnits lists ISO 4217 currencies whose minor unit is not 1/100.
+var minorUnits = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"IQD": 3,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"PYG": 0,
+	"TND": 3,
+	"UGX": 0,
+	"VND": 0,
+}
+
+// MinorUnits returns the number of decimal places used by a currency.
+func MinorUnits(currency string) int {
+	if places, ok := minorUnits[currency]; ok {
+		return places
+	}
+	return 2
+}
+
+func validateCurrency(currency string) error {
+	if !currencyPattern.MatchString(currency) {
+		return fmt.Errorf("invalid currency %q", currency)
+	}
+	return nil
+}
+
+// validateAmount checks that an amount is expressible in the currency's
+// minor unit, e.g. 10.5 KRW is rejected.
+func validateAmount(name string, d Decimal, currency string) error {
+	if places := MinorUnits(currency); !d.HasPlaces(places) {
+		return fmt.Errorf(
+			"%s %s has more than %d decimal places for %s",
+			name, d, places, currency,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package benefit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+)
+
+var decimalPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// Decimal is an exact non-negative decimal amount. Arithmetic is done on
+// rationals so that no precision is lost before the final rounding step.
+// The zero value is 0.
+type Decimal struct {
+	r *big.Rat
+}
+
+// ParseDecimal parses a plain decimal such as "12" or "12.50". Signs,
+// exponents and fractions are rejected.
+func ParseDecimal(s string) (Decimal, error) {
+	if !decimalPattern.MatchString(s) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	return Decimal{r: r}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on invalid input.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) rat() *big.Rat {
+	if d.r == nil {
+		return new(big.Rat)
+	}
+	return d.r
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Add(d.rat(), o.rat())}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Sub(d.rat(), o.rat())}
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Mul(d.rat(), o.rat())}
+}
+
+func (d Decimal) MulInt(n int64) Decimal {
+	return Decimal{r: new(big.Rat).Mul(d.rat(), new(big.Rat).SetInt64(n))}
+}
+
+// Percent returns p percent of d.
+func (d Decimal) Percent(p Decimal) Decimal {
+	r := new(big.Rat).Mul(d.rat(), p.rat())
+	return Decimal{r: r.Quo(r, big.NewRat(100, 1))}
+}
+
+func (d Decimal) Cmp(o Decimal) int {
+	return d.rat().Cmp(o.rat())
+}
+
+func (d Decimal) Sign() int {
+	return d.rat().Sign()
+}
+
+// Min returns the smaller of d and o.
+func (d Decimal) Min(o Decimal) Decimal {
+	if o.Cmp(d) < 0 {
+		return o
+	}
+	return d
+}
+
+// Floor rounds d down to the given number of decimal places. Discounts are
+// always rounded down so that a customer is never given more than the
+// benefit allows.
+func (d Decimal) Floor(places int) Decimal {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	n := new(big.Int).Mul(d.rat().Num(), scale)
+	n.Quo(n, d.rat().Denom()) // non-negative, so truncation is floor
+	return Decimal{r: new(big.Rat).SetFrac(n, scale)}
+}
+
+// HasPlaces reports whether d can be written with at most the given number
+// of decimal places.
+func (d Decimal) HasPlaces(places int) bool {
+	return d.Floor(places).Cmp(d) == 0
+}
+
+// StringFixed formats d with exactly the given number of decimal places.
+func (d Decimal) StringFixed(places int) string {
+	return d.rat().FloatString(places)
+}
+
+// maxStringPlaces bounds String for values such as 1/3 that have no finite
+// decimal expansion.
+const maxStringPlaces = 18
+
+// String formats d with as few decimal places as needed. A value without a
+// finite decimal expansion is rounded down to maxStringPlaces places.
+func (d Decimal) String() string {
+	places, exact := d.rat().FloatPrec()
+	if !exact {
+		return d.Floor(maxStringPlaces).StringFixed(maxStringPlaces)
+	}
+	return d.StringFixed(places)
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
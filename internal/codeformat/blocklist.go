@@ -0,0 +1,72 @@
+package codeformat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Blocklist rejects codes containing any of a list of substrings, such as
+// offensive words that random characters happen to spell. Substrings and
+// codes are compared in their normalized form. The nil Blocklist blocks
+// nothing.
+type Blocklist struct {
+	words []string
+}
+
+// NewBlocklist returns a blocklist of words. Words that normalize to
+// nothing are ignored.
+func NewBlocklist(words []string) *Blocklist {
+	b := &Blocklist{}
+	seen := make(map[string]bool)
+	for _, word := range words {
+		word = Normalize(word)
+		if word == "" || seen[word] {
+			continue
+		}
+		seen[word] = true
+		b.words = append(b.words, word)
+	}
+	return b
+}
+
+// ReadBlocklist reads a blocklist with one word per line. Blank lines and
+// lines starting with # are skipped.
+func ReadBlocklist(r io.Reader) (*Blocklist, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blocklist: %w", err)
+	}
+	return NewBlocklist(words), nil
+}
+
+// Len returns the number of words in the blocklist.
+func (b *Blocklist) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.words)
+}
+
+// Blocks reports whether a code contains a word of the blocklist.
+func (b *Blocklist) Blocks(code string) bool {
+	if b.Len() == 0 {
+		return false
+	}
+	code = Normalize(code)
+	for _, word := range b.words {
+		if strings.Contains(code, word) {
+			return true
+		}
+	}
+	return false
+}
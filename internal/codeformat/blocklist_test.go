@@ -0,0 +1,56 @@
+package codeformat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocklist(t *testing.T) {
+	b, err := ReadBlocklist(strings.NewReader(
+		"# offensive words\n\nfxck\n 시발 \nＦＸＣＫ\n-\n",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, 2, b.Len(), "comments, duplicates and separators")
+
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"AFXCKB", true},
+		{"a-fxck-b", true},
+		{"12시발34", true},
+		{"12시-발34", true},
+		{"FXCAKB", false},
+		{"시가발", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, b.Blocks(tt.code), tt.code)
+	}
+
+	var none *Blocklist
+	assert.False(t, none.Blocks("FXCK"))
+	assert.False(t, NewBlocklist(nil).Blocks("FXCK"))
+}
+
+func TestBlocklist_Sample(t *testing.T) {
+	l, err := Format{Alphabet: AlphabetDigits, Length: 6}.Compile()
+	require.NoError(t, err)
+	b := NewBlocklist([]string{"13", "444"})
+
+	src := NewSeededSource(1)
+	blocked := 0
+	const n = 100000
+	for range n {
+		code := l.Generate(src)
+		want := strings.Contains(code, "13") || strings.Contains(code, "444")
+		require.Equal(t, want, b.Blocks(code), code)
+		if want {
+			blocked++
+		}
+	}
+	// About 1 - 0.99^5 of codes contain 13 and a few more 444
+	assert.InDelta(t, 0.053, float64(blocked)/n, 0.01)
+}
@@ -0,0 +1,244 @@
+package codeformat
+
+import (
+	"errors"
+	"fmt"
+)
+
+type CheckScheme string
+
+const (
+	CheckNone CheckScheme = ""
+	// CheckLuhn appends a Luhn mod N check character, where N is the size
+	// of the alphabet. It catches every single wrong character and most
+	// swaps of adjacent characters; with an odd N, every swap.
+	CheckLuhn CheckScheme = "luhn"
+	// CheckDamm appends a Damm check digit. It needs an alphabet of the ten
+	// digits and catches every single wrong digit and every swap of
+	// adjacent digits.
+	CheckDamm CheckScheme = "damm"
+)
+
+var dammTable = [10][10]int{
+	{0, 3, 1, 7, 5, 9, 8, 6, 4, 2},
+	{7, 0, 9, 2, 1, 5, 4, 8, 6, 3},
+	{4, 2, 0, 6, 8, 7, 1, 3, 5, 9},
+	{1, 7, 5, 0, 9, 8, 3, 4, 2, 6},
+	{6, 1, 2, 3, 0, 4, 5, 9, 7, 8},
+	{3, 6, 7, 4, 2, 0, 9, 5, 8, 1},
+	{5, 8, 6, 9, 7, 2, 0, 1, 3, 4},
+	{8, 9, 4, 5, 3, 6, 2, 0, 1, 7},
+	{9, 4, 3, 8, 6, 1, 7, 2, 0, 5},
+	{2, 5, 8, 1, 4, 3, 6, 7, 9, 0},
+}
+
+func (l *Layout) setCheck(scheme CheckScheme) error {
+	switch scheme {
+	case CheckNone:
+		return nil
+	case CheckLuhn:
+	case CheckDamm:
+		if len(l.alphabet) != 10 {
+			return errors.New("damm check digits need the ten digits")
+		}
+		l.digitValue = make([]int, len(l.alphabet))
+		for i, r := range l.alphabet {
+			if r < '0' || r > '9' {
+				return errors.New("damm check digits need the ten digits")
+			}
+			l.digitValue[i] = int(r - '0')
+			l.digitIndex[r-'0'] = i
+		}
+	default:
+		return fmt.Errorf("unknown check character scheme %q", scheme)
+	}
+
+	// The check character can only cover characters of the alphabet
+	if l.outside {
+		return errors.New("pattern uses characters outside the alphabet")
+	}
+	l.check = scheme
+	return nil
+}
+
+// checkValue returns the check character, as an index into the alphabet,
+// for the indices of a code's random characters.
+func (l *Layout) checkValue(values []int) int {
+	switch l.check {
+	case CheckLuhn:
+		n := len(l.alphabet)
+		sum := 0
+		for i, v := range values {
+			sum += l.luhnAddend(len(values)-i, v)
+		}
+		return (n - sum%n) % n
+	case CheckDamm:
+		interim := 0
+		for _, v := range values {
+			interim = dammTable[interim][l.digitValue[v]]
+		}
+		return l.digitIndex[interim]
+	}
+	return 0
+}
+
+// luhnAddend is what a character adds to a Luhn sum when it is k places
+// from the end of the code, counting the check character as 0.
+//
+// Summing the two base N digits of a doubled value only gives every
+// character a distinct addend when N is even. With an odd N, 2 is coprime
+// to N, so doubled values are taken mod N instead.
+func (l *Layout) luhnAddend(k, v int) int {
+	n := len(l.alphabet)
+	addend := v * (1 + k%2)
+	if n%2 == 1 {
+		return addend % n
+	}
+	return addend/n + addend%n
+}
+
+// HasCheck reports whether the layout's codes end with a check character.
+func (l *Layout) HasCheck() bool {
+	return l.check != CheckNone
+}
+
+// Matches reports whether a code has the layout's shape: its prefix, its
+// literal characters and alphabet characters everywhere else. The check
+// character is not verified.
+func (l *Layout) Matches(code string) bool {
+	_, ok := l.values(code)
+	return ok
+}
+
+// CheckValid reports whether a code has the layout's shape and a correct
+// check character. Codes of layouts without one only need the shape.
+func (l *Layout) CheckValid(code string) bool {
+	values, ok := l.values(code)
+	if !ok {
+		return false
+	}
+	if !l.HasCheck() {
+		return true
+	}
+	last := len(values) - 1
+	return l.checkValue(values[:last]) == values[last]
+}
+
+// Corrections returns the codes with a correct check character that differ
+// from code by one character or by a swap of two adjacent characters. They
+// are returned in their normalized form.
+func (l *Layout) Corrections(code string) []string {
+	values, ok := l.values(code)
+	if !ok || !l.HasCheck() {
+		return nil
+	}
+
+	var corrections []string
+	add := func(candidate []int) {
+		last := len(candidate) - 1
+		if l.checkValue(candidate[:last]) == candidate[last] {
+			corrections = append(corrections, l.format(candidate))
+		}
+	}
+
+	candidate := append([]int(nil), values...)
+	n := len(l.alphabet)
+	total := 0
+	if l.check == CheckLuhn {
+		for i, v := range values {
+			total += l.luhnAddend(len(values)-1-i, v)
+		}
+	}
+	for i, original := range values {
+		for v := range l.alphabet {
+			if v == original {
+				continue
+			}
+			// Luhn sums are additive, so only the changed character needs
+			// to be recomputed
+			if l.check == CheckLuhn {
+				k := len(values) - 1 - i
+				rest := total - l.luhnAddend(k, original)
+				if (rest+l.luhnAddend(k, v))%n != 0 {
+					continue
+				}
+			}
+			candidate[i] = v
+			add(candidate)
+		}
+		candidate[i] = original
+	}
+
+	// Only characters next to each other in the code can be swapped
+	positions := l.randomPositions()
+	for i := 0; i+1 < len(values); i++ {
+		if positions[i]+1 != positions[i+1] || values[i] == values[i+1] {
+			continue
+		}
+		candidate[i], candidate[i+1] = values[i+1], values[i]
+		add(candidate)
+		candidate[i], candidate[i+1] = values[i], values[i+1]
+	}
+	return corrections
+}
+
+// values returns the alphabet indices of a code's random characters
+// followed by its check character. The code is normalized first.
+func (l *Layout) values(code string) ([]int, bool) {
+	runes := []rune(Normalize(code))
+	prefix := []rune(l.canonPrefix)
+	n := len(prefix) + len(l.canonSlots)
+	if l.HasCheck() {
+		n++
+	}
+	if len(runes) != n || string(runes[:len(prefix)]) != l.canonPrefix {
+		return nil, false
+	}
+	runes = runes[len(prefix):]
+
+	var values []int
+	for i, r := range runes {
+		if i < len(l.canonSlots) && l.canonSlots[i].sets == nil {
+			if r != l.canonSlots[i].literal {
+				return nil, false
+			}
+			continue
+		}
+		v, ok := l.lookup()[r]
+		if !ok {
+			return nil, false
+		}
+		values = append(values, v)
+	}
+	return values, true
+}
+
+// format writes random character and check values back into a code in its
+// normalized form.
+func (l *Layout) format(values []int) string {
+	runes := []rune(l.canonPrefix)
+	for _, s := range l.canonSlots {
+		if s.sets == nil {
+			runes = append(runes, s.literal)
+			continue
+		}
+		runes = append(runes, l.chars[values[0]])
+		values = values[1:]
+	}
+	for _, v := range values {
+		runes = append(runes, l.alphabet[v])
+	}
+	return string(runes)
+}
+
+// randomPositions returns the position in the normalized code, after the
+// prefix, of each random character and of the check character.
+func (l *Layout) randomPositions() []int {
+	var positions []int
+	for i, s := range l.canonSlots {
+		if s.sets != nil {
+			positions = append(positions, i)
+		}
+	}
+	return append(positions, len(l.canonSlots))
+}
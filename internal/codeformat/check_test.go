@@ -0,0 +1,150 @@
+package codeformat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCharacter_KnownValues(t *testing.T) {
+	luhn, err := Format{
+		Alphabet:       AlphabetDigits,
+		CheckCharacter: CheckLuhn,
+	}.Compile()
+	require.NoError(t, err)
+	// Luhn mod 10 is the classic Luhn algorithm
+	assert.True(t, luhn.CheckValid("79927398713"))
+	assert.False(t, luhn.CheckValid("79927398710"))
+
+	damm, err := Format{
+		Alphabet:       AlphabetDigits,
+		Pattern:        "###",
+		CheckCharacter: CheckDamm,
+	}.Compile()
+	require.NoError(t, err)
+	assert.True(t, damm.CheckValid("5724"))
+	assert.False(t, damm.CheckValid("5274"))
+}
+
+func TestCheckCharacter_Compile(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		wantErr string
+	}{
+		{
+			"damm on letters",
+			Format{
+				Alphabet:       AlphabetLatinUppercase,
+				CheckCharacter: CheckDamm,
+			},
+			"damm check digits need the ten digits",
+		},
+		{
+			"pattern outside the alphabet",
+			Format{
+				Alphabet:       AlphabetHangul,
+				Pattern:        "@@##",
+				CheckCharacter: CheckLuhn,
+			},
+			"pattern uses characters outside the alphabet",
+		},
+		{
+			"unknown scheme",
+			Format{CheckCharacter: "crc"},
+			`unknown check character scheme "crc"`,
+		},
+		{
+			"check character counts towards the length",
+			Format{Length: MaxLength, CheckCharacter: CheckLuhn},
+			"codes would be 51 characters, at most 50 are allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.format.Compile()
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCheckCharacter_DetectsTypos(t *testing.T) {
+	formats := map[string]Format{
+		"mixed luhn": {CheckCharacter: CheckLuhn},
+		"crockford luhn": {
+			Alphabet:       AlphabetCrockfordBase32,
+			Prefix:         "GS-",
+			Pattern:        "####-@@@@",
+			CheckCharacter: CheckLuhn,
+		},
+		// Alphabets of an odd size double characters mod N
+		"readable luhn": {
+			Alphabet:       AlphabetReadable,
+			Length:         6,
+			CheckCharacter: CheckLuhn,
+		},
+		"odd custom luhn": {
+			CustomAlphabet: "ACDEFGHJKMN",
+			Length:         6,
+			CheckCharacter: CheckLuhn,
+		},
+		"digits damm": {
+			Alphabet:       AlphabetDigits,
+			Length:         8,
+			CheckCharacter: CheckDamm,
+		},
+	}
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			l, err := format.Compile()
+			require.NoError(t, err)
+
+			// Small alphabets are tried with every other character
+			substitutes := []rune("0Z가")
+			if alphabet := []rune(l.Alphabet()); len(alphabet) <= 32 {
+				substitutes = alphabet
+			}
+
+			src := NewSeededSource(1)
+			for range 20 {
+				code := l.Generate(src)
+				require.True(t, l.CheckValid(code), code)
+
+				// Corrections are normalized
+				want := Normalize(code)
+				runes := []rune(code)
+				for i, r := range runes {
+					// A wrong character fails the check and can be
+					// corrected. Literal positions never match.
+					for _, c := range substitutes {
+						typo := string(replace(runes, i, c))
+						if c == r || !l.Matches(typo) {
+							continue
+						}
+						assert.False(t, l.CheckValid(typo), typo)
+						assert.Contains(t, l.Corrections(typo), want)
+					}
+				}
+
+				// Damm catches every swap of adjacent digits, Luhn most
+				for i := 0; i+1 < len(runes); i++ {
+					swapped := append([]rune(nil), runes...)
+					swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+					typo := string(swapped)
+					if typo == code || !l.Matches(typo) ||
+						l.CheckValid(typo) {
+						continue
+					}
+					assert.Contains(t, l.Corrections(typo), want)
+				}
+			}
+		})
+	}
+}
+
+func replace(runes []rune, i int, r rune) []rune {
+	replaced := append([]rune(nil), runes...)
+	replaced[i] = r
+	return replaced
+}
@@ -0,0 +1,400 @@
+// Package codeformat describes what a campaign's coupon codes look like and
+// generates random codes in that shape.
+package codeformat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultLength is the number of random characters in a code when the
+	// format sets neither a length nor a pattern.
+	DefaultLength = 10
+	// MaxLength is the longest code, prefix included, that can be stored.
+	MaxLength = 50
+)
+
+type Alphabet string
+
+const (
+	// AlphabetMixed picks each character from Hangul syllables or digits
+	// with equal probability. It is the default.
+	AlphabetMixed Alphabet = ""
+	// AlphabetHangul is the 11,172 precomposed Hangul syllables.
+	AlphabetHangul Alphabet = "hangul"
+	// AlphabetDigits is 0-9.
+	AlphabetDigits Alphabet = "digits"
+	// AlphabetLatinUppercase is A-Z.
+	AlphabetLatinUppercase Alphabet = "latin_uppercase"
+	// AlphabetCrockfordBase32 is Crockford's base32, which leaves out I, L,
+	// O and U.
+	AlphabetCrockfordBase32 Alphabet = "crockford_base32"
+	// AlphabetReadable is digits and Latin capitals without the characters
+	// that are easily mistaken for one another when read or written: 0, O,
+	// Q and D; 1, I and L; 2 and Z; 5 and S; 8 and B; U and V.
+	AlphabetReadable Alphabet = "readable"
+)
+
+var (
+	digits    = runeRange('0', '9')
+	latin     = runeRange('A', 'Z')
+	hangul    = runeRange(0xAC00, 0xD7A3) // 가 to 힣
+	crockford = []rune("0123456789ABCDEFGHJKMNPQRSTVWXYZ")
+	readable  = []rune("34679ACEFGHJKMNPRTWXY")
+	// commonHangul is the 2,350 syllables of KS X 1001, out of the 11,172
+	// of hangul. Most of the others are rare and hard to read aloud.
+	commonHangul = ksx1001Hangul()
+)
+
+// Format is how a campaign's codes look. It is stored as JSON on the
+// campaign; the zero Format is the original 10 character mixed code.
+type Format struct {
+	Alphabet Alphabet `json:"alphabet,omitempty"`
+	// CustomAlphabet replaces Alphabet when set.
+	CustomAlphabet string `json:"custom_alphabet,omitempty"`
+	// CommonHangul restricts the Hangul syllables of the mixed and Hangul
+	// alphabets to the 2,350 of KS X 1001.
+	CommonHangul bool `json:"common_hangul,omitempty"`
+	// Length is the number of random characters. Not used with Pattern.
+	Length int `json:"length,omitempty"`
+	// Prefix is prepended to every code as is.
+	Prefix string `json:"prefix,omitempty"`
+	// Pattern is a template such as "SALE-####-@@@@" where # is a digit,
+	// @ a letter and * any character of the alphabet. A backslash makes
+	// the next character literal; other characters are copied as is.
+	Pattern string `json:"pattern,omitempty"`
+	// CheckCharacter appends a check character computed over the random
+	// characters, so that most typing mistakes can be told apart from
+	// unknown codes.
+	CheckCharacter CheckScheme `json:"check_character,omitempty"`
+}
+
+// slot is one character of a code: a literal, or a random character drawn
+// from one of its sets, picked with equal probability.
+type slot struct {
+	literal rune
+	sets    [][]rune
+}
+
+// Layout is a compiled Format.
+type Layout struct {
+	key      string
+	prefix   string
+	slots    []slot
+	alphabet []rune
+	check    CheckScheme
+	// outside is set when the pattern uses characters outside the alphabet
+	outside bool
+
+	// The prefix and slots of codes in their normalized form, in which
+	// literal separators are dropped
+	canonPrefix string
+	canonSlots  []slot
+
+	// chars is the alphabet followed by any pattern characters outside it,
+	// and index maps each of them to its position. Built on first use.
+	indexOnce sync.Once
+	chars     []rune
+	index     map[rune]int
+
+	// For Damm check digits, the digit value of each alphabet character
+	// and the alphabet index of each digit
+	digitValue []int
+	digitIndex [10]int
+}
+
+// Compile validates a format and prepares it for generating codes.
+func (f Format) Compile() (*Layout, error) {
+	var (
+		alphabet []rune
+		anyChar  [][]rune
+		digit    [][]rune
+		letter   [][]rune
+		// Placeholders whose characters are not in the alphabet
+		fallback = make(map[rune]bool)
+	)
+	syllables := hangul
+	if f.CommonHangul {
+		if f.CustomAlphabet != "" ||
+			(f.Alphabet != AlphabetMixed && f.Alphabet != AlphabetHangul) {
+			return nil, errors.New("common_hangul needs a Hangul alphabet")
+		}
+		syllables = commonHangul
+	}
+	switch {
+	case f.CustomAlphabet != "":
+		if f.Alphabet != AlphabetMixed {
+			return nil, errors.New("alphabet and custom_alphabet are exclusive")
+		}
+		var err error
+		if alphabet, err = parseAlphabet(f.CustomAlphabet); err != nil {
+			return nil, err
+		}
+		// Alphabets differing only in their form generate the same codes
+		f.CustomAlphabet = string(alphabet)
+	case f.Alphabet == AlphabetMixed:
+		alphabet = append(append([]rune(nil), digits...), syllables...)
+		anyChar = [][]rune{digits, syllables}
+		digit = [][]rune{digits}
+		letter = [][]rune{syllables}
+	case f.Alphabet == AlphabetHangul:
+		alphabet = syllables
+	case f.Alphabet == AlphabetDigits:
+		alphabet = digits
+	case f.Alphabet == AlphabetLatinUppercase:
+		alphabet = latin
+	case f.Alphabet == AlphabetCrockfordBase32:
+		alphabet = crockford
+	case f.Alphabet == AlphabetReadable:
+		alphabet = readable
+	default:
+		return nil, fmt.Errorf("unknown alphabet %q", f.Alphabet)
+	}
+	if anyChar == nil {
+		anyChar = [][]rune{alphabet}
+		digitSet, ok := subset(alphabet, unicode.IsDigit, digits)
+		digit, fallback['#'] = [][]rune{digitSet}, !ok
+		letterSet, ok := subset(alphabet, unicode.IsLetter, latin)
+		letter, fallback['@'] = [][]rune{letterSet}, !ok
+	}
+
+	if err := validateLiteral("prefix", f.Prefix); err != nil {
+		return nil, err
+	}
+	l := &Layout{prefix: f.Prefix, alphabet: alphabet}
+
+	if f.Pattern != "" {
+		if f.Length != 0 {
+			return nil, errors.New("length and pattern are exclusive")
+		}
+		if err := validateLiteral("pattern", f.Pattern); err != nil {
+			return nil, err
+		}
+		escaped := false
+		for _, r := range f.Pattern {
+			switch {
+			case escaped:
+				l.slots = append(l.slots, slot{literal: r})
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == '#':
+				l.slots = append(l.slots, slot{sets: digit})
+				l.outside = l.outside || fallback[r]
+			case r == '@':
+				l.slots = append(l.slots, slot{sets: letter})
+				l.outside = l.outside || fallback[r]
+			case r == '*':
+				l.slots = append(l.slots, slot{sets: anyChar})
+			default:
+				l.slots = append(l.slots, slot{literal: r})
+			}
+		}
+		if escaped {
+			return nil, errors.New("pattern ends with a backslash")
+		}
+	} else {
+		length := f.Length
+		if length == 0 {
+			length = DefaultLength
+		}
+		if length < 0 {
+			return nil, errors.New("length cannot be negative")
+		}
+		f.Length = length
+		for range length {
+			l.slots = append(l.slots, slot{sets: anyChar})
+		}
+	}
+
+	if l.Bits() == 0 {
+		return nil, errors.New("format has no random characters")
+	}
+	l.canonPrefix = Normalize(l.prefix)
+	for _, s := range l.slots {
+		if s.sets != nil {
+			l.canonSlots = append(l.canonSlots, s)
+			continue
+		}
+		for _, r := range normalizeRune(s.literal) {
+			l.canonSlots = append(l.canonSlots, slot{literal: r})
+		}
+	}
+	if err := l.setCheck(f.CheckCharacter); err != nil {
+		return nil, err
+	}
+	n := utf8.RuneCountInString(l.prefix) + len(l.slots)
+	if l.HasCheck() {
+		n++
+	}
+	if n > MaxLength {
+		return nil, fmt.Errorf(
+			"codes would be %d characters, at most %d are allowed",
+			n, MaxLength,
+		)
+	}
+
+	key, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	l.key = string(key)
+	return l, nil
+}
+
+// Key identifies the layout; formats that generate the same codes share it.
+func (l *Layout) Key() string {
+	return l.key
+}
+
+// Prefix returns the literal prefix of every code.
+func (l *Layout) Prefix() string {
+	return l.prefix
+}
+
+// Alphabet returns the characters of the format's alphabet. Pattern
+// digits and letters fall back to 0-9 and A-Z when it has none.
+func (l *Layout) Alphabet() string {
+	return string(l.alphabet)
+}
+
+// Bits is the min-entropy of a code in bits, i.e. log2 of one over the
+// chance of the likeliest code. Generate picks one of a slot's sets and
+// then one of its characters, so a mixed slot draws a digit half of the
+// time and is worth log2(20) bits, not log2(10+11172).
+func (l *Layout) Bits() float64 {
+	var bits float64
+	for _, s := range l.slots {
+		// The sets of a slot are disjoint, so the likeliest character is
+		// one of the smallest set
+		smallest := 0
+		for _, set := range s.sets {
+			if smallest == 0 || len(set) < smallest {
+				smallest = len(set)
+			}
+		}
+		if smallest > 0 {
+			bits += math.Log2(float64(len(s.sets) * smallest))
+		}
+	}
+	return bits
+}
+
+// Generate returns a random code drawn from src.
+func (l *Layout) Generate(src Source) string {
+	var (
+		b      strings.Builder
+		values []int
+	)
+	b.WriteString(l.prefix)
+	for _, s := range l.slots {
+		if s.sets == nil {
+			b.WriteRune(s.literal)
+			continue
+		}
+		set := s.sets[src.Intn(len(s.sets))]
+		r := set[src.Intn(len(set))]
+		b.WriteRune(r)
+		if l.HasCheck() {
+			values = append(values, l.lookup()[r])
+		}
+	}
+	if l.HasCheck() {
+		b.WriteRune(l.alphabet[l.checkValue(values)])
+	}
+	return b.String()
+}
+
+// lookup returns the index of every character a code can contain.
+func (l *Layout) lookup() map[rune]int {
+	l.indexOnce.Do(func() {
+		l.chars = append([]rune(nil), l.alphabet...)
+		l.index = make(map[rune]int, len(l.alphabet))
+		for i, r := range l.alphabet {
+			l.index[r] = i
+		}
+		for _, s := range l.slots {
+			for _, set := range s.sets {
+				for _, r := range set {
+					if _, ok := l.index[r]; !ok {
+						l.index[r] = len(l.chars)
+						l.chars = append(l.chars, r)
+					}
+				}
+			}
+		}
+	})
+	return l.index
+}
+
+func parseAlphabet(s string) ([]rune, error) {
+	if !utf8.ValidString(s) {
+		return nil, errors.New("custom_alphabet is not valid UTF-8")
+	}
+	seen := make(map[rune]bool)
+	var alphabet []rune
+	for _, r := range s {
+		// Codes are stored normalized, so every character must keep a form
+		// of its own
+		normalized := normalizeRune(r)
+		if !unicode.IsPrint(r) || len(normalized) != 1 {
+			return nil, fmt.Errorf("custom_alphabet cannot contain %q", r)
+		}
+		r = normalized[0]
+		if seen[r] {
+			return nil, fmt.Errorf("custom_alphabet repeats %q", r)
+		}
+		seen[r] = true
+		alphabet = append(alphabet, r)
+	}
+	if len(alphabet) < 2 {
+		return nil, errors.New("custom_alphabet needs at least two characters")
+	}
+	return alphabet, nil
+}
+
+func validateLiteral(name, s string) error {
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("%s is not valid UTF-8", name)
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) || unicode.IsSpace(r) {
+			return fmt.Errorf("%s cannot contain %q", name, r)
+		}
+	}
+	return nil
+}
+
+// subset returns the characters of alphabet matching f, or fallback and
+// false if none do.
+func subset(
+	alphabet []rune,
+	f func(rune) bool,
+	fallback []rune,
+) ([]rune, bool) {
+	var set []rune
+	for _, r := range alphabet {
+		if f(r) {
+			set = append(set, r)
+		}
+	}
+	if set == nil {
+		return fallback, false
+	}
+	return set, true
+}
+
+func runeRange(first, last rune) []rune {
+	runes := make([]rune, 0, last-first+1)
+	for r := first; r <= last; r++ {
+		runes = append(runes, r)
+	}
+	return runes
+}
@@ -0,0 +1,159 @@
+package codeformat
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		wantErr string
+	}{
+		{"default", Format{}, ""},
+		{"preset", Format{Alphabet: AlphabetDigits, Length: 12}, ""},
+		{"custom", Format{CustomAlphabet: "ABC123"}, ""},
+		{"pattern", Format{Pattern: `SALE-####-@@@@`}, ""},
+		{
+			"unknown preset",
+			Format{Alphabet: "emoji"},
+			`unknown alphabet "emoji"`,
+		},
+		{
+			"preset and custom",
+			Format{Alphabet: AlphabetDigits, CustomAlphabet: "AB"},
+			"alphabet and custom_alphabet are exclusive",
+		},
+		{
+			"custom too short",
+			Format{CustomAlphabet: "A"},
+			"custom_alphabet needs at least two characters",
+		},
+		{
+			"custom repeats",
+			Format{CustomAlphabet: "ABA"},
+			`custom_alphabet repeats 'A'`,
+		},
+		{
+			"custom with space",
+			Format{CustomAlphabet: "A B"},
+			`custom_alphabet cannot contain ' '`,
+		},
+		{
+			"length and pattern",
+			Format{Length: 4, Pattern: "####"},
+			"length and pattern are exclusive",
+		},
+		{
+			"negative length",
+			Format{Length: -1},
+			"length cannot be negative",
+		},
+		{
+			"literal only",
+			Format{Pattern: `SALE-\#`},
+			"format has no random characters",
+		},
+		{
+			"trailing backslash",
+			Format{Pattern: `##\`},
+			"pattern ends with a backslash",
+		},
+		{
+			"common hangul without hangul",
+			Format{Alphabet: AlphabetDigits, CommonHangul: true},
+			"common_hangul needs a Hangul alphabet",
+		},
+		{
+			"too long",
+			Format{Prefix: strings.Repeat("A", 45), Length: 6},
+			"codes would be 51 characters, at most 50 are allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.format.Compile()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLayout_Generate(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		match  *regexp.Regexp
+	}{
+		{
+			"default",
+			Format{},
+			regexp.MustCompile(`^[0-9가-힣]{10}$`),
+		},
+		{
+			"digits with prefix",
+			Format{Alphabet: AlphabetDigits, Length: 8, Prefix: "GS25-"},
+			regexp.MustCompile(`^GS25-[0-9]{8}$`),
+		},
+		{
+			"crockford pattern",
+			Format{
+				Alphabet: AlphabetCrockfordBase32,
+				Pattern:  `SALE-####-@@@@`,
+			},
+			regexp.MustCompile(`^SALE-[0-9]{4}-[A-HJKMNP-TV-Z]{4}$`),
+		},
+		{
+			"hangul pattern falls back to digits",
+			Format{Alphabet: AlphabetHangul, Pattern: `@@#\#*`},
+			regexp.MustCompile(`^[가-힣]{2}[0-9]#[가-힣]$`),
+		},
+		{
+			"readable",
+			Format{Alphabet: AlphabetReadable, Pattern: `**-#@`},
+			regexp.MustCompile(`^[34679ACEFGHJKMNPRTWXY]{2}-[34679][A-Y]$`),
+		},
+		{
+			"custom",
+			Format{CustomAlphabet: "XY", Length: 16},
+			regexp.MustCompile(`^[XY]{16}$`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := tt.format.Compile()
+			require.NoError(t, err)
+			src := NewSeededSource(1)
+			for range 100 {
+				assert.Regexp(t, tt.match, l.Generate(src))
+			}
+		})
+	}
+}
+
+func TestLayout(t *testing.T) {
+	l, err := Format{}.Compile()
+	require.NoError(t, err)
+	assert.Equal(t, 10+11172, utf8.RuneCountInString(l.Alphabet()))
+	assert.InDelta(t, 10*math.Log2(2*10), l.Bits(), 1e-9)
+
+	// Formats that generate the same codes share a pool
+	explicit, err := Format{Length: DefaultLength}.Compile()
+	require.NoError(t, err)
+	assert.Equal(t, l.Key(), explicit.Key())
+
+	other, err := Format{Alphabet: AlphabetDigits}.Compile()
+	require.NoError(t, err)
+	assert.NotEqual(t, l.Key(), other.Key())
+	assert.InDelta(t, 10*math.Log2(10), other.Bits(), 1e-9)
+}
@@ -0,0 +1,21 @@
+package codeformat
+
+import (
+	"golang.org/x/text/encoding/korean"
+)
+
+// ksx1001Hangul returns the 2,350 Hangul syllables of KS X 1001, the ones
+// in common use. EUC-KR encodes them in rows 0xB0 to 0xC8, in order.
+func ksx1001Hangul() []rune {
+	encoded := make([]byte, 0, 2*2350)
+	for row := byte(0xB0); row <= 0xC8; row++ {
+		for col := byte(0xA1); col <= 0xFE; col++ {
+			encoded = append(encoded, row, col)
+		}
+	}
+	decoded, err := korean.EUCKR.NewDecoder().Bytes(encoded)
+	if err != nil {
+		panic(err)
+	}
+	return []rune(string(decoded))
+}
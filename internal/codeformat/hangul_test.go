@@ -0,0 +1,57 @@
+package codeformat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKSX1001Hangul(t *testing.T) {
+	syllables := ksx1001Hangul()
+	require.Len(t, syllables, 2350)
+	assert.Equal(t, '가', syllables[0])
+	assert.Equal(t, '힝', syllables[len(syllables)-1])
+	for i, r := range syllables {
+		require.True(t, r >= '가' && r <= '힣', "%q", r)
+		if i > 0 {
+			require.Less(t, syllables[i-1], r, "in order")
+		}
+	}
+}
+
+func TestCommonHangul(t *testing.T) {
+	common := make(map[rune]bool)
+	for _, r := range commonHangul {
+		common[r] = true
+	}
+	for _, r := range "가각똥힝" {
+		assert.True(t, common[r], "%q", r)
+	}
+	// Rare syllables of the full range
+	for _, r := range "똠뷁햏샾쌰" {
+		assert.False(t, common[r], "%q", r)
+	}
+
+	for _, alphabet := range []Alphabet{AlphabetMixed, AlphabetHangul} {
+		t.Run(string(alphabet), func(t *testing.T) {
+			l, err := Format{Alphabet: alphabet, CommonHangul: true}.Compile()
+			require.NoError(t, err)
+
+			src := NewSeededSource(1)
+			seen := make(map[rune]bool)
+			for range 20000 {
+				for _, r := range l.Generate(src) {
+					if r >= '0' && r <= '9' {
+						require.Equal(t, AlphabetMixed, alphabet)
+						continue
+					}
+					require.True(t, common[r], "%q", r)
+					seen[r] = true
+				}
+			}
+			// 100,000 or more draws cover all of them
+			assert.Len(t, seen, len(commonHangul))
+		})
+	}
+}
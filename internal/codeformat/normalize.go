@@ -0,0 +1,42 @@
+package codeformat
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// Normalize returns the canonical form of a code, in which codes are stored
+// and looked up, so that a code typed or pasted in another form still
+// matches:
+//
+//   - full-width and half-width characters are folded to their usual width
+//   - decomposed Hangul jamo are composed into syllables (NFC)
+//   - spaces, dashes and invisible formatting characters are removed
+//   - Latin letters are upper-cased
+func Normalize(code string) string {
+	code = norm.NFC.String(width.Fold.String(code))
+
+	var b strings.Builder
+	b.Grow(len(code))
+	for _, r := range code {
+		switch {
+		case unicode.IsSpace(r),
+			unicode.Is(unicode.Pd, r),
+			unicode.Is(unicode.Cf, r):
+		case unicode.Is(unicode.Latin, r):
+			b.WriteRune(unicode.ToUpper(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// normalizeRune returns the canonical form of a single character, which is
+// empty for separators.
+func normalizeRune(r rune) []rune {
+	return []rune(Normalize(string(r)))
+}
@@ -0,0 +1,79 @@
+package codeformat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"canonical", "GS25AB12", "GS25AB12"},
+		{"lower case latin", "gs25ab12", "GS25AB12"},
+		{"separators", " GS25-AB 12 ", "GS25AB12"},
+		{"unicode dashes", "GS25\u2010AB\u201312", "GS25AB12"},
+		{"full width", "ＧＳ２５ＡＢ１２", "GS25AB12"},
+		{"ideographic space", "GS25\u3000AB12", "GS25AB12"},
+		{"zero width characters", "\ufeffGS25\u200bAB12", "GS25AB12"},
+		{"decomposed hangul", "\u1100\u1161\u11a8\u1102\u1161", "각나"},
+		{"mixed script", "ｇｓ-가나-12", "GS가나12"},
+		{"other scripts keep their case", "ΑβγΔ", "ΑβγΔ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Normalize(tt.code)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, got, Normalize(got), "idempotent")
+		})
+	}
+}
+
+func TestLayout_NormalizedInput(t *testing.T) {
+	l, err := Format{
+		Prefix:         "gs-",
+		Pattern:        "####-**",
+		CustomAlphabet: "0123456789ab가나",
+		CheckCharacter: CheckLuhn,
+	}.Compile()
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789AB가나", l.Alphabet())
+
+	// Alphabets differing only in their form share a key
+	other, err := Format{
+		Prefix:         "gs-",
+		Pattern:        "####-**",
+		CustomAlphabet: "0123456789AB가나",
+		CheckCharacter: CheckLuhn,
+	}.Compile()
+	require.NoError(t, err)
+	assert.Equal(t, l.Key(), other.Key())
+
+	code := l.Generate(NewSeededSource(1))
+	assert.Regexp(t, `^gs-[0-9]{4}-`, code)
+	for _, typed := range []string{
+		code,
+		Normalize(code),
+		" " + code + " ",
+		strings.ToLower(code),
+	} {
+		assert.True(t, l.CheckValid(typed), typed)
+	}
+
+	s := NewSequencer(l, []byte("test key"))
+	seqCode, err := s.Code(7)
+	require.NoError(t, err)
+	seq, ok := s.Sequence(Normalize(seqCode))
+	require.True(t, ok, seqCode)
+	assert.Equal(t, uint64(7), seq)
+
+	_, err = Format{CustomAlphabet: "0123456789aA"}.Compile()
+	assert.Error(t, err, "repeats after normalization")
+	_, err = Format{CustomAlphabet: "0123456789-"}.Compile()
+	assert.Error(t, err, "separators are dropped")
+}
@@ -0,0 +1,246 @@
+package codeformat
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// feistelRounds is the number of rounds of the permutation. It is even, so
+// both halves are mixed the same number of times.
+const feistelRounds = 8
+
+// ErrSequenceExhausted is returned for sequence numbers past the number of
+// codes a layout can generate.
+var ErrSequenceExhausted = errors.New("code space exhausted")
+
+// Sequencer derives codes from sequence numbers. Each number maps to a
+// distinct code through a keyed permutation of the layout's code space, so
+// codes are unique without coordination and cannot be guessed from each
+// other without the key.
+type Sequencer struct {
+	layout *Layout
+	size   *big.Int
+	perm   *permutation
+}
+
+// NewSequencer returns a sequencer for a layout. Sequencers with the same
+// layout and key produce the same codes.
+func NewSequencer(layout *Layout, key []byte) *Sequencer {
+	size := layout.Size()
+	return &Sequencer{
+		layout: layout,
+		size:   size,
+		perm:   newPermutation(key, size),
+	}
+}
+
+// Size is the number of codes the layout can generate, and one more than
+// the largest sequence number. It saturates at the largest uint64.
+func (s *Sequencer) Size() uint64 {
+	if !s.size.IsUint64() {
+		return ^uint64(0)
+	}
+	return s.size.Uint64()
+}
+
+// Code returns the code for a sequence number.
+func (s *Sequencer) Code(seq uint64) (string, error) {
+	x := new(big.Int).SetUint64(seq)
+	if x.Cmp(s.size) >= 0 {
+		return "", ErrSequenceExhausted
+	}
+	return s.layout.encode(s.perm.apply(x)), nil
+}
+
+// Sequence returns the sequence number a code was derived from. It fails
+// for codes that do not have the layout's shape or check character.
+func (s *Sequencer) Sequence(code string) (uint64, bool) {
+	x, ok := s.layout.decode(code)
+	if !ok {
+		return 0, false
+	}
+	seq := s.perm.invert(x)
+	if !seq.IsUint64() {
+		return 0, false
+	}
+	return seq.Uint64(), true
+}
+
+// Size returns the number of distinct codes the layout can generate.
+func (l *Layout) Size() *big.Int {
+	size := big.NewInt(1)
+	for _, s := range l.slots {
+		if n := slotSize(s); n > 0 {
+			size.Mul(size, big.NewInt(int64(n)))
+		}
+	}
+	return size
+}
+
+// encode writes x, which is below Size, as a code. The first random
+// character is the most significant digit. Every character of a slot is
+// equally likely, whichever of its sets it is in.
+func (l *Layout) encode(x *big.Int) string {
+	x = new(big.Int).Set(x)
+	picked := make([]rune, len(l.slots))
+	digit := new(big.Int)
+	for i := len(l.slots) - 1; i >= 0; i-- {
+		s := l.slots[i]
+		if s.sets == nil {
+			continue
+		}
+		x.DivMod(x, big.NewInt(int64(slotSize(s))), digit)
+		v := int(digit.Int64())
+		for _, set := range s.sets {
+			if v < len(set) {
+				picked[i] = set[v]
+				break
+			}
+			v -= len(set)
+		}
+	}
+
+	var (
+		b      strings.Builder
+		values []int
+	)
+	b.WriteString(l.prefix)
+	for i, s := range l.slots {
+		if s.sets == nil {
+			b.WriteRune(s.literal)
+			continue
+		}
+		b.WriteRune(picked[i])
+		values = append(values, l.lookup()[picked[i]])
+	}
+	if l.HasCheck() {
+		b.WriteRune(l.alphabet[l.checkValue(values)])
+	}
+	return b.String()
+}
+
+// decode is the inverse of encode. It accepts codes in any form that
+// normalizes to one of the layout's codes.
+func (l *Layout) decode(code string) (*big.Int, bool) {
+	if !l.CheckValid(code) {
+		return nil, false
+	}
+	code = Normalize(code)
+	runes := []rune(code)[len([]rune(l.canonPrefix)):]
+
+	x := new(big.Int)
+	for i, s := range l.canonSlots {
+		if s.sets == nil {
+			continue
+		}
+		// Linear, but codes are only decoded to look up their sequence
+		v, ok := slotIndex(s, runes[i])
+		if !ok {
+			return nil, false
+		}
+		x.Mul(x, big.NewInt(int64(slotSize(s))))
+		x.Add(x, big.NewInt(int64(v)))
+	}
+	return x, true
+}
+
+func slotSize(s slot) int {
+	n := 0
+	for _, set := range s.sets {
+		n += len(set)
+	}
+	return n
+}
+
+func slotIndex(s slot, r rune) (int, bool) {
+	offset := 0
+	for _, set := range s.sets {
+		for i, c := range set {
+			if c == r {
+				return offset + i, true
+			}
+		}
+		offset += len(set)
+	}
+	return 0, false
+}
+
+// permutation is a keyed pseudorandom permutation of [0, size): a
+// balanced Feistel network over [0, half²), where half² >= size, with
+// cycle walking to stay below size.
+type permutation struct {
+	key  []byte
+	size *big.Int
+	half *big.Int
+	// bytes of round function output, enough to reduce modulo half with
+	// negligible bias
+	outputLen int
+}
+
+func newPermutation(key []byte, size *big.Int) *permutation {
+	half := new(big.Int).Sqrt(size)
+	if new(big.Int).Mul(half, half).Cmp(size) < 0 {
+		half.Add(half, big.NewInt(1))
+	}
+	return &permutation{
+		key:       key,
+		size:      size,
+		half:      half,
+		outputLen: (half.BitLen()+7)/8 + 8,
+	}
+}
+
+func (p *permutation) apply(x *big.Int) *big.Int {
+	for {
+		x = p.rounds(x, false)
+		if x.Cmp(p.size) < 0 {
+			return x
+		}
+	}
+}
+
+func (p *permutation) invert(x *big.Int) *big.Int {
+	for {
+		x = p.rounds(x, true)
+		if x.Cmp(p.size) < 0 {
+			return x
+		}
+	}
+}
+
+func (p *permutation) rounds(x *big.Int, inverse bool) *big.Int {
+	l, r := new(big.Int).DivMod(x, p.half, new(big.Int))
+	for i := range feistelRounds {
+		if inverse {
+			// Undo (l, r) -> (r, l + F(r))
+			round := feistelRounds - 1 - i
+			f := p.round(round, l)
+			l, r = r.Sub(r, f).Mod(r, p.half), l
+		} else {
+			f := p.round(i, r)
+			l, r = r, l.Add(l, f).Mod(l, p.half)
+		}
+	}
+	return l.Mul(l, p.half).Add(l, r)
+}
+
+// round is the round function: HMAC-SHA256 of the round number and input,
+// extended in counter mode and reduced modulo half.
+func (p *permutation) round(i int, x *big.Int) *big.Int {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(i))
+	out := make([]byte, 0, p.outputLen+sha256.Size)
+	for counter := uint32(0); len(out) < p.outputLen; counter++ {
+		binary.BigEndian.PutUint32(header[4:], counter)
+		mac := hmac.New(sha256.New, p.key)
+		mac.Write(header[:])
+		mac.Write(x.Bytes())
+		out = mac.Sum(out)
+	}
+	f := new(big.Int).SetBytes(out[:p.outputLen])
+	return f.Mod(f, p.half)
+}
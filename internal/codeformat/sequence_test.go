@@ -0,0 +1,84 @@
+package codeformat
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequencer(t *testing.T) {
+	key := []byte("test key")
+
+	t.Run("every code of a small space once", func(t *testing.T) {
+		l, err := Format{CustomAlphabet: "ABC", Pattern: "X-**#"}.Compile()
+		require.NoError(t, err)
+		s := NewSequencer(l, key)
+		require.Equal(t, uint64(3*3*10), s.Size())
+
+		match := regexp.MustCompile(`^X-[ABC]{2}[0-9]$`)
+		seen := make(map[string]bool)
+		for seq := range s.Size() {
+			code, err := s.Code(seq)
+			require.NoError(t, err)
+			assert.Regexp(t, match, code)
+			assert.False(t, seen[code], code)
+			seen[code] = true
+
+			back, ok := s.Sequence(code)
+			require.True(t, ok, code)
+			assert.Equal(t, seq, back)
+		}
+
+		_, err = s.Code(s.Size())
+		assert.ErrorIs(t, err, ErrSequenceExhausted)
+	})
+
+	t.Run("large spaces", func(t *testing.T) {
+		l, err := Format{CheckCharacter: CheckLuhn, Prefix: "K"}.Compile()
+		require.NoError(t, err)
+		s := NewSequencer(l, key)
+		assert.Equal(t, ^uint64(0), s.Size())
+
+		for _, seq := range []uint64{0, 1, 2, 1 << 40} {
+			code, err := s.Code(seq)
+			require.NoError(t, err)
+			assert.True(t, l.CheckValid(code), code)
+
+			back, ok := s.Sequence(code)
+			require.True(t, ok, code)
+			assert.Equal(t, seq, back)
+		}
+	})
+
+	t.Run("codes depend on the key", func(t *testing.T) {
+		l, err := Format{Alphabet: AlphabetDigits, Length: 12}.Compile()
+		require.NoError(t, err)
+
+		first, err := NewSequencer(l, key).Code(1)
+		require.NoError(t, err)
+		again, err := NewSequencer(l, key).Code(1)
+		require.NoError(t, err)
+		other, err := NewSequencer(l, []byte("other key")).Code(1)
+		require.NoError(t, err)
+		assert.Equal(t, first, again)
+		assert.NotEqual(t, first, other)
+
+		// Consecutive numbers do not give neighbouring codes
+		next, err := NewSequencer(l, key).Code(2)
+		require.NoError(t, err)
+		assert.NotEqual(t, first[:8], next[:8])
+	})
+
+	t.Run("unknown codes", func(t *testing.T) {
+		l, err := Format{Alphabet: AlphabetDigits, Length: 6}.Compile()
+		require.NoError(t, err)
+		s := NewSequencer(l, key)
+
+		for _, code := range []string{"", "12345", "12345A", "1234567"} {
+			_, ok := s.Sequence(code)
+			assert.False(t, ok, code)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package codeformat
+
+import (
+	"bufio"
+	crand "crypto/rand"
+	"encoding/binary"
+	"io"
+	"math/rand/v2"
+	"sync"
+)
+
+// Source supplies the randomness codes are generated from.
+type Source interface {
+	// Intn returns a uniformly distributed integer in [0, n). It panics if
+	// n <= 0.
+	Intn(n int) int
+}
+
+// cryptoSource draws from crypto/rand. Values are taken by rejection
+// sampling, so every value is equally likely for any n.
+type cryptoSource struct {
+	mu sync.Mutex
+	r  *bufio.Reader
+}
+
+// NewCryptoSource returns a source that is safe for concurrent use and
+// whose output cannot be predicted from earlier codes.
+func NewCryptoSource() Source {
+	return &cryptoSource{r: bufio.NewReaderSize(crand.Reader, 4096)}
+}
+
+func (s *cryptoSource) Intn(n int) int {
+	if n <= 0 {
+		panic("codeformat: invalid argument to Intn")
+	}
+	bound := uint64(n)
+	// Values at or above the largest multiple of n that fits in 64 bits
+	// would make the smallest results more likely
+	excess := -bound % bound
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var b [8]byte
+	for {
+		if _, err := io.ReadFull(s.r, b[:]); err != nil {
+			panic("codeformat: failed to read randomness: " + err.Error())
+		}
+		v := binary.LittleEndian.Uint64(b[:])
+		if v <= ^uint64(0)-excess {
+			return int(v % bound)
+		}
+	}
+}
+
+type seededSource struct {
+	r *rand.Rand
+}
+
+// NewSeededSource returns a deterministic source for tests. The same seed
+// always produces the same codes. It is not safe for concurrent use.
+func NewSeededSource(seed uint64) Source {
+	return seededSource{r: rand.New(rand.NewPCG(seed, seed))}
+}
+
+func (s seededSource) Intn(n int) int {
+	return s.r.IntN(n)
+}
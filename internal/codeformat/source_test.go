@@ -0,0 +1,42 @@
+package codeformat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeededSource(t *testing.T) {
+	l, err := Format{}.Compile()
+	require.NoError(t, err)
+
+	generate := func(seed uint64) []string {
+		src := NewSeededSource(seed)
+		codes := make([]string, 10)
+		for i := range codes {
+			codes[i] = l.Generate(src)
+		}
+		return codes
+	}
+	assert.Equal(t, generate(1), generate(1))
+	assert.NotEqual(t, generate(1), generate(2))
+}
+
+func TestCryptoSource(t *testing.T) {
+	src := NewCryptoSource()
+
+	// Every value of a size that does not divide 2^64 comes up
+	const n = 11172
+	seen := make([]bool, n)
+	for range 50 * n {
+		v := src.Intn(n)
+		require.GreaterOrEqual(t, v, 0)
+		require.Less(t, v, n)
+		seen[v] = true
+	}
+	assert.NotContains(t, seen, false)
+
+	assert.Equal(t, 0, src.Intn(1))
+	assert.Panics(t, func() { src.Intn(0) })
+}
@@ -0,0 +1,311 @@
+// Package codeimage renders coupon codes as QR codes and barcodes that
+// store staff can scan from a phone, and signs the URLs they are served at.
+package codeimage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Symbology is the kind of symbol a code is drawn as.
+type Symbology string
+
+const (
+	// SymbologyQR is a QR code. It can hold any code.
+	SymbologyQR Symbology = "qr"
+	// SymbologyCode128 is a Code 128 barcode, for codes of ASCII
+	// characters.
+	SymbologyCode128 Symbology = "code128"
+	// SymbologyEAN13 is an EAN-13 barcode, for codes of 12 digits, or 13
+	// whose last one is the EAN check digit.
+	SymbologyEAN13 Symbology = "ean13"
+)
+
+// Format is the file format of an image.
+type Format string
+
+const (
+	FormatPNG Format = "png"
+	FormatSVG Format = "svg"
+)
+
+const (
+	// MaxTextLength is the longest branding text, in characters.
+	MaxTextLength = 40
+
+	// Size of a module, the smallest square or bar, in pixels
+	qrModuleSize    = 8
+	linearBarWidth  = 3
+	linearBarHeight = 120
+
+	// Blank modules around a symbol that scanners need to find it
+	qrQuietZone     = 4
+	linearQuietZone = 10
+
+	// Branding text is drawn with a 7x13 font scaled by textScale
+	textScale   = 2
+	textPadding = 8 * textScale
+	svgFontSize = 13 * textScale
+)
+
+// ErrUnsupported is returned for codes that a symbology cannot hold.
+var ErrUnsupported = errors.New("code cannot be written in this symbology")
+
+// Request is an image of a code.
+type Request struct {
+	Code      string
+	Symbology Symbology
+	Format    Format
+	// Text is optional branding text drawn under the symbol. PNG images
+	// can only draw ASCII text.
+	Text string
+}
+
+// ContentType returns the media type of images in a format.
+func (f Format) ContentType() string {
+	if f == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Validate checks that a request can be rendered.
+func (r Request) Validate() error {
+	_, err := r.encode()
+	return err
+}
+
+// Render writes the image of a request to w.
+func (r Request) Render(w io.Writer) error {
+	s, err := r.encode()
+	if err != nil {
+		return err
+	}
+	if r.Format == FormatSVG {
+		return s.writeSVG(w, r.Text)
+	}
+	return s.writePNG(w, r.Text)
+}
+
+// symbol is an encoded code measured in pixels, quiet zone included.
+type symbol struct {
+	width, height int
+	// top is where the first row of modules starts
+	top int
+	// rows holds the start and width of the dark runs of each row
+	rows                   [][][2]int
+	moduleWidth, rowHeight int
+}
+
+func (r Request) encode() (*symbol, error) {
+	switch r.Format {
+	case FormatPNG, FormatSVG:
+	default:
+		return nil, fmt.Errorf("unknown image format %q", r.Format)
+	}
+	if r.Code == "" {
+		return nil, errors.New("code cannot be empty")
+	}
+	textLen := utf8.RuneCountInString(r.Text)
+	if !utf8.ValidString(r.Text) || textLen > MaxTextLength {
+		return nil, fmt.Errorf(
+			"text must be valid UTF-8 of at most %d characters",
+			MaxTextLength,
+		)
+	}
+	for _, c := range r.Text {
+		if c < ' ' || c == 0x7F {
+			return nil, fmt.Errorf("text cannot contain %q", c)
+		}
+		if r.Format == FormatPNG && c > '~' {
+			return nil, errors.New("text in PNG images must be ASCII")
+		}
+	}
+
+	var (
+		bc  barcode.Barcode
+		err error
+	)
+	switch r.Symbology {
+	case SymbologyQR:
+		bc, err = qr.Encode(r.Code, qr.M, qr.Auto)
+	case SymbologyCode128:
+		for _, c := range r.Code {
+			if c > 0x7F {
+				return nil, fmt.Errorf("%w: %q is not ASCII", ErrUnsupported, c)
+			}
+		}
+		bc, err = code128.Encode(r.Code)
+	case SymbologyEAN13:
+		n := len(r.Code)
+		if (n != 12 && n != 13) || strings.Trim(r.Code, "0123456789") != "" {
+			return nil, fmt.Errorf(
+				"%w: EAN-13 needs 12 or 13 digits", ErrUnsupported,
+			)
+		}
+		bc, err = ean.Encode(r.Code)
+	default:
+		return nil, fmt.Errorf("unknown symbology %q", r.Symbology)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	return newSymbol(bc), nil
+}
+
+func newSymbol(bc barcode.Barcode) *symbol {
+	b := bc.Bounds()
+	s := &symbol{}
+	quiet := qrQuietZone
+	s.moduleWidth, s.rowHeight = qrModuleSize, qrModuleSize
+	if bc.Metadata().Dimensions == 1 {
+		quiet = linearQuietZone
+		s.moduleWidth, s.rowHeight = linearBarWidth, linearBarHeight
+	}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		var runs [][2]int
+		start := -1
+		for x := b.Min.X; x <= b.Max.X; x++ {
+			dark := x < b.Max.X && isDark(bc.At(x, y))
+			switch {
+			case dark && start < 0:
+				start = x
+			case !dark && start >= 0:
+				runs = append(runs, [2]int{
+					(start - b.Min.X + quiet) * s.moduleWidth,
+					(x - start) * s.moduleWidth,
+				})
+				start = -1
+			}
+		}
+		s.rows = append(s.rows, runs)
+	}
+
+	s.width = (b.Dx() + 2*quiet) * s.moduleWidth
+	s.height = b.Dy() * s.rowHeight
+	// Bars already run from top to bottom
+	if bc.Metadata().Dimensions == 2 {
+		s.top = quiet * s.rowHeight
+		s.height += 2 * s.top
+	}
+	return s
+}
+
+func isDark(c color.Color) bool {
+	gray := color.GrayModel.Convert(c).(color.Gray)
+	return gray.Y < 0x80
+}
+
+// layout returns the size of the image and where the symbol and the text go.
+func (s *symbol) layout(textWidth int) (width, height, left, textTop int) {
+	width, height = s.width, s.height
+	if textWidth > 0 {
+		width = max(width, textWidth+2*textPadding)
+		textTop = height
+		height += 13*textScale + textPadding
+	}
+	return width, height, (width - s.width) / 2, textTop
+}
+
+func (s *symbol) writePNG(w io.Writer, text string) error {
+	face := basicfont.Face7x13
+	textWidth := font.MeasureString(face, text).Ceil() * textScale
+	width, height, left, textTop := s.layout(textWidth)
+
+	img := image.NewPaletted(
+		image.Rect(0, 0, width, height),
+		color.Palette{color.White, color.Black},
+	)
+	for i, runs := range s.rows {
+		y := s.top + i*s.rowHeight
+		for _, run := range runs {
+			for dy := range s.rowHeight {
+				row := img.Pix[(y+dy)*img.Stride:]
+				for x := left + run[0]; x < left+run[0]+run[1]; x++ {
+					row[x] = 1
+				}
+			}
+		}
+	}
+
+	if text != "" {
+		// Draw at the font's size, then scale up without smoothing
+		small := image.NewPaletted(
+			image.Rect(0, 0, textWidth/textScale, 13),
+			color.Palette{color.White, color.Black},
+		)
+		d := font.Drawer{
+			Dst:  small,
+			Src:  image.Black,
+			Face: face,
+			Dot:  fixed.P(0, face.Ascent),
+		}
+		d.DrawString(text)
+		textLeft := (width - textWidth) / 2
+		for y := range textScale * 13 {
+			for x := range textWidth {
+				if small.ColorIndexAt(x/textScale, y/textScale) == 1 {
+					img.SetColorIndex(textLeft+x, textTop+y, 1)
+				}
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+func (s *symbol) writeSVG(w io.Writer, text string) error {
+	// Glyphs of the 7x13 font are 7 pixels wide; SVG text is sized alike
+	textWidth := utf8.RuneCountInString(text) * 7 * textScale
+	width, height, left, textTop := s.layout(textWidth)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" `+
+			`viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n",
+		width, height, width, height,
+	)
+	b.WriteString(`<rect width="100%" height="100%" fill="#fff"/>` + "\n")
+	b.WriteString(`<path fill="#000" d="`)
+	for i, runs := range s.rows {
+		for _, run := range runs {
+			fmt.Fprintf(&b, "M%d %dh%dv%dh-%dz",
+				left+run[0], s.top+i*s.rowHeight, run[1], s.rowHeight, run[1],
+			)
+		}
+	}
+	b.WriteString(`"/>` + "\n")
+
+	if text != "" {
+		fmt.Fprintf(&b,
+			`<text x="%d" y="%d" text-anchor="middle" `+
+				`font-family="sans-serif" font-size="%d">`,
+			width/2, textTop+11*textScale, svgFontSize,
+		)
+		if err := xml.EscapeText(&b, []byte(text)); err != nil {
+			return err
+		}
+		b.WriteString("</text>\n")
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := b.WriteTo(w)
+	return err
+}
@@ -0,0 +1,130 @@
+package codeimage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender_PNG(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+	}{
+		{"qr", Request{Code: "A7K2M9QX", Symbology: SymbologyQR}},
+		{"qr hangul", Request{Code: "봄세일가득", Symbology: SymbologyQR}},
+		{"code128", Request{Code: "SALE-2024", Symbology: SymbologyCode128}},
+		{"ean13", Request{Code: "590123412345", Symbology: SymbologyEAN13}},
+		{"ean13 check digit", Request{
+			Code:      "5901234123457",
+			Symbology: SymbologyEAN13,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, text := tt.req, tt.req
+			plain.Format, text.Format = FormatPNG, FormatPNG
+			text.Text = "Spring Sale"
+
+			var b bytes.Buffer
+			require.NoError(t, plain.Render(&b))
+			img, err := png.Decode(&b)
+			require.NoError(t, err)
+			// Quiet zone corners are blank
+			assert.True(t, !isDark(img.At(0, 0)))
+			size := img.Bounds()
+
+			b.Reset()
+			require.NoError(t, text.Render(&b))
+			withText, err := png.Decode(&b)
+			require.NoError(t, err)
+			assert.Greater(t, withText.Bounds().Dy(), size.Dy())
+			assert.GreaterOrEqual(t, withText.Bounds().Dx(), size.Dx())
+		})
+	}
+}
+
+func TestRender_SVG(t *testing.T) {
+	r := Request{
+		Code:      "A7K2M9QX",
+		Symbology: SymbologyQR,
+		Format:    FormatSVG,
+		Text:      "<봄> & sale",
+	}
+	var b bytes.Buffer
+	require.NoError(t, r.Render(&b))
+
+	var svg struct {
+		XMLName xml.Name
+		Width   int `xml:"width,attr"`
+		Path    struct {
+			D string `xml:"d,attr"`
+		} `xml:"path"`
+		Text string `xml:"text"`
+	}
+	require.NoError(t, xml.Unmarshal(b.Bytes(), &svg))
+	assert.Equal(t, "svg", svg.XMLName.Local)
+	assert.Positive(t, svg.Width)
+	assert.True(t, strings.HasPrefix(svg.Path.D, "M"))
+	assert.Equal(t, r.Text, svg.Text)
+	assert.Equal(t, "image/svg+xml", r.Format.ContentType())
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         Request
+		unsupported bool
+	}{
+		{"unknown format", Request{
+			Code: "ABC", Symbology: SymbologyQR, Format: "gif",
+		}, false},
+		{"unknown symbology", Request{
+			Code: "ABC", Symbology: "pdf417", Format: FormatPNG,
+		}, false},
+		{"empty code", Request{
+			Symbology: SymbologyQR, Format: FormatPNG,
+		}, false},
+		{"long text", Request{
+			Code: "ABC", Symbology: SymbologyQR, Format: FormatSVG,
+			Text: strings.Repeat("a", MaxTextLength+1),
+		}, false},
+		{"control text", Request{
+			Code: "ABC", Symbology: SymbologyQR, Format: FormatSVG,
+			Text: "a\nb",
+		}, false},
+		{"hangul text in png", Request{
+			Code: "ABC", Symbology: SymbologyQR, Format: FormatPNG,
+			Text: "봄 세일",
+		}, false},
+		{"code128 hangul", Request{
+			Code: "봄세일", Symbology: SymbologyCode128, Format: FormatPNG,
+		}, true},
+		{"ean13 letters", Request{
+			Code: "59012341234A", Symbology: SymbologyEAN13, Format: FormatPNG,
+		}, true},
+		{"ean13 short", Request{
+			Code: "12345", Symbology: SymbologyEAN13, Format: FormatPNG,
+		}, true},
+		{"ean13 bad check digit", Request{
+			Code: "5901234123458", Symbology: SymbologyEAN13, Format: FormatPNG,
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			require.Error(t, err)
+			assert.Equal(t, tt.unsupported, errors.Is(err, ErrUnsupported))
+		})
+	}
+
+	ok := Request{Code: "ABC", Symbology: SymbologyQR, Format: FormatSVG}
+	ok.Text = "봄 세일"
+	assert.NoError(t, ok.Validate())
+}
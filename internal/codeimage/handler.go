@@ -0,0 +1,56 @@
+package codeimage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// NewHandler serves the images of URLs signed by signer.
+func NewHandler(signer *Signer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		now := time.Now()
+		r, expires, err := signer.Verify(req.URL.Query(), now)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		var b bytes.Buffer
+		if err := r.Render(&b); err != nil {
+			// Signed requests were validated when they were signed
+			if errors.Is(err, ErrUnsupported) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log.Printf("Failed to render coupon image: %v", err)
+			http.Error(
+				w, "failed to render image", http.StatusInternalServerError,
+			)
+			return
+		}
+
+		// Images can be cached until their URL expires, but only by the
+		// user's device since they show a coupon code
+		maxAge := int(expires.Sub(now).Seconds())
+		w.Header().Set(
+			"Cache-Control", fmt.Sprintf("private, max-age=%d", maxAge),
+		)
+		w.Header().Set("Content-Type", r.Format.ContentType())
+		w.Header().Set("Content-Length", strconv.Itoa(b.Len()))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if _, err := b.WriteTo(w); err != nil {
+			log.Printf("Failed to write coupon image: %v", err)
+		}
+	})
+}
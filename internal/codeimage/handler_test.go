@@ -0,0 +1,100 @@
+package codeimage
+
+import (
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	r := Request{
+		Code:      "A7K2M9QX",
+		Symbology: SymbologyCode128,
+		Format:    FormatSVG,
+		Text:      "Spring Sale",
+	}
+	now := time.Now()
+	expires := now.Add(time.Hour).Truncate(time.Second)
+
+	u, err := url.Parse(signer.URL(r, expires))
+	require.NoError(t, err)
+	assert.Equal(t, Path, u.Path)
+
+	got, gotExpires, err := signer.Verify(u.Query(), now)
+	require.NoError(t, err)
+	assert.Equal(t, r, got)
+	assert.True(t, expires.Equal(gotExpires))
+
+	_, _, err = signer.Verify(u.Query(), expires)
+	assert.ErrorIs(t, err, ErrExpired)
+
+	other := NewSigner([]byte("other"))
+	_, _, err = other.Verify(u.Query(), now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	for _, param := range []string{"code", "type", "format", "text", "expires"} {
+		q := u.Query()
+		q.Set(param, q.Get(param)+"1")
+		_, _, err := signer.Verify(q, now)
+		assert.ErrorIs(t, err, ErrInvalidSignature, param)
+	}
+	q := u.Query()
+	q.Del("text")
+	_, _, err = signer.Verify(q, now)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "text removed")
+}
+
+func TestHandler(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	srv := httptest.NewServer(NewHandler(signer))
+	defer srv.Close()
+
+	r := Request{Code: "A7K2M9QX", Symbology: SymbologyQR, Format: FormatPNG}
+	signed := srv.URL + signer.URL(r, time.Now().Add(time.Hour))
+
+	resp, err := http.Get(signed)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(
+		resp.Header.Get("Cache-Control"), "private, max-age=",
+	))
+	_, err = png.Decode(resp.Body)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		want   int
+	}{
+		{"unsigned", http.MethodGet, srv.URL + Path + "?code=A7K2M9QX" +
+			"&type=qr&format=png", http.StatusForbidden},
+		{"other code", http.MethodGet, strings.Replace(
+			signed, "A7K2M9QX", "A7K2M9QY", 1,
+		), http.StatusForbidden},
+		{"expired", http.MethodGet, srv.URL + signer.URL(
+			r, time.Now().Add(-time.Second),
+		), http.StatusForbidden},
+		{"post", http.MethodPost, signed, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, nil)
+			require.NoError(t, err)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package codeimage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Path is where the image handler is mounted.
+const Path = "/images/coupon"
+
+var (
+	// ErrInvalidSignature is returned for URLs that were not signed by the
+	// signer or were changed after signing.
+	ErrInvalidSignature = errors.New("invalid image URL signature")
+	// ErrExpired is returned for signed URLs past their expiry.
+	ErrExpired = errors.New("image URL has expired")
+)
+
+// Signer signs image URLs so that only codes the service handed out can be
+// rendered. Without a signature anyone could probe the endpoint for codes.
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret []byte) *Signer {
+	return &Signer{secret: secret}
+}
+
+// URL returns the signed path and query of an image, valid until expires.
+// The caller prefixes it with the server's public address.
+func (s *Signer) URL(r Request, expires time.Time) string {
+	q := params(r, expires)
+	q.Set("sig", s.sign(q))
+	return Path + "?" + q.Encode()
+}
+
+// Verify checks the signature and expiry of an image URL's query and
+// returns the image it asks for and when the URL expires.
+func (s *Signer) Verify(
+	q url.Values,
+	now time.Time,
+) (Request, time.Time, error) {
+	sig, err := base64.RawURLEncoding.DecodeString(q.Get("sig"))
+	if err != nil {
+		return Request{}, time.Time{}, ErrInvalidSignature
+	}
+	expiresAt, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil {
+		return Request{}, time.Time{}, ErrInvalidSignature
+	}
+
+	r := Request{
+		Code:      q.Get("code"),
+		Symbology: Symbology(q.Get("type")),
+		Format:    Format(q.Get("format")),
+		Text:      q.Get("text"),
+	}
+	// Re-encoding drops any parameters that were not signed
+	expires := time.Unix(expiresAt, 0)
+	want, _ := base64.RawURLEncoding.DecodeString(
+		s.sign(params(r, expires)),
+	)
+	if !hmac.Equal(sig, want) {
+		return Request{}, time.Time{}, ErrInvalidSignature
+	}
+	if !now.Before(expires) {
+		return Request{}, time.Time{}, ErrExpired
+	}
+	return r, expires, nil
+}
+
+func params(r Request, expires time.Time) url.Values {
+	q := url.Values{
+		"code":    {r.Code},
+		"type":    {string(r.Symbology)},
+		"format":  {string(r.Format)},
+		"expires": {strconv.FormatInt(expires.Unix(), 10)},
+	}
+	if r.Text != "" {
+		q.Set("text", r.Text)
+	}
+	return q
+}
+
+func (s *Signer) sign(q url.Values) string {
+	mac := hmac.New(sha256.New, s.secret)
+	// Encode sorts by key, so the message does not depend on the URL
+	mac.Write([]byte(q.Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
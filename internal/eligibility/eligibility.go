@@ -0,0 +1,122 @@
+// Package eligibility evaluates campaign targeting rules written in a small
+// CEL-like expression language, for example:
+//
+//	user.tier in ["gold", "vip"] && user.region == "KR" &&
+//	    user.signup_date >= now() - duration("720h") &&
+//	    user.app_version >= "5.2"
+//
+// Supported attributes are user.tier, user.region, user.signup_date and
+// user.app_version. The functions timestamp(), duration(), version() and
+// now() build typed values; string literals compared against a timestamp or
+// version attribute are converted automatically.
+package eligibility
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Attributes are the request attributes an expression can refer to.
+type Attributes struct {
+	Tier       string
+	Region     string
+	SignupDate string // RFC3339 timestamp or YYYY-MM-DD
+	AppVersion string // dotted numeric version
+}
+
+type Reason int
+
+const (
+	ReasonNone Reason = iota
+	// ReasonRuleNotSatisfied means the attributes did not match the rule.
+	ReasonRuleNotSatisfied
+	// ReasonMissingAttribute means the rule needs an attribute that the
+	// request did not provide.
+	ReasonMissingAttribute
+	// ReasonInvalidAttribute means a provided attribute could not be parsed.
+	ReasonInvalidAttribute
+)
+
+// Decision is the outcome of evaluating a Program.
+type Decision struct {
+	Allowed bool
+	Reason  Reason
+	// Clause is the source text of the first top-level condition that
+	// denied the request.
+	Clause string
+	// Attribute is set for missing or invalid attribute denials.
+	Attribute string
+}
+
+// Program is a compiled eligibility expression.
+type Program struct {
+	source    string
+	conjuncts []node
+}
+
+// Compile parses and type-checks an expression.
+func Compile(expr string) (*Program, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	root, err := parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	root, kind, err := check(root)
+	if err != nil {
+		return nil, err
+	}
+	if kind != kindBool {
+		return nil, fmt.Errorf("expression must evaluate to bool, not %s", kind)
+	}
+	return &Program{source: expr, conjuncts: splitConjuncts(root, nil)}, nil
+}
+
+// Source returns the expression the program was compiled from.
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Evaluate runs the program against attrs using the current time.
+func (p *Program) Evaluate(attrs Attributes) Decision {
+	return p.EvaluateAt(attrs, time.Now())
+}
+
+// EvaluateAt runs the program with now() fixed to the given time.
+func (p *Program) EvaluateAt(attrs Attributes, now time.Time) Decision {
+	e := &evaluator{attrs: attrs, now: now}
+	for _, conjunct := range p.conjuncts {
+		start, end := conjunct.span()
+		clause := strings.TrimSpace(p.source[start:end])
+
+		v, err := e.eval(conjunct)
+		if err != nil {
+			decision := Decision{Reason: ReasonInvalidAttribute, Clause: clause}
+			var attrErr *attributeError
+			if errors.As(err, &attrErr) {
+				decision.Attribute = attrErr.name
+				if attrErr.missing {
+					decision.Reason = ReasonMissingAttribute
+				}
+			}
+			return decision
+		}
+		if !v.b {
+			return Decision{Reason: ReasonRuleNotSatisfied, Clause: clause}
+		}
+	}
+	return Decision{Allowed: true}
+}
+
+// splitConjuncts flattens the top-level && chain so a denial can point at
+// the specific condition that failed.
+func splitConjuncts(n node, out []node) []node {
+	if b, ok := n.(*binaryNode); ok && b.op == tokenAnd {
+		out = splitConjuncts(b.left, out)
+		return splitConjuncts(b.right, out)
+	}
+	return append(out, n)
+}
@@ -0,0 +1,150 @@
+package eligibility
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		expectErr bool
+	}{
+		{"tier list", `user.tier in ["gold", "vip"]`, false},
+		{"combined", `user.region == "KR" && user.app_version >= "5.2"`, false},
+		{"relative date", `user.signup_date >= now() - duration("720h")`, false},
+		{"grouping", `!(user.tier == "basic") || user.region != "US"`, false},
+		{"empty", "", true},
+		{"unknown attribute", `user.age > 20`, true},
+		{"unknown function", `lower(user.tier) == "gold"`, true},
+		{"not bool", `user.tier`, true},
+		{"type mismatch", `user.tier > 3`, true},
+		{"bad version literal", `user.app_version >= "five"`, true},
+		{"bad timestamp", `user.signup_date > timestamp("yesterday")`, true},
+		{"trailing tokens", `user.tier == "gold" "vip"`, true},
+		{"unterminated string", `user.tier == "gold`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.expr)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestProgram_Evaluate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	program, err := Compile(`user.tier in ["gold", "vip"] && ` +
+		`user.region == "KR" && ` +
+		`user.signup_date >= now() - duration("720h") && ` +
+		`user.app_version >= "5.2"`)
+	require.NoError(t, err)
+
+	eligible := Attributes{
+		Tier:       "gold",
+		Region:     "KR",
+		SignupDate: "2026-02-20",
+		AppVersion: "5.10.1",
+	}
+
+	tests := []struct {
+		name      string
+		mutate    func(a *Attributes)
+		allowed   bool
+		reason    Reason
+		clause    string
+		attribute string
+	}{
+		{
+			name:    "all conditions met",
+			mutate:  func(a *Attributes) {},
+			allowed: true,
+		},
+		{
+			name:   "wrong tier",
+			mutate: func(a *Attributes) { a.Tier = "basic" },
+			reason: ReasonRuleNotSatisfied,
+			clause: `user.tier in ["gold", "vip"]`,
+		},
+		{
+			name:   "old account",
+			mutate: func(a *Attributes) { a.SignupDate = "2025-01-01T00:00:00Z" },
+			reason: ReasonRuleNotSatisfied,
+			clause: `user.signup_date >= now() - duration("720h")`,
+		},
+		{
+			name:   "outdated app",
+			mutate: func(a *Attributes) { a.AppVersion = "5.1.9" },
+			reason: ReasonRuleNotSatisfied,
+			clause: `user.app_version >= "5.2"`,
+		},
+		{
+			name:      "missing region",
+			mutate:    func(a *Attributes) { a.Region = "" },
+			reason:    ReasonMissingAttribute,
+			clause:    `user.region == "KR"`,
+			attribute: "region",
+		},
+		{
+			name:      "malformed version",
+			mutate:    func(a *Attributes) { a.AppVersion = "latest" },
+			reason:    ReasonInvalidAttribute,
+			clause:    `user.app_version >= "5.2"`,
+			attribute: "app_version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := eligible
+			tt.mutate(&attrs)
+
+			decision := program.EvaluateAt(attrs, now)
+			assert.Equal(t, tt.allowed, decision.Allowed)
+			assert.Equal(t, tt.reason, decision.Reason)
+			assert.Equal(t, tt.clause, decision.Clause)
+			assert.Equal(t, tt.attribute, decision.Attribute)
+		})
+	}
+}
+
+func TestProgram_ShortCircuit(t *testing.T) {
+	program, err := Compile(`user.tier == "vip" || user.region == "KR"`)
+	require.NoError(t, err)
+
+	// The region is never consulted once the tier matches.
+	decision := program.Evaluate(Attributes{Tier: "vip"})
+	assert.True(t, decision.Allowed)
+
+	decision = program.Evaluate(Attributes{Tier: "gold"})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonMissingAttribute, decision.Reason)
+
+	// A missing tier does not deny a request the region allows.
+	decision = program.Evaluate(Attributes{Region: "KR"})
+	assert.True(t, decision.Allowed)
+
+	decision = program.Evaluate(Attributes{Region: "US"})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonMissingAttribute, decision.Reason)
+	assert.Equal(t, "tier", decision.Attribute)
+
+	// Nor does it make a request the region rules out look incomplete.
+	program, err = Compile(`(user.tier == "vip" && user.region == "KR") ||
+		user.region == "JP"`)
+	require.NoError(t, err)
+	decision = program.Evaluate(Attributes{Region: "JP"})
+	assert.True(t, decision.Allowed)
+	decision = program.Evaluate(Attributes{Region: "US"})
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonRuleNotSatisfied, decision.Reason)
+}
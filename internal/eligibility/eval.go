@@ -0,0 +1,390 @@
+package eligibility
+
+import (
+	"fmt"
+	"time"
+)
+
+var attributeKinds = map[string]valueKind{
+	"tier":        kindString,
+	"region":      kindString,
+	"signup_date": kindTimestamp,
+	"app_version": kindVersion,
+}
+
+type function struct {
+	params []valueKind
+	result valueKind
+	call   func(args []value, now time.Time) (value, error)
+}
+
+var functions = map[string]function{
+	"timestamp": {
+		params: []valueKind{kindString},
+		result: kindTimestamp,
+		call: func(args []value, _ time.Time) (value, error) {
+			return convert(args[0], kindTimestamp)
+		},
+	},
+	"duration": {
+		params: []valueKind{kindString},
+		result: kindDuration,
+		call: func(args []value, _ time.Time) (value, error) {
+			d, err := time.ParseDuration(args[0].s)
+			if err != nil {
+				return value{}, fmt.Errorf("invalid duration %q", args[0].s)
+			}
+			return durationValue(d), nil
+		},
+	},
+	"version": {
+		params: []valueKind{kindString},
+		result: kindVersion,
+		call: func(args []value, _ time.Time) (value, error) {
+			return parseVersion(args[0].s)
+		},
+	},
+	"now": {
+		result: kindTimestamp,
+		call: func(_ []value, now time.Time) (value, error) {
+			return timestampValue(now), nil
+		},
+	},
+}
+
+// attributeError reports a request attribute that the expression needs
+// but that was absent or malformed.
+type attributeError struct {
+	name    string
+	missing bool
+	err     error
+}
+
+func (e *attributeError) Error() string {
+	if e.missing {
+		return fmt.Sprintf("attribute user.%s is required", e.name)
+	}
+	return fmt.Sprintf("attribute user.%s is invalid: %v", e.name, e.err)
+}
+
+// check validates types ahead of evaluation and folds constant conversions
+// so that malformed literals are rejected when a campaign is created.
+func check(n node) (node, valueKind, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n, n.val.kind, nil
+	case *attributeNode:
+		return n, attributeKinds[n.name], nil
+	case *listNode:
+		for i, elem := range n.elems {
+			checked, _, err := check(elem)
+			if err != nil {
+				return nil, 0, err
+			}
+			n.elems[i] = checked
+		}
+		return n, kindList, nil
+	case *callNode:
+		return checkCall(n)
+	case *unaryNode:
+		operand, kind, err := check(n.operand)
+		if err != nil {
+			return nil, 0, err
+		}
+		if kind != kindBool {
+			return nil, 0, typeError(n, "operand of ! must be bool")
+		}
+		n.operand = operand
+		return n, kindBool, nil
+	case *binaryNode:
+		return checkBinary(n)
+	}
+	return nil, 0, fmt.Errorf("unsupported expression")
+}
+
+func checkCall(n *callNode) (node, valueKind, error) {
+	fn := functions[n.fn]
+	if len(n.args) != len(fn.params) {
+		return nil, 0, typeError(n, fmt.Sprintf(
+			"%s() takes %d argument(s)", n.fn, len(fn.params),
+		))
+	}
+	constant := true
+	args := make([]value, len(n.args))
+	for i, arg := range n.args {
+		checked, kind, err := check(arg)
+		if err != nil {
+			return nil, 0, err
+		}
+		if kind != fn.params[i] {
+			return nil, 0, typeError(n, fmt.Sprintf(
+				"%s() expects a %s argument", n.fn, fn.params[i],
+			))
+		}
+		n.args[i] = checked
+		if lit, ok := checked.(*literalNode); ok {
+			args[i] = lit.val
+		} else {
+			constant = false
+		}
+	}
+	if !constant || n.fn == "now" {
+		return n, fn.result, nil
+	}
+	v, err := fn.call(args, time.Time{})
+	if err != nil {
+		return nil, 0, typeError(n, err.Error())
+	}
+	return &literalNode{n.pos, v}, fn.result, nil
+}
+
+func checkBinary(n *binaryNode) (node, valueKind, error) {
+	left, lk, err := check(n.left)
+	if err != nil {
+		return nil, 0, err
+	}
+	right, rk, err := check(n.right)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch n.op {
+	case tokenAnd, tokenOr:
+		if lk != kindBool || rk != kindBool {
+			return nil, 0, typeError(n, "operands of && and || must be bool")
+		}
+	case tokenEq, tokenNe, tokenLt, tokenLe, tokenGt, tokenGe:
+		if left, lk, err = coerceLiteral(left, lk, rk); err != nil {
+			return nil, 0, typeError(n, err.Error())
+		}
+		if right, rk, err = coerceLiteral(right, rk, lk); err != nil {
+			return nil, 0, typeError(n, err.Error())
+		}
+		if lk != rk {
+			return nil, 0, typeError(n, fmt.Sprintf(
+				"cannot compare %s with %s", lk, rk,
+			))
+		}
+		ordered := n.op != tokenEq && n.op != tokenNe
+		if ordered && (lk == kindBool || lk == kindList) {
+			return nil, 0, typeError(n, fmt.Sprintf(
+				"%s values are not ordered", lk,
+			))
+		}
+	case tokenIn:
+		if rk != kindList {
+			return nil, 0, typeError(n, "right operand of in must be a list")
+		}
+		if list, ok := right.(*listNode); ok {
+			for i, elem := range list.elems {
+				ek := kindString
+				if lit, ok := elem.(*literalNode); ok {
+					ek = lit.val.kind
+				}
+				converted, _, err := coerceLiteral(elem, ek, lk)
+				if err != nil {
+					return nil, 0, typeError(n, err.Error())
+				}
+				list.elems[i] = converted
+			}
+		}
+	case tokenPlus, tokenMinus:
+		kind, ok := arithmeticResult(n.op, lk, rk)
+		if !ok {
+			return nil, 0, typeError(n, fmt.Sprintf(
+				"invalid operands %s and %s", lk, rk,
+			))
+		}
+		n.left, n.right = left, right
+		return n, kind, nil
+	}
+
+	n.left, n.right = left, right
+	return n, kindBool, nil
+}
+
+// coerceLiteral converts a string literal compared against a timestamp or
+// version so that user.app_version >= "5.2" reads naturally.
+func coerceLiteral(
+	n node,
+	kind valueKind,
+	target valueKind,
+) (node, valueKind, error) {
+	lit, ok := n.(*literalNode)
+	if !ok || kind != kindString {
+		return n, kind, nil
+	}
+	if target != kindTimestamp && target != kindVersion {
+		return n, kind, nil
+	}
+	v, err := convert(lit.val, target)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &literalNode{lit.pos, v}, target, nil
+}
+
+func arithmeticResult(op tokenKind, lk, rk valueKind) (valueKind, bool) {
+	switch {
+	case lk == kindNumber && rk == kindNumber:
+		return kindNumber, true
+	case lk == kindDuration && rk == kindDuration:
+		return kindDuration, true
+	case lk == kindTimestamp && rk == kindDuration:
+		return kindTimestamp, true
+	case lk == kindTimestamp && rk == kindTimestamp && op == tokenMinus:
+		return kindDuration, true
+	}
+	return 0, false
+}
+
+func typeError(n node, msg string) error {
+	start, _ := n.span()
+	return fmt.Errorf("%s at %d", msg, start)
+}
+
+type evaluator struct {
+	attrs Attributes
+	now   time.Time
+}
+
+func (e *evaluator) eval(n node) (value, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.val, nil
+	case *attributeNode:
+		return e.attribute(n.name)
+	case *listNode:
+		list := make([]value, len(n.elems))
+		for i, elem := range n.elems {
+			v, err := e.eval(elem)
+			if err != nil {
+				return value{}, err
+			}
+			list[i] = v
+		}
+		return value{kind: kindList, list: list}, nil
+	case *callNode:
+		args := make([]value, len(n.args))
+		for i, arg := range n.args {
+			v, err := e.eval(arg)
+			if err != nil {
+				return value{}, err
+			}
+			args[i] = v
+		}
+		return functions[n.fn].call(args, e.now)
+	case *unaryNode:
+		v, err := e.eval(n.operand)
+		if err != nil {
+			return value{}, err
+		}
+		return boolValue(!v.b), nil
+	case *binaryNode:
+		return e.evalBinary(n)
+	}
+	return value{}, fmt.Errorf("unsupported expression")
+}
+
+func (e *evaluator) evalBinary(n *binaryNode) (value, error) {
+	if n.op == tokenAnd || n.op == tokenOr {
+		return e.evalLogical(n)
+	}
+	left, err := e.eval(n.left)
+	if err != nil {
+		return value{}, err
+	}
+
+	right, err := e.eval(n.right)
+	if err != nil {
+		return value{}, err
+	}
+	switch n.op {
+	case tokenEq:
+		return boolValue(equal(left, right)), nil
+	case tokenNe:
+		return boolValue(!equal(left, right)), nil
+	case tokenIn:
+		for _, elem := range right.list {
+			if equal(left, elem) {
+				return boolValue(true), nil
+			}
+		}
+		return boolValue(false), nil
+	case tokenPlus, tokenMinus:
+		return arithmetic(n.op, left, right), nil
+	}
+
+	c, err := compare(left, right)
+	if err != nil {
+		return value{}, err
+	}
+	switch n.op {
+	case tokenLt:
+		return boolValue(c < 0), nil
+	case tokenLe:
+		return boolValue(c <= 0), nil
+	case tokenGt:
+		return boolValue(c > 0), nil
+	}
+	return boolValue(c >= 0), nil
+}
+
+// evalLogical evaluates && and || left to right, stopping as soon as the
+// result is known. An operand that cannot be evaluated, such as one that
+// needs a missing attribute, only fails the expression when the other
+// operand does not decide it: user.tier == "vip" || user.region == "KR"
+// allows a KR request without a tier.
+func (e *evaluator) evalLogical(n *binaryNode) (value, error) {
+	decisive := n.op == tokenOr // the operand value that decides the result
+	left, leftErr := e.eval(n.left)
+	if leftErr == nil && left.b == decisive {
+		return left, nil
+	}
+	right, err := e.eval(n.right)
+	if err != nil {
+		return value{}, err
+	}
+	if leftErr != nil && right.b != decisive {
+		return value{}, leftErr
+	}
+	return right, nil
+}
+
+func arithmetic(op tokenKind, left, right value) value {
+	sign := 1
+	if op == tokenMinus {
+		sign = -1
+	}
+	switch {
+	case left.kind == kindNumber:
+		return numberValue(left.n + float64(sign)*right.n)
+	case left.kind == kindDuration:
+		return durationValue(left.d + time.Duration(sign)*right.d)
+	case right.kind == kindTimestamp:
+		return durationValue(left.t.Sub(right.t))
+	}
+	return timestampValue(left.t.Add(time.Duration(sign) * right.d))
+}
+
+func (e *evaluator) attribute(name string) (value, error) {
+	var raw string
+	switch name {
+	case "tier":
+		raw = e.attrs.Tier
+	case "region":
+		raw = e.attrs.Region
+	case "signup_date":
+		raw = e.attrs.SignupDate
+	case "app_version":
+		raw = e.attrs.AppVersion
+	}
+	if raw == "" {
+		return value{}, &attributeError{name: name, missing: true}
+	}
+	v, err := convert(stringValue(raw), attributeKinds[name])
+	if err != nil {
+		return value{}, &attributeError{name: name, err: err}
+	}
+	return v, nil
+}
@@ -0,0 +1,145 @@
+package eligibility
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenAnd     // &&
+	tokenOr      // ||
+	tokenNot     // !
+	tokenEq      // ==
+	tokenNe      // !=
+	tokenLt      // <
+	tokenLe      // <=
+	tokenGt      // >
+	tokenGe      // >=
+	tokenPlus    // +
+	tokenMinus   // -
+	tokenDot     // .
+	tokenComma   // ,
+	tokenLParen  // (
+	tokenRParen  // )
+	tokenLBrack  // [
+	tokenRBrack  // ]
+	tokenKeyword // in, true, false
+	tokenIn
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	start int
+	end   int
+}
+
+var operators = []struct {
+	text string
+	kind tokenKind
+}{
+	{"&&", tokenAnd},
+	{"||", tokenOr},
+	{"==", tokenEq},
+	{"!=", tokenNe},
+	{"<=", tokenLe},
+	{">=", tokenGe},
+	{"<", tokenLt},
+	{">", tokenGt},
+	{"!", tokenNot},
+	{"+", tokenPlus},
+	{"-", tokenMinus},
+	{".", tokenDot},
+	{",", tokenComma},
+	{"(", tokenLParen},
+	{")", tokenRParen},
+	{"[", tokenLBrack},
+	{"]", tokenRBrack},
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '"' || r == '\'':
+			tok, err := scanString(src, i, r)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = tok.end
+		case r >= '0' && r <= '9':
+			start := i
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, src[start:i], start, i})
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			text := src[start:i]
+			kind := tokenIdent
+			if text == "in" || text == "true" || text == "false" {
+				kind = tokenKeyword
+			}
+			tokens = append(tokens, token{kind, text, start, i})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(src[i:], op.text) {
+					end := i + len(op.text)
+					tokens = append(tokens, token{op.kind, op.text, i, end})
+					i = end
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at %d", r, i)
+			}
+		}
+	}
+	tokens = append(tokens, token{tokenEOF, "", len(src), len(src)})
+	return tokens, nil
+}
+
+func scanString(src string, start int, quote rune) (token, error) {
+	var b strings.Builder
+	i := start + 1
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case rune(c) == quote:
+			return token{tokenString, b.String(), start, i + 1}, nil
+		case c == '\\' && i+1 < len(src):
+			b.WriteByte(src[i+1])
+			i += 2
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return token{}, fmt.Errorf("unterminated string starting at %d", start)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,298 @@
+package eligibility
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type node interface {
+	span() (int, int)
+}
+
+type pos struct {
+	start int
+	end   int
+}
+
+func (p pos) span() (int, int) {
+	return p.start, p.end
+}
+
+type literalNode struct {
+	pos
+	val value
+}
+
+type attributeNode struct {
+	pos
+	name string
+}
+
+type listNode struct {
+	pos
+	elems []node
+}
+
+type unaryNode struct {
+	pos
+	op      tokenKind
+	operand node
+}
+
+type binaryNode struct {
+	pos
+	op    tokenKind
+	left  node
+	right node
+}
+
+type callNode struct {
+	pos
+	fn   string
+	args []node
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func parse(src string) (node, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.start)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.i]
+	if tok.kind != tokenEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind, text string) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, fmt.Errorf("expected %q at %d", text, tok.start)
+	}
+	return tok, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = newBinary(tokenOr, left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseRelation()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseRelation()
+		if err != nil {
+			return nil, err
+		}
+		left = newBinary(tokenAnd, left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) parseRelation() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEq, tok.kind == tokenNe,
+			tok.kind == tokenLt, tok.kind == tokenLe,
+			tok.kind == tokenGt, tok.kind == tokenGe:
+		case tok.kind == tokenKeyword && tok.text == "in":
+		default:
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		op := tok.kind
+		if op == tokenKeyword {
+			op = tokenIn
+		}
+		left = newBinary(op, left, right)
+	}
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenPlus || p.peek().kind == tokenMinus {
+		op := p.next().kind
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = newBinary(op, left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peek().kind == tokenNot {
+		tok := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		_, end := operand.span()
+		return &unaryNode{pos{tok.start, end}, tokenNot, operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		return &literalNode{pos{tok.start, tok.end}, stringValue(tok.text)}, nil
+	case tokenNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d", tok.text, tok.start)
+		}
+		return &literalNode{pos{tok.start, tok.end}, numberValue(n)}, nil
+	case tokenKeyword:
+		if tok.text == "true" || tok.text == "false" {
+			v := boolValue(tok.text == "true")
+			return &literalNode{pos{tok.start, tok.end}, v}, nil
+		}
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case tokenLBrack:
+		return p.parseList(tok)
+	case tokenIdent:
+		if p.peek().kind == tokenDot {
+			return p.parseAttribute(tok)
+		}
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(tok)
+		}
+		return nil, fmt.Errorf("unknown identifier %q at %d", tok.text, tok.start)
+	}
+	if tok.kind == tokenEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.start)
+}
+
+func (p *parser) parseList(open token) (node, error) {
+	var elems []node
+	if p.peek().kind != tokenRBrack {
+		for {
+			elem, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	closing, err := p.expect(tokenRBrack, "]")
+	if err != nil {
+		return nil, err
+	}
+	return &listNode{pos{open.start, closing.end}, elems}, nil
+}
+
+func (p *parser) parseAttribute(object token) (node, error) {
+	if object.text != "user" {
+		return nil, fmt.Errorf(
+			"unknown identifier %q at %d", object.text, object.start,
+		)
+	}
+	p.next() // .
+	field, err := p.expect(tokenIdent, "attribute name")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := attributeKinds[field.text]; !ok {
+		return nil, fmt.Errorf(
+			"unknown attribute user.%s at %d", field.text, field.start,
+		)
+	}
+	return &attributeNode{pos{object.start, field.end}, field.text}, nil
+}
+
+func (p *parser) parseCall(fn token) (node, error) {
+	if _, ok := functions[fn.text]; !ok {
+		return nil, fmt.Errorf("unknown function %q at %d", fn.text, fn.start)
+	}
+	p.next() // (
+	var args []node
+	if p.peek().kind != tokenRParen {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	closing, err := p.expect(tokenRParen, ")")
+	if err != nil {
+		return nil, err
+	}
+	return &callNode{pos{fn.start, closing.end}, fn.text, args}, nil
+}
+
+func newBinary(op tokenKind, left, right node) node {
+	start, _ := left.span()
+	_, end := right.span()
+	return &binaryNode{pos{start, end}, op, left, right}
+}
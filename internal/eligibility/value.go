@@ -0,0 +1,201 @@
+package eligibility
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumber
+	kindBool
+	kindList
+	kindTimestamp
+	kindDuration
+	kindVersion
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindString:
+		return "string"
+	case kindNumber:
+		return "number"
+	case kindBool:
+		return "bool"
+	case kindList:
+		return "list"
+	case kindTimestamp:
+		return "timestamp"
+	case kindDuration:
+		return "duration"
+	case kindVersion:
+		return "version"
+	}
+	return "unknown"
+}
+
+type value struct {
+	kind valueKind
+	s    string
+	n    float64
+	b    bool
+	list []value
+	t    time.Time
+	d    time.Duration
+	v    []int
+}
+
+func stringValue(s string) value {
+	return value{kind: kindString, s: s}
+}
+
+func numberValue(n float64) value {
+	return value{kind: kindNumber, n: n}
+}
+
+func boolValue(b bool) value {
+	return value{kind: kindBool, b: b}
+}
+
+func timestampValue(t time.Time) value {
+	return value{kind: kindTimestamp, t: t}
+}
+
+func durationValue(d time.Duration) value {
+	return value{kind: kindDuration, d: d}
+}
+
+// parseTimestamp accepts RFC3339 timestamps and plain dates.
+func parseTimestamp(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+	}
+	return t, nil
+}
+
+// parseVersion accepts dotted numeric versions such as "5.2" or "v5.2.1".
+func parseVersion(s string) (value, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if trimmed == "" {
+		return value{}, fmt.Errorf("invalid version %q", s)
+	}
+	parts := strings.Split(trimmed, ".")
+	v := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return value{}, fmt.Errorf("invalid version %q", s)
+		}
+		v[i] = n
+	}
+	return value{kind: kindVersion, s: s, v: v}, nil
+}
+
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// convert turns a string into the given kind, which is how literals are
+// compared against timestamp and version attributes.
+func convert(v value, kind valueKind) (value, error) {
+	if v.kind == kind || v.kind != kindString {
+		return v, nil
+	}
+	switch kind {
+	case kindTimestamp:
+		t, err := parseTimestamp(v.s)
+		if err != nil {
+			return value{}, err
+		}
+		return timestampValue(t), nil
+	case kindVersion:
+		return parseVersion(v.s)
+	}
+	return v, nil
+}
+
+func equal(a, b value) bool {
+	if a.kind != b.kind {
+		return false
+	}
+	switch a.kind {
+	case kindString:
+		return a.s == b.s
+	case kindNumber:
+		return a.n == b.n
+	case kindBool:
+		return a.b == b.b
+	case kindTimestamp:
+		return a.t.Equal(b.t)
+	case kindDuration:
+		return a.d == b.d
+	case kindVersion:
+		return compareVersions(a.v, b.v) == 0
+	case kindList:
+		if len(a.list) != len(b.list) {
+			return false
+		}
+		for i := range a.list {
+			if !equal(a.list[i], b.list[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func compare(a, b value) (int, error) {
+	if a.kind != b.kind {
+		return 0, fmt.Errorf("cannot compare %s with %s", a.kind, b.kind)
+	}
+	switch a.kind {
+	case kindString:
+		return strings.Compare(a.s, b.s), nil
+	case kindNumber:
+		switch {
+		case a.n < b.n:
+			return -1, nil
+		case a.n > b.n:
+			return 1, nil
+		}
+		return 0, nil
+	case kindTimestamp:
+		return a.t.Compare(b.t), nil
+	case kindDuration:
+		switch {
+		case a.d < b.d:
+			return -1, nil
+		case a.d > b.d:
+			return 1, nil
+		}
+		return 0, nil
+	case kindVersion:
+		return compareVersions(a.v, b.v), nil
+	}
+	return 0, fmt.Errorf("%s values are not ordered", a.kind)
+}
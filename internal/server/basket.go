@@ -0,0 +1,266 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/benefit"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type (
+	ValidateBasketReq  = connect.Request[coupon.ValidateBasketRequest]
+	ValidateBasketResp = connect.Response[coupon.ValidateBasketResponse]
+)
+
+const maxBasketCoupons = 20
+
+var stackingPolicies = map[coupon.StackingPolicy]benefit.StackingPolicy{
+	coupon.StackingPolicy_STACKING_POLICY_UNSPECIFIED: benefit.PolicyStackable,
+	coupon.StackingPolicy_STACKING_POLICY_STACKABLE:   benefit.PolicyStackable,
+	coupon.StackingPolicy_STACKING_POLICY_EXCLUSIVE:   benefit.PolicyExclusive,
+}
+
+var basketRejections = map[benefit.Rejection]coupon.BasketRejectionReason{
+	benefit.RejectNotApplicable: coupon.
+		BasketRejectionReason_BASKET_REJECTION_REASON_NOT_APPLICABLE,
+	benefit.RejectExclusivityGroup: coupon.
+		BasketRejectionReason_BASKET_REJECTION_REASON_EXCLUSIVITY_GROUP,
+	benefit.RejectNotStackable: coupon.
+		BasketRejectionReason_BASKET_REJECTION_REASON_NOT_STACKABLE,
+	benefit.RejectNoRemainingValue: coupon.
+		BasketRejectionReason_BASKET_REJECTION_REASON_NO_REMAINING_VALUE,
+}
+
+func stackingPolicyToProto(p benefit.StackingPolicy) coupon.StackingPolicy {
+	if p == benefit.PolicyExclusive {
+		return coupon.StackingPolicy_STACKING_POLICY_EXCLUSIVE
+	}
+	return coupon.StackingPolicy_STACKING_POLICY_STACKABLE
+}
+
+// basketCoupon is what ValidateBasket needs to know about each code.
+type basketCoupon struct {
+	campaignID pgtype.UUID
+	state      couponState
+	owner      *string
+	expiresAt  *time.Time
+	benefit    *benefit.Benefit
+	group      *string
+	policy     *benefit.StackingPolicy
+	priority   *int32
+	// vanity codes apply through the user's claim, which sets state and
+	// expiresAt
+	vanity  bool
+	claimed bool
+}
+
+// ValidateBasket works out which of a set of coupons can be used together
+// on a cart and in which order they apply, following each campaign's
+// exclusivity group, stacking policy and priority. Nothing is redeemed.
+func (s *CouponService) ValidateBasket(
+	ctx context.Context,
+	req *ValidateBasketReq,
+) (*ValidateBasketResp, error) {
+	if len(req.Msg.Codes) == 0 {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("codes cannot be empty"),
+		)
+	}
+	if len(req.Msg.Codes) > maxBasketCoupons {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("at most %d codes can be validated", maxBasketCoupons),
+		)
+	}
+	cart, err := parseCart(req.Msg.Cart)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, len(req.Msg.Codes))
+	for i, code := range req.Msg.Codes {
+		codes[i] = codeformat.Normalize(code)
+		if err := s.flushPendingCoupon(ctx, codes[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	coupons, err := s.getBasketCoupons(ctx, codes, req.Msg.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rejections are reported in request order
+	type rejection struct {
+		index int
+		*coupon.RejectedCoupon
+	}
+	var rejected []rejection
+	reject := func(i int, reason coupon.BasketRejectionReason) {
+		rejected = append(rejected, rejection{i, &coupon.RejectedCoupon{
+			Code:   codes[i],
+			Reason: reason,
+		}})
+	}
+
+	var candidates []benefit.Candidate
+	position := map[string]int{}
+	campaignIDs := map[string]string{}
+	for i, code := range codes {
+		c, ok := coupons[code]
+		state := c.state
+		if ok && state == couponIssued && isExpired(c.expiresAt) {
+			state = couponExpired
+		}
+		_, duplicate := position[code]
+		switch {
+		case duplicate:
+			reject(i, coupon.
+				BasketRejectionReason_BASKET_REJECTION_REASON_DUPLICATE)
+		case !ok:
+			reject(i, coupon.
+				BasketRejectionReason_BASKET_REJECTION_REASON_NOT_FOUND)
+		case c.owner != nil && *c.owner != req.Msg.UserId,
+			c.vanity && !c.claimed:
+			reject(i, coupon.
+				BasketRejectionReason_BASKET_REJECTION_REASON_NOT_OWNER)
+		case state != couponIssued:
+			reject(i, coupon.
+				BasketRejectionReason_BASKET_REJECTION_REASON_NOT_REDEEMABLE)
+		case c.benefit == nil:
+			reject(i, coupon.
+				BasketRejectionReason_BASKET_REJECTION_REASON_NO_BENEFIT)
+		default:
+			candidates = append(candidates, benefit.Candidate{
+				Code:     code,
+				Benefit:  c.benefit,
+				Group:    derefString(c.group),
+				Policy:   *c.policy,
+				Priority: *c.priority,
+			})
+			campaignIDs[code] = c.campaignID.String()
+		}
+		if !duplicate {
+			position[code] = i
+		}
+	}
+
+	result, err := benefit.ResolveBasket(cart, candidates)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("invalid cart: %v", err),
+		)
+	}
+
+	resp := &coupon.ValidateBasketResponse{}
+	places := benefit.MinorUnits(cart.Currency)
+	for _, a := range result.Applied {
+		resp.Applied = append(resp.Applied, &coupon.BasketCoupon{
+			Code:        a.Code,
+			CampaignId:  campaignIDs[a.Code],
+			Discount:    a.Discount.StringFixed(places),
+			FreeItemSku: a.FreeItemSKU,
+		})
+	}
+	for _, r := range result.Rejected {
+		rejected = append(rejected, rejection{
+			position[r.Code],
+			&coupon.RejectedCoupon{
+				Code:          r.Code,
+				Reason:        basketRejections[r.Rejection],
+				ConflictsWith: r.ConflictsWith,
+				BenefitReason: notApplicableReasons[r.BenefitReason],
+			},
+		})
+	}
+	slices.SortFunc(rejected, func(a, b rejection) int {
+		return a.index - b.index
+	})
+	for _, r := range rejected {
+		resp.Rejected = append(resp.Rejected, r.RejectedCoupon)
+	}
+
+	resp.Subtotal = result.Subtotal.StringFixed(places)
+	resp.Discount = result.Discount.StringFixed(places)
+	resp.Total = result.Total.StringFixed(places)
+	return connect.NewResponse(resp), nil
+}
+
+func (s *CouponService) getBasketCoupons(
+	ctx context.Context,
+	codes []string,
+	userID string,
+) (map[string]basketCoupon, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT c.code, c.campaign_id, c.state, c.user_id, c.expires_at,
+			cp.benefit, cp.exclusivity_group, cp.stacking_policy, cp.priority,
+			COALESCE(cp.vanity_code = c.code, false)
+		FROM coupons c
+		LEFT JOIN campaigns cp ON cp.id = c.campaign_id
+		WHERE c.code = ANY($1)`,
+		codes,
+	)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupons: %v", err),
+		)
+	}
+	defer rows.Close()
+
+	coupons := make(map[string]basketCoupon, len(codes))
+	for rows.Next() {
+		var (
+			code string
+			c    basketCoupon
+		)
+		err := rows.Scan(
+			&code,
+			&c.campaignID,
+			&c.state,
+			&c.owner,
+			&c.expiresAt,
+			&c.benefit,
+			&c.group,
+			&c.policy,
+			&c.priority,
+			&c.vanity,
+		)
+		if err != nil {
+			return nil, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to scan coupon: %v", err),
+			)
+		}
+		coupons[code] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("error iterating coupons: %v", err),
+		)
+	}
+
+	for code, c := range coupons {
+		if !c.vanity {
+			continue
+		}
+		c.state, c.expiresAt, c.claimed, err = s.vanityClaimState(
+			ctx, c.campaignID, userID, c.state, c.expiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		coupons[code] = c
+	}
+	return coupons, nil
+}
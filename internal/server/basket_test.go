@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issueBasketCoupon creates an active campaign with the given benefit and
+// stacking settings and issues one coupon from it.
+func issueBasketCoupon(
+	t *testing.T,
+	service *CouponService,
+	benefit string,
+	group *string,
+	policy string,
+	priority int32,
+) string {
+	ctx := context.Background()
+
+	var campaignID string
+	err := service.pool.QueryRow(ctx,
+		`INSERT INTO campaigns (name, start_time, coupon_limit, status,
+			benefit, exclusivity_group, stacking_policy, priority)
+		VALUES ('Basket Campaign', $1, 10, 'active', $2, $3, $4, $5)
+		RETURNING id::text`,
+		time.Now(),
+		benefit,
+		group,
+		policy,
+		priority,
+	).Scan(&campaignID)
+	require.NoError(t, err)
+
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, campaignID)
+	require.NoError(t, service.redis.Set(ctx, counterKey, 10, 0).Err())
+
+	resp, err := service.IssueCoupon(
+		ctx,
+		connect.NewRequest(&coupon.IssueCouponRequest{
+			CampaignId: campaignID,
+			UserId:     "user-1",
+		}),
+	)
+	require.NoError(t, err)
+	return resp.Msg.CouponCode
+}
+
+func TestCouponService_ValidateBasket(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	welcome := "welcome"
+	tenPercent := issueBasketCoupon(t, service,
+		`{"kind": "percent_off", "percent": "10"}`,
+		&welcome, "stackable", 0,
+	)
+	fixed := issueBasketCoupon(t, service,
+		`{"kind": "fixed_amount", "amount": "3000", "currency": "KRW"}`,
+		&welcome, "stackable", 0,
+	)
+	freeLatte := issueBasketCoupon(t, service,
+		`{"kind": "free_item", "sku": "LATTE"}`,
+		nil, "stackable", 1,
+	)
+	exclusive := issueBasketCoupon(t, service,
+		`{"kind": "percent_off", "percent": "50"}`,
+		nil, "exclusive", 0,
+	)
+
+	cart := &coupon.Cart{
+		Currency: "KRW",
+		Items: []*coupon.CartItem{
+			{Sku: "LATTE", Category: "drinks", Quantity: 2, UnitPrice: "5000"},
+			{Sku: "CAKE", Category: "food", Quantity: 1, UnitPrice: "8000"},
+		},
+	}
+
+	resp, err := service.ValidateBasket(
+		ctx,
+		connect.NewRequest(&coupon.ValidateBasketRequest{
+			Codes:  []string{tenPercent, fixed, freeLatte, exclusive, fixed},
+			UserId: "user-1",
+			Cart:   cart,
+		}),
+	)
+	require.NoError(t, err)
+
+	// The free item has the highest priority, then the larger discount of
+	// the welcome group
+	require.Len(t, resp.Msg.Applied, 2)
+	assert.Equal(t, freeLatte, resp.Msg.Applied[0].Code)
+	assert.Equal(t, "5000", resp.Msg.Applied[0].Discount)
+	assert.Equal(t, fixed, resp.Msg.Applied[1].Code)
+	assert.Equal(t, "3000", resp.Msg.Applied[1].Discount)
+	assert.Equal(t, "18000", resp.Msg.Subtotal)
+	assert.Equal(t, "8000", resp.Msg.Discount)
+	assert.Equal(t, "10000", resp.Msg.Total)
+
+	require.Len(t, resp.Msg.Rejected, 3)
+	assert.Equal(t, tenPercent, resp.Msg.Rejected[0].Code)
+	assert.Equal(t,
+		coupon.BasketRejectionReason_BASKET_REJECTION_REASON_EXCLUSIVITY_GROUP,
+		resp.Msg.Rejected[0].Reason,
+	)
+	assert.Equal(t, fixed, resp.Msg.Rejected[0].ConflictsWith)
+	assert.Equal(t, exclusive, resp.Msg.Rejected[1].Code)
+	assert.Equal(t,
+		coupon.BasketRejectionReason_BASKET_REJECTION_REASON_NOT_STACKABLE,
+		resp.Msg.Rejected[1].Reason,
+	)
+	assert.Equal(t,
+		coupon.BasketRejectionReason_BASKET_REJECTION_REASON_DUPLICATE,
+		resp.Msg.Rejected[2].Reason,
+	)
+
+	t.Run("other users' coupons are rejected", func(t *testing.T) {
+		resp, err := service.ValidateBasket(
+			ctx,
+			connect.NewRequest(&coupon.ValidateBasketRequest{
+				Codes:  []string{tenPercent, "unknown"},
+				UserId: "user-2",
+				Cart:   cart,
+			}),
+		)
+		require.NoError(t, err)
+		assert.Empty(t, resp.Msg.Applied)
+		require.Len(t, resp.Msg.Rejected, 2)
+		assert.Equal(t,
+			coupon.BasketRejectionReason_BASKET_REJECTION_REASON_NOT_OWNER,
+			resp.Msg.Rejected[0].Reason,
+		)
+		assert.Equal(t,
+			coupon.BasketRejectionReason_BASKET_REJECTION_REASON_NOT_FOUND,
+			resp.Msg.Rejected[1].Reason,
+		)
+	})
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/benefit"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type (
+	ApplyCouponReq  = connect.Request[coupon.ApplyCouponRequest]
+	ApplyCouponResp = connect.Response[coupon.ApplyCouponResponse]
+)
+
+var notApplicableReasons = map[benefit.Reason]coupon.BenefitNotApplicableReason{
+	benefit.ReasonCurrencyMismatch: coupon.
+		BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_CURRENCY_MISMATCH,
+	benefit.ReasonMinOrderNotMet: coupon.
+		BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_MIN_ORDER_NOT_MET,
+	benefit.ReasonNoEligibleItems: coupon.
+		BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_NO_ELIGIBLE_ITEMS,
+	benefit.ReasonFreeItemNotInCart: coupon.
+		BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_FREE_ITEM_MISSING,
+}
+
+// ApplyCoupon computes the discount a coupon gives on a cart without
+// redeeming it.
+func (s *CouponService) ApplyCoupon(
+	ctx context.Context,
+	req *ApplyCouponReq,
+) (*ApplyCouponResp, error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	if code == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code cannot be empty"),
+		)
+	}
+	cart, err := parseCart(req.Msg.Cart)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.flushPendingCoupon(ctx, code); err != nil {
+		return nil, err
+	}
+
+	var (
+		campaignID pgtype.UUID
+		state      couponState
+		owner      *string
+		expiresAt  *time.Time
+		b          *benefit.Benefit
+		vanity     bool
+	)
+	err = s.pool.QueryRow(ctx,
+		`SELECT c.campaign_id, c.state, c.user_id, c.expires_at, cp.benefit,
+			COALESCE(cp.vanity_code = c.code, false)
+		FROM coupons c
+		LEFT JOIN campaigns cp ON cp.id = c.campaign_id
+		WHERE c.code = $1`,
+		code,
+	).Scan(&campaignID, &state, &owner, &expiresAt, &b, &vanity)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("coupon not found"),
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon: %v", err),
+		)
+	}
+
+	if owner != nil && *owner != req.Msg.UserId {
+		return nil, connect.NewError(
+			connect.CodePermissionDenied,
+			fmt.Errorf("coupon belongs to another user"),
+		)
+	}
+	// A vanity code applies through the user's claim of it
+	if vanity {
+		var claimed bool
+		state, expiresAt, claimed, err = s.vanityClaimState(
+			ctx, campaignID, req.Msg.UserId, state, expiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			return nil, vanityNotClaimedError()
+		}
+	}
+	if state == couponIssued && isExpired(expiresAt) {
+		state = couponExpired
+	}
+	if state != couponIssued {
+		return nil, transitionError(state, couponRedeemed)
+	}
+	if b == nil {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("campaign has no benefit"),
+		)
+	}
+
+	result, err := b.Apply(cart)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("invalid cart: %v", err),
+		)
+	}
+
+	places := benefit.MinorUnits(cart.Currency)
+	return connect.NewResponse(&coupon.ApplyCouponResponse{
+		Code:        code,
+		CampaignId:  campaignID.String(),
+		Applicable:  result.Applicable,
+		Reason:      notApplicableReasons[result.Reason],
+		Subtotal:    result.Subtotal.StringFixed(places),
+		Discount:    result.Discount.StringFixed(places),
+		Total:       result.Total.StringFixed(places),
+		FreeItemSku: result.FreeItemSKU,
+	}), nil
+}
+
+// parseBenefit converts and validates a campaign benefit. A nil benefit is
+// allowed and returns nil.
+func parseBenefit(pb *coupon.Benefit) (*benefit.Benefit, error) {
+	if pb == nil {
+		return nil, nil
+	}
+
+	b := &benefit.Benefit{
+		Currency:   strings.TrimSpace(pb.Currency),
+		Categories: pb.EligibleCategories,
+	}
+	var err error
+	parse := func(name, s string) *benefit.Decimal {
+		if s == "" || err != nil {
+			return nil
+		}
+		d, parseErr := benefit.ParseDecimal(s)
+		if parseErr != nil {
+			err = fmt.Errorf("%s: %v", name, parseErr)
+			return nil
+		}
+		return &d
+	}
+
+	switch kind := pb.Kind.(type) {
+	case *coupon.Benefit_PercentOff:
+		b.Kind = benefit.KindPercentOff
+		b.Percent = parse("percent", kind.PercentOff.GetPercent())
+		b.MaxDiscount = parse("max_discount", kind.PercentOff.GetMaxDiscount())
+	case *coupon.Benefit_FixedAmount:
+		b.Kind = benefit.KindFixedAmount
+		b.Amount = parse("amount", kind.FixedAmount.GetAmount())
+	case *coupon.Benefit_FreeItem:
+		b.Kind = benefit.KindFreeItem
+		b.SKU = strings.TrimSpace(kind.FreeItem.GetSku())
+	}
+	b.MinOrderAmount = parse("min_order_amount", pb.MinOrderAmount)
+
+	if err == nil {
+		err = b.Validate()
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("invalid benefit: %v", err),
+		)
+	}
+	return b, nil
+}
+
+func benefitToProto(b *benefit.Benefit) *coupon.Benefit {
+	if b == nil {
+		return nil
+	}
+
+	format := func(d *benefit.Decimal) string {
+		if d == nil {
+			return ""
+		}
+		return d.String()
+	}
+
+	pb := &coupon.Benefit{
+		Currency:           b.Currency,
+		MinOrderAmount:     format(b.MinOrderAmount),
+		EligibleCategories: b.Categories,
+	}
+	switch b.Kind {
+	case benefit.KindPercentOff:
+		pb.Kind = &coupon.Benefit_PercentOff{PercentOff: &coupon.PercentOff{
+			Percent:     format(b.Percent),
+			MaxDiscount: format(b.MaxDiscount),
+		}}
+	case benefit.KindFixedAmount:
+		pb.Kind = &coupon.Benefit_FixedAmount{FixedAmount: &coupon.FixedAmount{
+			Amount: format(b.Amount),
+		}}
+	case benefit.KindFreeItem:
+		pb.Kind = &coupon.Benefit_FreeItem{FreeItem: &coupon.FreeItem{
+			Sku: b.SKU,
+		}}
+	}
+	return pb
+}
+
+func parseCart(pc *coupon.Cart) (benefit.Cart, error) {
+	if pc == nil {
+		return benefit.Cart{}, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("cart is required"),
+		)
+	}
+
+	cart := benefit.Cart{
+		Currency: strings.TrimSpace(pc.Currency),
+		Items:    make([]benefit.Item, len(pc.Items)),
+	}
+	for i, item := range pc.Items {
+		price, err := benefit.ParseDecimal(item.UnitPrice)
+		if err != nil {
+			return benefit.Cart{}, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("item %d: unit_price: %v", i, err),
+			)
+		}
+		cart.Items[i] = benefit.Item{
+			SKU:       strings.TrimSpace(item.Sku),
+			Category:  item.Category,
+			Quantity:  item.Quantity,
+			UnitPrice: price,
+		}
+	}
+	return cart, nil
+}
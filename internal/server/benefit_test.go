@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCouponService_CampaignBenefit(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	b := &coupon.Benefit{
+		Kind: &coupon.Benefit_PercentOff{PercentOff: &coupon.PercentOff{
+			Percent:     "12.5",
+			MaxDiscount: "5000",
+		}},
+		Currency:           "KRW",
+		MinOrderAmount:     "20000",
+		EligibleCategories: []string{"drinks"},
+	}
+
+	createResp, err := service.CreateCampaign(
+		ctx,
+		connect.NewRequest(&coupon.CreateCampaignRequest{
+			Name:        "Benefit Campaign",
+			StartTime:   time.Now().Add(time.Hour).Format(time.RFC3339),
+			CouponLimit: 10,
+			Benefit:     b,
+		}),
+	)
+	require.NoError(t, err)
+
+	getResp, err := service.GetCampaign(
+		ctx,
+		connect.NewRequest(&coupon.GetCampaignRequest{
+			CampaignId: createResp.Msg.CampaignId,
+		}),
+	)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(b, getResp.Msg.Benefit))
+
+	t.Run("invalid benefit", func(t *testing.T) {
+		_, err := service.CreateCampaign(
+			ctx,
+			connect.NewRequest(&coupon.CreateCampaignRequest{
+				Name:        "Benefit Campaign",
+				StartTime:   time.Now().Format(time.RFC3339),
+				CouponLimit: 10,
+				Benefit: &coupon.Benefit{
+					Kind: &coupon.Benefit_FixedAmount{
+						FixedAmount: &coupon.FixedAmount{Amount: "1.5"},
+					},
+				},
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
+
+func TestCouponService_ApplyCoupon(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	campaignID, code := issueTestCoupon(t, service, "user-1")
+	cart := &coupon.Cart{
+		Currency: "USD",
+		Items: []*coupon.CartItem{
+			{Sku: "LATTE", Category: "drinks", Quantity: 2, UnitPrice: "4.50"},
+			{Sku: "MUG", Category: "goods", Quantity: 1, UnitPrice: "12"},
+		},
+	}
+
+	t.Run("campaign without benefit", func(t *testing.T) {
+		_, err := service.ApplyCoupon(
+			ctx,
+			connect.NewRequest(&coupon.ApplyCouponRequest{
+				Code:   code,
+				UserId: "user-1",
+				Cart:   cart,
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	_, err := service.pool.Exec(ctx,
+		`UPDATE campaigns SET benefit = $2 WHERE id = $1`,
+		campaignID,
+		`{"kind": "fixed_amount", "amount": "5", "currency": "USD",
+			"min_order_amount": "15"}`,
+	)
+	require.NoError(t, err)
+
+	t.Run("computes the discount", func(t *testing.T) {
+		resp, err := service.ApplyCoupon(
+			ctx,
+			connect.NewRequest(&coupon.ApplyCouponRequest{
+				Code:   code,
+				UserId: "user-1",
+				Cart:   cart,
+			}),
+		)
+		require.NoError(t, err)
+		assert.True(t, resp.Msg.Applicable)
+		assert.Equal(t, campaignID, resp.Msg.CampaignId)
+		assert.Equal(t, "21.00", resp.Msg.Subtotal)
+		assert.Equal(t, "5.00", resp.Msg.Discount)
+		assert.Equal(t, "16.00", resp.Msg.Total)
+	})
+
+	t.Run("reports why the benefit does not apply", func(t *testing.T) {
+		resp, err := service.ApplyCoupon(
+			ctx,
+			connect.NewRequest(&coupon.ApplyCouponRequest{
+				Code:   code,
+				UserId: "user-1",
+				Cart: &coupon.Cart{
+					Currency: "USD",
+					Items: []*coupon.CartItem{
+						{Sku: "LATTE", Quantity: 1, UnitPrice: "4.50"},
+					},
+				},
+			}),
+		)
+		require.NoError(t, err)
+		assert.False(t, resp.Msg.Applicable)
+		assert.Equal(t,
+			coupon.
+				BenefitNotApplicableReason_BENEFIT_NOT_APPLICABLE_REASON_MIN_ORDER_NOT_MET,
+			resp.Msg.Reason,
+		)
+		assert.Equal(t, "0.00", resp.Msg.Discount)
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		_, err := service.ApplyCoupon(
+			ctx,
+			connect.NewRequest(&coupon.ApplyCouponRequest{
+				Code:   code,
+				UserId: "user-2",
+				Cart:   cart,
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("invalid cart", func(t *testing.T) {
+		_, err := service.ApplyCoupon(
+			ctx,
+			connect.NewRequest(&coupon.ApplyCouponRequest{
+				Code:   code,
+				UserId: "user-1",
+				Cart: &coupon.Cart{
+					Currency: "USD",
+					Items: []*coupon.CartItem{
+						{Sku: "LATTE", Quantity: 1, UnitPrice: "4.5e0"},
+					},
+				},
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
@@ -0,0 +1,310 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+)
+
+type (
+	LockCouponReq        = connect.Request[coupon.LockCouponRequest]
+	LockCouponResp       = connect.Response[coupon.LockCouponResponse]
+	CommitRedemptionReq  = connect.Request[coupon.CommitRedemptionRequest]
+	CommitRedemptionResp = connect.Response[coupon.RedeemCouponResponse]
+	ReleaseLockReq       = connect.Request[coupon.ReleaseLockRequest]
+	ReleaseLockResp      = connect.Response[coupon.ReleaseLockResponse]
+)
+
+const (
+	defaultCheckoutLockTTL = 5 * time.Minute
+	maxCheckoutLockTTL     = time.Hour
+)
+
+// checkoutLockScript takes the lock for a checkout, or extends it if the
+// checkout already holds it. Returns 0 if another checkout holds it.
+const checkoutLockScript = `
+	local holder = redis.call('GET', KEYS[1])
+	if holder == ARGV[1] then
+		redis.call('PEXPIRE', KEYS[1], ARGV[2])
+		return 1
+	end
+	if holder then
+		return 0
+	end
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+`
+
+// checkoutUnlockScript deletes the lock only if the checkout holds it.
+const checkoutUnlockScript = `
+	if redis.call('GET', KEYS[1]) == ARGV[1] then
+		return redis.call('DEL', KEYS[1])
+	end
+	return 0
+`
+
+func checkoutLockKey(code string) string {
+	return fmt.Sprintf("%s%s", couponLockKey, code)
+}
+
+// LockCoupon reserves a redeemable coupon for a checkout between payment
+// authorization and capture. The lock lives in Redis with a TTL, so a
+// checkout that crashes frees the coupon when the lock expires. It is then
+// recorded on the coupon row, where redemptions, transfers and revocations
+// check it in the same statement as their write; the coupon's state only
+// changes on CommitRedemption.
+func (s *CouponService) LockCoupon(
+	ctx context.Context,
+	req *LockCouponReq,
+) (*LockCouponResp, error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	checkoutID := strings.TrimSpace(req.Msg.CheckoutId)
+	if code == "" || checkoutID == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code and checkout_id are required"),
+		)
+	}
+
+	ttl := defaultCheckoutLockTTL
+	if req.Msg.Ttl != "" {
+		var err error
+		ttl, err = time.ParseDuration(req.Msg.Ttl)
+		if err != nil {
+			return nil, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("invalid ttl: %v", err),
+			)
+		}
+		if ttl < time.Second || ttl > maxCheckoutLockTTL {
+			return nil, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("ttl must be between 1s and %s", maxCheckoutLockTTL),
+			)
+		}
+	}
+
+	// A lock on a shared code would hold it for every user
+	err := s.rejectVanityCode(ctx, code, "vanity codes cannot be locked")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkRedeemable(ctx, code, req.Msg.UserId); err != nil {
+		return nil, err
+	}
+
+	locked, err := s.redis.Eval(ctx, checkoutLockScript,
+		[]string{checkoutLockKey(code)},
+		checkoutID,
+		ttl.Milliseconds(),
+	).Int()
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to lock coupon: %v", err),
+		)
+	}
+	if locked == 0 {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("coupon is locked by another checkout"),
+		)
+	}
+
+	// The row may still hold a lock Redis lost, or the coupon may have been
+	// redeemed since it was checked
+	var expiresAt time.Time
+	err = s.pool.QueryRow(ctx,
+		`UPDATE coupons
+		SET locked_by = $2,
+			locked_until = now() + make_interval(secs => $3)
+		WHERE code = $1
+		AND state = ANY($4::coupon_state[])
+		AND (locked_by IS NULL OR locked_by = $2 OR locked_until <= now())
+		RETURNING locked_until`,
+		code,
+		checkoutID,
+		ttl.Seconds(),
+		statesTo(couponRedeemed),
+	).Scan(&expiresAt)
+	if err != nil {
+		s.releaseRedisLock(ctx, code, checkoutID)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to lock coupon: %v", err),
+			)
+		}
+		if err := s.checkRedeemable(ctx, code, req.Msg.UserId); err != nil {
+			return nil, err
+		}
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("coupon is locked by another checkout"),
+		)
+	}
+
+	return connect.NewResponse(&coupon.LockCouponResponse{
+		Code:       code,
+		CheckoutId: checkoutID,
+		ExpiresAt:  expiresAt.Format(time.RFC3339),
+	}), nil
+}
+
+// CommitRedemption redeems a coupon locked by the checkout and releases
+// the lock. Retrying a commit that succeeded returns the original
+// redemption.
+func (s *CouponService) CommitRedemption(
+	ctx context.Context,
+	req *CommitRedemptionReq,
+) (*CommitRedemptionResp, error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	checkoutID := strings.TrimSpace(req.Msg.CheckoutId)
+	orderRef := strings.TrimSpace(req.Msg.OrderRef)
+	if code == "" || checkoutID == "" || orderRef == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code, checkout_id and order_ref are required"),
+		)
+	}
+
+	// The redemption only succeeds while the coupon row holds the lock.
+	// The lock is released on commit, so a retried commit finds its
+	// redemption in the ledger instead.
+	resp, err := s.redeemCoupon(
+		ctx,
+		code,
+		req.Msg.UserId,
+		orderRef,
+		checkoutID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.releaseRedisLock(ctx, code, checkoutID)
+
+	return connect.NewResponse(resp), nil
+}
+
+// releaseRedisLock deletes a checkout's lock in Redis, if it holds it.
+// Failures are only logged since the lock still expires on its own.
+func (s *CouponService) releaseRedisLock(
+	ctx context.Context,
+	code string,
+	checkoutID string,
+) {
+	err := s.redis.Eval(ctx, checkoutUnlockScript,
+		[]string{checkoutLockKey(code)},
+		checkoutID,
+	).Err()
+	if err != nil {
+		log.Printf("Failed to release lock on coupon %s: %v", code, err)
+	}
+}
+
+// ReleaseLock gives up a checkout's lock, e.g. when payment fails.
+func (s *CouponService) ReleaseLock(
+	ctx context.Context,
+	req *ReleaseLockReq,
+) (*ReleaseLockResp, error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	checkoutID := strings.TrimSpace(req.Msg.CheckoutId)
+	if code == "" || checkoutID == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code and checkout_id are required"),
+		)
+	}
+
+	released, err := s.redis.Eval(ctx, checkoutUnlockScript,
+		[]string{checkoutLockKey(code)},
+		checkoutID,
+	).Int()
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to release coupon lock: %v", err),
+		)
+	}
+
+	_, err = s.pool.Exec(ctx,
+		`UPDATE coupons SET locked_by = NULL, locked_until = NULL
+		WHERE code = $1 AND locked_by = $2`,
+		code,
+		checkoutID,
+	)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to release coupon lock: %v", err),
+		)
+	}
+
+	return connect.NewResponse(&coupon.ReleaseLockResponse{
+		Released: released == 1,
+	}), nil
+}
+
+// couponLockedError is returned when a checkout holds the coupon.
+func couponLockedError() error {
+	return connect.NewError(
+		connect.CodeFailedPrecondition,
+		fmt.Errorf("coupon is locked by a checkout"),
+	)
+}
+
+// checkRedeemable fails if the user could not redeem the coupon now.
+func (s *CouponService) checkRedeemable(
+	ctx context.Context,
+	code string,
+	userID string,
+) error {
+	if err := s.flushPendingCoupon(ctx, code); err != nil {
+		return err
+	}
+
+	var (
+		state     couponState
+		owner     *string
+		expiresAt *time.Time
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT state, user_id, expires_at FROM coupons WHERE code = $1`,
+		code,
+	).Scan(&state, &owner, &expiresAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("coupon not found"),
+		)
+	}
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon: %v", err),
+		)
+	}
+
+	if owner != nil && *owner != userID {
+		return connect.NewError(
+			connect.CodePermissionDenied,
+			fmt.Errorf("coupon belongs to another user"),
+		)
+	}
+	if state == couponIssued && isExpired(expiresAt) {
+		state = couponExpired
+	}
+	return checkTransition(state, couponRedeemed)
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponService_CheckoutLock(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	lock := func(code, checkoutID, ttl string) (*LockCouponResp, error) {
+		return service.LockCoupon(
+			ctx,
+			connect.NewRequest(&coupon.LockCouponRequest{
+				Code:       code,
+				CheckoutId: checkoutID,
+				Ttl:        ttl,
+				UserId:     "user-1",
+			}),
+		)
+	}
+	commit := func(code, checkoutID string) (*CommitRedemptionResp, error) {
+		return service.CommitRedemption(
+			ctx,
+			connect.NewRequest(&coupon.CommitRedemptionRequest{
+				Code:       code,
+				CheckoutId: checkoutID,
+				OrderRef:   "order-" + checkoutID,
+				UserId:     "user-1",
+			}),
+		)
+	}
+	release := func(code, checkoutID string) (*ReleaseLockResp, error) {
+		return service.ReleaseLock(
+			ctx,
+			connect.NewRequest(&coupon.ReleaseLockRequest{
+				Code:       code,
+				CheckoutId: checkoutID,
+			}),
+		)
+	}
+
+	t.Run("blocks other checkouts until committed", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+
+		resp, err := lock(code, "checkout-1", "")
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.Msg.ExpiresAt)
+
+		// The holder may extend its own lock
+		_, err = lock(code, "checkout-1", "10m")
+		require.NoError(t, err)
+
+		_, err = lock(code, "checkout-2", "")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		_, err = service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "user-1",
+				OrderRef: "order-direct",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		_, err = commit(code, "checkout-2")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		committed, err := commit(code, "checkout-1")
+		require.NoError(t, err)
+		assert.Equal(t, "redeemed", committed.Msg.State)
+
+		// The lock is gone and a retried commit returns the redemption
+		exists, err := service.redis.Exists(ctx, checkoutLockKey(code)).Result()
+		require.NoError(t, err)
+		assert.Zero(t, exists)
+
+		retried, err := commit(code, "checkout-1")
+		require.NoError(t, err)
+		assert.Equal(t, committed.Msg.RedeemedAt, retried.Msg.RedeemedAt)
+	})
+
+	t.Run("the coupon row holds the lock", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+
+		_, err := lock(code, "checkout-1", "")
+		require.NoError(t, err)
+
+		// Losing the Redis key does not free the coupon
+		err = service.redis.Del(ctx, checkoutLockKey(code)).Err()
+		require.NoError(t, err)
+
+		_, err = lock(code, "checkout-2", "")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+		exists, err := service.redis.Exists(ctx, checkoutLockKey(code)).Result()
+		require.NoError(t, err)
+		assert.Zero(t, exists, "failed lock is released in Redis")
+
+		_, err = service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "user-1",
+				OrderRef: "order-direct",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		_, err = service.RevokeCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RevokeCouponRequest{
+				Code:   code,
+				Reason: "fraud",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		committed, err := commit(code, "checkout-1")
+		require.NoError(t, err)
+		assert.Equal(t, "redeemed", committed.Msg.State)
+	})
+
+	t.Run("expired locks cannot commit", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+
+		_, err := lock(code, "checkout-1", "")
+		require.NoError(t, err)
+		_, err = service.pool.Exec(ctx,
+			`UPDATE coupons SET locked_until = now() - interval '1 second'
+			WHERE code = $1`,
+			code,
+		)
+		require.NoError(t, err)
+
+		_, err = commit(code, "checkout-1")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		// Another checkout takes over the expired lock
+		err = service.redis.Del(ctx, checkoutLockKey(code)).Err()
+		require.NoError(t, err)
+		_, err = lock(code, "checkout-2", "")
+		require.NoError(t, err)
+		_, err = commit(code, "checkout-2")
+		require.NoError(t, err)
+	})
+
+	t.Run("release frees the coupon", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+
+		_, err := lock(code, "checkout-1", "1m")
+		require.NoError(t, err)
+
+		// Only the holder can release the lock
+		resp, err := release(code, "checkout-2")
+		require.NoError(t, err)
+		assert.False(t, resp.Msg.Released)
+
+		resp, err = release(code, "checkout-1")
+		require.NoError(t, err)
+		assert.True(t, resp.Msg.Released)
+
+		resp, err = release(code, "checkout-1")
+		require.NoError(t, err)
+		assert.False(t, resp.Msg.Released)
+
+		_, err = lock(code, "checkout-2", "")
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects coupons that cannot be redeemed", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+
+		_, err := service.LockCoupon(
+			ctx,
+			connect.NewRequest(&coupon.LockCouponRequest{
+				Code:       code,
+				CheckoutId: "checkout-1",
+				UserId:     "user-2",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+
+		_, err = lock("missing-code", "checkout-1", "")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+
+	t.Run("validates ttl", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+
+		for _, ttl := range []string{"soon", "500ms", "2h"} {
+			_, err := lock(code, "checkout-1", ttl)
+			require.Error(t, err)
+			assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+		}
+	})
+}
@@ -0,0 +1,574 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// codeBatchReserveScript takes up to ARGV[1] coupons from a campaign
+// counter and returns how many it took.
+const codeBatchReserveScript = `
+	local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+	local take = math.min(current, tonumber(ARGV[1]))
+	if take > 0 then
+		redis.call('DECRBY', KEYS[1], take)
+	end
+	return take
+`
+
+const (
+	codeBatchSize     = 1000
+	maxCodeBatchCount = 1_000_000
+
+	// codeBatchChunkSize is about how many bytes of the file are sent per
+	// message of an export.
+	codeBatchChunkSize = 64 * 1024
+)
+
+type (
+	GenerateCodeBatchReq  = connect.Request[coupon.GenerateCodeBatchRequest]
+	GenerateCodeBatchResp = connect.Response[coupon.GenerateCodeBatchResponse]
+	ExportCodeBatchReq    = connect.Request[coupon.ExportCodeBatchRequest]
+	ExportCodeBatchStream = connect.ServerStream[coupon.ExportCodeBatchResponse]
+)
+
+// GenerateCodeBatch queues a job that pre-issues codes of a campaign without
+// an owner, to be printed or handed out outside the service. They count
+// against the campaign's coupon limit like any other issued coupon. The job
+// can run before the campaign starts; its codes are exported with
+// ExportCodeBatch once it has completed.
+func (s *CouponService) GenerateCodeBatch(
+	ctx context.Context,
+	req *GenerateCodeBatchReq,
+) (*GenerateCodeBatchResp, error) {
+	count := req.Msg.Count
+	if count <= 0 || count > maxCodeBatchCount {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("count must be between 1 and %d", maxCodeBatchCount),
+		)
+	}
+
+	var (
+		status string
+		vanity bool
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT status, vanity_code IS NOT NULL FROM campaigns WHERE id = $1`,
+		req.Msg.CampaignId,
+	).Scan(&status, &vanity)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("campaign not found: %v", err),
+		)
+	}
+	if vanity {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("vanity codes are claimed with IssueCoupon"),
+		)
+	}
+	if status == "finished" {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("campaign has already finished"),
+		)
+	}
+
+	// The job checks the counter again for every block it reserves
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, req.Msg.CampaignId)
+	remaining, err := s.redis.Get(ctx, counterKey).Int()
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon counter: %v", err),
+		)
+	}
+	if int(count) > remaining {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("campaign has only %d coupons left", remaining),
+		)
+	}
+
+	var jobID pgtype.UUID
+	err = s.pool.QueryRow(ctx,
+		`INSERT INTO jobs (type, campaign_id, total)
+		VALUES ($1, $2, $3) RETURNING id`,
+		jobTypeCodeBatch,
+		req.Msg.CampaignId,
+		count,
+	).Scan(&jobID)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to create job: %v", err),
+		)
+	}
+
+	return connect.NewResponse(&coupon.GenerateCodeBatchResponse{
+		JobId: jobID.String(),
+	}), nil
+}
+
+// runCodeBatch pre-issues the remaining codes of a code batch job block by
+// block. Every block commits its codes together with the job's progress,
+// so a restarted server continues where the previous one stopped.
+func (s *CouponService) runCodeBatch(
+	ctx context.Context,
+	j *job,
+) (bool, error) {
+	serverCtx := s.context
+
+	_, format, err := s.campaignCodeFormat(serverCtx, j.campaignID)
+	if err != nil {
+		return false, err
+	}
+
+	for ctx.Err() == nil {
+		done, err := s.processCodeBatch(serverCtx, j, format)
+		if err != nil || done {
+			return done, err
+		}
+		j.nextBatch++
+	}
+	return false, nil
+}
+
+func (s *CouponService) processCodeBatch(
+	ctx context.Context,
+	j *job,
+	format codeFormat,
+) (bool, error) {
+	var total, processed int
+	err := s.pool.QueryRow(ctx,
+		`SELECT total, processed FROM jobs WHERE id = $1`,
+		j.id,
+	).Scan(&total, &processed)
+	if err != nil {
+		return false, fmt.Errorf("failed to get job progress: %w", err)
+	}
+	if processed >= total {
+		return true, s.completeJob(ctx, j.id)
+	}
+	n := min(codeBatchSize, total-processed)
+
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, j.campaignID)
+	granted, err := s.reserveCodeBatch(ctx, j, n)
+	if err != nil {
+		return false, err
+	}
+
+	printed, err := s.codeGen.takeCodes(
+		ctx,
+		s.pool,
+		j.campaignID,
+		format,
+		granted,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to generate coupon codes: %w", err)
+	}
+	// Codes go back to the pool unless the block commits
+	returnCodes := true
+	defer func() {
+		if returnCodes {
+			s.codeGen.returnCodes(j.campaignID, format, printed)
+		}
+	}()
+	codes := make([]string, len(printed))
+	for i, code := range printed {
+		codes[i] = codeformat.Normalize(code)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if tx != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE coupons c
+		SET campaign_id = $1,
+			state = 'issued',
+			pre_issued = true,
+			issued_at = now(),
+			expires_at = LEAST(
+				cp.valid_until,
+				now() + make_interval(secs => cp.validity_seconds)
+			)
+		FROM campaigns cp
+		WHERE cp.id = $1
+		AND c.code = ANY($2)
+		AND c.campaign_id IS NULL
+		AND c.state = 'available'`,
+		j.campaignID,
+		codes,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to write issued codes: %w", err)
+	}
+	if tag.RowsAffected() != int64(len(codes)) {
+		// It is unknown which ones, so none are used again
+		returnCodes = false
+		return false, fmt.Errorf("some coupon codes were already issued")
+	}
+	if format.kind == codesImported {
+		if err := markImportedCodesIssued(ctx, tx, codes); err != nil {
+			return false, err
+		}
+	}
+	if err := recordIssuedEvents(ctx, tx, codes); err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO code_batch_codes (job_id, position, code, printed_code)
+		SELECT $1, $2 + a.n, a.code, a.printed
+		FROM unnest($3::text[], $4::text[]) WITH ORDINALITY
+			AS a(code, printed, n)`,
+		j.id,
+		processed,
+		codes,
+		printed,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record batch codes: %w", err)
+	}
+
+	// The next_batch check makes sure a server whose lease has expired
+	// cannot commit a block another server has already taken over.
+	failed := n - granted
+	tag, err = tx.Exec(ctx,
+		`UPDATE jobs
+		SET processed = processed + $2,
+			succeeded = succeeded + $3,
+			failed = failed + $4,
+			error = CASE WHEN $4 > 0 THEN $5 ELSE error END,
+			next_batch = next_batch + 1,
+			reserved = NULL,
+			attempts = 0,
+			locked_until = now() + make_interval(secs => $6)
+		WHERE id = $1 AND next_batch = $7`,
+		j.id,
+		n,
+		granted,
+		failed,
+		errPushLimitReached,
+		jobLease.Seconds(),
+		j.nextBatch,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update job progress: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return false, fmt.Errorf("job was taken over by another worker")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit batch: %w", err)
+	}
+	tx = nil // Set tx to nil after successful commit
+	returnCodes = false
+
+	if granted > 0 {
+		remaining, err := s.redis.Get(ctx, counterKey).Int()
+		if err != nil {
+			return false, fmt.Errorf("failed to get coupon counter: %w", err)
+		}
+		if remaining == 0 {
+			err := s.updateCampaignToFinished(ctx, j.campaignID)
+			if err != nil {
+				return false, fmt.Errorf("failed to finish campaign: %w", err)
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// reserveCodeBatch takes up to n coupons from the campaign counter for the
+// job's next block and records them in the job. A retried block gets the
+// coupons recorded by the earlier attempt instead of taking more.
+func (s *CouponService) reserveCodeBatch(
+	ctx context.Context,
+	j *job,
+	n int,
+) (int, error) {
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, j.campaignID)
+	for {
+		var reserved *int
+		err := s.pool.QueryRow(ctx,
+			`SELECT reserved FROM jobs WHERE id = $1 AND next_batch = $2`,
+			j.id,
+			j.nextBatch,
+		).Scan(&reserved)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("job was taken over by another worker")
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get job reservation: %w", err)
+		}
+		if reserved != nil {
+			return *reserved, nil
+		}
+
+		taken, err := s.redis.Eval(ctx, codeBatchReserveScript,
+			[]string{counterKey},
+			n,
+		).Int()
+		if err != nil {
+			return 0, fmt.Errorf("failed to reserve coupons: %w", err)
+		}
+
+		// If this fails the coupons stay taken, as the reservation may have
+		// been recorded; the campaign then issues fewer rather than more
+		tag, err := s.pool.Exec(ctx,
+			`UPDATE jobs SET reserved = $3
+			WHERE id = $1 AND next_batch = $2 AND reserved IS NULL`,
+			j.id,
+			j.nextBatch,
+			taken,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to record reservation: %w", err)
+		}
+		if tag.RowsAffected() == 1 {
+			return taken, nil
+		}
+
+		// Another worker reserved the block first
+		if taken > 0 {
+			err := s.redis.IncrBy(ctx, counterKey, int64(taken)).Err()
+			if err != nil {
+				return 0, fmt.Errorf("failed to return coupons: %w", err)
+			}
+		}
+	}
+}
+
+// releaseCodeBatchReservation gives back the coupons recorded in the job for
+// its next block.
+func (s *CouponService) releaseCodeBatchReservation(
+	ctx context.Context,
+	j *job,
+) error {
+	var reserved *int
+	err := s.pool.QueryRow(ctx,
+		`UPDATE jobs j SET reserved = NULL
+		FROM (
+			SELECT id, reserved FROM jobs
+			WHERE id = $1 AND next_batch = $2
+			FOR UPDATE
+		) old
+		WHERE j.id = old.id
+		RETURNING old.reserved`,
+		j.id,
+		j.nextBatch,
+	).Scan(&reserved)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release reservation: %w", err)
+	}
+	if reserved != nil {
+		// The block may have finished the campaign
+		s.returnCampaignSlots(j.campaignID, *reserved, true)
+	}
+	return nil
+}
+
+// codeBatchRow is a code of an exported batch.
+type codeBatchRow struct {
+	Code      string `json:"code"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+// ExportCodeBatch streams the codes of a completed code batch job as a file,
+// in the order they were generated and as the format writes them.
+func (s *CouponService) ExportCodeBatch(
+	ctx context.Context,
+	req *ExportCodeBatchReq,
+	stream *ExportCodeBatchStream,
+) error {
+	var (
+		jobType string
+		status  string
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT type, status FROM jobs WHERE id = $1`,
+		req.Msg.JobId,
+	).Scan(&jobType, &status)
+	if err != nil {
+		return connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("job not found: %v", err),
+		)
+	}
+	if jobType != jobTypeCodeBatch {
+		return connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("job %s is not a code batch", req.Msg.JobId),
+		)
+	}
+	if status != "completed" {
+		return connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("code batch is %s", status),
+		)
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT b.printed_code, c.expires_at
+		FROM code_batch_codes b
+		JOIN coupons c ON c.code = b.code
+		WHERE b.job_id = $1
+		ORDER BY b.position`,
+		req.Msg.JobId,
+	)
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get batch codes: %v", err),
+		)
+	}
+	defer rows.Close()
+
+	w := bufio.NewWriterSize(&codeBatchWriter{stream: stream}, codeBatchChunkSize)
+	write, err := newCodeBatchEncoder(w, req.Msg.Format)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var (
+			row       codeBatchRow
+			expiresAt *time.Time
+		)
+		if err := rows.Scan(&row.Code, &expiresAt); err != nil {
+			return connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to scan batch code: %v", err),
+			)
+		}
+		row.ExpiresAt = formatOptionalTime(expiresAt)
+		if err := write(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("error iterating batch codes: %v", err),
+		)
+	}
+	return w.Flush()
+}
+
+// newCodeBatchEncoder returns a function writing one code of a batch to w in
+// the requested format. CSV files start with a header row.
+func newCodeBatchEncoder(
+	w *bufio.Writer,
+	format coupon.CodeBatchFormat,
+) (func(codeBatchRow) error, error) {
+	if format == coupon.CodeBatchFormat_CODE_BATCH_FORMAT_JSONL {
+		encoder := json.NewEncoder(w)
+		return func(row codeBatchRow) error {
+			return encoder.Encode(row)
+		}, nil
+	}
+
+	cw := csv.NewWriter(w)
+	write := func(record []string) error {
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+		// Flush into w, which only sends full chunks
+		cw.Flush()
+		return cw.Error()
+	}
+	if err := write([]string{"code", "expires_at"}); err != nil {
+		return nil, err
+	}
+	return func(row codeBatchRow) error {
+		return write([]string{row.Code, row.ExpiresAt})
+	}, nil
+}
+
+// codeBatchWriter sends what is written to it as messages of an export.
+type codeBatchWriter struct {
+	stream *ExportCodeBatchStream
+}
+
+func (w *codeBatchWriter) Write(p []byte) (int, error) {
+	err := w.stream.Send(&coupon.ExportCodeBatchResponse{
+		Data: append([]byte(nil), p...),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// campaignCodeFormat returns a campaign's status and the format of its
+// codes.
+func (s *CouponService) campaignCodeFormat(
+	ctx context.Context,
+	campaignID string,
+) (string, codeFormat, error) {
+	var (
+		status         string
+		signedCodes    bool
+		sequencedCodes bool
+		importedCodes  bool
+		storedFormat   *codeformat.Format
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT status, signed_codes, sequenced_codes, imported_codes,
+			code_format
+		FROM campaigns WHERE id = $1`,
+		campaignID,
+	).Scan(
+		&status,
+		&signedCodes,
+		&sequencedCodes,
+		&importedCodes,
+		&storedFormat,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", codeFormat{}, fmt.Errorf("campaign %s not found", campaignID)
+	}
+	if err != nil {
+		return "", codeFormat{}, fmt.Errorf(
+			"failed to get campaign status: %w", err,
+		)
+	}
+	format, err := newCodeFormat(
+		campaignCodeKind(signedCodes, sequencedCodes, importedCodes),
+		storedFormat,
+	)
+	if err != nil {
+		return "", codeFormat{}, err
+	}
+	return status, format, nil
+}
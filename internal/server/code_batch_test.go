@@ -0,0 +1,232 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponService_CodeBatch(t *testing.T) {
+	service := setupTestService(t)
+	client := newTestClient(t, service)
+	ctx := context.Background()
+
+	// Codes are printed before the campaign starts
+	created, err := service.CreateCampaign(
+		ctx,
+		connect.NewRequest(&coupon.CreateCampaignRequest{
+			Name:        "Flyer Campaign",
+			StartTime:   time.Now().Add(time.Hour).Format(time.RFC3339),
+			CouponLimit: 2500,
+			CodeFormat:  &coupon.CodeFormat{Prefix: "FLY-", Length: 8},
+		}),
+	)
+	require.NoError(t, err)
+	campaignID := created.Msg.CampaignId
+
+	generate := func(count int32) (string, error) {
+		resp, err := service.GenerateCodeBatch(
+			ctx,
+			connect.NewRequest(&coupon.GenerateCodeBatchRequest{
+				CampaignId: campaignID,
+				Count:      count,
+			}),
+		)
+		if err != nil {
+			return "", err
+		}
+		return resp.Msg.JobId, nil
+	}
+	export := func(
+		jobID string,
+		format coupon.CodeBatchFormat,
+	) ([]byte, error) {
+		stream, err := client.ExportCodeBatch(
+			ctx,
+			connect.NewRequest(&coupon.ExportCodeBatchRequest{
+				JobId:  jobID,
+				Format: format,
+			}),
+		)
+		require.NoError(t, err)
+		var data bytes.Buffer
+		for stream.Receive() {
+			data.Write(stream.Msg().Data)
+		}
+		return data.Bytes(), stream.Err()
+	}
+
+	t.Run("invalid counts", func(t *testing.T) {
+		for _, count := range []int32{0, -1, maxCodeBatchCount + 1} {
+			_, err := generate(count)
+			require.Error(t, err)
+			assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+		}
+
+		_, err := generate(2501)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	jobID, err := generate(2400)
+	require.NoError(t, err)
+
+	_, err = export(jobID, coupon.CodeBatchFormat_CODE_BATCH_FORMAT_UNSPECIFIED)
+	if err != nil {
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	}
+
+	require.Eventually(t, func() bool {
+		resp, err := service.GetJob(
+			ctx,
+			connect.NewRequest(&coupon.GetJobRequest{JobId: jobID}),
+		)
+		return err == nil && resp.Msg.Status == "completed"
+	}, 20*time.Second, 100*time.Millisecond)
+
+	var codes []string
+	t.Run("csv", func(t *testing.T) {
+		data, err := export(
+			jobID,
+			coupon.CodeBatchFormat_CODE_BATCH_FORMAT_UNSPECIFIED,
+		)
+		require.NoError(t, err)
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2401)
+		assert.Equal(t, []string{"code", "expires_at"}, records[0])
+
+		seen := make(map[string]bool)
+		for _, record := range records[1:] {
+			code := record[0]
+			assert.True(t, strings.HasPrefix(code, "FLY-"), code)
+			assert.False(t, seen[code], code)
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		data, err := export(jobID, coupon.CodeBatchFormat_CODE_BATCH_FORMAT_JSONL)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		require.Len(t, lines, len(codes))
+		for i, line := range lines {
+			var row codeBatchRow
+			require.NoError(t, json.Unmarshal([]byte(line), &row))
+			assert.Equal(t, codes[i], row.Code, "same order")
+		}
+	})
+
+	t.Run("codes are pre-issued", func(t *testing.T) {
+		var preIssued int
+		err := service.pool.QueryRow(ctx,
+			`SELECT count(*) FROM coupons
+			WHERE campaign_id = $1 AND state = 'issued' AND pre_issued
+			AND user_id IS NULL`,
+			campaignID,
+		).Scan(&preIssued)
+		require.NoError(t, err)
+		assert.Equal(t, len(codes), preIssued)
+
+		// They count against the coupon limit
+		_, err = generate(101)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		// Printed codes are found in their normalized form
+		var issuedAt time.Time
+		err = service.pool.QueryRow(ctx,
+			`SELECT issued_at FROM coupons WHERE code = $1`,
+			codeformat.Normalize(codes[0]),
+		).Scan(&issuedAt)
+		require.NoError(t, err)
+	})
+
+	t.Run("a retried block keeps its reservation", func(t *testing.T) {
+		var jobID string
+		err := service.pool.QueryRow(ctx,
+			`INSERT INTO jobs (type, campaign_id, status, total)
+			VALUES ($1, $2, 'completed', 50) RETURNING id::text`,
+			jobTypeCodeBatch,
+			campaignID,
+		).Scan(&jobID)
+		require.NoError(t, err)
+
+		counterKey := campaignCounterKey + campaignID
+		before, err := service.redis.Get(ctx, counterKey).Int()
+		require.NoError(t, err)
+
+		j := &job{id: jobID, campaignID: campaignID}
+		for i := 0; i < 2; i++ {
+			granted, err := service.reserveCodeBatch(ctx, j, 50)
+			require.NoError(t, err)
+			assert.Equal(t, 50, granted)
+		}
+
+		after, err := service.redis.Get(ctx, counterKey).Int()
+		require.NoError(t, err)
+		assert.Equal(t, before-50, after)
+	})
+
+	t.Run("a block that keeps failing fails the job", func(t *testing.T) {
+		var jobID string
+		err := service.pool.QueryRow(ctx,
+			`INSERT INTO jobs (type, campaign_id, status, total)
+			VALUES ($1, $2, 'running', 50) RETURNING id::text`,
+			jobTypeCodeBatch,
+			campaignID,
+		).Scan(&jobID)
+		require.NoError(t, err)
+
+		counterKey := campaignCounterKey + campaignID
+		before, err := service.redis.Get(ctx, counterKey).Int()
+		require.NoError(t, err)
+
+		j := &job{id: jobID, jobType: jobTypeCodeBatch, campaignID: campaignID}
+		_, err = service.reserveCodeBatch(ctx, j, 50)
+		require.NoError(t, err)
+
+		for range maxJobAttempts {
+			service.retryJob(ctx, j, errors.New("block failed"))
+		}
+
+		var (
+			status   string
+			jobError string
+		)
+		err = service.pool.QueryRow(ctx,
+			`SELECT status, error FROM jobs WHERE id = $1`,
+			jobID,
+		).Scan(&status, &jobError)
+		require.NoError(t, err)
+		assert.Equal(t, "failed", status)
+		assert.Contains(t, jobError, "block failed")
+
+		// The block's share of the limit is given back
+		after, err := service.redis.Get(ctx, counterKey).Int()
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("other jobs cannot be exported", func(t *testing.T) {
+		_, err := export(
+			"00000000-0000-0000-0000-000000000001",
+			coupon.CodeBatchFormat_CODE_BATCH_FORMAT_UNSPECIFIED,
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+}
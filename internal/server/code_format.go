@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+	"coupon-issuance/internal/utils"
+
+	"connectrpc.com/connect"
+)
+
+// codeSpaceFactor is how many more codes a format must be able to generate
+// than the campaign's coupon limit, so that random codes rarely collide.
+const codeSpaceFactor = 100
+
+var codeAlphabets = map[coupon.CodeAlphabet]codeformat.Alphabet{
+	coupon.CodeAlphabet_CODE_ALPHABET_UNSPECIFIED: codeformat.AlphabetMixed,
+	coupon.CodeAlphabet_CODE_ALPHABET_HANGUL:      codeformat.AlphabetHangul,
+	coupon.CodeAlphabet_CODE_ALPHABET_DIGITS:      codeformat.AlphabetDigits,
+	coupon.CodeAlphabet_CODE_ALPHABET_LATIN_UPPERCASE: codeformat.
+		AlphabetLatinUppercase,
+	coupon.CodeAlphabet_CODE_ALPHABET_CROCKFORD_BASE32: codeformat.
+		AlphabetCrockfordBase32,
+	coupon.CodeAlphabet_CODE_ALPHABET_READABLE: codeformat.AlphabetReadable,
+}
+
+// codeBlocklistFromEnv reads the words generated codes must not contain
+// from the file named by CODE_BLOCKLIST_FILE, if set.
+func codeBlocklistFromEnv() (*codeformat.Blocklist, error) {
+	path := utils.GetEnv("CODE_BLOCKLIST_FILE", "")
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CODE_BLOCKLIST_FILE: %w", err)
+	}
+	defer f.Close()
+	return codeformat.ReadBlocklist(f)
+}
+
+var checkCharacters = map[coupon.CheckCharacter]codeformat.CheckScheme{
+	coupon.CheckCharacter_CHECK_CHARACTER_UNSPECIFIED: codeformat.CheckNone,
+	coupon.CheckCharacter_CHECK_CHARACTER_LUHN:        codeformat.CheckLuhn,
+	coupon.CheckCharacter_CHECK_CHARACTER_DAMM:        codeformat.CheckDamm,
+}
+
+// parseCodeFormat converts and validates a campaign's code format against
+// its coupon limit. A nil format is allowed and returns nil.
+func parseCodeFormat(
+	pf *coupon.CodeFormat,
+	couponLimit int32,
+	signed bool,
+) (*codeformat.Format, error) {
+	if pf == nil {
+		return nil, nil
+	}
+
+	alphabet, ok := codeAlphabets[pf.Alphabet]
+	if !ok {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("unknown code alphabet %v", pf.Alphabet),
+		)
+	}
+	check, ok := checkCharacters[pf.CheckCharacter]
+	if !ok {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("unknown check character %v", pf.CheckCharacter),
+		)
+	}
+	f := &codeformat.Format{
+		Alphabet:       alphabet,
+		CustomAlphabet: pf.CustomAlphabet,
+		CommonHangul:   pf.CommonHangul,
+		Length:         int(pf.Length),
+		Prefix:         pf.Prefix,
+		Pattern:        pf.Pattern,
+		CheckCharacter: check,
+	}
+
+	layout, err := f.Compile()
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("invalid code format: %v", err),
+		)
+	}
+
+	if signed {
+		// The signature determines the rest of the code and already
+		// catches typos
+		if f.Length != 0 || f.Pattern != "" || f.CheckCharacter != "" {
+			return nil, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf(
+					"signed codes cannot set a length, pattern or check character",
+				),
+			)
+		}
+		if _, err := newSignedCodec(layout); err != nil {
+			return nil, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("invalid code format: %v", err),
+			)
+		}
+		return f, nil
+	}
+
+	if layout.Bits() < math.Log2(float64(couponLimit)*codeSpaceFactor) {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code format has too few codes for the coupon limit"),
+		)
+	}
+	return f, nil
+}
+
+func codeFormatToProto(f *codeformat.Format) *coupon.CodeFormat {
+	if f == nil {
+		return nil
+	}
+
+	pf := &coupon.CodeFormat{
+		CustomAlphabet: f.CustomAlphabet,
+		CommonHangul:   f.CommonHangul,
+		Length:         int32(f.Length),
+		Prefix:         f.Prefix,
+		Pattern:        f.Pattern,
+	}
+	for pa, a := range codeAlphabets {
+		if a == f.Alphabet {
+			pf.Alphabet = pa
+		}
+	}
+	for pc, c := range checkCharacters {
+		if c == f.CheckCharacter {
+			pf.CheckCharacter = pc
+		}
+	}
+	return pf
+}
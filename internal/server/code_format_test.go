@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCodeFormat(t *testing.T) {
+	digits := coupon.CodeAlphabet_CODE_ALPHABET_DIGITS
+	tests := []struct {
+		name    string
+		format  *coupon.CodeFormat
+		limit   int32
+		signed  bool
+		wantErr bool
+	}{
+		{"default", nil, 1000, false, false},
+		{
+			"pattern",
+			&coupon.CodeFormat{Pattern: "SALE-####-@@@@"},
+			1000, false, false,
+		},
+		{
+			"invalid",
+			&coupon.CodeFormat{Length: 4, Pattern: "####"},
+			1000, false, true,
+		},
+		{
+			"enough codes",
+			&coupon.CodeFormat{Alphabet: digits, Length: 6},
+			10000, false, false,
+		},
+		{
+			"too few codes",
+			&coupon.CodeFormat{Alphabet: digits, Length: 6},
+			10001, false, true,
+		},
+		{
+			"common hangul",
+			&coupon.CodeFormat{CommonHangul: true},
+			1000, false, false,
+		},
+		{
+			"common hangul without hangul",
+			&coupon.CodeFormat{Alphabet: digits, CommonHangul: true},
+			1000, false, true,
+		},
+		{
+			"readable",
+			&coupon.CodeFormat{
+				Alphabet: coupon.CodeAlphabet_CODE_ALPHABET_READABLE,
+			},
+			1000, false, false,
+		},
+		{
+			"signed with prefix",
+			&coupon.CodeFormat{Alphabet: digits, Prefix: "POS-"},
+			1000, true, false,
+		},
+		{
+			"signed with length",
+			&coupon.CodeFormat{Alphabet: digits, Length: 6},
+			1000, true, true,
+		},
+		{
+			"signed codes too long",
+			&coupon.CodeFormat{
+				CustomAlphabet: "AB",
+				Prefix:         "POS-",
+			},
+			1000, true, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCodeFormat(tt.format, tt.limit, tt.signed)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCouponService_CodeFormat(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	format := &coupon.CodeFormat{
+		Alphabet: coupon.CodeAlphabet_CODE_ALPHABET_CROCKFORD_BASE32,
+		Prefix:   "GS25-",
+		Pattern:  "####-@@@@",
+	}
+	created, err := service.CreateCampaign(
+		ctx,
+		connect.NewRequest(&coupon.CreateCampaignRequest{
+			Name:        "Partner Campaign",
+			StartTime:   time.Now().Format(time.RFC3339),
+			CouponLimit: 10,
+			CodeFormat:  format,
+		}),
+	)
+	require.NoError(t, err)
+	campaignID := created.Msg.CampaignId
+
+	_, err = service.pool.Exec(ctx,
+		`UPDATE campaigns SET status = 'active' WHERE id = $1`,
+		campaignID,
+	)
+	require.NoError(t, err)
+
+	for range 3 {
+		issued, err := service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: campaignID,
+				UserId:     "user-1",
+			}),
+		)
+		require.NoError(t, err)
+		assert.Regexp(t,
+			`^GS25-[0-9]{4}-[A-HJKMNP-TV-Z]{4}$`,
+			issued.Msg.CouponCode,
+		)
+	}
+
+	campaign, err := service.GetCampaign(
+		ctx,
+		connect.NewRequest(&coupon.GetCampaignRequest{
+			CampaignId: campaignID,
+		}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, format.Alphabet, campaign.Msg.CodeFormat.Alphabet)
+	assert.Equal(t, format.Prefix, campaign.Msg.CodeFormat.Prefix)
+	assert.Equal(t, format.Pattern, campaign.Msg.CodeFormat.Pattern)
+}
@@ -2,60 +2,177 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"math/rand"
+	"math"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
+	"coupon-issuance/internal/codeformat"
+	"coupon-issuance/pkg/signedcode"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// formatBatchSize is how many codes a server reserves at a time for a
+// format other than the default, and how many serials for a campaign with
+// signed codes
+const formatBatchSize = 100
+
+var defaultLayout = func() *codeformat.Layout {
+	layout, err := codeformat.Format{}.Compile()
+	if err != nil {
+		panic(err)
+	}
+	return layout
+}()
+
+// codeKind is where a campaign's codes come from.
+type codeKind int
+
 const (
-	koreanStart = 0xAC00 // 가
-	koreanEnd   = 0xD7A3 // 힣
-	numberStart = 0x0030 // 0
-	numberEnd   = 0x0039 // 9
+	// codesRandom are drawn at random and reserved before they are issued
+	codesRandom codeKind = iota
+	// codesSigned are minted from the campaign's active signing key
+	codesSigned
+	// codesSequenced are derived from a number counted per layout, so they
+	// need no reservation
+	codesSequenced
+	// codesImported were supplied by a partner through ImportCodes
+	codesImported
 )
 
+// campaignCodeKind returns the kind of codes from a campaign's columns, at
+// most one of which is set.
+func campaignCodeKind(signed, sequenced, imported bool) codeKind {
+	switch {
+	case signed:
+		return codesSigned
+	case sequenced:
+		return codesSequenced
+	case imported:
+		return codesImported
+	default:
+		return codesRandom
+	}
+}
+
+// codeFormat describes how a campaign's codes are generated.
+type codeFormat struct {
+	kind codeKind
+	// layout is nil for the default format
+	layout *codeformat.Layout
+}
+
+func newCodeFormat(kind codeKind, f *codeformat.Format) (codeFormat, error) {
+	format := codeFormat{kind: kind}
+	if f != nil {
+		var err error
+		if format.layout, err = f.Compile(); err != nil {
+			return format, fmt.Errorf("invalid code format: %w", err)
+		}
+	}
+	return format, nil
+}
+
+func (f codeFormat) getLayout() *codeformat.Layout {
+	if f.layout == nil {
+		return defaultLayout
+	}
+	return f.layout
+}
+
+// poolKey returns the pool a campaign's codes are drawn from, or "" for the
+// shared pool of default codes.
+func (f codeFormat) poolKey(campaignID string) string {
+	switch {
+	case f.kind == codesSigned:
+		return "signed:" + campaignID
+	case f.kind == codesSequenced:
+		return "sequenced:" + f.getLayout().Key()
+	case f.kind == codesImported:
+		return "imported:" + campaignID
+	case f.getLayout().Key() == defaultLayout.Key():
+		return ""
+	default:
+		return f.getLayout().Key()
+	}
+}
+
+// newSignedCodec returns the codec that writes signed codes in a layout's
+// alphabet.
+func newSignedCodec(layout *codeformat.Layout) (*signedcode.Codec, error) {
+	codec, err := signedcode.NewCodec(layout.Alphabet())
+	if err != nil {
+		return nil, err
+	}
+	n := len([]rune(layout.Prefix())) + codec.Length()
+	if n > codeformat.MaxLength {
+		return nil, fmt.Errorf(
+			"signed codes would be %d characters, at most %d are allowed",
+			n, codeformat.MaxLength,
+		)
+	}
+	return codec, nil
+}
+
+type issuedCoupon struct {
+	campaignID string
+	userID     string // empty for anonymous issuance
+	issuedAt   time.Time
+	// imported codes are marked issued so they are not claimed again
+	imported bool
+}
+
 type codeGenerator struct {
 	mu          sync.Mutex
-	codePool    []string
-	usedCoupons map[string]string // map of code to campaign ID
+	codePool    []string                // pool of default codes
+	pools       map[string][]string     // map of pool key to codes
+	leases      map[string]time.Time    // map of pool key to claim expiry
+	usedCoupons map[string]issuedCoupon // map of normalized code to issuance
 	batchSize   int
+	source      codeformat.Source
+	// blocklist rejects generated codes spelling unwanted words
+	blocklist *codeformat.Blocklist
 }
 
 func newCodeGenerator() *codeGenerator {
+	return newCodeGeneratorWithSource(codeformat.NewCryptoSource())
+}
+
+// newCodeGeneratorWithSource returns a generator drawing codes from src.
+// Tests pass a seeded source to make codes reproducible.
+func newCodeGeneratorWithSource(src codeformat.Source) *codeGenerator {
 	batchSize := 1000
 	return &codeGenerator{
 		batchSize:   batchSize,
+		source:      src,
 		codePool:    make([]string, 0, batchSize),
-		usedCoupons: make(map[string]string, batchSize),
+		pools:       make(map[string][]string),
+		leases:      make(map[string]time.Time),
+		usedCoupons: make(map[string]issuedCoupon, batchSize),
 	}
 }
 
-func generateRandomKoreanChar() rune {
-	return rune(koreanStart + rand.Intn(koreanEnd-koreanStart+1))
-}
-
-func generateRandomNumber() rune {
-	return rune(numberStart + rand.Intn(numberEnd-numberStart+1))
-}
-
-func (g *codeGenerator) generateBatch() []string {
-	length := 10
-	codes := make([]string, g.batchSize)
-	for i := 0; i < g.batchSize; i++ {
-		code := make([]rune, length)
-		for j := 0; j < length; j++ {
-			if rand.Float32() < 0.5 {
-				code[j] = generateRandomKoreanChar()
-			} else {
-				code[j] = generateRandomNumber()
-			}
+// generateBatch returns up to n random codes, leaving out those the
+// blocklist blocks. The caller must hold g.mu, as the source may not be safe
+// for concurrent use.
+func (g *codeGenerator) generateBatch(
+	layout *codeformat.Layout,
+	n int,
+) []string {
+	codes := make([]string, 0, n)
+	for range n {
+		code := layout.Generate(g.source)
+		if g.blocklist.Blocks(code) {
+			continue
 		}
-		codes[i] = string(code)
+		codes = append(codes, code)
 	}
 	return codes
 }
@@ -64,21 +181,56 @@ func (g *codeGenerator) writeIssuedCodes(
 	ctx context.Context,
 	pool *pgxpool.Pool,
 ) error {
-	g.mu.Lock()
-	if len(g.usedCoupons) == 0 {
-		g.mu.Unlock()
-		return nil
-	}
+	return g.writeCoupons(ctx, pool, nil)
+}
+
+// writeUserCodes writes only the pending coupons issued to a user.
+func (g *codeGenerator) writeUserCodes(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	userID string,
+) error {
+	return g.writeCoupons(ctx, pool, func(c issuedCoupon) bool {
+		return c.userID == userID
+	})
+}
 
-	// Take a copy of used codes and clear the map
+// writeCoupons writes the pending coupons keep returns true for, or all of
+// them if keep is nil. Coupons that are not written are put back, to be
+// written by a later flush.
+func (g *codeGenerator) writeCoupons(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	keep func(issuedCoupon) bool,
+) error {
+	g.mu.Lock()
+	// Take the coupons to write out of the map
 	codes := make([]string, 0, len(g.usedCoupons))
-	campaignIDs := make([]string, 0, len(g.usedCoupons))
-	for code, campaignID := range g.usedCoupons {
+	issued := make([]issuedCoupon, 0, len(g.usedCoupons))
+	for code, coupon := range g.usedCoupons {
+		if keep != nil && !keep(coupon) {
+			continue
+		}
 		codes = append(codes, code)
-		campaignIDs = append(campaignIDs, campaignID)
+		issued = append(issued, coupon)
+		delete(g.usedCoupons, code)
 	}
-	g.usedCoupons = make(map[string]string)
 	g.mu.Unlock()
+	if len(codes) == 0 {
+		return nil
+	}
+
+	// Codes written by the transaction, once it has committed
+	var committed map[string]struct{}
+	defer func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		for i, code := range codes {
+			if _, ok := committed[code]; !ok {
+				g.usedCoupons[code] = issued[i]
+			}
+		}
+	}()
 
 	tx, err := pool.Begin(ctx)
 	if err != nil {
@@ -92,40 +244,60 @@ func (g *codeGenerator) writeIssuedCodes(
 		}
 	}()
 
-	// Update the codes with campaign_id and mark as issued
+	var imported []string
+	for i, coupon := range issued {
+		if coupon.imported {
+			imported = append(imported, codes[i])
+		}
+	}
+
+	// Update the codes with campaign_id, owner and expiry and mark as issued
+	const columns = 4
 	placeholders := make([]string, len(codes))
-	args := make([]interface{}, len(codes)*2)
+	args := make([]interface{}, len(codes)*columns)
 	for i := range codes {
-		placeholders[i] = fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2)
-		args[i*2] = codes[i]
+		n := i * columns
+		placeholders[i] = fmt.Sprintf(
+			"($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4,
+		)
+		args[n] = codes[i]
 		var campaignID pgtype.UUID
-		err := campaignID.Scan(campaignIDs[i])
+		err := campaignID.Scan(issued[i].campaignID)
 		if err != nil {
 			return fmt.Errorf("failed to parse campaign ID: %w", err)
 		}
-		args[i*2+1] = campaignID
+		args[n+1] = campaignID
+		var userID *string
+		if issued[i].userID != "" {
+			userID = &issued[i].userID
+		}
+		args[n+2] = userID
+		args[n+3] = issued[i].issuedAt
 	}
 
 	query := fmt.Sprintf(`
-		WITH input_codes(code, campaign_id) AS (
+		WITH input_codes(code, campaign_id, user_id, issued_at) AS (
 			VALUES %s
 		)
 		UPDATE coupons c
-		SET campaign_id = i.campaign_id::uuid, issued = TRUE
+		SET campaign_id = i.campaign_id::uuid,
+			user_id = i.user_id::varchar,
+			state = 'issued',
+			issued_at = i.issued_at::timestamptz,
+			expires_at = LEAST(
+				cp.valid_until,
+				i.issued_at::timestamptz +
+					make_interval(secs => cp.validity_seconds)
+			)
 		FROM input_codes i
+		JOIN campaigns cp ON cp.id = i.campaign_id::uuid
 		WHERE c.code = i.code 
 		AND c.campaign_id IS NULL 
-		AND c.issued = FALSE
+		AND c.state = 'available'
 		RETURNING c.code`, strings.Join(placeholders, ","))
 
 	rows, err := tx.Query(ctx, query, args...)
 	if err != nil {
-		// If write fails, put the codes back in usedCodes
-		g.mu.Lock()
-		for i := range codes {
-			g.usedCoupons[codes[i]] = campaignIDs[i]
-		}
-		g.mu.Unlock()
 		return fmt.Errorf("failed to write used codes: %w", err)
 	}
 	defer rows.Close()
@@ -140,26 +312,37 @@ func (g *codeGenerator) writeIssuedCodes(
 		updatedCodes[code] = struct{}{}
 	}
 
-	// Check if any codes failed to update
-	for _, code := range codes {
-		if _, updated := updatedCodes[code]; !updated {
-			// Put failed codes back in usedCodes
-			g.mu.Lock()
-			for i, c := range codes {
-				if c == code {
-					g.usedCoupons[code] = campaignIDs[i]
-					break
-				}
-			}
-			g.mu.Unlock()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to write used codes: %w", err)
+	}
+
+	// Only codes that were written are issued
+	importedIssued := make([]string, 0, len(imported))
+	for _, code := range imported {
+		if _, updated := updatedCodes[code]; updated {
+			importedIssued = append(importedIssued, code)
 		}
 	}
+	if err := markImportedCodesIssued(ctx, tx, importedIssued); err != nil {
+		return err
+	}
+
+	written := make([]string, 0, len(updatedCodes))
+	for code := range updatedCodes {
+		written = append(written, code)
+	}
+	if err := recordIssuedEvents(ctx, tx, written); err != nil {
+		return err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 	tx = nil // Set tx to nil after successful commit
 
+	// Codes that failed to update are put back
+	committed = updatedCodes
+
 	return nil
 }
 
@@ -174,11 +357,230 @@ func (g *codeGenerator) refillPool(
 		return nil
 	}
 
-	codes := g.generateBatch()
+	codes := g.generateBatch(defaultLayout, g.batchSize)
+	reserved, err := reserveCodes(ctx, pool, codes)
+	if err != nil {
+		return err
+	}
+	g.codePool = append(g.codePool, reserved...)
+
+	return nil
+}
+
+// refillFormatPool reserves codes of a format other than the default. They
+// can be issued by any campaign with the same format.
+func (g *codeGenerator) refillFormatPool(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	layout *codeformat.Layout,
+) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.pools[layout.Key()]) > 0 {
+		return nil
+	}
+
+	codes := g.generateBatch(layout, formatBatchSize)
+	reserved, err := reserveCodes(ctx, pool, codes)
+	if err != nil {
+		return err
+	}
+	g.pools[layout.Key()] = reserved
+
+	return nil
+}
+
+// refillSignedPool signs a block of serials from the campaign's active key.
+// Codes left in the pool after a rotation keep the previous key, which
+// still verifies them.
+func (g *codeGenerator) refillSignedPool(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	campaignID string,
+	format codeFormat,
+) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := format.poolKey(campaignID)
+	if len(g.pools[key]) > 0 {
+		return nil
+	}
+
+	layout := format.getLayout()
+	codec, err := newSignedCodec(layout)
+	if err != nil {
+		return err
+	}
+
+	var (
+		signingKey signedcode.Key
+		first      int64
+	)
+	err = pool.QueryRow(ctx,
+		`UPDATE campaign_signing_keys
+		SET next_serial = next_serial + $2
+		WHERE campaign_id = $1 AND retired_at IS NULL
+		RETURNING key_id, secret, next_serial - $2`,
+		campaignID,
+		formatBatchSize,
+	).Scan(&signingKey.ID, &signingKey.Secret, &first)
+	if err != nil {
+		return fmt.Errorf("failed to reserve serials: %w", err)
+	}
+
+	codes := make([]string, formatBatchSize)
+	for i := range codes {
+		code, err := codec.Sign(signingKey, uint64(first)+uint64(i))
+		if err != nil {
+			return fmt.Errorf("failed to sign code: %w", err)
+		}
+		codes[i] = layout.Prefix() + code
+	}
+
+	reserved, err := reserveCodes(ctx, pool, codes)
+	if err != nil {
+		return err
+	}
+	g.pools[key] = reserved
+
+	return nil
+}
+
+// refillSequencedPool derives codes from a block of sequence numbers of the
+// layout's code space and reserves them like random codes. Codes already
+// stored, e.g. imported or vanity codes of the same spelling, are skipped
+// for good, as are blocked ones.
+func (g *codeGenerator) refillSequencedPool(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	layout *codeformat.Layout,
+) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := "sequenced:" + layout.Key()
+	if len(g.pools[key]) > 0 {
+		return nil
+	}
+
+	var (
+		secret []byte
+		first  int64
+	)
+	limit := int64(math.MaxInt64)
+	if size := layout.Size(); size.IsInt64() {
+		limit = size.Int64()
+	}
+	err := pool.QueryRow(ctx,
+		`UPDATE code_spaces
+		SET next_sequence = next_sequence + $2
+		WHERE format_key = $1 AND next_sequence < $3
+		RETURNING secret, next_sequence - $2`,
+		layout.Key(),
+		formatBatchSize,
+		limit,
+	).Scan(&secret, &first)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return codeformat.ErrSequenceExhausted
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reserve sequence numbers: %w", err)
+	}
+
+	sequencer := codeformat.NewSequencer(layout, secret)
+	codes := make([]string, 0, formatBatchSize)
+	for seq := first; seq < first+formatBatchSize && seq < limit; seq++ {
+		code, err := sequencer.Code(uint64(seq))
+		if err != nil {
+			return fmt.Errorf("failed to derive code: %w", err)
+		}
+		// Blocked sequence numbers are skipped for good
+		if g.blocklist.Blocks(code) {
+			continue
+		}
+		codes = append(codes, code)
+	}
+	reserved, err := reserveCodes(ctx, pool, codes)
+	if err != nil {
+		return err
+	}
+	g.pools[key] = reserved
+
+	return nil
+}
+
+// refillImportedPool claims imported codes of a campaign that no other
+// server holds. The claim is a lease: codes left in the pool of a server
+// that stopped are claimed again once it expires, so a pool is dropped
+// shortly before its lease runs out rather than issued from.
+func (g *codeGenerator) refillImportedPool(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	campaignID string,
+) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := "imported:" + campaignID
+	if len(g.pools[key]) > 0 &&
+		time.Until(g.leases[key]) > importedClaimMargin {
+		return nil
+	}
+
+	var leaseEnd time.Time
+	rows, err := pool.Query(ctx,
+		`UPDATE imported_codes
+		SET claimed_until = now() + make_interval(secs => $3)
+		WHERE code IN (
+			SELECT code FROM imported_codes
+			WHERE campaign_id = $1 AND claimed_at IS NULL
+			AND (claimed_until IS NULL OR claimed_until < now())
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING code, claimed_until`,
+		campaignID,
+		importedPoolSize,
+		importedClaimLease.Seconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to claim imported codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := make([]string, 0, importedPoolSize)
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code, &leaseEnd); err != nil {
+			return fmt.Errorf("failed to scan imported code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to claim imported codes: %w", err)
+	}
+	g.pools[key] = codes
+	g.leases[key] = leaseEnd
+
+	return nil
+}
+
+// reserveCodes inserts unissued codes and returns those that did not exist
+// yet. Codes are stored in their normalized form but returned as generated.
+func reserveCodes(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	codes []string,
+) ([]string, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
 
 	tx, err := pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer func() {
 		if tx != nil {
@@ -189,11 +591,17 @@ func (g *codeGenerator) refillPool(
 	}()
 
 	// Insert unissued codes into coupons table
-	placeholders := make([]string, len(codes))
-	args := make([]interface{}, len(codes))
-	for i, code := range codes {
-		placeholders[i] = fmt.Sprintf("($%d)", i+1)
-		args[i] = code
+	display := make(map[string]string, len(codes))
+	placeholders := make([]string, 0, len(codes))
+	args := make([]interface{}, 0, len(codes))
+	for _, code := range codes {
+		normalized := codeformat.Normalize(code)
+		if _, ok := display[normalized]; ok {
+			continue
+		}
+		display[normalized] = code
+		placeholders = append(placeholders, fmt.Sprintf("($%d)", len(args)+1))
+		args = append(args, normalized)
 	}
 
 	query := fmt.Sprintf(`
@@ -208,48 +616,168 @@ func (g *codeGenerator) refillPool(
 
 	rows, err := tx.Query(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to check reserved codes: %w", err)
+		return nil, fmt.Errorf("failed to check reserved codes: %w", err)
 	}
 	defer rows.Close()
 
-	// Add successfully reserved codes to the pool
+	// Collect the successfully reserved codes
+	reserved := make([]string, 0, len(codes))
 	for rows.Next() {
 		var code string
 		if err := rows.Scan(&code); err != nil {
-			return fmt.Errorf("failed to scan reserved code: %w", err)
+			return nil, fmt.Errorf("failed to scan reserved code: %w", err)
 		}
-		g.codePool = append(g.codePool, code)
+		reserved = append(reserved, display[code])
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 	tx = nil // Set tx to nil after successful commit
 
-	return nil
+	return reserved, nil
+}
+
+// refill makes sure the pool for a format has codes. Callers must not hold
+// g.mu.
+func (g *codeGenerator) refill(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	campaignID string,
+	format codeFormat,
+) error {
+	switch {
+	case format.kind == codesSigned:
+		return g.refillSignedPool(ctx, pool, campaignID, format)
+	case format.kind == codesSequenced:
+		return g.refillSequencedPool(ctx, pool, format.getLayout())
+	case format.kind == codesImported:
+		return g.refillImportedPool(ctx, pool, campaignID)
+	case format.poolKey(campaignID) == "":
+		return g.refillPool(ctx, pool)
+	default:
+		return g.refillFormatPool(ctx, pool, format.layout)
+	}
+}
+
+// take removes up to n codes from the pool for a format. Callers hold g.mu.
+func (g *codeGenerator) take(
+	campaignID string,
+	format codeFormat,
+	n int,
+) []string {
+	key := format.poolKey(campaignID)
+	if key == "" {
+		count := min(n, len(g.codePool))
+		codes := g.codePool[:count:count]
+		g.codePool = g.codePool[count:]
+		return codes
+	}
+
+	codePool := g.pools[key]
+	count := min(n, len(codePool))
+	if count == len(codePool) {
+		delete(g.pools, key)
+	} else {
+		g.pools[key] = codePool[count:]
+	}
+	return codePool[:count:count]
 }
 
 func (g *codeGenerator) generateCouponCode(
 	ctx context.Context,
 	pool *pgxpool.Pool,
 	campaignID string,
+	userID string,
+	format codeFormat,
 ) (string, error) {
-	if err := g.refillPool(ctx, pool); err != nil {
+	if err := g.refill(ctx, pool, campaignID, format); err != nil {
 		return "", err
 	}
 
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	code := g.codePool[0]
-	g.codePool = g.codePool[1:]
-	g.usedCoupons[code] = campaignID
+	codes := g.take(campaignID, format, 1)
+	if len(codes) == 0 {
+		return "", fmt.Errorf("failed to reserve coupon code")
+	}
+	code := codes[0]
+	g.usedCoupons[codeformat.Normalize(code)] = issuedCoupon{
+		campaignID: campaignID,
+		userID:     userID,
+		issuedAt:   time.Now(),
+		imported:   format.kind == codesImported,
+	}
 
 	return code, nil
 }
 
+// takeCodes removes n reserved codes from the pool without recording them
+// as issued. Callers are responsible for writing the issuance themselves,
+// and return the codes with returnCodes if they cannot.
+func (g *codeGenerator) takeCodes(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	campaignID string,
+	format codeFormat,
+	n int,
+) ([]string, error) {
+	codes := make([]string, 0, n)
+	for len(codes) < n {
+		if err := g.refill(ctx, pool, campaignID, format); err != nil {
+			g.returnCodes(campaignID, format, codes)
+			return nil, err
+		}
+
+		g.mu.Lock()
+		taken := g.take(campaignID, format, n-len(codes))
+		codes = append(codes, taken...)
+		g.mu.Unlock()
+
+		if len(taken) == 0 {
+			g.returnCodes(campaignID, format, codes)
+			return nil, fmt.Errorf("failed to reserve coupon codes")
+		}
+	}
+	return codes, nil
+}
+
+// returnCodes puts codes taken with takeCodes back at the front of the
+// pool for a format, for a caller that could not issue them.
+func (g *codeGenerator) returnCodes(
+	campaignID string,
+	format codeFormat,
+	codes []string,
+) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := format.poolKey(campaignID)
+	if key == "" {
+		g.codePool = append(slices.Clone(codes), g.codePool...)
+		return
+	}
+	g.pools[key] = append(slices.Clone(codes), g.pools[key]...)
+}
+
 func (g *codeGenerator) hasPendingCodes() bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	return len(g.usedCoupons) > 0
 }
+
+func (g *codeGenerator) isPending(code string) bool {
+	_, ok := g.pendingCoupon(code)
+	return ok
+}
+
+// pendingCoupon returns the issuance of a code that has not been written to
+// the database yet.
+func (g *codeGenerator) pendingCoupon(code string) (issuedCoupon, bool) {
+	code = codeformat.Normalize(code)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	coupon, ok := g.usedCoupons[code]
+	return coupon, ok
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"coupon-issuance/internal/codeformat"
 	"coupon-issuance/internal/database"
 	"testing"
 	"time"
@@ -19,10 +20,18 @@ func setupTestDB(t *testing.T) (*pgxpool.Pool, string) {
 	require.NoError(t, err)
 
 	// clean up database
+	_, err = pool.Exec(ctx, "DELETE FROM jobs")
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, "DELETE FROM coupon_redemptions")
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, "DELETE FROM coupon_events")
+	require.NoError(t, err)
 	_, err = pool.Exec(ctx, "DELETE FROM coupons")
 	require.NoError(t, err)
 	_, err = pool.Exec(ctx, "DELETE FROM campaigns")
 	require.NoError(t, err)
+	_, err = pool.Exec(ctx, "DELETE FROM code_spaces")
+	require.NoError(t, err)
 
 	campaignID := "00000000-0000-0000-0000-000000000000"
 	_, err = pool.Exec(ctx,
@@ -46,7 +55,9 @@ func TestCodeGenerator_GenerateCouponCode(t *testing.T) {
 	campaignID := "00000000-0000-0000-0000-000000000000"
 
 	// Test generating a single code
-	code, err := generator.generateCouponCode(ctx, pool, campaignID)
+	code, err := generator.generateCouponCode(
+		ctx, pool, campaignID, "", codeFormat{},
+	)
 	require.NoError(t, err)
 	assert.NotEmpty(t, code)
 	assert.Equal(t, len([]rune(code)), 10)
@@ -54,7 +65,9 @@ func TestCodeGenerator_GenerateCouponCode(t *testing.T) {
 	// Test code uniqueness
 	codes := make(map[string]bool)
 	for i := 0; i < 100; i++ {
-		code, err := generator.generateCouponCode(ctx, pool, campaignID)
+		code, err := generator.generateCouponCode(
+			ctx, pool, campaignID, "", codeFormat{},
+		)
 		require.NoError(t, err)
 		assert.False(t, codes[code], "Generated duplicate code: %s", code)
 		codes[code] = true
@@ -72,7 +85,9 @@ func TestCodeGenerator_ConcurrentAccess(t *testing.T) {
 	// Generate codes concurrently
 	for i := 0; i < 1000; i++ {
 		go func() {
-			code, err := generator.generateCouponCode(ctx, pool, campaignID)
+			code, err := generator.generateCouponCode(
+				ctx, pool, campaignID, "", codeFormat{},
+			)
 			if err != nil {
 				errors <- err
 				return
@@ -96,6 +111,61 @@ func TestCodeGenerator_ConcurrentAccess(t *testing.T) {
 	}
 }
 
+func TestCodeGenerator_Collisions(t *testing.T) {
+	pool, campaignID := setupTestDB(t)
+	ctx := context.Background()
+
+	// Two servers drawing the same codes never hand out the same one
+	first := newCodeGeneratorWithSource(codeformat.NewSeededSource(1))
+	second := newCodeGeneratorWithSource(codeformat.NewSeededSource(1))
+
+	code, err := first.generateCouponCode(
+		ctx, pool, campaignID, "", codeFormat{},
+	)
+	require.NoError(t, err)
+	reserved := append([]string{code}, first.codePool...)
+
+	// Every code of the second server's first batch is already reserved
+	_, err = second.generateCouponCode(
+		ctx, pool, campaignID, "", codeFormat{},
+	)
+	require.Error(t, err)
+
+	code, err = second.generateCouponCode(
+		ctx, pool, campaignID, "", codeFormat{},
+	)
+	require.NoError(t, err)
+	assert.NotContains(t, reserved, code)
+}
+
+func TestCodeGenerator_Blocklist(t *testing.T) {
+	generator := newCodeGeneratorWithSource(codeformat.NewSeededSource(1))
+	generator.blocklist = codeformat.NewBlocklist([]string{"가", "99"})
+
+	codes := generator.generateBatch(defaultLayout, 20000)
+	// About 2% of codes contain 99, and a few 가
+	assert.Greater(t, len(codes), 19000)
+	assert.Less(t, len(codes), 20000)
+	for _, code := range codes {
+		require.NotContains(t, code, "가")
+		require.NotContains(t, code, "99")
+	}
+}
+
+func TestCodeGenerator_ReturnCodes(t *testing.T) {
+	generator := newCodeGenerator()
+	format := codeFormat{layout: defaultLayout}
+	generator.codePool = []string{"A", "B", "C"}
+
+	generator.mu.Lock()
+	taken := generator.take("", format, 2)
+	generator.mu.Unlock()
+	require.Equal(t, []string{"A", "B"}, taken)
+
+	generator.returnCodes("", format, taken)
+	assert.Equal(t, []string{"A", "B", "C"}, generator.codePool)
+}
+
 func TestCodeGenerator_WriteIssuedCodes(t *testing.T) {
 	pool, campaignID := setupTestDB(t)
 	generator := newCodeGenerator()
@@ -103,7 +173,9 @@ func TestCodeGenerator_WriteIssuedCodes(t *testing.T) {
 
 	codes := make([]string, 10)
 	for i := 0; i < 10; i++ {
-		code, err := generator.generateCouponCode(ctx, pool, campaignID)
+		code, err := generator.generateCouponCode(
+			ctx, pool, campaignID, "", codeFormat{},
+		)
 		require.NoError(t, err)
 		codes[i] = code
 	}
@@ -139,7 +211,9 @@ func TestCodeGenerator_RefillPool(t *testing.T) {
 	// Test pool refill after using some codes
 	for i := 0; i < len(generator.codePool); i++ {
 		go func() {
-			_, err := generator.generateCouponCode(ctx, pool, campaignID)
+			_, err := generator.generateCouponCode(
+				ctx, pool, campaignID, "", codeFormat{},
+			)
 			require.NoError(t, err)
 		}()
 	}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+	"coupon-issuance/internal/codeimage"
+	"coupon-issuance/internal/utils"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultImageURLTTL = time.Hour
+	maxImageURLTTL     = 24 * time.Hour
+)
+
+var (
+	barcodeSymbologies = map[coupon.BarcodeSymbology]codeimage.Symbology{
+		coupon.BarcodeSymbology_BARCODE_SYMBOLOGY_UNSPECIFIED: codeimage.
+			SymbologyQR,
+		coupon.BarcodeSymbology_BARCODE_SYMBOLOGY_CODE128: codeimage.
+			SymbologyCode128,
+		coupon.BarcodeSymbology_BARCODE_SYMBOLOGY_EAN13: codeimage.
+			SymbologyEAN13,
+	}
+	imageFormats = map[coupon.ImageFormat]codeimage.Format{
+		coupon.ImageFormat_IMAGE_FORMAT_UNSPECIFIED: codeimage.FormatPNG,
+		coupon.ImageFormat_IMAGE_FORMAT_SVG:         codeimage.FormatSVG,
+	}
+)
+
+// imageSignerFromEnv returns the signer of coupon image URLs. Every server
+// must sign with the same IMAGE_URL_SECRET, so a URL keeps working across
+// restarts and on any server.
+func imageSignerFromEnv() (*codeimage.Signer, error) {
+	secret := []byte(utils.GetEnv("IMAGE_URL_SECRET", ""))
+	if len(secret) < signingKeySize {
+		return nil, fmt.Errorf(
+			"IMAGE_URL_SECRET must be at least %d bytes", signingKeySize,
+		)
+	}
+	return codeimage.NewSigner(secret), nil
+}
+
+// ImageHandler serves the coupon images of URLs from CreateCouponImageUrl.
+// It is mounted at codeimage.Path.
+func (s *CouponService) ImageHandler() http.Handler {
+	return codeimage.NewHandler(s.imageSigner)
+}
+
+// CreateCouponImageUrl returns a short-lived signed URL of a QR code or
+// barcode of a coupon. Only codes that exist can be rendered, so the image
+// endpoint cannot be used to probe for codes.
+func (s *CouponService) CreateCouponImageUrl(
+	ctx context.Context,
+	req *connect.Request[coupon.CreateCouponImageUrlRequest],
+) (*connect.Response[coupon.CreateCouponImageUrlResponse], error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	if code == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code cannot be empty"),
+		)
+	}
+	symbology, ok := barcodeSymbologies[req.Msg.Symbology]
+	if !ok {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("unknown symbology %v", req.Msg.Symbology),
+		)
+	}
+	format, ok := imageFormats[req.Msg.Format]
+	if !ok {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("unknown image format %v", req.Msg.Format),
+		)
+	}
+	ttl := defaultImageURLTTL
+	if req.Msg.TtlSeconds != 0 {
+		ttl = time.Duration(req.Msg.TtlSeconds) * time.Second
+	}
+	if ttl <= 0 || ttl > maxImageURLTTL {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("ttl_seconds must be between 1 and %d",
+				int(maxImageURLTTL.Seconds())),
+		)
+	}
+
+	image := codeimage.Request{
+		Code:      code,
+		Symbology: symbology,
+		Format:    format,
+		Text:      strings.TrimSpace(req.Msg.Text),
+	}
+	if err := image.Validate(); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("cannot render code: %v", err),
+		)
+	}
+
+	if err := s.flushPendingCoupon(ctx, code); err != nil {
+		return nil, err
+	}
+
+	var owner *string
+	err := s.pool.QueryRow(ctx,
+		`SELECT user_id FROM coupons WHERE code = $1`,
+		code,
+	).Scan(&owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("coupon not found"),
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon: %v", err),
+		)
+	}
+	if owner != nil && *owner != strings.TrimSpace(req.Msg.UserId) {
+		return nil, connect.NewError(
+			connect.CodePermissionDenied,
+			fmt.Errorf("coupon belongs to another user"),
+		)
+	}
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	return connect.NewResponse(&coupon.CreateCouponImageUrlResponse{
+		Url:       s.publicBaseURL + s.imageSigner.URL(image, expiresAt),
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	}), nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeimage"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponService_CreateCouponImageUrl(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+	images := httptest.NewServer(service.ImageHandler())
+	t.Cleanup(images.Close)
+	service.publicBaseURL = images.URL
+
+	_, code := issueTestCoupon(t, service, "user-1")
+
+	createURL := func(
+		req *coupon.CreateCouponImageUrlRequest,
+	) (*coupon.CreateCouponImageUrlResponse, error) {
+		resp, err := service.CreateCouponImageUrl(ctx, connect.NewRequest(req))
+		if err != nil {
+			return nil, err
+		}
+		return resp.Msg, nil
+	}
+
+	t.Run("serves the owner's coupon", func(t *testing.T) {
+		resp, err := createURL(&coupon.CreateCouponImageUrlRequest{
+			Code:   code,
+			UserId: "user-1",
+			Text:   "Spring Sale",
+		})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(resp.Url, images.URL+codeimage.Path))
+		expiresAt, err := time.Parse(time.RFC3339, resp.ExpiresAt)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+		image, err := http.Get(resp.Url)
+		require.NoError(t, err)
+		defer image.Body.Close()
+		assert.Equal(t, http.StatusOK, image.StatusCode)
+		_, err = png.Decode(image.Body)
+		require.NoError(t, err)
+	})
+
+	t.Run("signed URLs cannot show other codes", func(t *testing.T) {
+		resp, err := createURL(&coupon.CreateCouponImageUrlRequest{
+			Code:   code,
+			UserId: "user-1",
+			Format: coupon.ImageFormat_IMAGE_FORMAT_SVG,
+		})
+		require.NoError(t, err)
+		other := strings.Replace(resp.Url, "code=", "code=X", 1)
+
+		image, err := http.Get(other)
+		require.NoError(t, err)
+		defer image.Body.Close()
+		assert.Equal(t, http.StatusForbidden, image.StatusCode)
+	})
+
+	t.Run("rejects other users and unknown codes", func(t *testing.T) {
+		_, err := createURL(&coupon.CreateCouponImageUrlRequest{
+			Code:   code,
+			UserId: "user-2",
+		})
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+
+		_, err = createURL(&coupon.CreateCouponImageUrlRequest{
+			Code:   "NOSUCHCODE",
+			UserId: "user-1",
+		})
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+
+	t.Run("rejects codes the symbology cannot hold", func(t *testing.T) {
+		_, err := createURL(&coupon.CreateCouponImageUrlRequest{
+			Code:      code,
+			UserId:    "user-1",
+			Symbology: coupon.BarcodeSymbology_BARCODE_SYMBOLOGY_EAN13,
+		})
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+
+		_, err = createURL(&coupon.CreateCouponImageUrlRequest{
+			Code:       code,
+			UserId:     "user-1",
+			TtlSeconds: int32((25 * time.Hour).Seconds()),
+		})
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type couponState string
+
+const (
+	couponAvailable couponState = "available"
+	couponIssued    couponState = "issued"
+	couponRedeemed  couponState = "redeemed"
+	couponVoid      couponState = "void"
+	couponExpired   couponState = "expired"
+)
+
+// couponTransitions lists the states each coupon state may move to. State
+// changes are applied with an UPDATE conditioned on the current state being
+// one of statesTo(next), so of two racing transitions only one can succeed.
+// Redeemed coupons return to issued when their redemption is reversed.
+var couponTransitions = map[couponState][]couponState{
+	couponAvailable: {couponIssued},
+	couponIssued:    {couponRedeemed, couponVoid, couponExpired},
+	couponRedeemed:  {couponIssued},
+}
+
+func (s couponState) valid() bool {
+	switch s {
+	case couponAvailable,
+		couponIssued,
+		couponRedeemed,
+		couponVoid,
+		couponExpired:
+		return true
+	}
+	return false
+}
+
+func (s couponState) canTransitionTo(next couponState) bool {
+	return slices.Contains(couponTransitions[s], next)
+}
+
+// statesTo returns the states that may move to next, as the condition on
+// the current state of the UPDATE applying the transition.
+func statesTo(next couponState) []string {
+	var states []string
+	for from, allowed := range couponTransitions {
+		if slices.Contains(allowed, next) {
+			states = append(states, string(from))
+		}
+	}
+	slices.Sort(states)
+	return states
+}
+
+// checkTransition fails with transitionError unless a coupon in the current
+// state may move to next.
+func checkTransition(current, next couponState) error {
+	if current.canTransitionTo(next) {
+		return nil
+	}
+	return transitionError(current, next)
+}
+
+// transitionError explains why a coupon in the given state cannot move to
+// the next one.
+func transitionError(current, next couponState) error {
+	switch current {
+	case couponAvailable:
+		return connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("coupon has not been issued"),
+		)
+	case couponRedeemed:
+		return connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("coupon has already been redeemed"),
+		)
+	case couponVoid:
+		return connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("coupon has been voided"),
+		)
+	case couponExpired:
+		return connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("coupon has expired"),
+		)
+	}
+	return connect.NewError(
+		connect.CodeFailedPrecondition,
+		fmt.Errorf("coupon cannot move from %s to %s", current, next),
+	)
+}
+
+const couponEventIssued = "issued"
+
+// couponEvent is an entry in a coupon's audit trail. Details holds data
+// specific to the event and is stored as JSON.
+type couponEvent struct {
+	couponID  pgtype.UUID
+	event     string
+	fromState couponState
+	toState   couponState
+	reason    string
+	details   map[string]any
+}
+
+func recordCouponEvent(ctx context.Context, tx pgx.Tx, e couponEvent) error {
+	details := e.details
+	if details == nil {
+		details = map[string]any{}
+	}
+	_, err := tx.Exec(ctx,
+		`INSERT INTO coupon_events
+			(coupon_id, event, from_state, to_state, reason, details)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)`,
+		e.couponID,
+		e.event,
+		string(e.fromState),
+		string(e.toState),
+		e.reason,
+		details,
+	)
+	return err
+}
+
+// flushUserCoupons writes the coupons issued to a user that have not been
+// flushed by the coupon code writer yet, so that queries by owner see them.
+func (s *CouponService) flushUserCoupons(
+	ctx context.Context,
+	userID string,
+) error {
+	if err := s.codeGen.writeUserCodes(ctx, s.pool, userID); err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to write issued codes: %v", err),
+		)
+	}
+	return nil
+}
+
+// recordIssuedEvents starts the audit trail of coupons that have just been
+// written as issued, dated when they were issued.
+func recordIssuedEvents(ctx context.Context, tx pgx.Tx, codes []string) error {
+	if len(codes) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx,
+		`INSERT INTO coupon_events
+			(coupon_id, event, from_state, to_state, details, created_at)
+		SELECT id, $2, 'available', 'issued',
+			jsonb_strip_nulls(jsonb_build_object(
+				'user_id', user_id,
+				'pre_issued', NULLIF(pre_issued, false)
+			)),
+			issued_at
+		FROM coupons WHERE code = ANY($1)`,
+		codes,
+		couponEventIssued,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record coupon events: %w", err)
+	}
+	return nil
+}
+
+// flushPendingCoupon writes the issued codes to the database if the given
+// code has been issued but not yet flushed by the coupon code writer, so
+// that lookups see its issued state.
+func (s *CouponService) flushPendingCoupon(
+	ctx context.Context,
+	code string,
+) error {
+	if !s.codeGen.isPending(code) {
+		return nil
+	}
+	if err := s.codeGen.writeIssuedCodes(ctx, s.pool); err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to write issued codes: %v", err),
+		)
+	}
+	return nil
+}
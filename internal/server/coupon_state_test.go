@@ -0,0 +1,42 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCouponState_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from    couponState
+		to      couponState
+		allowed bool
+	}{
+		{couponAvailable, couponIssued, true},
+		{couponAvailable, couponRedeemed, false},
+		{couponIssued, couponRedeemed, true},
+		{couponIssued, couponVoid, true},
+		{couponIssued, couponExpired, true},
+		{couponIssued, couponAvailable, false},
+		{couponRedeemed, couponRedeemed, false},
+		{couponRedeemed, couponVoid, false},
+		{couponRedeemed, couponIssued, true},
+		{couponVoid, couponIssued, false},
+		{couponExpired, couponRedeemed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.allowed, tt.from.canTransitionTo(tt.to))
+		})
+	}
+}
+
+func TestStatesTo(t *testing.T) {
+	assert.Equal(t, []string{"issued"}, statesTo(couponRedeemed))
+	assert.Equal(t, []string{"issued"}, statesTo(couponVoid))
+	assert.Equal(t, []string{"issued"}, statesTo(couponExpired))
+	assert.Equal(t, []string{"available", "redeemed"}, statesTo(couponIssued))
+	assert.Empty(t, statesTo(couponAvailable))
+	assert.Equal(t, []string{"issued", "redeemed"}, reversibleStates())
+}
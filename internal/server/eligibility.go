@@ -0,0 +1,141 @@
+package server
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/eligibility"
+
+	"connectrpc.com/connect"
+)
+
+var denialReasons = map[eligibility.Reason]coupon.EligibilityDenialReason{
+	eligibility.ReasonRuleNotSatisfied: coupon.
+		EligibilityDenialReason_ELIGIBILITY_DENIAL_REASON_RULE_NOT_SATISFIED,
+	eligibility.ReasonMissingAttribute: coupon.
+		EligibilityDenialReason_ELIGIBILITY_DENIAL_REASON_MISSING_ATTRIBUTE,
+	eligibility.ReasonInvalidAttribute: coupon.
+		EligibilityDenialReason_ELIGIBILITY_DENIAL_REASON_INVALID_ATTRIBUTE,
+}
+
+// maxCachedRules bounds the eligibility cache. Rules of campaigns that are
+// no longer issued are pushed out by the ones in use.
+const maxCachedRules = 1024
+
+// eligibilityCache holds the compiled eligibility rules of campaigns, so a
+// rule is compiled once rather than on every issuance. The least recently
+// used rule is evicted once the cache holds more than max rules.
+type eligibilityCache struct {
+	mu       sync.Mutex
+	max      int
+	order    *list.List // of *cachedRule, most recently used first
+	programs map[string]*list.Element
+}
+
+type cachedRule struct {
+	campaignID string
+	program    *eligibility.Program
+}
+
+func newEligibilityCache() *eligibilityCache {
+	return &eligibilityCache{
+		max:      maxCachedRules,
+		order:    list.New(),
+		programs: make(map[string]*list.Element),
+	}
+}
+
+func (c *eligibilityCache) add(campaignID string, p *eligibility.Program) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.programs[campaignID]; ok {
+		e.Value.(*cachedRule).program = p
+		c.order.MoveToFront(e)
+		return
+	}
+	c.programs[campaignID] = c.order.PushFront(&cachedRule{
+		campaignID: campaignID,
+		program:    p,
+	})
+	for c.order.Len() > c.max {
+		oldest := c.order.Remove(c.order.Back()).(*cachedRule)
+		delete(c.programs, oldest.campaignID)
+	}
+}
+
+func (c *eligibilityCache) get(campaignID string) *eligibility.Program {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.programs[campaignID]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*cachedRule).program
+}
+
+// program returns the compiled rule of a campaign. Rules of campaigns
+// created by another server, before a restart, or evicted since are
+// compiled on first use.
+func (c *eligibilityCache) program(
+	campaignID string,
+	expr string,
+) (*eligibility.Program, error) {
+	if p := c.get(campaignID); p != nil && p.Source() == expr {
+		return p, nil
+	}
+
+	p, err := eligibility.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	c.add(campaignID, p)
+	return p, nil
+}
+
+// checkEligibility evaluates a campaign's eligibility expression against the
+// request attributes. It returns a PermissionDenied error carrying an
+// EligibilityDenial detail when the request is not eligible.
+func (s *CouponService) checkEligibility(
+	campaignID string,
+	expr string,
+	attrs *coupon.UserAttributes,
+) error {
+	if expr == "" {
+		return nil
+	}
+
+	program, err := s.eligibility.program(campaignID, expr)
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("invalid campaign eligibility rule: %v", err),
+		)
+	}
+
+	decision := program.Evaluate(eligibility.Attributes{
+		Tier:       attrs.GetTier(),
+		Region:     attrs.GetRegion(),
+		SignupDate: attrs.GetSignupDate(),
+		AppVersion: attrs.GetAppVersion(),
+	})
+	if decision.Allowed {
+		return nil
+	}
+
+	connectErr := connect.NewError(
+		connect.CodePermissionDenied,
+		fmt.Errorf("not eligible for campaign: %s", decision.Clause),
+	)
+	detail, err := connect.NewErrorDetail(&coupon.EligibilityDenial{
+		Reason:    denialReasons[decision.Reason],
+		Clause:    decision.Clause,
+		Attribute: decision.Attribute,
+	})
+	if err == nil {
+		connectErr.AddDetail(detail)
+	}
+	return connectErr
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEligibilityCache(t *testing.T) {
+	cache := newEligibilityCache()
+
+	first, err := cache.program("campaign", `user.tier == "gold"`)
+	require.NoError(t, err)
+	again, err := cache.program("campaign", `user.tier == "gold"`)
+	require.NoError(t, err)
+	assert.Same(t, first, again)
+
+	// A changed rule is compiled again
+	changed, err := cache.program("campaign", `user.tier == "silver"`)
+	require.NoError(t, err)
+	assert.NotSame(t, first, changed)
+	assert.Equal(t, `user.tier == "silver"`, changed.Source())
+
+	_, err = cache.program("other", `user.tier ==`)
+	assert.Error(t, err)
+
+	// The least recently used rule is evicted
+	cache.max = 2
+	other, err := cache.program("other", `user.region == "KR"`)
+	require.NoError(t, err)
+	again, err = cache.program("campaign", `user.tier == "silver"`)
+	require.NoError(t, err)
+	assert.Same(t, changed, again)
+	_, err = cache.program("third", `user.region == "JP"`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cache.order.Len())
+	assert.Nil(t, cache.get("other"))
+	again, err = cache.program("other", `user.region == "KR"`)
+	require.NoError(t, err)
+	assert.NotSame(t, other, again)
+}
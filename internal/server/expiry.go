@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+const expiryBatchSize = 1000
+
+// campaignValidity is how long coupons issued by a campaign stay valid.
+// Either field may be nil; a coupon with neither never expires.
+type campaignValidity struct {
+	validUntil      *time.Time
+	validitySeconds *int64
+}
+
+func parseCampaignValidity(
+	validUntil string,
+	duration string,
+	startTime time.Time,
+) (campaignValidity, error) {
+	var v campaignValidity
+
+	if validUntil = strings.TrimSpace(validUntil); validUntil != "" {
+		t, err := time.Parse(time.RFC3339, validUntil)
+		if err != nil {
+			return v, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("invalid valid_until format: %v", err),
+			)
+		}
+		if !t.After(startTime) {
+			return v, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("valid_until must be after start_time"),
+			)
+		}
+		v.validUntil = &t
+	}
+
+	if duration = strings.TrimSpace(duration); duration != "" {
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			return v, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("invalid validity_duration: %v", err),
+			)
+		}
+		if d < time.Second {
+			return v, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("validity_duration must be at least one second"),
+			)
+		}
+		// Validity is stored in whole seconds
+		if d%time.Second != 0 {
+			return v, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("validity_duration must be whole seconds"),
+			)
+		}
+		seconds := int64(d / time.Second)
+		v.validitySeconds = &seconds
+	}
+
+	return v, nil
+}
+
+// expiresAt returns when a coupon issued at the given time expires, or nil
+// if it does not.
+func (v campaignValidity) expiresAt(issuedAt time.Time) *time.Time {
+	var expiry *time.Time
+	if v.validitySeconds != nil {
+		t := issuedAt.Add(time.Duration(*v.validitySeconds) * time.Second)
+		expiry = &t
+	}
+	if v.validUntil != nil && (expiry == nil || v.validUntil.Before(*expiry)) {
+		expiry = v.validUntil
+	}
+	return expiry
+}
+
+func (v campaignValidity) durationString() string {
+	if v.validitySeconds == nil {
+		return ""
+	}
+	return (time.Duration(*v.validitySeconds) * time.Second).String()
+}
+
+// isExpired reports whether an expiry has passed. Coupons are treated as
+// expired from this moment even if the sweeper has not updated them yet.
+func isExpired(expiresAt *time.Time) bool {
+	return expiresAt != nil && !expiresAt.After(time.Now())
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// startCouponExpiryWorker moves issued coupons past their expiry to the
+// expired state in batches. Validation and redemption also check expires_at
+// directly, so coupons are rejected even before the sweep reaches them.
+func (s *CouponService) startCouponExpiryWorker(ctx context.Context) {
+	serverCtx := s.context
+	interval := 10 * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.backgroundWorkersStopped <- struct{}{}
+			return
+		case <-ticker.C:
+			if err := s.expireCoupons(serverCtx, ctx); err != nil {
+				log.Printf("Failed to expire coupons: %v", err)
+			}
+		}
+	}
+}
+
+func (s *CouponService) expireCoupons(
+	ctx context.Context,
+	workerCtx context.Context,
+) error {
+	for workerCtx.Err() == nil {
+		tag, err := s.pool.Exec(ctx,
+			`UPDATE coupons SET state = 'expired'
+			WHERE id IN (
+				SELECT id FROM coupons
+				WHERE state = ANY($2::coupon_state[])
+				AND expires_at <= now()
+				ORDER BY expires_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)`,
+			expiryBatchSize,
+			statesTo(couponExpired),
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() < expiryBatchSize {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCampaignValidity_ExpiresAt(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	issuedAt := start.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		validUntil string
+		duration   string
+		expected   *time.Time
+		expectErr  bool
+	}{
+		{
+			name: "no validity",
+		},
+		{
+			name:       "absolute",
+			validUntil: "2026-02-01T00:00:00Z",
+			expected:   ptr(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:     "relative",
+			duration: "24h",
+			expected: ptr(issuedAt.Add(24 * time.Hour)),
+		},
+		{
+			name:       "earliest of both",
+			validUntil: "2026-01-01T12:00:00Z",
+			duration:   "24h",
+			expected:   ptr(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:       "valid_until before start",
+			validUntil: "2025-12-31T00:00:00Z",
+			expectErr:  true,
+		},
+		{
+			name:      "invalid duration",
+			duration:  "a week",
+			expectErr: true,
+		},
+		{
+			name:      "sub-second duration",
+			duration:  "10ms",
+			expectErr: true,
+		},
+		{
+			name:      "fractional duration",
+			duration:  "1500ms",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validity, err := parseCampaignValidity(
+				tt.validUntil,
+				tt.duration,
+				start,
+			)
+			if tt.expectErr {
+				require.Error(t, err)
+				assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+
+			expiresAt := validity.expiresAt(issuedAt)
+			if tt.expected == nil {
+				assert.Nil(t, expiresAt)
+				return
+			}
+			require.NotNil(t, expiresAt)
+			assert.True(t, tt.expected.Equal(*expiresAt))
+		})
+	}
+}
+
+func TestCouponService_ExpireCoupons(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	_, code := issueTestCoupon(t, service, "user-1")
+	require.NoError(t, service.codeGen.writeIssuedCodes(ctx, service.pool))
+
+	_, err := service.pool.Exec(ctx,
+		`UPDATE coupons SET expires_at = now() - interval '1 minute'
+		WHERE code = $1`,
+		code,
+	)
+	require.NoError(t, err)
+
+	t.Run("rejected before the sweep", func(t *testing.T) {
+		_, err := service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "user-1",
+				OrderRef: "order-1",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		resp, err := service.ValidateCoupon(
+			ctx,
+			connect.NewRequest(&coupon.ValidateCouponRequest{Code: code}),
+		)
+		require.NoError(t, err)
+		assert.False(t, resp.Msg.Valid)
+		assert.Equal(t,
+			coupon.CouponInvalidReason_COUPON_INVALID_REASON_EXPIRED,
+			resp.Msg.Reason,
+		)
+	})
+
+	require.NoError(t, service.expireCoupons(ctx, ctx))
+
+	var state string
+	err = service.pool.QueryRow(ctx,
+		"SELECT state FROM coupons WHERE code = $1",
+		code,
+	).Scan(&state)
+	require.NoError(t, err)
+	assert.Equal(t, "expired", state)
+}
+
+func TestCouponService_IssueAfterValidUntil(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	campaignID, _ := issueTestCoupon(t, service, "user-1")
+	_, err := service.pool.Exec(ctx,
+		`UPDATE campaigns SET valid_until = now() - interval '1 minute'
+		WHERE id = $1`,
+		campaignID,
+	)
+	require.NoError(t, err)
+
+	_, err = service.IssueCoupon(
+		ctx,
+		connect.NewRequest(&coupon.IssueCouponRequest{
+			CampaignId: campaignID,
+			UserId:     "user-2",
+		}),
+	)
+	require.Error(t, err)
+	assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
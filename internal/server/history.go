@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type (
+	GetCouponHistoryReq  = connect.Request[coupon.GetCouponHistoryRequest]
+	GetCouponHistoryResp = connect.Response[coupon.GetCouponHistoryResponse]
+)
+
+// GetCouponHistory returns a coupon's audit trail: issuance, revocations,
+// reversals and changes of ownership.
+func (s *CouponService) GetCouponHistory(
+	ctx context.Context,
+	req *GetCouponHistoryReq,
+) (*GetCouponHistoryResp, error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	if code == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code cannot be empty"),
+		)
+	}
+
+	if err := s.flushPendingCoupon(ctx, code); err != nil {
+		return nil, err
+	}
+
+	var couponID pgtype.UUID
+	err := s.pool.QueryRow(ctx,
+		`SELECT id FROM coupons WHERE code = $1`,
+		code,
+	).Scan(&couponID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("coupon not found"),
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon: %v", err),
+		)
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT event, from_state, to_state, reason, details::text,
+			created_at
+		FROM coupon_events
+		WHERE coupon_id = $1
+		ORDER BY created_at, id`,
+		couponID,
+	)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon events: %v", err),
+		)
+	}
+	defer rows.Close()
+
+	resp := &coupon.GetCouponHistoryResponse{Code: code}
+	for rows.Next() {
+		var (
+			event     string
+			fromState *string
+			toState   *string
+			reason    *string
+			details   string
+			createdAt time.Time
+		)
+		err := rows.Scan(
+			&event,
+			&fromState,
+			&toState,
+			&reason,
+			&details,
+			&createdAt,
+		)
+		if err != nil {
+			return nil, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to scan coupon event: %v", err),
+			)
+		}
+		resp.Events = append(resp.Events, &coupon.CouponEvent{
+			Event:     event,
+			FromState: derefString(fromState),
+			ToState:   derefString(toState),
+			Reason:    derefString(reason),
+			Details:   details,
+			CreatedAt: createdAt.Format(time.RFC3339),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("error iterating coupon events: %v", err),
+		)
+	}
+
+	return connect.NewResponse(resp), nil
+}
@@ -0,0 +1,378 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	importBatchSize = 5000
+	// importedPoolSize is how many imported codes a server takes at a time.
+	// It is small since, unlike other codes, the supply is fixed and codes
+	// held by one server cannot be issued by another.
+	importedPoolSize = 10
+	// A server's claim on imported codes expires after importedClaimLease.
+	// Its pool is dropped importedClaimMargin before that, leaving time to
+	// write the codes it issued.
+	importedClaimLease  = 10 * time.Minute
+	importedClaimMargin = time.Minute
+)
+
+type (
+	ImportCodesStream = connect.ClientStream[coupon.ImportCodesRequest]
+	ImportCodesResp   = connect.Response[coupon.ImportCodesResponse]
+)
+
+// ImportCodes adds a partner's codes to a campaign with imported codes.
+// Codes that already exist, in any campaign, are skipped. The import is
+// all or nothing: an invalid code fails the whole call. Codes are normalized
+// first, so codes differing only in their form count as duplicates.
+func (s *CouponService) ImportCodes(
+	ctx context.Context,
+	stream *ImportCodesStream,
+) (*ImportCodesResp, error) {
+	if !stream.Receive() {
+		if err := stream.Err(); err != nil {
+			return nil, err
+		}
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("request stream is empty"),
+		)
+	}
+	campaignID := stream.Msg().CampaignId
+	listFormat := stream.Msg().Format
+	if _, ok := coupon.CodeListFormat_name[int32(listFormat)]; !ok {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("unknown code list format %v", listFormat),
+		)
+	}
+
+	// The upload is staged in a temporary table first, so the campaign is
+	// only locked while the staged codes are moved in, however slowly the
+	// client streams
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to begin transaction: %v", err),
+		)
+	}
+	defer func() {
+		if tx != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	checkCampaign := func(lock string) error {
+		var importedCodes bool
+		err := tx.QueryRow(ctx,
+			`SELECT imported_codes FROM campaigns WHERE id = $1 `+lock,
+			campaignID,
+		).Scan(&importedCodes)
+		if err != nil {
+			return connect.NewError(
+				connect.CodeNotFound,
+				fmt.Errorf("campaign not found: %v", err),
+			)
+		}
+		if !importedCodes {
+			return connect.NewError(
+				connect.CodeFailedPrecondition,
+				fmt.Errorf("campaign does not use imported codes"),
+			)
+		}
+		return nil
+	}
+	if err := checkCampaign(""); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`CREATE TEMPORARY TABLE import_staging (code TEXT PRIMARY KEY)
+		ON COMMIT DROP`,
+	)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to stage codes: %v", err),
+		)
+	}
+
+	var (
+		total int
+		batch = make([]string, 0, importBatchSize)
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		// Codes repeated in the list are staged once
+		_, err := tx.Exec(ctx,
+			`INSERT INTO import_staging (code)
+			SELECT unnest($1::text[])
+			ON CONFLICT (code) DO NOTHING`,
+			batch,
+		)
+		if err != nil {
+			return connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to stage codes: %v", err),
+			)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	r := &importReader{stream: stream, data: stream.Msg().Data}
+	err = readCodeList(r, listFormat, func(line int, code string) error {
+		code = codeformat.Normalize(code)
+		if code == "" {
+			return connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("line %d: code has only separators", line),
+			)
+		}
+		if err := validateCustomCode(code); err != nil {
+			return connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("line %d: %v", line, err),
+			)
+		}
+		total++
+		batch = append(batch, code)
+		if len(batch) >= importBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if total == 0 {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("no codes provided"),
+		)
+	}
+
+	// Imports into the same campaign are serialized
+	if err := checkCampaign("FOR UPDATE"); err != nil {
+		return nil, err
+	}
+
+	// Codes that already exist, in any campaign, are skipped by the
+	// conflict on coupons
+	tag, err := tx.Exec(ctx,
+		`WITH inserted AS (
+			INSERT INTO coupons (code)
+			SELECT code FROM import_staging
+			ON CONFLICT (code) DO NOTHING
+			RETURNING code
+		)
+		INSERT INTO imported_codes (code, campaign_id)
+		SELECT code, $1 FROM inserted`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to store codes: %v", err),
+		)
+	}
+	imported := tag.RowsAffected()
+
+	var couponLimit int32
+	err = tx.QueryRow(ctx,
+		`UPDATE campaigns
+		SET coupon_limit = coupon_limit + $2,
+			status = CASE status WHEN 'finished' THEN 'active' ELSE status END
+		WHERE id = $1
+		RETURNING coupon_limit`,
+		campaignID,
+		imported,
+	).Scan(&couponLimit)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to update coupon limit: %v", err),
+		)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to commit import: %v", err),
+		)
+	}
+	tx = nil // Set tx to nil after successful commit
+
+	// The counter is only incremented once the codes can be claimed, so
+	// issuance never counts codes it cannot see yet
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, campaignID)
+	if err := s.redis.IncrBy(ctx, counterKey, imported).Err(); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("codes were imported but cannot be issued yet: %v", err),
+		)
+	}
+
+	return connect.NewResponse(&coupon.ImportCodesResponse{
+		Imported:    int32(imported),
+		Duplicates:  int32(int64(total) - imported),
+		CouponLimit: couponLimit,
+	}), nil
+}
+
+// importReader reads the data of an ImportCodes stream as one byte stream,
+// starting with the data of the message already received.
+type importReader struct {
+	stream *ImportCodesStream
+	data   []byte
+}
+
+func (r *importReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		if !r.stream.Receive() {
+			if err := r.stream.Err(); err != nil {
+				return 0, err
+			}
+			return 0, io.EOF
+		}
+		r.data = r.stream.Msg().Data
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// readCodeList calls yield with every code of a list and the line it is on.
+// Blank lines are skipped.
+func readCodeList(
+	r io.Reader,
+	format coupon.CodeListFormat,
+	yield func(line int, code string) error,
+) error {
+	invalid := func(err error) error {
+		var connectErr *connect.Error
+		if errors.As(err, &connectErr) {
+			return err
+		}
+		return connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("failed to read code list: %v", err),
+		)
+	}
+
+	// Skip the byte order mark spreadsheets write at the start of a file
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); string(bom) == "\ufeff" {
+		if _, err := br.Discard(len(bom)); err != nil {
+			return invalid(err)
+		}
+	}
+
+	if format == coupon.CodeListFormat_CODE_LIST_FORMAT_CSV {
+		cr := csv.NewReader(br)
+		cr.FieldsPerRecord = -1
+		cr.ReuseRecord = true
+		for first := true; ; first = false {
+			record, err := cr.Read()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return invalid(err)
+			}
+			code := strings.TrimSpace(record[0])
+			if first && strings.EqualFold(code, "code") {
+				continue
+			}
+			if code == "" {
+				continue
+			}
+			line, _ := cr.FieldPos(0)
+			if err := yield(line, code); err != nil {
+				return err
+			}
+		}
+	}
+
+	scanner := bufio.NewScanner(br)
+	for line := 1; scanner.Scan(); line++ {
+		code := strings.TrimSpace(scanner.Text())
+		if code == "" {
+			continue
+		}
+		if err := yield(line, code); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return invalid(err)
+	}
+	return nil
+}
+
+// validateCustomCode checks a code chosen by people rather than generated:
+// an imported or vanity code.
+func validateCustomCode(code string) error {
+	if !utf8.ValidString(code) {
+		return fmt.Errorf("code is not valid UTF-8")
+	}
+	if n := utf8.RuneCountInString(code); n > codeformat.MaxLength {
+		return fmt.Errorf(
+			"code %q is %d characters, at most %d are allowed",
+			code, n, codeformat.MaxLength,
+		)
+	}
+	for _, r := range code {
+		if !unicode.IsPrint(r) || unicode.IsSpace(r) {
+			return fmt.Errorf("code %q cannot contain %q", code, r)
+		}
+	}
+	return nil
+}
+
+// markImportedCodesIssued records that imported codes were issued, so that
+// they are never claimed again.
+func markImportedCodesIssued(
+	ctx context.Context,
+	tx pgx.Tx,
+	codes []string,
+) error {
+	if len(codes) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx,
+		`UPDATE imported_codes SET claimed_at = now()
+		WHERE code = ANY($1)`,
+		codes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark imported codes issued: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCodeList(t *testing.T) {
+	csvFormat := coupon.CodeListFormat_CODE_LIST_FORMAT_CSV
+	tests := []struct {
+		name    string
+		format  coupon.CodeListFormat
+		list    string
+		want    []string
+		lines   []int
+		wantErr bool
+	}{
+		{
+			name:  "lines",
+			list:  "\ufeffABC-1\r\n\n  ABC-2 \nABC-3",
+			want:  []string{"ABC-1", "ABC-2", "ABC-3"},
+			lines: []int{1, 3, 4},
+		},
+		{
+			name:   "csv with header",
+			format: csvFormat,
+			list:   "\ufeffCode,value\nABC-1,5000\n\"ABC,2\"\n",
+			want:   []string{"ABC-1", "ABC,2"},
+			lines:  []int{2, 3},
+		},
+		{
+			name:   "csv without header",
+			format: csvFormat,
+			list:   "ABC-1,5000\nABC-2,5000\n",
+			want:   []string{"ABC-1", "ABC-2"},
+			lines:  []int{1, 2},
+		},
+		{
+			name:    "malformed csv",
+			format:  csvFormat,
+			list:    "ABC-1\n\"ABC-2\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				codes []string
+				lines []int
+			)
+			err := readCodeList(
+				strings.NewReader(tt.list),
+				tt.format,
+				func(line int, code string) error {
+					codes = append(codes, code)
+					lines = append(lines, line)
+					return nil
+				},
+			)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, codes)
+			assert.Equal(t, tt.lines, lines)
+		})
+	}
+}
+
+func TestCouponService_ImportCodes(t *testing.T) {
+	service := setupTestService(t)
+	client := newTestClient(t, service)
+	ctx := context.Background()
+
+	created, err := service.CreateCampaign(
+		ctx,
+		connect.NewRequest(&coupon.CreateCampaignRequest{
+			Name:          "Partner Campaign",
+			StartTime:     time.Now().Format(time.RFC3339),
+			ImportedCodes: true,
+		}),
+	)
+	require.NoError(t, err)
+	campaignID := created.Msg.CampaignId
+	_, err = service.pool.Exec(ctx,
+		`UPDATE campaigns SET status = 'active' WHERE id = $1`,
+		campaignID,
+	)
+	require.NoError(t, err)
+
+	importCodes := func(chunks ...string) (*coupon.ImportCodesResponse, error) {
+		stream := client.ImportCodes(ctx)
+		for i, chunk := range chunks {
+			req := &coupon.ImportCodesRequest{Data: []byte(chunk)}
+			if i == 0 {
+				req.CampaignId = campaignID
+			}
+			require.NoError(t, stream.Send(req))
+		}
+		resp, err := stream.CloseAndReceive()
+		if err != nil {
+			return nil, err
+		}
+		return resp.Msg, nil
+	}
+
+	t.Run("codes are imported once", func(t *testing.T) {
+		// Codes are compared in their normalized form
+		resp, err := importCodes("GS25-AAA\nGS25-B", "BB\ngs25 aaa\n")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), resp.Imported)
+		assert.Equal(t, int32(1), resp.Duplicates)
+		assert.Equal(t, int32(2), resp.CouponLimit)
+
+		resp, err = importCodes("GS25-BBB\nGS25-CCC\n")
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), resp.Imported)
+		assert.Equal(t, int32(1), resp.Duplicates)
+		assert.Equal(t, int32(3), resp.CouponLimit)
+	})
+
+	t.Run("invalid codes fail the import", func(t *testing.T) {
+		_, err := importCodes("GS25-DDD\nGS25\u0007EEE\n")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+		assert.Contains(t, err.Error(), "line 2")
+
+		var exists bool
+		err = service.pool.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM coupons WHERE code = 'GS25DDD')`,
+		).Scan(&exists)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("only imported codes are issued", func(t *testing.T) {
+		issued := make([]string, 0, 3)
+		for range 3 {
+			resp, err := service.IssueCoupon(
+				ctx,
+				connect.NewRequest(&coupon.IssueCouponRequest{
+					CampaignId: campaignID,
+					UserId:     "user-1",
+				}),
+			)
+			require.NoError(t, err)
+			issued = append(issued, resp.Msg.CouponCode)
+		}
+		assert.ElementsMatch(t,
+			[]string{"GS25AAA", "GS25BBB", "GS25CCC"},
+			issued,
+		)
+
+		_, err := service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: campaignID,
+				UserId:     "user-1",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		// A new import reopens the finished campaign
+		_, err = importCodes("GS25-FFF\n")
+		require.NoError(t, err)
+		resp, err := service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: campaignID,
+				UserId:     "user-1",
+			}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "GS25FFF", resp.Msg.CouponCode)
+	})
+
+	t.Run("revoked codes do not return to the pool", func(t *testing.T) {
+		revoke := func(returnToPool bool) error {
+			_, err := service.RevokeCoupon(
+				ctx,
+				connect.NewRequest(&coupon.RevokeCouponRequest{
+					Code:         "GS25FFF",
+					Reason:       "leaked",
+					ReturnToPool: returnToPool,
+				}),
+			)
+			return err
+		}
+
+		err := revoke(true)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+		require.NoError(t, revoke(false))
+	})
+
+	t.Run("uploads do not lock the campaign", func(t *testing.T) {
+		stream := client.ImportCodes(ctx)
+		require.NoError(t, stream.Send(&coupon.ImportCodesRequest{
+			CampaignId: campaignID,
+			Data:       []byte("GS25-HHH\n"),
+		}))
+		// Give the server time to read the first message
+		time.Sleep(100 * time.Millisecond)
+
+		lockCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		_, err := service.pool.Exec(lockCtx,
+			`UPDATE campaigns SET priority = priority WHERE id = $1`,
+			campaignID,
+		)
+		require.NoError(t, err, "campaign is writable during an upload")
+
+		resp, err := stream.CloseAndReceive()
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), resp.Msg.Imported)
+	})
+
+	t.Run("other campaigns cannot import", func(t *testing.T) {
+		otherID, _ := issueTestCoupon(t, service, "user-1")
+		stream := client.ImportCodes(ctx)
+		require.NoError(t, stream.Send(&coupon.ImportCodesRequest{
+			CampaignId: otherID,
+			Data:       []byte("GS25-GGG\n"),
+		}))
+		_, err := stream.CloseAndReceive()
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+}
+
+func TestCouponService_ImportCodes_Restart(t *testing.T) {
+	service := setupTestService(t)
+	client := newTestClient(t, service)
+	ctx := context.Background()
+
+	created, err := service.CreateCampaign(
+		ctx,
+		connect.NewRequest(&coupon.CreateCampaignRequest{
+			Name:          "Partner Campaign",
+			StartTime:     time.Now().Format(time.RFC3339),
+			ImportedCodes: true,
+		}),
+	)
+	require.NoError(t, err)
+	campaignID := created.Msg.CampaignId
+	_, err = service.pool.Exec(ctx,
+		`UPDATE campaigns SET status = 'active' WHERE id = $1`,
+		campaignID,
+	)
+	require.NoError(t, err)
+
+	stream := client.ImportCodes(ctx)
+	require.NoError(t, stream.Send(&coupon.ImportCodesRequest{
+		CampaignId: campaignID,
+		Data:       []byte("RS-AAA\nRS-BBB\nRS-CCC\n"),
+	}))
+	_, err = stream.CloseAndReceive()
+	require.NoError(t, err)
+
+	// Another server claims the codes into its pool, then stops
+	stopped := newCodeGenerator()
+	format := codeFormat{kind: codesImported}
+	err = stopped.refill(ctx, service.pool, campaignID, format)
+	require.NoError(t, err)
+	_, err = service.codeGen.takeCodes(ctx, service.pool, campaignID, format, 1)
+	require.Error(t, err, "codes are claimed by the stopped server")
+
+	// Once the claim expires, the codes are issued by the next server
+	_, err = service.pool.Exec(ctx,
+		`UPDATE imported_codes SET claimed_until = now() - interval '1 second'
+		WHERE campaign_id = $1`,
+		campaignID,
+	)
+	require.NoError(t, err)
+
+	issue := func() (string, error) {
+		resp, err := service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: campaignID,
+				UserId:     "user-1",
+			}),
+		)
+		if err != nil {
+			return "", err
+		}
+		return resp.Msg.CouponCode, nil
+	}
+	issued := make([]string, 0, 3)
+	for range 3 {
+		code, err := issue()
+		require.NoError(t, err)
+		issued = append(issued, code)
+	}
+	assert.ElementsMatch(t, []string{"RSAAA", "RSBBB", "RSCCC"}, issued)
+
+	_, err = issue()
+	require.Error(t, err)
+	assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+	// Issued codes are never claimed again
+	require.NoError(t, service.codeGen.writeIssuedCodes(ctx, service.pool))
+	var unissued int
+	err = service.pool.QueryRow(ctx,
+		`SELECT count(*) FROM imported_codes
+		WHERE campaign_id = $1 AND claimed_at IS NULL`,
+		campaignID,
+	).Scan(&unissued)
+	require.NoError(t, err)
+	assert.Zero(t, unissued)
+}
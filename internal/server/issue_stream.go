@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+)
+
+// issueStreamWindow bounds how many coupons a single stream may have issued
+// but not yet delivered. A consumer that stops reading stops receiving new
+// coupons once the window is full instead of accumulating them server-side.
+const issueStreamWindow = 64
+
+type IssueCouponBidiStream = connect.BidiStream[
+	coupon.IssueCouponStreamRequest,
+	coupon.IssueCouponStreamResponse,
+]
+
+// IssueCouponStream issues coupons for a stream of requests. Requests are
+// processed concurrently within the window, so responses can arrive out of
+// order and are matched to requests by correlation ID.
+func (s *CouponService) IssueCouponStream(
+	ctx context.Context,
+	stream *IssueCouponBidiStream,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	window := make(chan struct{}, issueStreamWindow)
+	results := make(chan issueStreamResult, issueStreamWindow)
+
+	var sendErr error
+	senderDone := make(chan struct{})
+	go func() {
+		defer close(senderDone)
+		for result := range results {
+			if sendErr == nil {
+				if err := stream.Send(result.resp); err != nil {
+					sendErr = err
+					cancel()
+				}
+			}
+			if code := result.resp.GetCouponCode(); sendErr != nil && code != "" {
+				log.Printf("Coupon %s could not be delivered: %v", code, sendErr)
+				s.returnUndeliveredCoupon(result.req, result.issued)
+			}
+			<-window
+		}
+	}()
+
+	var (
+		wg         sync.WaitGroup
+		receiveErr error
+	)
+	for {
+		req, err := stream.Receive()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				receiveErr = err
+			}
+			break
+		}
+
+		select {
+		case window <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- s.issueFromStream(ctx, req)
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+	<-senderDone
+
+	if sendErr != nil {
+		return sendErr
+	}
+	return receiveErr
+}
+
+type issueStreamResult struct {
+	req    *coupon.IssueCouponStreamRequest
+	resp   *coupon.IssueCouponStreamResponse
+	issued issuedCode
+}
+
+// returnUndeliveredCoupon voids a coupon that was issued on a stream that
+// closed before it could be sent and gives its slot back to the campaign, as
+// RevokeCoupon with return_to_pool does; an imported code, which cannot back
+// another slot, is only voided. For a vanity code only a claim the
+// request created, and that is not redeemed, is withdrawn; a claim the user
+// already held took no slot of the request.
+func (s *CouponService) returnUndeliveredCoupon(
+	req *coupon.IssueCouponStreamRequest,
+	issued issuedCode,
+) {
+	// The stream's context is already canceled
+	ctx := s.context
+	campaignID := req.Request.CampaignId
+
+	if issued.vanity {
+		if !issued.newClaim {
+			return
+		}
+		tag, err := s.pool.Exec(ctx,
+			`DELETE FROM vanity_claims
+			WHERE campaign_id = $1 AND user_id = $2 AND redeemed_at IS NULL`,
+			campaignID,
+			req.Request.UserId,
+		)
+		if err != nil {
+			log.Printf(
+				"Failed to return undelivered coupon %s: %v", issued.code, err)
+			return
+		}
+		if tag.RowsAffected() > 0 {
+			s.returnCampaignSlot(campaignID, true)
+		}
+		return
+	}
+
+	_, err := s.RevokeCoupon(ctx, connect.NewRequest(&coupon.RevokeCouponRequest{
+		Code:         issued.code,
+		Reason:       "not delivered: issuance stream closed",
+		ReturnToPool: !issued.imported,
+	}))
+	if err != nil {
+		log.Printf(
+			"Failed to return undelivered coupon %s: %v", issued.code, err)
+	}
+}
+
+// issueFromStream issues the coupon of a stream request.
+func (s *CouponService) issueFromStream(
+	ctx context.Context,
+	req *coupon.IssueCouponStreamRequest,
+) issueStreamResult {
+	result := issueStreamResult{
+		req: req,
+		resp: &coupon.IssueCouponStreamResponse{
+			CorrelationId: req.CorrelationId,
+		},
+	}
+	if req.Request == nil {
+		result.resp.Result = &coupon.IssueCouponStreamResponse_Error{
+			Error: &coupon.IssueCouponError{
+				Code:    connect.CodeInvalidArgument.String(),
+				Message: "request is required",
+			},
+		}
+		return result
+	}
+
+	issued, err := s.issueCoupon(ctx, req.Request)
+	if err != nil {
+		result.resp.Result = &coupon.IssueCouponStreamResponse_Error{
+			Error: issueCouponError(err),
+		}
+		return result
+	}
+
+	result.issued = issued
+	result.resp.Result = &coupon.IssueCouponStreamResponse_CouponCode{
+		CouponCode: issued.code,
+	}
+	return result
+}
+
+func issueCouponError(err error) *coupon.IssueCouponError {
+	issueErr := &coupon.IssueCouponError{
+		Code:    connect.CodeOf(err).String(),
+		Message: err.Error(),
+	}
+
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		issueErr.Message = connectErr.Message()
+		for _, detail := range connectErr.Details() {
+			value, err := detail.Value()
+			if err != nil {
+				continue
+			}
+			if denial, ok := value.(*coupon.EligibilityDenial); ok {
+				issueErr.EligibilityDenial = denial
+			}
+		}
+	}
+	return issueErr
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponService_IssueCouponStream(t *testing.T) {
+	service := setupTestService(t)
+	client := newTestClient(t, service)
+	ctx := context.Background()
+
+	campaignID := "00000000-0000-0000-0000-000000000000"
+	_, err := service.pool.Exec(ctx,
+		`INSERT INTO campaigns (id, name, start_time, coupon_limit, status)
+		VALUES ($1, $2, $3, $4, $5)`,
+		campaignID,
+		"Partner Campaign",
+		time.Now(),
+		2,
+		"active",
+	)
+	require.NoError(t, err)
+
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, campaignID)
+	err = service.redis.Set(ctx, counterKey, 2, 0).Err()
+	require.NoError(t, err)
+
+	stream := client.IssueCouponStream(ctx)
+	for i := 0; i < 3; i++ {
+		err := stream.Send(&coupon.IssueCouponStreamRequest{
+			CorrelationId: fmt.Sprintf("req-%d", i),
+			Request:       &coupon.IssueCouponRequest{CampaignId: campaignID},
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, stream.Send(&coupon.IssueCouponStreamRequest{
+		CorrelationId: "missing-request",
+	}))
+	require.NoError(t, stream.CloseRequest())
+
+	codes := make(map[string]string)
+	errorCodes := make(map[string]string)
+	for i := 0; i < 4; i++ {
+		resp, err := stream.Receive()
+		require.NoError(t, err)
+		if code := resp.GetCouponCode(); code != "" {
+			codes[resp.CorrelationId] = code
+		} else {
+			errorCodes[resp.CorrelationId] = resp.GetError().Code
+		}
+	}
+	require.NoError(t, stream.CloseResponse())
+
+	assert.Len(t, codes, 2)
+	assert.Len(t, errorCodes, 2)
+	assert.Equal(t,
+		connect.CodeInvalidArgument.String(),
+		errorCodes["missing-request"],
+	)
+	for id, code := range errorCodes {
+		if id != "missing-request" {
+			assert.Equal(t, connect.CodeResourceExhausted.String(), code)
+		}
+	}
+}
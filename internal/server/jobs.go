@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	jobTypePushIssuance = "push_issuance"
+	jobTypeCodeBatch    = "code_batch"
+
+	// A claimed job is owned by one server until its lease expires, so a
+	// job abandoned by a crashed server is picked up again automatically.
+	jobLease = 30 * time.Second
+
+	maxJobFailures = 100
+
+	// A job is marked failed once its current batch has failed this many
+	// times in a row.
+	maxJobAttempts = 10
+)
+
+type job struct {
+	id         string
+	jobType    string
+	campaignID string
+	nextBatch  int
+}
+
+type (
+	GetJobReq  = connect.Request[coupon.GetJobRequest]
+	GetJobResp = connect.Response[coupon.GetJobResponse]
+)
+
+func (s *CouponService) startJobWorker(ctx context.Context) {
+	interval := time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.backgroundWorkersStopped <- struct{}{}
+			return
+		case <-ticker.C:
+			s.runPendingJobs(ctx)
+		}
+	}
+}
+
+// runPendingJobs claims and runs jobs until none are left or the worker is
+// stopped. Jobs that cannot make progress yet are skipped until the next
+// tick.
+func (s *CouponService) runPendingJobs(ctx context.Context) {
+	serverCtx := s.context
+	seen := []pgtype.UUID{}
+
+	for ctx.Err() == nil {
+		j, err := s.claimJob(serverCtx, seen)
+		if err != nil {
+			log.Printf("Failed to claim job: %v", err)
+			return
+		}
+		if j == nil {
+			return
+		}
+		var id pgtype.UUID
+		if err := id.Scan(j.id); err == nil {
+			seen = append(seen, id)
+		}
+
+		done, err := s.runJob(ctx, j)
+		if err != nil {
+			s.retryJob(serverCtx, j, err)
+			continue
+		}
+		if !done {
+			if err := s.releaseJob(serverCtx, j.id); err != nil {
+				log.Printf("Failed to release job %s: %v", j.id, err)
+			}
+		}
+	}
+}
+
+func (s *CouponService) claimJob(
+	ctx context.Context,
+	skip []pgtype.UUID,
+) (*job, error) {
+	var (
+		j          job
+		id         pgtype.UUID
+		campaignID pgtype.UUID
+	)
+	err := s.pool.QueryRow(ctx,
+		`UPDATE jobs
+		SET status = 'running',
+			locked_until = now() + make_interval(secs => $1)
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status IN ('pending', 'running')
+			AND (locked_until IS NULL OR locked_until < now())
+			AND NOT (id = ANY($2))
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, type, campaign_id, next_batch`,
+		jobLease.Seconds(),
+		skip,
+	).Scan(&id, &j.jobType, &campaignID, &j.nextBatch)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	j.id = id.String()
+	j.campaignID = campaignID.String()
+	return &j, nil
+}
+
+// runJob reports whether the job has finished.
+func (s *CouponService) runJob(ctx context.Context, j *job) (bool, error) {
+	switch j.jobType {
+	case jobTypePushIssuance:
+		return s.runPushIssuance(ctx, j)
+	case jobTypeCodeBatch:
+		return s.runCodeBatch(ctx, j)
+	}
+
+	reason := fmt.Sprintf("unknown job type %q", j.jobType)
+	return true, s.failJob(s.context, j.id, reason)
+}
+
+// retryJob counts a failed attempt at the job's current batch. Once the
+// batch has failed maxJobAttempts times, the coupons reserved for it are
+// given back and the job is marked failed.
+func (s *CouponService) retryJob(ctx context.Context, j *job, jobErr error) {
+	var attempts int
+	err := s.pool.QueryRow(ctx,
+		`UPDATE jobs SET attempts = attempts + 1
+		WHERE id = $1 RETURNING attempts`,
+		j.id,
+	).Scan(&attempts)
+	if err != nil {
+		log.Printf("Failed to record failure of job %s: %v", j.id, err)
+		return
+	}
+	if attempts < maxJobAttempts {
+		log.Printf("Job %s failed, will retry: %v", j.id, jobErr)
+		return
+	}
+
+	log.Printf("Job %s failed %d times, giving up: %v", j.id, attempts, jobErr)
+	if err := s.releaseJobReservation(ctx, j); err != nil {
+		log.Printf("Failed to release reservation of job %s: %v", j.id, err)
+	}
+	reason := fmt.Sprintf("failed after %d attempts: %v", attempts, jobErr)
+	if err := s.failJob(ctx, j.id, reason); err != nil {
+		log.Printf("Failed to mark job %s failed: %v", j.id, err)
+	}
+}
+
+// releaseJobReservation gives back the coupons a job took from the campaign
+// counter for a batch it did not commit.
+func (s *CouponService) releaseJobReservation(
+	ctx context.Context,
+	j *job,
+) error {
+	switch j.jobType {
+	case jobTypePushIssuance:
+		return s.releasePushReservation(ctx, j)
+	case jobTypeCodeBatch:
+		return s.releaseCodeBatchReservation(ctx, j)
+	}
+	return nil
+}
+
+func (s *CouponService) releaseJob(ctx context.Context, jobID string) error {
+	_, err := s.pool.Exec(ctx,
+		`UPDATE jobs SET locked_until = NULL WHERE id = $1`,
+		jobID,
+	)
+	return err
+}
+
+func (s *CouponService) completeJob(ctx context.Context, jobID string) error {
+	_, err := s.pool.Exec(ctx,
+		`UPDATE jobs SET status = 'completed', locked_until = NULL
+		WHERE id = $1`,
+		jobID,
+	)
+	return err
+}
+
+func (s *CouponService) failJob(
+	ctx context.Context,
+	jobID string,
+	reason string,
+) error {
+	_, err := s.pool.Exec(ctx,
+		`UPDATE jobs SET status = 'failed', error = $2, locked_until = NULL
+		WHERE id = $1`,
+		jobID,
+		reason,
+	)
+	return err
+}
+
+func (s *CouponService) GetJob(
+	ctx context.Context,
+	req *GetJobReq,
+) (*GetJobResp, error) {
+	var (
+		resp       coupon.GetJobResponse
+		campaignID pgtype.UUID
+		jobError   *string
+		createdAt  time.Time
+		updatedAt  time.Time
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT type, campaign_id, status, total, processed, succeeded,
+			failed, error, created_at, updated_at
+		FROM jobs WHERE id = $1`,
+		req.Msg.JobId,
+	).Scan(
+		&resp.Type,
+		&campaignID,
+		&resp.Status,
+		&resp.Total,
+		&resp.Processed,
+		&resp.Succeeded,
+		&resp.Failed,
+		&jobError,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("job not found: %v", err),
+		)
+	}
+
+	resp.JobId = req.Msg.JobId
+	resp.CampaignId = campaignID.String()
+	resp.Error = derefString(jobError)
+	resp.CreatedAt = createdAt.Format(time.RFC3339)
+	resp.UpdatedAt = updatedAt.Format(time.RFC3339)
+
+	if resp.Type == jobTypePushIssuance && resp.Failed > 0 {
+		failures, err := s.pushIssuanceFailures(ctx, req.Msg.JobId)
+		if err != nil {
+			return nil, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to get job failures: %v", err),
+			)
+		}
+		resp.Failures = failures
+	}
+
+	return connect.NewResponse(&resp), nil
+}
@@ -0,0 +1,305 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"coupon-issuance/internal/codeformat"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const normalizeCodesBatchSize = 1000
+
+// Reasons a stored code cannot be normalized
+const (
+	normalizeConflictEmpty = "empty"
+	normalizeConflictTaken = "taken"
+)
+
+type storedCode struct {
+	couponID string
+	code     string
+}
+
+type codeRename struct {
+	couponID   string
+	code       string
+	normalized string
+}
+
+type codeConflict struct {
+	codeRename
+	reason string
+}
+
+// NormalizeCodesResult counts the coupons NormalizeStoredCodes went through.
+type NormalizeCodesResult struct {
+	Renamed   int
+	Conflicts int
+}
+
+// planCodeNormalization decides which of the coupons get their normalized
+// code. taken holds the codes already stored; a code whose normalized form
+// is taken, including by an earlier coupon of the same plan, is a conflict.
+// Codes given to renamed coupons are added to taken.
+func planCodeNormalization(
+	codes []storedCode,
+	taken map[string]bool,
+) ([]codeRename, []codeConflict) {
+	var (
+		renames   []codeRename
+		conflicts []codeConflict
+	)
+	for _, c := range codes {
+		normalized := codeformat.Normalize(c.code)
+		if normalized == c.code {
+			continue
+		}
+
+		rename := codeRename{
+			couponID:   c.couponID,
+			code:       c.code,
+			normalized: normalized,
+		}
+		switch {
+		case normalized == "":
+			conflicts = append(conflicts,
+				codeConflict{rename, normalizeConflictEmpty})
+		case taken[normalized]:
+			conflicts = append(conflicts,
+				codeConflict{rename, normalizeConflictTaken})
+		default:
+			taken[normalized] = true
+			renames = append(renames, rename)
+		}
+	}
+	return renames, conflicts
+}
+
+// NormalizeStoredCodes rewrites the codes stored before codes were
+// normalized into the form codeformat.Normalize gives them, oldest coupon
+// first. Coupons whose normalized code is empty or already taken keep their
+// code and are written to code_normalization_conflicts for review. It runs
+// in a single transaction and should run while no server is issuing codes.
+func NormalizeStoredCodes(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+) (NormalizeCodesResult, error) {
+	var result NormalizeCodesResult
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if tx != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// Normalized codes given to coupons of earlier batches
+	taken := make(map[string]bool)
+
+	// Coupons without a creation time come first
+	lastCreatedAt := pgtype.Timestamptz{
+		InfinityModifier: pgtype.NegativeInfinity,
+		Valid:            true,
+	}
+	lastID := "00000000-0000-0000-0000-000000000000"
+	for {
+		rows, err := tx.Query(ctx,
+			`SELECT id::text, code, COALESCE(created_at, '-infinity')
+			FROM coupons
+			WHERE (COALESCE(created_at, '-infinity'), id)
+				> ($1::timestamptz, $2::uuid)
+			ORDER BY COALESCE(created_at, '-infinity'), id
+			LIMIT $3`,
+			lastCreatedAt,
+			lastID,
+			normalizeCodesBatchSize,
+		)
+		if err != nil {
+			return result, fmt.Errorf("failed to read coupons: %w", err)
+		}
+
+		var codes []storedCode
+		for rows.Next() {
+			var c storedCode
+			err := rows.Scan(&c.couponID, &c.code, &lastCreatedAt)
+			if err != nil {
+				rows.Close()
+				return result, fmt.Errorf("failed to read coupons: %w", err)
+			}
+			lastID = c.couponID
+			codes = append(codes, c)
+		}
+		if err := rows.Err(); err != nil {
+			return result, fmt.Errorf("failed to read coupons: %w", err)
+		}
+		if len(codes) == 0 {
+			break
+		}
+
+		if err := loadTakenCodes(ctx, tx, codes, taken); err != nil {
+			return result, err
+		}
+		renames, conflicts := planCodeNormalization(codes, taken)
+
+		for _, r := range renames {
+			_, err := tx.Exec(ctx,
+				`UPDATE coupons SET code = $2 WHERE id = $1`,
+				r.couponID,
+				r.normalized,
+			)
+			if err != nil {
+				return result, fmt.Errorf(
+					"failed to normalize code %q: %w", r.code, err)
+			}
+		}
+		for _, c := range conflicts {
+			_, err := tx.Exec(ctx,
+				`INSERT INTO code_normalization_conflicts
+				(coupon_id, code, normalized_code, reason)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (coupon_id) DO UPDATE
+				SET code = EXCLUDED.code,
+					normalized_code = EXCLUDED.normalized_code,
+					reason = EXCLUDED.reason`,
+				c.couponID,
+				c.code,
+				c.normalized,
+				c.reason,
+			)
+			if err != nil {
+				return result, fmt.Errorf(
+					"failed to record conflict for code %q: %w", c.code, err)
+			}
+		}
+		result.Renamed += len(renames)
+		result.Conflicts += len(conflicts)
+	}
+
+	if err := normalizeCodeReferences(ctx, tx); err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	tx = nil // Set tx to nil after successful commit
+
+	return result, nil
+}
+
+// loadTakenCodes adds the normalized forms of the codes that are already
+// stored to taken.
+func loadTakenCodes(
+	ctx context.Context,
+	tx pgx.Tx,
+	codes []storedCode,
+	taken map[string]bool,
+) error {
+	var normalized []string
+	for _, c := range codes {
+		n := codeformat.Normalize(c.code)
+		if n != c.code && n != "" && !taken[n] {
+			normalized = append(normalized, n)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT code FROM coupons WHERE code = ANY($1)`,
+		normalized,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to read coupons: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return fmt.Errorf("failed to read coupons: %w", err)
+		}
+		taken[code] = true
+	}
+	return rows.Err()
+}
+
+// normalizeCodeReferences points the codes stored outside coupons, which
+// have no foreign key, at the normalized code of their coupon.
+// Imported and batch codes follow their coupon through ON UPDATE CASCADE.
+func normalizeCodeReferences(ctx context.Context, tx pgx.Tx) error {
+	vanity, err := collectCodes(ctx, tx,
+		`SELECT vanity_code FROM campaigns WHERE vanity_code IS NOT NULL`)
+	if err != nil {
+		return err
+	}
+	for _, code := range vanity {
+		normalized := codeformat.Normalize(code)
+		if normalized == code {
+			continue
+		}
+		_, err := tx.Exec(ctx,
+			`UPDATE campaigns cp SET vanity_code = $2
+			WHERE vanity_code = $1
+			AND EXISTS (
+				SELECT 1 FROM coupons c
+				WHERE c.campaign_id = cp.id AND c.code = $2
+			)`,
+			code,
+			normalized,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to normalize vanity code %q: %w", code, err)
+		}
+	}
+
+	recipients, err := collectCodes(ctx, tx,
+		`SELECT DISTINCT coupon_code FROM push_issuance_recipients
+		WHERE coupon_code IS NOT NULL`)
+	if err != nil {
+		return err
+	}
+	for _, code := range recipients {
+		normalized := codeformat.Normalize(code)
+		if normalized == code {
+			continue
+		}
+		_, err := tx.Exec(ctx,
+			`UPDATE push_issuance_recipients SET coupon_code = $2
+			WHERE coupon_code = $1
+			AND NOT EXISTS (SELECT 1 FROM coupons WHERE code = $1)
+			AND EXISTS (SELECT 1 FROM coupons WHERE code = $2)`,
+			code,
+			normalized,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to normalize recipient code %q: %w", code, err)
+		}
+	}
+	return nil
+}
+
+func collectCodes(ctx context.Context, tx pgx.Tx, query string) (
+	[]string,
+	error,
+) {
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read codes: %w", err)
+	}
+	codes, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read codes: %w", err)
+	}
+	return codes, nil
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanCodeNormalization(t *testing.T) {
+	taken := map[string]bool{"TAKEN1": true}
+	codes := []storedCode{
+		{couponID: "1", code: "ABC123"},
+		{couponID: "2", code: "abc-456"},
+		{couponID: "3", code: "ＡＢＣ４５６"},
+		{couponID: "4", code: "taken1"},
+		{couponID: "5", code: "ｶﾞ12"},
+		{couponID: "6", code: " - "},
+	}
+
+	renames, conflicts := planCodeNormalization(codes, taken)
+
+	// The oldest coupon keeps the normalized code
+	assert.Equal(t, []codeRename{
+		{couponID: "2", code: "abc-456", normalized: "ABC456"},
+		{couponID: "5", code: "ｶﾞ12", normalized: "ガ12"},
+	}, renames)
+	assert.Equal(t, []codeConflict{
+		{codeRename{"3", "ＡＢＣ４５６", "ABC456"}, normalizeConflictTaken},
+		{codeRename{"4", "taken1", "TAKEN1"}, normalizeConflictTaken},
+		{codeRename{"6", " - ", ""}, normalizeConflictEmpty},
+	}, conflicts)
+	assert.True(t, taken["ABC456"])
+}
@@ -0,0 +1,565 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	pushReservationKey    = "campaign:push:"
+	pushReservationTTL    = 24 * time.Hour
+	pushIssuanceBatchSize = 500
+	pushRecipientCopySize = 5000
+	maxUserIDLength       = 255
+
+	errPushLimitReached = "campaign has reached its coupon limit"
+	errPushMaxHeld      = "user already holds max_held_per_user coupons"
+)
+
+// Lua script to reserve up to ARGV[1] coupons for one batch of a push job.
+// The grant is remembered under KEYS[2], so a batch retried after a crash
+// gets the same number of coupons instead of decrementing the counter twice.
+const pushReserveScript = `
+	local granted = redis.call('GET', KEYS[2])
+	if granted then
+		return tonumber(granted)
+	end
+	local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+	local take = math.min(current, tonumber(ARGV[1]))
+	if take > 0 then
+		redis.call('DECRBY', KEYS[1], take)
+	end
+	redis.call('SET', KEYS[2], take, 'EX', ARGV[2])
+	return take
+`
+
+type (
+	StartPushIssuanceStream = connect.ClientStream[coupon.StartPushIssuanceRequest]
+	StartPushIssuanceResp   = connect.Response[coupon.StartPushIssuanceResponse]
+)
+
+// StartPushIssuance queues a job that grants a coupon to every user in the
+// streamed list. The list is stored before the call returns; coupons are
+// assigned in the background and progress is reported through GetJob.
+func (s *CouponService) StartPushIssuance(
+	ctx context.Context,
+	stream *StartPushIssuanceStream,
+) (*StartPushIssuanceResp, error) {
+	if !stream.Receive() {
+		if err := stream.Err(); err != nil {
+			return nil, err
+		}
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("request stream is empty"),
+		)
+	}
+	campaignID := stream.Msg().CampaignId
+
+	var (
+		status string
+		vanity bool
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT status, vanity_code IS NOT NULL FROM campaigns WHERE id = $1`,
+		campaignID,
+	).Scan(&status, &vanity)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("campaign not found: %v", err),
+		)
+	}
+	if vanity {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("vanity codes are claimed with IssueCoupon"),
+		)
+	}
+	if status == "finished" {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("campaign has already finished"),
+		)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to begin transaction: %v", err),
+		)
+	}
+	defer func() {
+		if tx != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	var jobID pgtype.UUID
+	err = tx.QueryRow(ctx,
+		`INSERT INTO jobs (type, campaign_id) VALUES ($1, $2) RETURNING id`,
+		jobTypePushIssuance,
+		campaignID,
+	).Scan(&jobID)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to create job: %v", err),
+		)
+	}
+
+	seen := make(map[string]struct{})
+	rows := make([][]any, 0, pushRecipientCopySize)
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		_, err := tx.CopyFrom(ctx,
+			pgx.Identifier{"push_issuance_recipients"},
+			[]string{"job_id", "user_id"},
+			pgx.CopyFromRows(rows),
+		)
+		rows = rows[:0]
+		return err
+	}
+
+	for {
+		for _, userID := range stream.Msg().UserIds {
+			userID = strings.TrimSpace(userID)
+			if userID == "" {
+				continue
+			}
+			if len(userID) > maxUserIDLength {
+				return nil, connect.NewError(
+					connect.CodeInvalidArgument,
+					fmt.Errorf("user ID %q is too long", userID),
+				)
+			}
+			if _, ok := seen[userID]; ok {
+				continue
+			}
+			seen[userID] = struct{}{}
+			rows = append(rows, []any{jobID, userID})
+		}
+		if len(rows) >= pushRecipientCopySize {
+			if err := flush(); err != nil {
+				return nil, connect.NewError(
+					connect.CodeInternal,
+					fmt.Errorf("failed to store recipients: %v", err),
+				)
+			}
+		}
+		if !stream.Receive() {
+			break
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to store recipients: %v", err),
+		)
+	}
+
+	if len(seen) == 0 {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("no user IDs provided"),
+		)
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE jobs SET total = $2 WHERE id = $1`,
+		jobID,
+		len(seen),
+	)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to update job: %v", err),
+		)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to commit job: %v", err),
+		)
+	}
+	tx = nil // Set tx to nil after successful commit
+
+	return connect.NewResponse(&coupon.StartPushIssuanceResponse{
+		JobId:      jobID.String(),
+		TotalUsers: int32(len(seen)),
+	}), nil
+}
+
+// runPushIssuance processes the remaining recipients of a push job batch by
+// batch. Every batch commits its coupons together with the job's progress,
+// so a restarted server continues where the previous one stopped.
+func (s *CouponService) runPushIssuance(
+	ctx context.Context,
+	j *job,
+) (bool, error) {
+	serverCtx := s.context
+
+	status, format, err := s.campaignCodeFormat(serverCtx, j.campaignID)
+	if err != nil {
+		return false, err
+	}
+
+	// Wait for the campaign to start
+	if status == "scheduled" {
+		return false, nil
+	}
+
+	for ctx.Err() == nil {
+		done, err := s.processPushBatch(serverCtx, j, format)
+		if err != nil || done {
+			return done, err
+		}
+		j.nextBatch++
+	}
+	return false, nil
+}
+
+func (s *CouponService) processPushBatch(
+	ctx context.Context,
+	j *job,
+	format codeFormat,
+) (bool, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT user_id FROM push_issuance_recipients
+		WHERE job_id = $1 AND status = 'pending'
+		ORDER BY user_id
+		LIMIT $2`,
+		j.id,
+		pushIssuanceBatchSize,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to get recipients: %w", err)
+	}
+	users, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return false, fmt.Errorf("failed to scan recipients: %w", err)
+	}
+
+	if len(users) == 0 {
+		return true, s.completeJob(ctx, j.id)
+	}
+
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, j.campaignID)
+	reservationKey := fmt.Sprintf(
+		"%s%s:%d", pushReservationKey, j.id, j.nextBatch,
+	)
+	granted, err := s.redis.Eval(ctx, pushReserveScript,
+		[]string{counterKey, reservationKey},
+		len(users),
+		int(pushReservationTTL.Seconds()),
+	).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve coupons: %w", err)
+	}
+
+	grantedUsers, failedUsers := users[:granted], users[granted:]
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if tx != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// Recipients holding max_held_per_user coupons get none; their share of
+	// the limit is given back once the batch commits
+	issuedUsers, heldUsers, err := heldRecipients(
+		ctx, tx, j.campaignID, grantedUsers,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	codes, err := s.codeGen.takeCodes(
+		ctx,
+		s.pool,
+		j.campaignID,
+		format,
+		len(issuedUsers),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to generate coupon codes: %w", err)
+	}
+	for i, code := range codes {
+		codes[i] = codeformat.Normalize(code)
+	}
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE coupons c
+		SET campaign_id = $1,
+			user_id = a.user_id,
+			state = 'issued',
+			issued_at = now(),
+			expires_at = LEAST(
+				cp.valid_until,
+				now() + make_interval(secs => cp.validity_seconds)
+			)
+		FROM unnest($2::text[], $3::text[]) AS a(code, user_id),
+			campaigns cp
+		WHERE cp.id = $1
+		AND c.code = a.code
+		AND c.campaign_id IS NULL
+		AND c.state = 'available'`,
+		j.campaignID,
+		codes,
+		issuedUsers,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to write issued codes: %w", err)
+	}
+	if tag.RowsAffected() != int64(len(codes)) {
+		return false, fmt.Errorf("some coupon codes were already issued")
+	}
+	if format.kind == codesImported {
+		if err := markImportedCodesIssued(ctx, tx, codes); err != nil {
+			return false, err
+		}
+	}
+	if err := recordIssuedEvents(ctx, tx, codes); err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE push_issuance_recipients r
+		SET status = 'issued', coupon_code = a.code
+		FROM unnest($2::text[], $3::text[]) AS a(code, user_id)
+		WHERE r.job_id = $1 AND r.user_id = a.user_id`,
+		j.id,
+		codes,
+		issuedUsers,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update recipients: %w", err)
+	}
+
+	if len(failedUsers) > 0 {
+		_, err = tx.Exec(ctx,
+			`UPDATE push_issuance_recipients
+			SET status = 'failed', error = $3
+			WHERE job_id = $1 AND user_id = ANY($2)`,
+			j.id,
+			failedUsers,
+			errPushLimitReached,
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to update recipients: %w", err)
+		}
+	}
+	if len(heldUsers) > 0 {
+		_, err = tx.Exec(ctx,
+			`UPDATE push_issuance_recipients
+			SET status = 'failed', error = $3
+			WHERE job_id = $1 AND user_id = ANY($2)`,
+			j.id,
+			heldUsers,
+			errPushMaxHeld,
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to update recipients: %w", err)
+		}
+	}
+
+	// The next_batch check makes sure a server whose lease has expired
+	// cannot commit a batch another server has already taken over.
+	tag, err = tx.Exec(ctx,
+		`UPDATE jobs
+		SET processed = processed + $2,
+			succeeded = succeeded + $3,
+			failed = failed + $4,
+			next_batch = next_batch + 1,
+			attempts = 0,
+			locked_until = now() + make_interval(secs => $5)
+		WHERE id = $1 AND next_batch = $6`,
+		j.id,
+		len(users),
+		len(issuedUsers),
+		len(failedUsers)+len(heldUsers),
+		jobLease.Seconds(),
+		j.nextBatch,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update job progress: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return false, fmt.Errorf("job was taken over by another worker")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit batch: %w", err)
+	}
+	tx = nil // Set tx to nil after successful commit
+	s.returnCampaignSlots(j.campaignID, len(heldUsers), true)
+
+	if granted > 0 {
+		remaining, err := s.redis.Get(ctx, counterKey).Int()
+		if err != nil {
+			return false, fmt.Errorf("failed to get coupon counter: %w", err)
+		}
+		if remaining == 0 {
+			err := s.updateCampaignToFinished(ctx, j.campaignID)
+			if err != nil {
+				return false, fmt.Errorf("failed to finish campaign: %w", err)
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// heldRecipients splits users into those who may get a coupon of the
+// campaign and those who already hold its max_held_per_user. Their holdings
+// stay locked until the transaction ends, as in lockHeldCoupons.
+func heldRecipients(
+	ctx context.Context,
+	tx pgx.Tx,
+	campaignID string,
+	users []string,
+) ([]string, []string, error) {
+	var maxHeldPerUser *int32
+	err := tx.QueryRow(ctx,
+		`SELECT max_held_per_user FROM campaigns WHERE id = $1`,
+		campaignID,
+	).Scan(&maxHeldPerUser)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get campaign: %w", err)
+	}
+	if maxHeldPerUser == nil || len(users) == 0 {
+		return users, nil, nil
+	}
+
+	// Users are sorted, so batches lock holders in the same order
+	keys := make([]string, len(users))
+	for i, user := range users {
+		keys[i] = holderLockKey(campaignID, user)
+	}
+	_, err = tx.Exec(ctx,
+		`SELECT pg_advisory_xact_lock(hashtextextended(k, 0))
+		FROM unnest($1::text[]) WITH ORDINALITY AS a(k, n)
+		ORDER BY n`,
+		keys,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock recipients: %w", err)
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT user_id FROM coupons
+		WHERE campaign_id = $1 AND user_id = ANY($2) AND state = 'issued'
+		AND (expires_at IS NULL OR expires_at > now())
+		GROUP BY user_id
+		HAVING count(*) >= $3`,
+		campaignID,
+		users,
+		*maxHeldPerUser,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count held coupons: %w", err)
+	}
+	full, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count held coupons: %w", err)
+	}
+	if len(full) == 0 {
+		return users, nil, nil
+	}
+
+	held := make(map[string]bool, len(full))
+	for _, user := range full {
+		held[user] = true
+	}
+	var issued, skipped []string
+	for _, user := range users {
+		if held[user] {
+			skipped = append(skipped, user)
+		} else {
+			issued = append(issued, user)
+		}
+	}
+	return issued, skipped, nil
+}
+
+// releasePushReservation gives back the coupons reserved for the job's
+// current batch.
+func (s *CouponService) releasePushReservation(
+	ctx context.Context,
+	j *job,
+) error {
+	reservationKey := fmt.Sprintf(
+		"%s%s:%d", pushReservationKey, j.id, j.nextBatch,
+	)
+	granted, err := s.redis.GetDel(ctx, reservationKey).Int()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get reservation: %w", err)
+	}
+	// The batch may have finished the campaign
+	s.returnCampaignSlots(j.campaignID, granted, true)
+	return nil
+}
+
+func (s *CouponService) pushIssuanceFailures(
+	ctx context.Context,
+	jobID string,
+) ([]*coupon.JobFailure, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT user_id, error FROM push_issuance_recipients
+		WHERE job_id = $1 AND status = 'failed'
+		ORDER BY user_id
+		LIMIT $2`,
+		jobID,
+		maxJobFailures,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failures []*coupon.JobFailure
+	for rows.Next() {
+		var (
+			userID   string
+			errorMsg *string
+		)
+		if err := rows.Scan(&userID, &errorMsg); err != nil {
+			return nil, err
+		}
+		failures = append(failures, &coupon.JobFailure{
+			UserId: userID,
+			Error:  derefString(errorMsg),
+		})
+	}
+	return failures, rows.Err()
+}
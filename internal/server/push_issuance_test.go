@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/gen/coupon/v1/v1connect"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient serves the service over HTTP/2 so that streaming RPCs can be
+// exercised end to end.
+func newTestClient(
+	t *testing.T,
+	service *CouponService,
+) v1connect.CouponServiceClient {
+	_, handler := v1connect.NewCouponServiceHandler(service)
+	server := httptest.NewUnstartedServer(handler)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return v1connect.NewCouponServiceClient(server.Client(), server.URL)
+}
+
+func TestCouponService_StartPushIssuance(t *testing.T) {
+	service := setupTestService(t)
+	client := newTestClient(t, service)
+	ctx := context.Background()
+
+	campaignID := "00000000-0000-0000-0000-000000000000"
+	_, err := service.pool.Exec(ctx,
+		`INSERT INTO campaigns (id, name, start_time, coupon_limit, status)
+		VALUES ($1, $2, $3, $4, $5)`,
+		campaignID,
+		"Push Campaign",
+		time.Now(),
+		5,
+		"active",
+	)
+	require.NoError(t, err)
+
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, campaignID)
+	err = service.redis.Set(ctx, counterKey, 5, 0).Err()
+	require.NoError(t, err)
+
+	t.Run("empty user list is rejected", func(t *testing.T) {
+		stream := client.StartPushIssuance(ctx)
+		require.NoError(t, stream.Send(&coupon.StartPushIssuanceRequest{
+			CampaignId: campaignID,
+		}))
+		_, err := stream.CloseAndReceive()
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+
+	stream := client.StartPushIssuance(ctx)
+	require.NoError(t, stream.Send(&coupon.StartPushIssuanceRequest{
+		CampaignId: campaignID,
+		UserIds:    []string{"user-1", "user-2", "user-3", "user-4"},
+	}))
+	require.NoError(t, stream.Send(&coupon.StartPushIssuanceRequest{
+		UserIds: []string{"user-4", "user-5", "user-6", "user-7"},
+	}))
+	resp, err := stream.CloseAndReceive()
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), resp.Msg.TotalUsers)
+	jobID := resp.Msg.JobId
+
+	var job *coupon.GetJobResponse
+	require.Eventually(t, func() bool {
+		resp, err := service.GetJob(
+			ctx,
+			connect.NewRequest(&coupon.GetJobRequest{JobId: jobID}),
+		)
+		if err != nil {
+			return false
+		}
+		job = resp.Msg
+		return job.Status == "completed"
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, int32(7), job.Total)
+	assert.Equal(t, int32(7), job.Processed)
+	assert.Equal(t, int32(5), job.Succeeded)
+	assert.Equal(t, int32(2), job.Failed)
+	assert.Len(t, job.Failures, 2)
+
+	var owned int
+	err = service.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM coupons
+		WHERE campaign_id = $1 AND user_id IS NOT NULL`,
+		campaignID,
+	).Scan(&owned)
+	require.NoError(t, err)
+	assert.Equal(t, 5, owned)
+
+	var status string
+	err = service.pool.QueryRow(ctx,
+		"SELECT status FROM campaigns WHERE id = $1",
+		campaignID,
+	).Scan(&status)
+	require.NoError(t, err)
+	assert.Equal(t, "finished", status)
+
+	t.Run("unknown job", func(t *testing.T) {
+		_, err := service.GetJob(
+			ctx,
+			connect.NewRequest(&coupon.GetJobRequest{
+				JobId: "00000000-0000-0000-0000-000000000001",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+}
@@ -0,0 +1,512 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type (
+	RedeemCouponReq  = connect.Request[coupon.RedeemCouponRequest]
+	RedeemCouponResp = connect.Response[coupon.RedeemCouponResponse]
+)
+
+// uniqueViolation is the PostgreSQL error code for unique_violation.
+const uniqueViolation = "23505"
+
+// RedeemCoupon records a redemption of an issued coupon. A coupon can be
+// redeemed up to its max_redemptions; the usage count is checked and
+// incremented by a single conditional UPDATE, so racing checkouts can never
+// exceed the limit, and the coupon moves to redeemed with its last use.
+// Retrying with the same order reference returns the original redemption.
+func (s *CouponService) RedeemCoupon(
+	ctx context.Context,
+	req *RedeemCouponReq,
+) (*RedeemCouponResp, error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	if code == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code cannot be empty"),
+		)
+	}
+	orderRef := strings.TrimSpace(req.Msg.OrderRef)
+	if orderRef == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("order_ref cannot be empty"),
+		)
+	}
+
+	// A coupon held by a checkout can only be redeemed by that checkout,
+	// through CommitRedemption
+	resp, err := s.redeemCoupon(ctx, code, req.Msg.UserId, orderRef, "")
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// redeemCoupon holds the redemption logic shared by RedeemCoupon and
+// CommitRedemption. A checkoutID redeems a coupon locked by that checkout;
+// without one, the coupon must not be locked. Errors are always
+// *connect.Error.
+func (s *CouponService) redeemCoupon(
+	ctx context.Context,
+	code string,
+	userID string,
+	orderRef string,
+	checkoutID string,
+) (*coupon.RedeemCouponResponse, error) {
+	if err := s.flushPendingCoupon(ctx, code); err != nil {
+		return nil, err
+	}
+
+	// A vanity code is shared, so the user's claim is redeemed instead
+	campaignID, vanity, err := s.vanityCampaign(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if vanity {
+		if checkoutID != "" {
+			return nil, connect.NewError(
+				connect.CodeFailedPrecondition,
+				fmt.Errorf("coupon is not locked by this checkout"),
+			)
+		}
+		return s.redeemVanityCode(ctx, campaignID, code, userID, orderRef)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to begin transaction: %v", err),
+		)
+	}
+	defer func() {
+		if tx != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	var (
+		couponID       pgtype.UUID
+		state          couponState
+		count          int32
+		maxRedemptions int32
+		redeemedAt     time.Time
+	)
+	err = tx.QueryRow(ctx,
+		`UPDATE coupons c
+		SET redemption_count = c.redemption_count + 1,
+			state = CASE
+				WHEN c.redemption_count + 1 >=
+					COALESCE(c.max_redemptions, cp.max_redemptions)
+				THEN 'redeemed'::coupon_state
+				ELSE c.state
+			END,
+			redeemed_at = now(),
+			order_ref = $3,
+			locked_by = NULL,
+			locked_until = NULL
+		FROM campaigns cp
+		WHERE cp.id = c.campaign_id
+		AND c.code = $1
+		AND c.state = ANY($5::coupon_state[])
+		AND (c.expires_at IS NULL OR c.expires_at > now())
+		AND (c.user_id IS NULL OR c.user_id = $2)
+		AND CASE WHEN $4 = ''
+			THEN c.locked_until IS NULL OR c.locked_until <= now()
+			ELSE c.locked_by = $4 AND c.locked_until > now()
+		END
+		AND NOT EXISTS (
+			SELECT 1 FROM coupon_redemptions r
+			WHERE r.coupon_id = c.id AND r.order_ref = $3
+		)
+		RETURNING c.id, c.campaign_id, c.state, c.redemption_count,
+			COALESCE(c.max_redemptions, cp.max_redemptions), c.redeemed_at`,
+		code,
+		userID,
+		orderRef,
+		checkoutID,
+		statesTo(couponRedeemed),
+	).Scan(
+		&couponID,
+		&campaignID,
+		&state,
+		&count,
+		&maxRedemptions,
+		&redeemedAt,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.explainRedemptionFailure(
+			ctx, code, userID, orderRef, checkoutID,
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to redeem coupon: %v", err),
+		)
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO coupon_redemptions
+			(coupon_id, order_ref, user_id, redeemed_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4)`,
+		couponID,
+		orderRef,
+		userID,
+		redeemedAt,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		// The same order was redeemed concurrently
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			log.Printf("failed to rollback transaction: %v", rollbackErr)
+		}
+		tx = nil
+		return s.explainRedemptionFailure(
+			ctx, code, userID, orderRef, checkoutID,
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to record redemption: %v", err),
+		)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to commit transaction: %v", err),
+		)
+	}
+	tx = nil // Set tx to nil after successful commit
+
+	return &coupon.RedeemCouponResponse{
+		Code:            code,
+		CampaignId:      campaignID.String(),
+		State:           string(state),
+		RedeemedAt:      redeemedAt.Format(time.RFC3339),
+		OrderRef:        orderRef,
+		RedemptionCount: count,
+		MaxRedemptions:  maxRedemptions,
+	}, nil
+}
+
+func (s *CouponService) explainRedemptionFailure(
+	ctx context.Context,
+	code string,
+	userID string,
+	orderRef string,
+	checkoutID string,
+) (*coupon.RedeemCouponResponse, error) {
+	var (
+		couponID       pgtype.UUID
+		campaignID     pgtype.UUID
+		state          couponState
+		owner          *string
+		expiresAt      *time.Time
+		count          int32
+		maxRedemptions *int32
+		lockedBy       *string
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT c.id, c.campaign_id, c.state, c.user_id, c.expires_at,
+			c.redemption_count,
+			COALESCE(c.max_redemptions, cp.max_redemptions),
+			CASE WHEN c.locked_until > now() THEN c.locked_by END
+		FROM coupons c
+		LEFT JOIN campaigns cp ON cp.id = c.campaign_id
+		WHERE c.code = $1`,
+		code,
+	).Scan(
+		&couponID,
+		&campaignID,
+		&state,
+		&owner,
+		&expiresAt,
+		&count,
+		&maxRedemptions,
+		&lockedBy,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("coupon not found"),
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon: %v", err),
+		)
+	}
+
+	if owner != nil && *owner != userID {
+		return nil, connect.NewError(
+			connect.CodePermissionDenied,
+			fmt.Errorf("coupon belongs to another user"),
+		)
+	}
+
+	// Retried redemption of the same order
+	var (
+		redeemedAt time.Time
+		reversedAt *time.Time
+	)
+	err = s.pool.QueryRow(ctx,
+		`SELECT redeemed_at, reversed_at FROM coupon_redemptions
+		WHERE coupon_id = $1 AND order_ref = $2`,
+		couponID,
+		orderRef,
+	).Scan(&redeemedAt, &reversedAt)
+	if err == nil && reversedAt != nil {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("redemption for this order has been reversed"),
+		)
+	}
+	if err == nil {
+		return &coupon.RedeemCouponResponse{
+			Code:            code,
+			CampaignId:      campaignID.String(),
+			State:           string(state),
+			RedeemedAt:      redeemedAt.Format(time.RFC3339),
+			OrderRef:        orderRef,
+			RedemptionCount: count,
+			MaxRedemptions:  derefInt32(maxRedemptions),
+		}, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get redemption: %v", err),
+		)
+	}
+
+	if state == couponIssued && isExpired(expiresAt) {
+		state = couponExpired
+	}
+	if state.canTransitionTo(couponRedeemed) {
+		if checkoutID != "" && derefString(lockedBy) != checkoutID {
+			return nil, connect.NewError(
+				connect.CodeFailedPrecondition,
+				fmt.Errorf("coupon is not locked by this checkout"),
+			)
+		}
+		if checkoutID == "" && lockedBy != nil {
+			return nil, couponLockedError()
+		}
+	}
+	return nil, transitionError(state, couponRedeemed)
+}
+
+// SetCouponRedemptionLimit overrides the campaign's max_redemptions for a
+// single issued code. The new limit must exceed the redemptions already
+// made, so lowering it can never leave a coupon over its limit.
+func (s *CouponService) SetCouponRedemptionLimit(
+	ctx context.Context,
+	req *connect.Request[coupon.SetCouponRedemptionLimitRequest],
+) (*connect.Response[coupon.SetCouponRedemptionLimitResponse], error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	if code == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code cannot be empty"),
+		)
+	}
+	if req.Msg.MaxRedemptions <= 0 {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("max_redemptions must be greater than 0"),
+		)
+	}
+
+	if err := s.flushPendingCoupon(ctx, code); err != nil {
+		return nil, err
+	}
+
+	// Each claim of a vanity code is redeemed once
+	err := s.rejectVanityCode(ctx, code,
+		"vanity codes are redeemed once per claim")
+	if err != nil {
+		return nil, err
+	}
+
+	var count int32
+	err = s.pool.QueryRow(ctx,
+		`UPDATE coupons SET max_redemptions = $2
+		WHERE code = $1
+		AND state = ANY($3::coupon_state[])
+		AND redemption_count < $2
+		RETURNING redemption_count`,
+		code,
+		req.Msg.MaxRedemptions,
+		statesTo(couponRedeemed),
+	).Scan(&count)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, s.explainRedemptionLimitFailure(ctx, code)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to update redemption limit: %v", err),
+		)
+	}
+
+	return connect.NewResponse(&coupon.SetCouponRedemptionLimitResponse{
+		Code:            code,
+		RedemptionCount: count,
+		MaxRedemptions:  req.Msg.MaxRedemptions,
+	}), nil
+}
+
+func (s *CouponService) explainRedemptionLimitFailure(
+	ctx context.Context,
+	code string,
+) error {
+	var (
+		state couponState
+		count int32
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT state, redemption_count FROM coupons WHERE code = $1`,
+		code,
+	).Scan(&state, &count)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("coupon not found"),
+		)
+	}
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon: %v", err),
+		)
+	}
+
+	if err := checkTransition(state, couponRedeemed); err != nil {
+		return err
+	}
+	return connect.NewError(
+		connect.CodeFailedPrecondition,
+		fmt.Errorf(
+			"max_redemptions must exceed the %d redemptions already made",
+			count,
+		),
+	)
+}
+
+// ListCouponRedemptions returns a code's redemption ledger, oldest first.
+func (s *CouponService) ListCouponRedemptions(
+	ctx context.Context,
+	req *connect.Request[coupon.ListCouponRedemptionsRequest],
+) (*connect.Response[coupon.ListCouponRedemptionsResponse], error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	if code == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code cannot be empty"),
+		)
+	}
+
+	if err := s.flushPendingCoupon(ctx, code); err != nil {
+		return nil, err
+	}
+
+	var (
+		couponID       pgtype.UUID
+		maxRedemptions *int32
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT c.id, COALESCE(c.max_redemptions, cp.max_redemptions)
+		FROM coupons c
+		LEFT JOIN campaigns cp ON cp.id = c.campaign_id
+		WHERE c.code = $1`,
+		code,
+	).Scan(&couponID, &maxRedemptions)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("coupon not found"),
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon: %v", err),
+		)
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT order_ref, user_id, redeemed_at, reversed_at
+		FROM coupon_redemptions
+		WHERE coupon_id = $1
+		ORDER BY redeemed_at, id`,
+		couponID,
+	)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get redemptions: %v", err),
+		)
+	}
+	defer rows.Close()
+
+	resp := &coupon.ListCouponRedemptionsResponse{
+		Code:           code,
+		MaxRedemptions: derefInt32(maxRedemptions),
+	}
+	for rows.Next() {
+		var (
+			orderRef   string
+			userID     *string
+			redeemedAt time.Time
+			reversedAt *time.Time
+		)
+		err := rows.Scan(&orderRef, &userID, &redeemedAt, &reversedAt)
+		if err != nil {
+			return nil, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to scan redemption: %v", err),
+			)
+		}
+		resp.Redemptions = append(resp.Redemptions, &coupon.CouponRedemption{
+			OrderRef:   orderRef,
+			UserId:     derefString(userID),
+			RedeemedAt: redeemedAt.Format(time.RFC3339),
+			ReversedAt: formatOptionalTime(reversedAt),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("error iterating redemptions: %v", err),
+		)
+	}
+
+	return connect.NewResponse(resp), nil
+}
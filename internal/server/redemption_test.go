@@ -0,0 +1,263 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issueTestCoupon creates an active campaign and issues one coupon from it.
+func issueTestCoupon(
+	t *testing.T,
+	service *CouponService,
+	userID string,
+) (string, string) {
+	ctx := context.Background()
+
+	campaignID := "00000000-0000-0000-0000-000000000000"
+	_, err := service.pool.Exec(ctx,
+		`INSERT INTO campaigns (id, name, start_time, coupon_limit, status)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO NOTHING`,
+		campaignID,
+		"Test Campaign",
+		time.Now(),
+		100,
+		"active",
+	)
+	require.NoError(t, err)
+
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, campaignID)
+	err = service.redis.SetNX(ctx, counterKey, 100, 0).Err()
+	require.NoError(t, err)
+
+	resp, err := service.IssueCoupon(
+		ctx,
+		connect.NewRequest(&coupon.IssueCouponRequest{
+			CampaignId: campaignID,
+			UserId:     userID,
+		}),
+	)
+	require.NoError(t, err)
+
+	return campaignID, resp.Msg.CouponCode
+}
+
+func TestCouponService_RedeemCoupon(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	t.Run("redeems an unflushed coupon", func(t *testing.T) {
+		campaignID, code := issueTestCoupon(t, service, "user-1")
+
+		resp, err := service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "user-1",
+				OrderRef: "order-1",
+			}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, campaignID, resp.Msg.CampaignId)
+		assert.Equal(t, "redeemed", resp.Msg.State)
+		assert.NotEmpty(t, resp.Msg.RedeemedAt)
+
+		var (
+			state    string
+			orderRef string
+		)
+		err = service.pool.QueryRow(ctx,
+			"SELECT state, order_ref FROM coupons WHERE code = $1",
+			code,
+		).Scan(&state, &orderRef)
+		require.NoError(t, err)
+		assert.Equal(t, "redeemed", state)
+		assert.Equal(t, "order-1", orderRef)
+
+		// Retrying the same order is idempotent
+		_, err = service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "user-1",
+				OrderRef: "order-1",
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "user-1",
+				OrderRef: "order-2",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	t.Run("rejects another user's coupon", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+
+		_, err := service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "user-2",
+				OrderRef: "order-3",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		_, err := service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     "does-not-exist",
+				OrderRef: "order-4",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+
+	t.Run("concurrent checkouts redeem once", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "")
+
+		results := make(chan error, 5)
+		for i := 0; i < 5; i++ {
+			go func() {
+				_, err := service.RedeemCoupon(
+					ctx,
+					connect.NewRequest(&coupon.RedeemCouponRequest{
+						Code:     code,
+						OrderRef: fmt.Sprintf("checkout-%d", i),
+					}),
+				)
+				results <- err
+			}()
+		}
+
+		successCount := 0
+		for i := 0; i < 5; i++ {
+			if err := <-results; err == nil {
+				successCount++
+			}
+		}
+		assert.Equal(t, 1, successCount)
+	})
+}
+
+func TestCouponService_MultiUseCoupon(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	_, code := issueTestCoupon(t, service, "")
+	limitResp, err := service.SetCouponRedemptionLimit(
+		ctx,
+		connect.NewRequest(&coupon.SetCouponRedemptionLimitRequest{
+			Code:           code,
+			MaxRedemptions: 3,
+		}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), limitResp.Msg.MaxRedemptions)
+
+	results := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			_, err := service.RedeemCoupon(
+				ctx,
+				connect.NewRequest(&coupon.RedeemCouponRequest{
+					Code:     code,
+					UserId:   fmt.Sprintf("buyer-%d", i),
+					OrderRef: fmt.Sprintf("order-%d", i),
+				}),
+			)
+			results <- err
+		}()
+	}
+
+	successCount := 0
+	for i := 0; i < 10; i++ {
+		if err := <-results; err == nil {
+			successCount++
+		}
+	}
+	assert.Equal(t, 3, successCount)
+
+	listResp, err := service.ListCouponRedemptions(
+		ctx,
+		connect.NewRequest(&coupon.ListCouponRedemptionsRequest{Code: code}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), listResp.Msg.MaxRedemptions)
+	require.Len(t, listResp.Msg.Redemptions, 3)
+
+	var (
+		state string
+		count int
+	)
+	err = service.pool.QueryRow(ctx,
+		"SELECT state, redemption_count FROM coupons WHERE code = $1",
+		code,
+	).Scan(&state, &count)
+	require.NoError(t, err)
+	assert.Equal(t, "redeemed", state)
+	assert.Equal(t, 3, count)
+
+	// Retrying a recorded order is idempotent even after the last use
+	first := listResp.Msg.Redemptions[0]
+	resp, err := service.RedeemCoupon(
+		ctx,
+		connect.NewRequest(&coupon.RedeemCouponRequest{
+			Code:     code,
+			UserId:   first.UserId,
+			OrderRef: first.OrderRef,
+		}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), resp.Msg.RedemptionCount)
+
+	t.Run("limit must exceed redemptions made", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "")
+		_, err := service.SetCouponRedemptionLimit(
+			ctx,
+			connect.NewRequest(&coupon.SetCouponRedemptionLimitRequest{
+				Code:           code,
+				MaxRedemptions: 2,
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				OrderRef: "limit-order",
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = service.SetCouponRedemptionLimit(
+			ctx,
+			connect.NewRequest(&coupon.SetCouponRedemptionLimitRequest{
+				Code:           code,
+				MaxRedemptions: 1,
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+}
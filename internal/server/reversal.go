@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+	"coupon-issuance/internal/utils"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type (
+	ReverseRedemptionReq  = connect.Request[coupon.ReverseRedemptionRequest]
+	ReverseRedemptionResp = connect.Response[coupon.ReverseRedemptionResponse]
+)
+
+const couponEventRedemptionReversed = "redemption_reversed"
+
+// reversalWindowFromEnv returns how long after a redemption it can still be
+// reversed.
+func reversalWindowFromEnv() (time.Duration, error) {
+	value := utils.GetEnv("REDEMPTION_REVERSAL_WINDOW", "720h")
+	window, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid REDEMPTION_REVERSAL_WINDOW: %w", err)
+	}
+	if window <= 0 {
+		return 0, fmt.Errorf("REDEMPTION_REVERSAL_WINDOW must be positive")
+	}
+	return window, nil
+}
+
+// ReverseRedemption undoes the redemption made for an order, typically
+// after a refund, and returns the coupon to issued. Only redemptions inside
+// the reversal window of coupons that have not expired can be reversed.
+// The ledger entry is marked as reversed rather than deleted, so repeating
+// the call for the same order does not reverse it twice.
+func (s *CouponService) ReverseRedemption(
+	ctx context.Context,
+	req *ReverseRedemptionReq,
+) (*ReverseRedemptionResp, error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	if code == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code cannot be empty"),
+		)
+	}
+	orderRef := strings.TrimSpace(req.Msg.OrderRef)
+	if orderRef == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("order_ref cannot be empty"),
+		)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to begin transaction: %v", err),
+		)
+	}
+	defer func() {
+		if tx != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	var (
+		couponID   pgtype.UUID
+		reversedAt time.Time
+	)
+	err = tx.QueryRow(ctx,
+		`UPDATE coupon_redemptions r SET reversed_at = now()
+		FROM coupons c
+		WHERE c.id = r.coupon_id
+		AND c.code = $1
+		AND r.order_ref = $2
+		AND r.reversed_at IS NULL
+		AND r.redeemed_at > now() - make_interval(secs => $3)
+		RETURNING r.coupon_id, r.reversed_at`,
+		code,
+		orderRef,
+		s.reversalWindow.Seconds(),
+	).Scan(&couponID, &reversedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.explainReversalFailure(ctx, code, orderRef)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to reverse redemption: %v", err),
+		)
+	}
+
+	campaignID, vanity, err := s.vanityCampaign(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if vanity {
+		err := reverseVanityRedemption(ctx, tx, couponID, orderRef)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to commit transaction: %v", err),
+			)
+		}
+		tx = nil // Set tx to nil after successful commit
+
+		return connect.NewResponse(&coupon.ReverseRedemptionResponse{
+			Code:       code,
+			CampaignId: campaignID.String(),
+			State:      string(couponIssued),
+			OrderRef:   orderRef,
+			ReversedAt: reversedAt.Format(time.RFC3339),
+		}), nil
+	}
+
+	// The coupon's last redemption is recomputed from the ledger
+	var (
+		fromState couponState
+		count     int32
+	)
+	err = tx.QueryRow(ctx,
+		`UPDATE coupons c
+		SET state = 'issued',
+			redemption_count = c.redemption_count - 1,
+			redeemed_at = last.redeemed_at,
+			order_ref = last.order_ref
+		FROM coupons prev
+		LEFT JOIN LATERAL (
+			SELECT redeemed_at, order_ref FROM coupon_redemptions
+			WHERE coupon_id = prev.id AND reversed_at IS NULL
+			ORDER BY redeemed_at DESC, id DESC
+			LIMIT 1
+		) last ON true
+		WHERE c.id = $1
+		AND prev.id = c.id
+		AND c.state = ANY($2::coupon_state[])
+		AND (c.expires_at IS NULL OR c.expires_at > now())
+		RETURNING c.campaign_id, prev.state, c.redemption_count`,
+		couponID,
+		reversibleStates(),
+	).Scan(&campaignID, &fromState, &count)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, s.explainReversalStateFailure(ctx, couponID)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to restore coupon: %v", err),
+		)
+	}
+
+	err = recordCouponEvent(ctx, tx, couponEvent{
+		couponID:  couponID,
+		event:     couponEventRedemptionReversed,
+		fromState: fromState,
+		toState:   couponIssued,
+		details:   map[string]any{"order_ref": orderRef},
+	})
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to record coupon event: %v", err),
+		)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to commit transaction: %v", err),
+		)
+	}
+	tx = nil // Set tx to nil after successful commit
+
+	return connect.NewResponse(&coupon.ReverseRedemptionResponse{
+		Code:            code,
+		CampaignId:      campaignID.String(),
+		State:           string(couponIssued),
+		OrderRef:        orderRef,
+		ReversedAt:      reversedAt.Format(time.RFC3339),
+		RedemptionCount: count,
+	}), nil
+}
+
+func (s *CouponService) explainReversalFailure(
+	ctx context.Context,
+	code string,
+	orderRef string,
+) (*ReverseRedemptionResp, error) {
+	var (
+		campaignID pgtype.UUID
+		state      couponState
+		count      int32
+		reversedAt *time.Time
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT c.campaign_id, c.state, c.redemption_count, r.reversed_at
+		FROM coupons c
+		JOIN coupon_redemptions r ON r.coupon_id = c.id
+		WHERE c.code = $1 AND r.order_ref = $2`,
+		code,
+		orderRef,
+	).Scan(&campaignID, &state, &count, &reversedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("no redemption of this coupon for order %s", orderRef),
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get redemption: %v", err),
+		)
+	}
+
+	// Repeated reversal of the same order
+	if reversedAt != nil {
+		return connect.NewResponse(&coupon.ReverseRedemptionResponse{
+			Code:            code,
+			CampaignId:      campaignID.String(),
+			State:           string(state),
+			OrderRef:        orderRef,
+			ReversedAt:      reversedAt.Format(time.RFC3339),
+			RedemptionCount: count,
+		}), nil
+	}
+
+	return nil, connect.NewError(
+		connect.CodeFailedPrecondition,
+		fmt.Errorf("reversal window of %s has passed", s.reversalWindow),
+	)
+}
+
+// reversibleStates are the states a redemption can leave a coupon in: the
+// states that may move to redeemed, which a coupon with redemptions left
+// stays in, and redeemed itself. Reversing returns the coupon to issued.
+func reversibleStates() []string {
+	states := append(statesTo(couponRedeemed), string(couponRedeemed))
+	slices.Sort(states)
+	return states
+}
+
+func (s *CouponService) explainReversalStateFailure(
+	ctx context.Context,
+	couponID pgtype.UUID,
+) error {
+	var (
+		state     couponState
+		expiresAt *time.Time
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT state, expires_at FROM coupons WHERE id = $1`,
+		couponID,
+	).Scan(&state, &expiresAt)
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon: %v", err),
+		)
+	}
+
+	if isExpired(expiresAt) {
+		state = couponExpired
+	}
+	return transitionError(state, couponIssued)
+}
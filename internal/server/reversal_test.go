@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReversalWindowFromEnv(t *testing.T) {
+	window, err := reversalWindowFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 720*time.Hour, window)
+
+	t.Setenv("REDEMPTION_REVERSAL_WINDOW", "48h")
+	window, err = reversalWindowFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, window)
+
+	t.Setenv("REDEMPTION_REVERSAL_WINDOW", "two days")
+	_, err = reversalWindowFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("REDEMPTION_REVERSAL_WINDOW", "-1h")
+	_, err = reversalWindowFromEnv()
+	assert.Error(t, err)
+}
+
+func TestCouponService_ReverseRedemption(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	redeem := func(t *testing.T, code, orderRef string) {
+		_, err := service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "user-1",
+				OrderRef: orderRef,
+			}),
+		)
+		require.NoError(t, err)
+	}
+	reverse := func(code, orderRef string) (*ReverseRedemptionResp, error) {
+		return service.ReverseRedemption(
+			ctx,
+			connect.NewRequest(&coupon.ReverseRedemptionRequest{
+				Code:     code,
+				OrderRef: orderRef,
+			}),
+		)
+	}
+
+	t.Run("restores the coupon once", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+		redeem(t, code, "order-1")
+
+		resp, err := reverse(code, "order-1")
+		require.NoError(t, err)
+		assert.Equal(t, "issued", resp.Msg.State)
+		assert.Equal(t, int32(0), resp.Msg.RedemptionCount)
+
+		// Repeating the reversal does not reverse it twice
+		resp, err = reverse(code, "order-1")
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.Msg.RedemptionCount)
+
+		var events int
+		err = service.pool.QueryRow(ctx,
+			`SELECT count(*) FROM coupon_events e
+			JOIN coupons c ON c.id = e.coupon_id
+			WHERE c.code = $1 AND e.event = 'redemption_reversed'`,
+			code,
+		).Scan(&events)
+		require.NoError(t, err)
+		assert.Equal(t, 1, events)
+
+		// The refunded order cannot use the coupon again, another one can
+		_, err = service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "user-1",
+				OrderRef: "order-1",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+		redeem(t, code, "order-2")
+	})
+
+	t.Run("outside the reversal window", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+		redeem(t, code, "order-3")
+
+		_, err := service.pool.Exec(ctx,
+			`UPDATE coupon_redemptions
+			SET redeemed_at = now() - make_interval(secs => $2)
+			WHERE order_ref = $1`,
+			"order-3",
+			(service.reversalWindow + time.Hour).Seconds(),
+		)
+		require.NoError(t, err)
+
+		_, err = reverse(code, "order-3")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	t.Run("expired coupon", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+		redeem(t, code, "order-4")
+
+		_, err := service.pool.Exec(ctx,
+			`UPDATE coupons SET expires_at = now() - interval '1 minute'
+			WHERE code = $1`,
+			code,
+		)
+		require.NoError(t, err)
+
+		_, err = reverse(code, "order-4")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		var state string
+		err = service.pool.QueryRow(ctx,
+			"SELECT state FROM coupons WHERE code = $1",
+			code,
+		).Scan(&state)
+		require.NoError(t, err)
+		assert.Equal(t, "redeemed", state)
+	})
+
+	t.Run("unknown order", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+
+		_, err := reverse(code, "order-5")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type (
+	RevokeCouponReq  = connect.Request[coupon.RevokeCouponRequest]
+	RevokeCouponResp = connect.Response[coupon.RevokeCouponResponse]
+)
+
+const couponEventRevoked = "revoked"
+
+// RevokeCoupon voids an issued coupon and records why in its audit trail.
+// With return_to_pool the campaign gets the slot back: its counter is
+// incremented and a finished campaign becomes active again.
+func (s *CouponService) RevokeCoupon(
+	ctx context.Context,
+	req *RevokeCouponReq,
+) (*RevokeCouponResp, error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	if code == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code cannot be empty"),
+		)
+	}
+	reason := strings.TrimSpace(req.Msg.Reason)
+	if reason == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("reason cannot be empty"),
+		)
+	}
+
+	if err := s.flushPendingCoupon(ctx, code); err != nil {
+		return nil, err
+	}
+
+	// Voiding a vanity code would end it for every user who claimed it,
+	// and its slots are held by the claims rather than the code
+	err := s.rejectVanityCode(ctx, code,
+		"vanity codes are shared by every claim and cannot be revoked")
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to begin transaction: %v", err),
+		)
+	}
+	defer func() {
+		if tx != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	var (
+		couponID   pgtype.UUID
+		campaignID pgtype.UUID
+		revokedAt  time.Time
+		imported   bool
+	)
+	err = tx.QueryRow(ctx,
+		`UPDATE coupons c SET state = 'void'
+		FROM campaigns cp
+		WHERE c.code = $1
+		AND cp.id = c.campaign_id
+		AND c.state = ANY($2::coupon_state[])
+		AND (c.expires_at IS NULL OR c.expires_at > now())
+		AND (c.locked_until IS NULL OR c.locked_until <= now())
+		RETURNING c.id, c.campaign_id, c.updated_at, cp.imported_codes`,
+		code,
+		statesTo(couponVoid),
+	).Scan(&couponID, &campaignID, &revokedAt, &imported)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, s.explainRevocationFailure(ctx, code)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to revoke coupon: %v", err),
+		)
+	}
+
+	err = recordCouponEvent(ctx, tx, couponEvent{
+		couponID:  couponID,
+		event:     couponEventRevoked,
+		fromState: couponIssued,
+		toState:   couponVoid,
+		reason:    reason,
+		details:   map[string]any{"return_to_pool": req.Msg.ReturnToPool},
+	})
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to record coupon event: %v", err),
+		)
+	}
+
+	// Every slot of an imported campaign is backed by one of its codes, and
+	// a revoked code is not issued again
+	if req.Msg.ReturnToPool && imported {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("imported codes cannot be returned to the pool"),
+		)
+	}
+
+	var counterKey string
+	if req.Msg.ReturnToPool {
+		_, err = tx.Exec(ctx,
+			`UPDATE campaigns SET status = 'active'
+			WHERE id = $1 AND status = 'finished'`,
+			campaignID,
+		)
+		if err != nil {
+			return nil, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to reopen campaign: %v", err),
+			)
+		}
+
+		// The counter is incremented before commit and decremented again if
+		// the commit fails, so the slot is never returned for a coupon that
+		// is still issued
+		counterKey = fmt.Sprintf("%s%s", campaignCounterKey, campaignID.String())
+		if err := s.redis.Incr(ctx, counterKey).Err(); err != nil {
+			return nil, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to return coupon to campaign: %v", err),
+			)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		if counterKey != "" {
+			s.redis.Decr(ctx, counterKey)
+		}
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to commit transaction: %v", err),
+		)
+	}
+	tx = nil // Set tx to nil after successful commit
+
+	return connect.NewResponse(&coupon.RevokeCouponResponse{
+		Code:           code,
+		CampaignId:     campaignID.String(),
+		State:          string(couponVoid),
+		RevokedAt:      revokedAt.Format(time.RFC3339),
+		ReturnedToPool: req.Msg.ReturnToPool,
+	}), nil
+}
+
+func (s *CouponService) explainRevocationFailure(
+	ctx context.Context,
+	code string,
+) error {
+	var (
+		state     couponState
+		expiresAt *time.Time
+		locked    bool
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT state, expires_at, COALESCE(locked_until > now(), false)
+		FROM coupons WHERE code = $1`,
+		code,
+	).Scan(&state, &expiresAt, &locked)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("coupon not found"),
+		)
+	}
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon: %v", err),
+		)
+	}
+
+	if state == couponIssued && isExpired(expiresAt) {
+		state = couponExpired
+	}
+	if state.canTransitionTo(couponVoid) && locked {
+		return couponLockedError()
+	}
+	return transitionError(state, couponVoid)
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponService_RevokeCoupon(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	t.Run("voids the coupon and records the reason", func(t *testing.T) {
+		campaignID, code := issueTestCoupon(t, service, "user-1")
+
+		resp, err := service.RevokeCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RevokeCouponRequest{
+				Code:   code,
+				Reason: "issued by mistake",
+			}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, campaignID, resp.Msg.CampaignId)
+		assert.Equal(t, "void", resp.Msg.State)
+		assert.False(t, resp.Msg.ReturnedToPool)
+
+		var reason, fromState, toState string
+		err = service.pool.QueryRow(ctx,
+			`SELECT e.reason, e.from_state, e.to_state
+			FROM coupon_events e
+			JOIN coupons c ON c.id = e.coupon_id
+			WHERE c.code = $1 AND e.event = 'revoked'`,
+			code,
+		).Scan(&reason, &fromState, &toState)
+		require.NoError(t, err)
+		assert.Equal(t, "issued by mistake", reason)
+		assert.Equal(t, "issued", fromState)
+		assert.Equal(t, "void", toState)
+
+		_, err = service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "user-1",
+				OrderRef: "order-1",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	t.Run("cannot revoke twice", func(t *testing.T) {
+		_, code := issueTestCoupon(t, service, "user-1")
+
+		req := &coupon.RevokeCouponRequest{Code: code, Reason: "fraud"}
+		_, err := service.RevokeCoupon(ctx, connect.NewRequest(req))
+		require.NoError(t, err)
+
+		_, err = service.RevokeCoupon(ctx, connect.NewRequest(req))
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	t.Run("returns the slot and reopens a finished campaign", func(t *testing.T) {
+		campaignID, code := issueTestCoupon(t, service, "user-1")
+
+		counterKey := fmt.Sprintf("%s%s", campaignCounterKey, campaignID)
+		require.NoError(t, service.redis.Set(ctx, counterKey, 0, 0).Err())
+		require.NoError(t, service.updateCampaignToFinished(ctx, campaignID))
+
+		resp, err := service.RevokeCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RevokeCouponRequest{
+				Code:         code,
+				Reason:       "fraud",
+				ReturnToPool: true,
+			}),
+		)
+		require.NoError(t, err)
+		assert.True(t, resp.Msg.ReturnedToPool)
+
+		remaining, err := service.redis.Get(ctx, counterKey).Int()
+		require.NoError(t, err)
+		assert.Equal(t, 1, remaining)
+
+		var status string
+		err = service.pool.QueryRow(ctx,
+			"SELECT status FROM campaigns WHERE id = $1",
+			campaignID,
+		).Scan(&status)
+		require.NoError(t, err)
+		assert.Equal(t, "active", status)
+
+		_, err = service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: campaignID,
+				UserId:     "user-2",
+			}),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		_, err := service.RevokeCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RevokeCouponRequest{
+				Code:   "unknown",
+				Reason: "fraud",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	})
+
+	t.Run("requires a reason", func(t *testing.T) {
+		_, err := service.RevokeCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RevokeCouponRequest{Code: "code"}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
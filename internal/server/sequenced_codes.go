@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+)
+
+// claimCodeSpace records which kind of codes a new unsigned campaign's
+// format is used for. Random codes are only unique among the codes
+// reserved so far, so a format cannot be shared by random and sequenced
+// campaigns.
+func claimCodeSpace(ctx context.Context, tx pgx.Tx, format codeFormat) error {
+	key := format.getLayout().Key()
+
+	// Serializes campaigns created with the same format
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key)
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to lock code format: %v", err),
+		)
+	}
+
+	var sequenced bool
+	err = tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM code_spaces WHERE format_key = $1)`,
+		key,
+	).Scan(&sequenced)
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to check code format: %v", err),
+		)
+	}
+	if format.kind != codesSequenced {
+		if sequenced {
+			return connect.NewError(
+				connect.CodeFailedPrecondition,
+				fmt.Errorf("code format is used for sequenced codes"),
+			)
+		}
+		return nil
+	}
+	if sequenced {
+		return nil
+	}
+
+	random, err := usesRandomCodes(ctx, tx, key)
+	if err != nil {
+		return err
+	}
+	if random {
+		return connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("code format is used for random codes"),
+		)
+	}
+
+	secret := make([]byte, signingKeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to generate code space key: %v", err),
+		)
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO code_spaces (format_key, secret) VALUES ($1, $2)`,
+		key,
+		secret,
+	)
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to create code space: %v", err),
+		)
+	}
+	return nil
+}
+
+// usesRandomCodes reports whether any campaign draws random codes of the
+// layout with the given key.
+func usesRandomCodes(ctx context.Context, tx pgx.Tx, key string) (bool, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT DISTINCT code_format FROM campaigns
+		WHERE NOT signed_codes AND NOT sequenced_codes`,
+	)
+	if err != nil {
+		return false, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get code formats: %v", err),
+		)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f *codeformat.Format
+		if err := rows.Scan(&f); err != nil {
+			return false, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to scan code format: %v", err),
+			)
+		}
+		format, err := newCodeFormat(codesRandom, f)
+		if err != nil {
+			return false, connect.NewError(connect.CodeInternal, err)
+		}
+		if format.getLayout().Key() == key {
+			return true, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("error iterating code formats: %v", err),
+		)
+	}
+	return false, nil
+}
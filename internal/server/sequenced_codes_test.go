@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponService_SequencedCodes(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	format := &coupon.CodeFormat{
+		Alphabet: coupon.CodeAlphabet_CODE_ALPHABET_DIGITS,
+		Length:   8,
+		Prefix:   "SEQ-",
+	}
+	createCampaign := func(sequenced, signed bool) (string, error) {
+		created, err := service.CreateCampaign(
+			ctx,
+			connect.NewRequest(&coupon.CreateCampaignRequest{
+				Name:           "Sequenced Campaign",
+				StartTime:      time.Now().Format(time.RFC3339),
+				CouponLimit:    100,
+				CodeFormat:     format,
+				SequencedCodes: sequenced,
+				SignedCodes:    signed,
+			}),
+		)
+		if err != nil {
+			return "", err
+		}
+		_, err = service.pool.Exec(ctx,
+			`UPDATE campaigns SET status = 'active' WHERE id = $1`,
+			created.Msg.CampaignId,
+		)
+		require.NoError(t, err)
+		return created.Msg.CampaignId, nil
+	}
+
+	campaignID, err := createCampaign(true, false)
+	require.NoError(t, err)
+
+	t.Run("codes are reserved in blocks", func(t *testing.T) {
+		var available int
+		err := service.pool.QueryRow(ctx,
+			`SELECT count(*) FROM coupons WHERE code LIKE 'SEQ%'`,
+		).Scan(&available)
+		require.NoError(t, err)
+		assert.Zero(t, available)
+
+		codes := make(map[string]bool)
+		for range 20 {
+			issued, err := service.IssueCoupon(
+				ctx,
+				connect.NewRequest(&coupon.IssueCouponRequest{
+					CampaignId: campaignID,
+					UserId:     "user-1",
+				}),
+			)
+			require.NoError(t, err)
+			code := issued.Msg.CouponCode
+			assert.Regexp(t, `^SEQ-[0-9]{8}$`, code)
+			assert.False(t, codes[code], code)
+			codes[code] = true
+		}
+		require.NoError(t, service.codeGen.writeIssuedCodes(ctx, service.pool))
+
+		var written int
+		err = service.pool.QueryRow(ctx,
+			`SELECT count(*) FROM coupons
+			WHERE campaign_id = $1 AND state = 'issued' AND user_id = 'user-1'`,
+			campaignID,
+		).Scan(&written)
+		require.NoError(t, err)
+		assert.Equal(t, len(codes), written)
+
+		err = service.pool.QueryRow(ctx,
+			`SELECT count(*) FROM coupons
+			WHERE code LIKE 'SEQ%' AND state = 'available'`,
+		).Scan(&available)
+		require.NoError(t, err)
+		assert.Equal(t, formatBatchSize-len(codes), available)
+	})
+
+	t.Run("codes reverse to their sequence number", func(t *testing.T) {
+		var secret []byte
+		err := service.pool.QueryRow(ctx,
+			`SELECT secret FROM code_spaces`,
+		).Scan(&secret)
+		require.NoError(t, err)
+
+		f, err := parseCodeFormat(format, 100, false)
+		require.NoError(t, err)
+		layout, err := f.Compile()
+		require.NoError(t, err)
+		sequencer := codeformat.NewSequencer(layout, secret)
+
+		rows, err := service.pool.Query(ctx,
+			`SELECT code FROM coupons WHERE campaign_id = $1`,
+			campaignID,
+		)
+		require.NoError(t, err)
+		defer rows.Close()
+		for rows.Next() {
+			var code string
+			require.NoError(t, rows.Scan(&code))
+			seq, ok := sequencer.Sequence(code)
+			require.True(t, ok, code)
+			assert.Less(t, seq, uint64(formatBatchSize))
+		}
+		require.NoError(t, rows.Err())
+	})
+
+	t.Run("a format is sequenced or random", func(t *testing.T) {
+		_, err := createCampaign(false, false)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		// Campaigns with the same format share the code space
+		_, err = createCampaign(true, false)
+		assert.NoError(t, err)
+
+		format = &coupon.CodeFormat{Pattern: "RND-**********"}
+		_, err = createCampaign(false, false)
+		require.NoError(t, err)
+		_, err = createCampaign(true, false)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	t.Run("not combinable with signed codes", func(t *testing.T) {
+		_, err := createCampaign(true, true)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+
+	t.Run("stored codes are skipped", func(t *testing.T) {
+		format = &coupon.CodeFormat{
+			Alphabet: coupon.CodeAlphabet_CODE_ALPHABET_DIGITS,
+			Length:   8,
+			Prefix:   "COL-",
+		}
+		campaignID, err := createCampaign(true, false)
+		require.NoError(t, err)
+
+		f, err := parseCodeFormat(format, 100, false)
+		require.NoError(t, err)
+		layout, err := f.Compile()
+		require.NoError(t, err)
+		var secret []byte
+		err = service.pool.QueryRow(ctx,
+			`SELECT secret FROM code_spaces WHERE format_key = $1`,
+			layout.Key(),
+		).Scan(&secret)
+		require.NoError(t, err)
+
+		// An imported code spelled like the first code of the space
+		taken, err := codeformat.NewSequencer(layout, secret).Code(0)
+		require.NoError(t, err)
+		_, err = service.pool.Exec(ctx,
+			`INSERT INTO coupons (code) VALUES ($1)`,
+			codeformat.Normalize(taken),
+		)
+		require.NoError(t, err)
+
+		issued, err := service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: campaignID,
+				UserId:     "user-1",
+			}),
+		)
+		require.NoError(t, err)
+		assert.NotEqual(t, taken, issued.Msg.CouponCode)
+	})
+}
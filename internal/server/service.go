@@ -8,10 +8,16 @@ import (
 	"time"
 
 	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/benefit"
+	"coupon-issuance/internal/codeformat"
+	"coupon-issuance/internal/codeimage"
 	"coupon-issuance/internal/database"
+	"coupon-issuance/internal/eligibility"
 	redisclient "coupon-issuance/internal/redis"
+	"coupon-issuance/internal/utils"
 
 	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
@@ -20,6 +26,9 @@ import (
 const (
 	campaignActivationKey = "campaign:activation:"
 	campaignCounterKey    = "campaign:counter:"
+	couponLockKey         = "coupon:lock:"
+
+	backgroundWorkerCount = 4
 )
 
 type CouponService struct {
@@ -29,6 +38,11 @@ type CouponService struct {
 	context                  context.Context
 	cancelBackgroundWorkers  context.CancelFunc
 	backgroundWorkersStopped chan struct{}
+	reversalWindow           time.Duration
+	imageSigner              *codeimage.Signer
+	eligibility              *eligibilityCache
+	// publicBaseURL prefixes the URLs of coupon images
+	publicBaseURL string
 }
 
 func (s *CouponService) updateCampaignStatus(
@@ -157,7 +171,23 @@ func NewCouponService() *CouponService {
 		log.Fatalf("Failed to create Redis client: %v", err)
 	}
 
+	reversalWindow, err := reversalWindowFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to read configuration: %v", err)
+	}
+
+	blocklist, err := codeBlocklistFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to read configuration: %v", err)
+	}
+
+	imageSigner, err := imageSignerFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to read configuration: %v", err)
+	}
+
 	codeGen := newCodeGenerator()
+	codeGen.blocklist = blocklist
 
 	service := &CouponService{
 		pool:                     pool,
@@ -165,11 +195,19 @@ func NewCouponService() *CouponService {
 		codeGen:                  codeGen,
 		context:                  ctx,
 		cancelBackgroundWorkers:  cancel,
-		backgroundWorkersStopped: make(chan struct{}, 2),
+		backgroundWorkersStopped: make(chan struct{}, backgroundWorkerCount),
+		reversalWindow:           reversalWindow,
+		imageSigner:              imageSigner,
+		eligibility:              newEligibilityCache(),
+		publicBaseURL: strings.TrimSuffix(
+			utils.GetEnv("PUBLIC_BASE_URL", ""), "/",
+		),
 	}
 
 	go service.startCampaignStatusWorker(backgroundCtx)
 	go service.startCouponCodeWriter(backgroundCtx)
+	go service.startJobWorker(backgroundCtx)
+	go service.startCouponExpiryWorker(backgroundCtx)
 
 	return service
 }
@@ -195,7 +233,63 @@ func (s *CouponService) CreateCampaign(
 		)
 	}
 
-	if req.Msg.CouponLimit <= 0 {
+	var vanityCode *string
+	if code := codeformat.Normalize(req.Msg.VanityCode); code != "" {
+		if err := validateCustomCode(code); err != nil {
+			return nil, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("invalid vanity_code: %v", err),
+			)
+		}
+		vanityCode = &code
+	}
+
+	kinds := 0
+	for _, set := range []bool{
+		req.Msg.SignedCodes,
+		req.Msg.SequencedCodes,
+		req.Msg.ImportedCodes,
+		vanityCode != nil,
+	} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds > 1 {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf(
+				"signed_codes, sequenced_codes, imported_codes and "+
+					"vanity_code are exclusive",
+			),
+		)
+	}
+	if vanityCode != nil &&
+		(req.Msg.CodeFormat != nil || req.Msg.Transferable) {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf(
+				"vanity codes cannot have a code format or be transferable",
+			),
+		)
+	}
+	kind := campaignCodeKind(
+		req.Msg.SignedCodes,
+		req.Msg.SequencedCodes,
+		req.Msg.ImportedCodes,
+	)
+
+	if kind == codesImported {
+		// The limit grows with every import
+		if req.Msg.CouponLimit != 0 || req.Msg.CodeFormat != nil {
+			return nil, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf(
+					"imported codes cannot set a coupon limit or code format",
+				),
+			)
+		}
+	} else if req.Msg.CouponLimit <= 0 {
 		return nil, connect.NewError(
 			connect.CodeInvalidArgument,
 			fmt.Errorf("coupon limit must be greater than 0"),
@@ -210,14 +304,125 @@ func (s *CouponService) CreateCampaign(
 		)
 	}
 
+	var (
+		eligibilityRule    *string
+		eligibilityProgram *eligibility.Program
+	)
+	if expr := strings.TrimSpace(req.Msg.Eligibility); expr != "" {
+		eligibilityProgram, err = eligibility.Compile(expr)
+		if err != nil {
+			return nil, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("invalid eligibility expression: %v", err),
+			)
+		}
+		eligibilityRule = &expr
+	}
+
+	validity, err := parseCampaignValidity(
+		req.Msg.ValidUntil,
+		req.Msg.ValidityDuration,
+		startTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	campaignBenefit, err := parseBenefit(req.Msg.Benefit)
+	if err != nil {
+		return nil, err
+	}
+
+	maxRedemptions := req.Msg.MaxRedemptions
+	if maxRedemptions < 0 {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("max_redemptions cannot be negative"),
+		)
+	}
+	if maxRedemptions == 0 {
+		maxRedemptions = 1
+	}
+
+	var maxHeldPerUser *int32
+	if req.Msg.MaxHeldPerUser < 0 {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("max_held_per_user cannot be negative"),
+		)
+	}
+	if req.Msg.MaxHeldPerUser > 0 {
+		maxHeldPerUser = &req.Msg.MaxHeldPerUser
+	}
+
+	var exclusivityGroup *string
+	if group := strings.TrimSpace(req.Msg.ExclusivityGroup); group != "" {
+		exclusivityGroup = &group
+	}
+	stackingPolicy, ok := stackingPolicies[req.Msg.StackingPolicy]
+	if !ok {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("unknown stacking policy %v", req.Msg.StackingPolicy),
+		)
+	}
+
+	codeFormat, err := parseCodeFormat(
+		req.Msg.CodeFormat,
+		req.Msg.CouponLimit,
+		req.Msg.SignedCodes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	format, err := newCodeFormat(kind, codeFormat)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to begin transaction: %v", err),
+		)
+	}
+	defer func() {
+		if tx != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
 	var campaignID pgtype.UUID
-	err = s.pool.QueryRow(ctx,
-		`INSERT INTO campaigns (name, start_time, coupon_limit)
-		VALUES ($1, $2, $3)
+	err = tx.QueryRow(ctx,
+		`INSERT INTO campaigns (name, start_time, coupon_limit, eligibility,
+			valid_until, validity_seconds, benefit, max_redemptions,
+			transferable, max_held_per_user, exclusivity_group,
+			stacking_policy, priority, signed_codes, code_format,
+			sequenced_codes, imported_codes, vanity_code)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+			$14, $15, $16, $17, $18)
 		RETURNING id`,
 		req.Msg.Name,
 		startTime,
 		req.Msg.CouponLimit,
+		eligibilityRule,
+		validity.validUntil,
+		validity.validitySeconds,
+		campaignBenefit,
+		maxRedemptions,
+		req.Msg.Transferable,
+		maxHeldPerUser,
+		exclusivityGroup,
+		stackingPolicy,
+		req.Msg.Priority,
+		req.Msg.SignedCodes,
+		codeFormat,
+		req.Msg.SequencedCodes,
+		req.Msg.ImportedCodes,
+		vanityCode,
 	).Scan(&campaignID)
 
 	if err != nil {
@@ -227,6 +432,34 @@ func (s *CouponService) CreateCampaign(
 		)
 	}
 
+	switch {
+	case vanityCode != nil:
+		if err := createVanityCode(ctx, tx, campaignID, *vanityCode); err != nil {
+			return nil, err
+		}
+	case kind == codesSigned:
+		_, _, err := createSigningKey(ctx, tx, campaignID)
+		if err != nil {
+			return nil, err
+		}
+	case kind == codesRandom, kind == codesSequenced:
+		if err := claimCodeSpace(ctx, tx, format); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to commit transaction: %v", err),
+		)
+	}
+	tx = nil // Set tx to nil after successful commit
+
+	if eligibilityProgram != nil {
+		s.eligibility.add(campaignID.String(), eligibilityProgram)
+	}
+
 	err = s.redis.ZAdd(ctx, campaignActivationKey, redis.Z{
 		Score:  float64(startTime.Unix()),
 		Member: campaignID.String(),
@@ -258,14 +491,54 @@ func (s *CouponService) GetCampaign(
 	req *GetCampaignReq,
 ) (*GetCampaignResp, error) {
 	var (
-		name      string
-		startTime time.Time
-		status    string
+		name            string
+		startTime       time.Time
+		status          string
+		eligibilityRule *string
+		validity        campaignValidity
+		campaignBenefit *benefit.Benefit
+		maxRedemptions  int32
+		transferable    bool
+		maxHeldPerUser  *int32
+		group           *string
+		stackingPolicy  benefit.StackingPolicy
+		priority        int32
+		signedCodes     bool
+		codeFormat      *codeformat.Format
+		sequencedCodes  bool
+		importedCodes   bool
+		couponLimit     int32
+		vanityCode      *string
 	)
 	err := s.pool.QueryRow(ctx,
-		`SELECT name, start_time, status FROM campaigns WHERE id = $1`,
+		`SELECT name, start_time, status, eligibility, valid_until,
+			validity_seconds, benefit, max_redemptions, transferable,
+			max_held_per_user, exclusivity_group, stacking_policy, priority,
+			signed_codes, code_format, sequenced_codes, imported_codes,
+			coupon_limit, vanity_code
+		FROM campaigns WHERE id = $1`,
 		req.Msg.CampaignId,
-	).Scan(&name, &startTime, &status)
+	).Scan(
+		&name,
+		&startTime,
+		&status,
+		&eligibilityRule,
+		&validity.validUntil,
+		&validity.validitySeconds,
+		&campaignBenefit,
+		&maxRedemptions,
+		&transferable,
+		&maxHeldPerUser,
+		&group,
+		&stackingPolicy,
+		&priority,
+		&signedCodes,
+		&codeFormat,
+		&sequencedCodes,
+		&importedCodes,
+		&couponLimit,
+		&vanityCode,
+	)
 
 	if err != nil {
 		return nil, connect.NewError(
@@ -306,10 +579,26 @@ func (s *CouponService) GetCampaign(
 	}
 
 	return connect.NewResponse(&coupon.GetCampaignResponse{
-		Name:          name,
-		StartTime:     startTime.Format(time.RFC3339),
-		Status:        status,
-		IssuedCoupons: issuedCoupons,
+		Name:             name,
+		StartTime:        startTime.Format(time.RFC3339),
+		Status:           status,
+		IssuedCoupons:    issuedCoupons,
+		Eligibility:      derefString(eligibilityRule),
+		ValidUntil:       formatOptionalTime(validity.validUntil),
+		ValidityDuration: validity.durationString(),
+		Benefit:          benefitToProto(campaignBenefit),
+		MaxRedemptions:   maxRedemptions,
+		Transferable:     transferable,
+		MaxHeldPerUser:   derefInt32(maxHeldPerUser),
+		ExclusivityGroup: derefString(group),
+		StackingPolicy:   stackingPolicyToProto(stackingPolicy),
+		Priority:         priority,
+		SignedCodes:      signedCodes,
+		CodeFormat:       codeFormatToProto(codeFormat),
+		SequencedCodes:   sequencedCodes,
+		ImportedCodes:    importedCodes,
+		CouponLimit:      couponLimit,
+		VanityCode:       derefString(vanityCode),
 	}), nil
 }
 
@@ -324,32 +613,195 @@ func (s *CouponService) updateCampaignToFinished(
 	return err
 }
 
+// returnCampaignSlot gives back a slot taken from the campaign counter for
+// a coupon that was not issued. If taking it finished the campaign, the
+// campaign becomes active again. It uses the server's context, as the
+// request's may already be canceled, e.g. by a broken stream.
+func (s *CouponService) returnCampaignSlot(campaignID string, finished bool) {
+	s.returnCampaignSlots(campaignID, 1, finished)
+}
+
+// returnCampaignSlots gives back n slots, like returnCampaignSlot.
+func (s *CouponService) returnCampaignSlots(
+	campaignID string,
+	n int,
+	finished bool,
+) {
+	if n <= 0 {
+		return
+	}
+	ctx := s.context
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, campaignID)
+	if err := s.redis.IncrBy(ctx, counterKey, int64(n)).Err(); err != nil {
+		log.Printf("Failed to return slots to campaign %s: %v", campaignID, err)
+		return
+	}
+	if !finished {
+		return
+	}
+	_, err := s.pool.Exec(ctx,
+		`UPDATE campaigns SET status = 'active'
+		WHERE id = $1 AND status = 'finished'`,
+		campaignID,
+	)
+	if err != nil {
+		log.Printf("Failed to reopen campaign %s: %v", campaignID, err)
+	}
+}
+
 func (s *CouponService) IssueCoupon(
 	ctx context.Context,
 	req *IssueCouponReq,
 ) (*IssueCouponResp, error) {
+	issued, err := s.issueCoupon(ctx, req.Msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&coupon.IssueCouponResponse{
+		CouponCode: issued.code,
+	}), nil
+}
+
+// issuedCode is a code handed out by issueCoupon.
+type issuedCode struct {
+	code string
+	// vanity is set for a campaign's vanity code, and newClaim when the
+	// request created the user's claim of it, taking a slot of the campaign
+	vanity   bool
+	newClaim bool
+	// imported codes cannot give their slot back when revoked
+	imported bool
+}
+
+// issueCoupon holds the issuance logic shared by the unary and streaming
+// RPCs. Errors are always *connect.Error.
+func (s *CouponService) issueCoupon(
+	ctx context.Context,
+	req *coupon.IssueCouponRequest,
+) (issuedCode, error) {
 	// Check if campaign exists and is active
-	var status string
+	var (
+		status          string
+		eligibilityRule *string
+		signedCodes     bool
+		sequencedCodes  bool
+		importedCodes   bool
+		codeFormat      *codeformat.Format
+		vanityCode      *string
+		validUntil      *time.Time
+		maxHeldPerUser  *int32
+	)
 	err := s.pool.QueryRow(ctx,
-		`SELECT status FROM campaigns WHERE id = $1`,
-		req.Msg.CampaignId,
-	).Scan(&status)
+		`SELECT status, eligibility, signed_codes, sequenced_codes,
+			imported_codes, code_format, vanity_code, valid_until,
+			max_held_per_user
+		FROM campaigns WHERE id = $1`,
+		req.CampaignId,
+	).Scan(
+		&status,
+		&eligibilityRule,
+		&signedCodes,
+		&sequencedCodes,
+		&importedCodes,
+		&codeFormat,
+		&vanityCode,
+		&validUntil,
+		&maxHeldPerUser,
+	)
 
 	if err != nil {
-		return nil, connect.NewError(
+		return issuedCode{}, connect.NewError(
 			connect.CodeNotFound,
 			fmt.Errorf("campaign not found: %v", err),
 		)
 	}
 
+	format, err := newCodeFormat(
+		campaignCodeKind(signedCodes, sequencedCodes, importedCodes),
+		codeFormat,
+	)
+	if err != nil {
+		return issuedCode{}, connect.NewError(connect.CodeInternal, err)
+	}
+
 	if status != "active" {
-		return nil, connect.NewError(
+		return issuedCode{}, connect.NewError(
 			connect.CodeFailedPrecondition,
 			fmt.Errorf("campaign is not active (status: %s)", status),
 		)
 	}
 
-	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, req.Msg.CampaignId)
+	// A coupon issued now would already be expired
+	if isExpired(validUntil) {
+		return issuedCode{}, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("campaign's coupons are no longer valid"),
+		)
+	}
+
+	// Eligibility is evaluated before the counter so that denied requests
+	// never consume a coupon
+	err = s.checkEligibility(
+		req.CampaignId,
+		derefString(eligibilityRule),
+		req.Attributes,
+	)
+	if err != nil {
+		return issuedCode{}, err
+	}
+
+	// Claiming a vanity code again returns it without taking another slot
+	if vanityCode != nil {
+		if req.UserId == "" {
+			return issuedCode{}, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("user_id is required to claim a vanity code"),
+			)
+		}
+		claimed, err := s.hasVanityClaim(ctx, req.CampaignId, req.UserId)
+		if err != nil {
+			return issuedCode{}, err
+		}
+		if claimed {
+			return issuedCode{code: *vanityCode, vanity: true}, nil
+		}
+	}
+
+	// A user's coupons of a campaign capping how many they hold are counted
+	// and written under a lock of their holdings, rather than queued
+	var holderTx pgx.Tx
+	if maxHeldPerUser != nil && req.UserId != "" && vanityCode == nil {
+		holderTx, err = s.pool.Begin(ctx)
+		if err != nil {
+			return issuedCode{}, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to begin transaction: %v", err),
+			)
+		}
+		defer func() {
+			if holderTx != nil {
+				if rollbackErr := holderTx.Rollback(ctx); rollbackErr != nil {
+					log.Printf("failed to rollback transaction: %v", rollbackErr)
+				}
+			}
+		}()
+
+		held, err := lockHeldCoupons(ctx, holderTx, req.CampaignId, req.UserId)
+		if err != nil {
+			return issuedCode{}, err
+		}
+		if held >= *maxHeldPerUser {
+			return issuedCode{}, connect.NewError(
+				connect.CodeFailedPrecondition,
+				fmt.Errorf(
+					"user already holds %d coupons from this campaign", held,
+				),
+			)
+		}
+	}
+
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, req.CampaignId)
 
 	// Lua script to atomically check and decrement
 	script := `
@@ -366,14 +818,14 @@ func (s *CouponService) IssueCoupon(
 
 	remaining, err := s.redis.Eval(ctx, script, []string{counterKey}).Int64()
 	if err != nil {
-		return nil, connect.NewError(
+		return issuedCode{}, connect.NewError(
 			connect.CodeInternal,
 			fmt.Errorf("failed to check coupon availability: %v", err),
 		)
 	}
 
 	if remaining == -1 {
-		return nil, connect.NewError(
+		return issuedCode{}, connect.NewError(
 			connect.CodeResourceExhausted,
 			fmt.Errorf("campaign has reached its coupon limit"),
 		)
@@ -381,35 +833,166 @@ func (s *CouponService) IssueCoupon(
 
 	if remaining == -2 {
 		// Update database status
-		if err := s.updateCampaignToFinished(ctx, req.Msg.CampaignId); err != nil {
-			return nil, connect.NewError(
+		if err := s.updateCampaignToFinished(ctx, req.CampaignId); err != nil {
+			return issuedCode{}, connect.NewError(
 				connect.CodeInternal,
 				fmt.Errorf("failed to update campaign status to finished: %v", err),
 			)
 		}
 	}
 
+	if vanityCode != nil {
+		claimed, err := s.claimVanityCode(ctx, req.CampaignId, req.UserId)
+		if err != nil {
+			s.returnCampaignSlot(req.CampaignId, remaining == -2)
+			return issuedCode{}, connect.NewError(connect.CodeInternal, err)
+		}
+		// A concurrent request of the same user claimed it first
+		if !claimed {
+			s.returnCampaignSlot(req.CampaignId, remaining == -2)
+		}
+		return issuedCode{
+			code:     *vanityCode,
+			vanity:   true,
+			newClaim: claimed,
+		}, nil
+	}
+
+	if holderTx != nil {
+		code, err := s.issueHeldCoupon(ctx, holderTx, req, format)
+		if err != nil {
+			s.returnCampaignSlot(req.CampaignId, remaining == -2)
+			return issuedCode{}, err
+		}
+		holderTx = nil // issueHeldCoupon commits the transaction
+		return issuedCode{
+			code:     code,
+			imported: format.kind == codesImported,
+		}, nil
+	}
+
 	// Generate a unique coupon code
-	code, err := s.codeGen.generateCouponCode(ctx, s.pool, req.Msg.CampaignId)
+	code, err := s.codeGen.generateCouponCode(
+		ctx,
+		s.pool,
+		req.CampaignId,
+		req.UserId,
+		format,
+	)
 	if err != nil {
-		// Increment back
-		s.redis.Incr(ctx, counterKey)
-		return nil, connect.NewError(
+		s.returnCampaignSlot(req.CampaignId, remaining == -2)
+		return issuedCode{}, connect.NewError(
 			connect.CodeInternal,
 			fmt.Errorf("failed to generate coupon code: %v", err),
 		)
 	}
 
-	return connect.NewResponse(&coupon.IssueCouponResponse{
-		CouponCode: code,
-	}), nil
+	return issuedCode{
+		code:     code,
+		imported: format.kind == codesImported,
+	}, nil
+}
+
+// issueHeldCoupon writes the coupon of a user of a campaign with
+// max_held_per_user in the transaction holding the lock of their holdings,
+// and commits it.
+func (s *CouponService) issueHeldCoupon(
+	ctx context.Context,
+	tx pgx.Tx,
+	req *coupon.IssueCouponRequest,
+	format codeFormat,
+) (string, error) {
+	codes, err := s.codeGen.takeCodes(ctx, s.pool, req.CampaignId, format, 1)
+	if err != nil {
+		return "", connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to generate coupon code: %v", err),
+		)
+	}
+	code := codes[0]
+	normalized := codeformat.Normalize(code)
+
+	// The code goes back to the pool unless it is written
+	returnCodes := true
+	defer func() {
+		if returnCodes {
+			s.codeGen.returnCodes(req.CampaignId, format, codes)
+		}
+	}()
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE coupons c
+		SET campaign_id = $1,
+			user_id = $2,
+			state = 'issued',
+			issued_at = now(),
+			expires_at = LEAST(
+				cp.valid_until,
+				now() + make_interval(secs => cp.validity_seconds)
+			)
+		FROM campaigns cp
+		WHERE cp.id = $1
+		AND c.code = $3
+		AND c.campaign_id IS NULL
+		AND c.state = 'available'`,
+		req.CampaignId,
+		req.UserId,
+		normalized,
+	)
+	if err != nil {
+		return "", connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to write issued code: %v", err),
+		)
+	}
+	if tag.RowsAffected() != 1 {
+		// Someone else has the code, so it is not used again
+		returnCodes = false
+		return "", connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("coupon code was already issued"),
+		)
+	}
+	if format.kind == codesImported {
+		err := markImportedCodesIssued(ctx, tx, []string{normalized})
+		if err != nil {
+			return "", connect.NewError(connect.CodeInternal, err)
+		}
+	}
+	if err := recordIssuedEvents(ctx, tx, []string{normalized}); err != nil {
+		return "", connect.NewError(connect.CodeInternal, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to commit transaction: %v", err),
+		)
+	}
+	returnCodes = false
+
+	return code, nil
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefInt32(n *int32) int32 {
+	if n == nil {
+		return 0
+	}
+	return *n
 }
 
 func (s *CouponService) Close() error {
 	s.cancelBackgroundWorkers()
 
-	// Wait for both background workers to stop
-	for i := 0; i < 2; i++ {
+	// Wait for all background workers to stop
+	for i := 0; i < backgroundWorkerCount; i++ {
 		select {
 		case <-s.backgroundWorkersStopped:
 		case <-time.After(5 * time.Second):
@@ -27,6 +27,12 @@ func setupTestService(t *testing.T) *CouponService {
 	redisClient, err := redis.NewClient(redisCfg)
 	require.NoError(t, err)
 
+	reversalWindow, err := reversalWindowFromEnv()
+	require.NoError(t, err)
+
+	imageSigner, err := imageSignerFromEnv()
+	require.NoError(t, err)
+
 	codeGen := newCodeGenerator()
 
 	service := &CouponService{
@@ -35,10 +41,15 @@ func setupTestService(t *testing.T) *CouponService {
 		codeGen:                 codeGen,
 		context:                 ctx,
 		cancelBackgroundWorkers: cancel,
+		reversalWindow:          reversalWindow,
+		imageSigner:             imageSigner,
+		eligibility:             newEligibilityCache(),
 	}
 
 	go service.startCampaignStatusWorker(backgroundCtx)
 	go service.startCouponCodeWriter(backgroundCtx)
+	go service.startJobWorker(backgroundCtx)
+	go service.startCouponExpiryWorker(backgroundCtx)
 
 	// Register cleanup to run after test
 	t.Cleanup(func() {
@@ -52,18 +63,28 @@ func cleanupTestData(t *testing.T, service *CouponService) {
 	ctx := context.Background()
 
 	// Clean up database
-	_, err := service.pool.Exec(ctx, "DELETE FROM coupons")
+	_, err := service.pool.Exec(ctx, "DELETE FROM jobs")
+	require.NoError(t, err)
+	_, err = service.pool.Exec(ctx, "DELETE FROM coupon_redemptions")
+	require.NoError(t, err)
+	_, err = service.pool.Exec(ctx, "DELETE FROM coupon_events")
+	require.NoError(t, err)
+	_, err = service.pool.Exec(ctx, "DELETE FROM coupons")
 	require.NoError(t, err)
 	_, err = service.pool.Exec(ctx, "DELETE FROM campaigns")
 	require.NoError(t, err)
+	_, err = service.pool.Exec(ctx, "DELETE FROM code_spaces")
+	require.NoError(t, err)
 
 	// Clean up Redis keys
-	iter := service.redis.Scan(ctx, 0, "campaign:*", 0).Iterator()
-	for iter.Next(ctx) {
-		err := service.redis.Del(ctx, iter.Val()).Err()
-		require.NoError(t, err)
+	for _, pattern := range []string{"campaign:*", "coupon:*"} {
+		iter := service.redis.Scan(ctx, 0, pattern, 0).Iterator()
+		for iter.Next(ctx) {
+			err := service.redis.Del(ctx, iter.Val()).Err()
+			require.NoError(t, err)
+		}
+		require.NoError(t, iter.Err())
 	}
-	require.NoError(t, iter.Err())
 
 	// Stop background workers and close connections
 	service.Close()
@@ -459,3 +480,81 @@ func TestCouponService_GetCampaign(t *testing.T) {
 		assert.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
 	})
 }
+
+func TestCouponService_IssueCouponEligibility(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	t.Run("invalid expression is rejected", func(t *testing.T) {
+		_, err := service.CreateCampaign(
+			ctx,
+			connect.NewRequest(&coupon.CreateCampaignRequest{
+				Name:        "Broken Rule",
+				StartTime:   time.Now().Format(time.RFC3339),
+				CouponLimit: 10,
+				Eligibility: `user.tier ==`,
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+
+	campaignID := "00000000-0000-0000-0000-000000000000"
+	_, err := service.pool.Exec(ctx,
+		`INSERT INTO campaigns
+		(id, name, start_time, coupon_limit, status, eligibility)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		campaignID,
+		"Gold Members",
+		time.Now(),
+		1,
+		"active",
+		`user.tier == "gold" && user.region == "KR"`,
+	)
+	require.NoError(t, err)
+
+	counterKey := fmt.Sprintf("%s%s", campaignCounterKey, campaignID)
+	err = service.redis.Set(ctx, counterKey, 1, 0).Err()
+	require.NoError(t, err)
+
+	t.Run("ineligible request keeps the counter", func(t *testing.T) {
+		_, err := service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: campaignID,
+				Attributes: &coupon.UserAttributes{Tier: "basic"},
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		require.Len(t, connectErr.Details(), 1)
+		detail, err := connectErr.Details()[0].Value()
+		require.NoError(t, err)
+		denial, ok := detail.(*coupon.EligibilityDenial)
+		require.True(t, ok)
+		assert.Equal(t,
+			coupon.EligibilityDenialReason_ELIGIBILITY_DENIAL_REASON_RULE_NOT_SATISFIED,
+			denial.Reason,
+		)
+		assert.Equal(t, `user.tier == "gold"`, denial.Clause)
+
+		remaining, err := service.redis.Get(ctx, counterKey).Int()
+		require.NoError(t, err)
+		assert.Equal(t, 1, remaining)
+	})
+
+	t.Run("eligible request is issued", func(t *testing.T) {
+		resp, err := service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: campaignID,
+				Attributes: &coupon.UserAttributes{Tier: "gold", Region: "KR"},
+			}),
+		)
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.Msg.CouponCode)
+	})
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type (
+	RotateSigningKeyReq     = connect.Request[coupon.RotateSigningKeyRequest]
+	RotateSigningKeyResp    = connect.Response[coupon.RotateSigningKeyResponse]
+	GetVerificationKeysResp = connect.Response[coupon.GetVerificationKeysResponse]
+)
+
+const signingKeySize = 32
+
+// createSigningKey adds a new active key for a campaign. The caller retires
+// the previous one first.
+func createSigningKey(
+	ctx context.Context,
+	tx pgx.Tx,
+	campaignID pgtype.UUID,
+) (uint32, time.Time, error) {
+	secret := make([]byte, signingKeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return 0, time.Time{}, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to generate signing key: %v", err),
+		)
+	}
+
+	var (
+		keyID     uint32
+		createdAt time.Time
+	)
+	err := tx.QueryRow(ctx,
+		`INSERT INTO campaign_signing_keys (campaign_id, secret)
+		VALUES ($1, $2)
+		RETURNING key_id, created_at`,
+		campaignID,
+		secret,
+	).Scan(&keyID, &createdAt)
+	if err != nil {
+		return 0, time.Time{}, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to create signing key: %v", err),
+		)
+	}
+	return keyID, createdAt, nil
+}
+
+// RotateSigningKey replaces a campaign's active signing key. The retired
+// key keeps verifying the codes it signed, so devices should keep every
+// key returned by GetVerificationKeys.
+func (s *CouponService) RotateSigningKey(
+	ctx context.Context,
+	req *RotateSigningKeyReq,
+) (*RotateSigningKeyResp, error) {
+	var campaignID pgtype.UUID
+	if err := campaignID.Scan(req.Msg.CampaignId); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("invalid campaign_id: %v", err),
+		)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to begin transaction: %v", err),
+		)
+	}
+	defer func() {
+		if tx != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// Locking the campaign serializes concurrent rotations
+	var signedCodes bool
+	err = tx.QueryRow(ctx,
+		`SELECT signed_codes FROM campaigns WHERE id = $1 FOR UPDATE`,
+		campaignID,
+	).Scan(&signedCodes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("campaign not found"),
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get campaign: %v", err),
+		)
+	}
+	if !signedCodes {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("campaign does not issue signed codes"),
+		)
+	}
+
+	var retiredKeyID uint32
+	err = tx.QueryRow(ctx,
+		`UPDATE campaign_signing_keys SET retired_at = now()
+		WHERE campaign_id = $1 AND retired_at IS NULL
+		RETURNING key_id`,
+		campaignID,
+	).Scan(&retiredKeyID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to retire signing key: %v", err),
+		)
+	}
+
+	keyID, createdAt, err := createSigningKey(ctx, tx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to commit transaction: %v", err),
+		)
+	}
+	tx = nil // Set tx to nil after successful commit
+
+	return connect.NewResponse(&coupon.RotateSigningKeyResponse{
+		KeyId:        keyID,
+		RetiredKeyId: retiredKeyID,
+		CreatedAt:    createdAt.Format(time.RFC3339),
+	}), nil
+}
+
+// GetVerificationKeys returns the keys devices need to verify signed codes
+// offline, each with the alphabet and prefix of its campaign's codes.
+func (s *CouponService) GetVerificationKeys(
+	ctx context.Context,
+	req *connect.Request[coupon.GetVerificationKeysRequest],
+) (*GetVerificationKeysResp, error) {
+	var campaignID pgtype.UUID
+	if id := strings.TrimSpace(req.Msg.CampaignId); id != "" {
+		if err := campaignID.Scan(id); err != nil {
+			return nil, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("invalid campaign_id: %v", err),
+			)
+		}
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT k.key_id, k.campaign_id, k.secret, k.created_at,
+			k.retired_at, cp.code_format
+		FROM campaign_signing_keys k
+		JOIN campaigns cp ON cp.id = k.campaign_id
+		WHERE $1::uuid IS NULL OR k.campaign_id = $1
+		ORDER BY k.key_id`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get signing keys: %v", err),
+		)
+	}
+	defer rows.Close()
+
+	resp := &coupon.GetVerificationKeysResponse{}
+	for rows.Next() {
+		var (
+			key        coupon.VerificationKey
+			campaignID pgtype.UUID
+			createdAt  time.Time
+			retiredAt  *time.Time
+			codeFormat *codeformat.Format
+		)
+		err := rows.Scan(
+			&key.KeyId,
+			&campaignID,
+			&key.Secret,
+			&createdAt,
+			&retiredAt,
+			&codeFormat,
+		)
+		if err != nil {
+			return nil, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to scan signing key: %v", err),
+			)
+		}
+		format, err := newCodeFormat(codesSigned, codeFormat)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		key.CampaignId = campaignID.String()
+		key.Alphabet = format.getLayout().Alphabet()
+		key.Prefix = format.getLayout().Prefix()
+		key.CreatedAt = createdAt.Format(time.RFC3339)
+		key.RetiredAt = formatOptionalTime(retiredAt)
+		resp.Keys = append(resp.Keys, &key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("error iterating signing keys: %v", err),
+		)
+	}
+
+	return connect.NewResponse(resp), nil
+}
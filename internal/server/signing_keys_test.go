@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/pkg/signedcode"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createSignedCampaign creates an active campaign with signed codes.
+func createSignedCampaign(
+	t *testing.T,
+	service *CouponService,
+	format *coupon.CodeFormat,
+) string {
+	ctx := context.Background()
+
+	created, err := service.CreateCampaign(
+		ctx,
+		connect.NewRequest(&coupon.CreateCampaignRequest{
+			Name:        "Signed Campaign",
+			StartTime:   time.Now().Format(time.RFC3339),
+			CouponLimit: 10,
+			SignedCodes: true,
+			CodeFormat:  format,
+		}),
+	)
+	require.NoError(t, err)
+	campaignID := created.Msg.CampaignId
+
+	_, err = service.pool.Exec(ctx,
+		`UPDATE campaigns SET status = 'active' WHERE id = $1`,
+		campaignID,
+	)
+	require.NoError(t, err)
+	return campaignID
+}
+
+// verifierFor builds a device's verifier for a campaign and returns it
+// with the prefix of the campaign's codes.
+func verifierFor(
+	t *testing.T,
+	service *CouponService,
+	campaignID string,
+) (*signedcode.Verifier, string) {
+	resp, err := service.GetVerificationKeys(
+		context.Background(),
+		connect.NewRequest(&coupon.GetVerificationKeysRequest{
+			CampaignId: campaignID,
+		}),
+	)
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Msg.Keys)
+
+	codec, err := signedcode.NewCodec(resp.Msg.Keys[0].Alphabet)
+	require.NoError(t, err)
+	keys := make([]signedcode.Key, len(resp.Msg.Keys))
+	for i, k := range resp.Msg.Keys {
+		keys[i] = signedcode.Key{ID: k.KeyId, Secret: k.Secret}
+	}
+	return signedcode.NewVerifier(codec, keys...), resp.Msg.Keys[0].Prefix
+}
+
+func TestCouponService_SignedCodes(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	campaignID := createSignedCampaign(t, service, nil)
+	issued, err := service.IssueCoupon(
+		ctx,
+		connect.NewRequest(&coupon.IssueCouponRequest{
+			CampaignId: campaignID,
+			UserId:     "user-1",
+		}),
+	)
+	require.NoError(t, err)
+	code := issued.Msg.CouponCode
+
+	verifier := func(t *testing.T) *signedcode.Verifier {
+		v, _ := verifierFor(t, service, campaignID)
+		return v
+	}
+
+	t.Run("codes verify offline", func(t *testing.T) {
+		p, err := verifier(t).Verify(code)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), p.Serial)
+	})
+
+	t.Run("rotation keeps old codes valid", func(t *testing.T) {
+		first, err := verifier(t).Verify(code)
+		require.NoError(t, err)
+
+		rotated, err := service.RotateSigningKey(
+			ctx,
+			connect.NewRequest(&coupon.RotateSigningKeyRequest{
+				CampaignId: campaignID,
+			}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, first.KeyID, rotated.Msg.RetiredKeyId)
+		assert.NotEqual(t, first.KeyID, rotated.Msg.KeyId)
+
+		keys, err := service.GetVerificationKeys(
+			ctx,
+			connect.NewRequest(&coupon.GetVerificationKeysRequest{
+				CampaignId: campaignID,
+			}),
+		)
+		require.NoError(t, err)
+		require.Len(t, keys.Msg.Keys, 2)
+		assert.NotEmpty(t, keys.Msg.Keys[0].RetiredAt)
+		assert.Empty(t, keys.Msg.Keys[1].RetiredAt)
+
+		_, err = verifier(t).Verify(code)
+		assert.NoError(t, err)
+	})
+
+	t.Run("codes use the campaign's format", func(t *testing.T) {
+		posCampaignID := createSignedCampaign(t, service, &coupon.CodeFormat{
+			Alphabet: coupon.CodeAlphabet_CODE_ALPHABET_CROCKFORD_BASE32,
+			Prefix:   "POS-",
+		})
+		issued, err := service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: posCampaignID,
+				UserId:     "user-1",
+			}),
+		)
+		require.NoError(t, err)
+
+		v, prefix := verifierFor(t, service, posCampaignID)
+		assert.Equal(t, "POS-", prefix)
+		code, ok := strings.CutPrefix(issued.Msg.CouponCode, prefix)
+		require.True(t, ok)
+		assert.Regexp(t, `^[0-9A-HJKMNP-TV-Z]{29}$`, code)
+		_, err = v.Verify(code)
+		assert.NoError(t, err)
+	})
+
+	t.Run("unsigned campaigns have no keys", func(t *testing.T) {
+		unsignedID, _ := issueTestCoupon(t, service, "user-1")
+
+		_, err := service.RotateSigningKey(
+			ctx,
+			connect.NewRequest(&coupon.RotateSigningKeyRequest{
+				CampaignId: unsignedID,
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type (
+	TransferCouponReq  = connect.Request[coupon.TransferCouponRequest]
+	TransferCouponResp = connect.Response[coupon.TransferCouponResponse]
+)
+
+const couponEventTransferred = "transferred"
+
+// TransferCoupon gives an unused coupon to another user. The coupon row is
+// locked for the duration of the transfer, and transfers into the same
+// user's holdings of a campaign are serialized with an advisory lock so that
+// concurrent gifts cannot exceed the campaign's max_held_per_user.
+func (s *CouponService) TransferCoupon(
+	ctx context.Context,
+	req *TransferCouponReq,
+) (*TransferCouponResp, error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	fromUser := strings.TrimSpace(req.Msg.FromUserId)
+	toUser := strings.TrimSpace(req.Msg.ToUserId)
+	if code == "" || fromUser == "" || toUser == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code, from_user_id and to_user_id are required"),
+		)
+	}
+	if fromUser == toUser {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("cannot transfer a coupon to its owner"),
+		)
+	}
+
+	if err := s.flushPendingCoupon(ctx, code); err != nil {
+		return nil, err
+	}
+	// The recipient's coupons are counted against max_held_per_user
+	if err := s.flushUserCoupons(ctx, toUser); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to begin transaction: %v", err),
+		)
+	}
+	defer func() {
+		if tx != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	var (
+		couponID       pgtype.UUID
+		campaignID     pgtype.UUID
+		state          couponState
+		owner          *string
+		expiresAt      *time.Time
+		redemptions    int32
+		transferable   bool
+		maxHeldPerUser *int32
+		locked         bool
+	)
+	err = tx.QueryRow(ctx,
+		`SELECT c.id, c.campaign_id, c.state, c.user_id, c.expires_at,
+			c.redemption_count, cp.transferable, cp.max_held_per_user,
+			COALESCE(c.locked_until > now(), false)
+		FROM coupons c
+		JOIN campaigns cp ON cp.id = c.campaign_id
+		WHERE c.code = $1
+		FOR UPDATE OF c`,
+		code,
+	).Scan(
+		&couponID,
+		&campaignID,
+		&state,
+		&owner,
+		&expiresAt,
+		&redemptions,
+		&transferable,
+		&maxHeldPerUser,
+		&locked,
+	)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("coupon not found"),
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon: %v", err),
+		)
+	}
+
+	if owner == nil || *owner != fromUser {
+		return nil, connect.NewError(
+			connect.CodePermissionDenied,
+			fmt.Errorf("coupon does not belong to %s", fromUser),
+		)
+	}
+	if state == couponIssued && isExpired(expiresAt) {
+		state = couponExpired
+	}
+	// Only coupons that can still be redeemed are transferred
+	if err := checkTransition(state, couponRedeemed); err != nil {
+		return nil, err
+	}
+	// The row lock keeps a checkout from locking it during the transfer
+	if locked {
+		return nil, couponLockedError()
+	}
+	if redemptions > 0 {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("coupon has already been partially redeemed"),
+		)
+	}
+	if !transferable {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("campaign does not allow transfers"),
+		)
+	}
+
+	if maxHeldPerUser != nil {
+		held, err := lockHeldCoupons(ctx, tx, campaignID.String(), toUser)
+		if err != nil {
+			return nil, err
+		}
+		if held >= *maxHeldPerUser {
+			return nil, connect.NewError(
+				connect.CodeFailedPrecondition,
+				fmt.Errorf(
+					"recipient already holds %d coupons from this campaign",
+					held,
+				),
+			)
+		}
+	}
+
+	var transferredAt time.Time
+	err = tx.QueryRow(ctx,
+		`UPDATE coupons SET user_id = $2 WHERE id = $1 RETURNING updated_at`,
+		couponID,
+		toUser,
+	).Scan(&transferredAt)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to transfer coupon: %v", err),
+		)
+	}
+
+	err = recordCouponEvent(ctx, tx, couponEvent{
+		couponID:  couponID,
+		event:     couponEventTransferred,
+		fromState: couponIssued,
+		toState:   couponIssued,
+		details: map[string]any{
+			"from_user_id": fromUser,
+			"to_user_id":   toUser,
+		},
+	})
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to record coupon event: %v", err),
+		)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to commit transaction: %v", err),
+		)
+	}
+	tx = nil // Set tx to nil after successful commit
+
+	return connect.NewResponse(&coupon.TransferCouponResponse{
+		Code:          code,
+		CampaignId:    campaignID.String(),
+		OwnerUserId:   toUser,
+		TransferredAt: transferredAt.Format(time.RFC3339),
+	}), nil
+}
+
+func holderLockKey(campaignID, userID string) string {
+	return fmt.Sprintf("coupon-holder:%s:%s", campaignID, userID)
+}
+
+// lockHeldCoupons serializes the issuance and transfer of a campaign's
+// coupons to a user until the transaction ends, and returns how many unused
+// coupons of the campaign the user holds, for max_held_per_user.
+func lockHeldCoupons(
+	ctx context.Context,
+	tx pgx.Tx,
+	campaignID string,
+	userID string,
+) (int32, error) {
+	_, err := tx.Exec(ctx,
+		`SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`,
+		holderLockKey(campaignID, userID),
+	)
+	if err != nil {
+		return 0, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to lock coupon holder: %v", err),
+		)
+	}
+
+	var held int32
+	err = tx.QueryRow(ctx,
+		`SELECT count(*) FROM coupons
+		WHERE campaign_id = $1 AND user_id = $2 AND state = 'issued'
+		AND (expires_at IS NULL OR expires_at > now())`,
+		campaignID,
+		userID,
+	).Scan(&held)
+	if err != nil {
+		return 0, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to count held coupons: %v", err),
+		)
+	}
+	return held, nil
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponService_TransferCoupon(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	transfer := func(code, from, to string) (*TransferCouponResp, error) {
+		return service.TransferCoupon(
+			ctx,
+			connect.NewRequest(&coupon.TransferCouponRequest{
+				Code:       code,
+				FromUserId: from,
+				ToUserId:   to,
+			}),
+		)
+	}
+
+	campaignID, code := issueTestCoupon(t, service, "alice")
+
+	t.Run("campaign must allow transfers", func(t *testing.T) {
+		_, err := transfer(code, "alice", "bob")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	_, err := service.pool.Exec(ctx,
+		`UPDATE campaigns SET transferable = true, max_held_per_user = 1
+		WHERE id = $1`,
+		campaignID,
+	)
+	require.NoError(t, err)
+
+	t.Run("only the owner can transfer", func(t *testing.T) {
+		_, err := transfer(code, "mallory", "bob")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("changes ownership and records history", func(t *testing.T) {
+		resp, err := transfer(code, "alice", "bob")
+		require.NoError(t, err)
+		assert.Equal(t, "bob", resp.Msg.OwnerUserId)
+
+		_, err = transfer(code, "bob", "carol")
+		require.NoError(t, err)
+
+		history, err := service.GetCouponHistory(
+			ctx,
+			connect.NewRequest(&coupon.GetCouponHistoryRequest{Code: code}),
+		)
+		require.NoError(t, err)
+		require.Len(t, history.Msg.Events, 3)
+		assert.Equal(t, "issued", history.Msg.Events[0].Event)
+		assert.JSONEq(t,
+			`{"user_id": "alice"}`,
+			history.Msg.Events[0].Details,
+		)
+
+		var owners []string
+		for _, event := range history.Msg.Events[1:] {
+			assert.Equal(t, "transferred", event.Event)
+			var details map[string]string
+			require.NoError(t, json.Unmarshal([]byte(event.Details), &details))
+			owners = append(owners, details["from_user_id"], details["to_user_id"])
+		}
+		assert.Equal(t, []string{"alice", "bob", "bob", "carol"}, owners)
+
+		// The new owner redeems it, the old one cannot
+		_, err = service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "bob",
+				OrderRef: "order-1",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodePermissionDenied, connect.CodeOf(err))
+	})
+
+	t.Run("recipient limit", func(t *testing.T) {
+		// carol already holds one coupon of the campaign
+		_, other := issueTestCoupon(t, service, "dave")
+
+		_, err := transfer(other, "dave", "carol")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	t.Run("issuance stops at the limit", func(t *testing.T) {
+		// Coupons of a capped campaign are written when they are issued
+		_, held := issueTestCoupon(t, service, "frank")
+		assert.False(t, service.codeGen.isPending(held))
+
+		_, err := service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: campaignID,
+				UserId:     "frank",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		_, other := issueTestCoupon(t, service, "gina")
+		_, err = transfer(other, "gina", "frank")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	t.Run("redeemed coupons cannot be transferred", func(t *testing.T) {
+		_, err := service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "carol",
+				OrderRef: "order-2",
+			}),
+		)
+		require.NoError(t, err)
+
+		_, err = transfer(code, "carol", "erin")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+}
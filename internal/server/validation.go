@@ -0,0 +1,215 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/codeformat"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type (
+	ValidateCouponReq  = connect.Request[coupon.ValidateCouponRequest]
+	ValidateCouponResp = connect.Response[coupon.ValidateCouponResponse]
+)
+
+var invalidReasons = map[couponState]coupon.CouponInvalidReason{
+	couponAvailable: coupon.CouponInvalidReason_COUPON_INVALID_REASON_NOT_ISSUED,
+	couponRedeemed:  coupon.CouponInvalidReason_COUPON_INVALID_REASON_REDEEMED,
+	couponVoid:      coupon.CouponInvalidReason_COUPON_INVALID_REASON_VOID,
+	couponExpired:   coupon.CouponInvalidReason_COUPON_INVALID_REASON_EXPIRED,
+}
+
+// ValidateCoupon looks a code up without consuming it. Unknown codes are
+// reported through the reason field rather than as an error.
+func (s *CouponService) ValidateCoupon(
+	ctx context.Context,
+	req *ValidateCouponReq,
+) (*ValidateCouponResp, error) {
+	code := codeformat.Normalize(req.Msg.Code)
+	if code == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("code cannot be empty"),
+		)
+	}
+
+	resp := &coupon.ValidateCouponResponse{Code: code}
+
+	// Codes issued since the last flush are only known in memory
+	if pending, ok := s.codeGen.pendingCoupon(code); ok {
+		var validity campaignValidity
+		err := s.pool.QueryRow(ctx,
+			`SELECT name, valid_until, validity_seconds
+			FROM campaigns WHERE id = $1`,
+			pending.campaignID,
+		).Scan(
+			&resp.CampaignName,
+			&validity.validUntil,
+			&validity.validitySeconds,
+		)
+		if err != nil {
+			return nil, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to get campaign: %v", err),
+			)
+		}
+		resp.CampaignId = pending.campaignID
+		resp.OwnerUserId = pending.userID
+		setValidationState(
+			resp,
+			couponIssued,
+			validity.expiresAt(pending.issuedAt),
+		)
+		return connect.NewResponse(resp), nil
+	}
+
+	var (
+		campaignID   pgtype.UUID
+		campaignName *string
+		owner        *string
+		state        couponState
+		expiresAt    *time.Time
+		vanity       bool
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT c.campaign_id, cp.name, c.user_id, c.state, c.expires_at,
+			COALESCE(cp.vanity_code = c.code, false)
+		FROM coupons c
+		LEFT JOIN campaigns cp ON cp.id = c.campaign_id
+		WHERE c.code = $1`,
+		code,
+	).Scan(&campaignID, &campaignName, &owner, &state, &expiresAt, &vanity)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		resp.Reason = coupon.CouponInvalidReason_COUPON_INVALID_REASON_NOT_FOUND
+		if err := s.checkTypo(ctx, resp, req.Msg.UserId); err != nil {
+			return nil, err
+		}
+		return connect.NewResponse(resp), nil
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupon: %v", err),
+		)
+	}
+
+	if campaignID.Valid {
+		resp.CampaignId = campaignID.String()
+	}
+	resp.CampaignName = derefString(campaignName)
+	resp.OwnerUserId = derefString(owner)
+
+	// A vanity code is only valid for a user who has claimed it; for anyone
+	// else it has not been issued yet
+	if vanity {
+		var claimed bool
+		state, expiresAt, claimed, err = s.vanityClaimState(
+			ctx, campaignID, req.Msg.UserId, state, expiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if !claimed && state == couponIssued && !isExpired(expiresAt) {
+			state = couponAvailable
+		}
+	}
+	setValidationState(resp, state, expiresAt)
+
+	return connect.NewResponse(resp), nil
+}
+
+// checkTypo reports an unknown code as a probable typo when it has the
+// shape of a campaign's codes but a wrong check character, and suggests the
+// user's coupons it may have been meant as.
+func (s *CouponService) checkTypo(
+	ctx context.Context,
+	resp *coupon.ValidateCouponResponse,
+	userID string,
+) error {
+	rows, err := s.pool.Query(ctx,
+		`SELECT DISTINCT code_format FROM campaigns
+		WHERE code_format->>'check_character' IS NOT NULL`,
+	)
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get code formats: %v", err),
+		)
+	}
+	formats, err := pgx.CollectRows(rows, pgx.RowTo[codeformat.Format])
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to scan code formats: %v", err),
+		)
+	}
+
+	var corrections []string
+	for _, f := range formats {
+		layout, err := f.Compile()
+		if err != nil || !layout.Matches(resp.Code) {
+			continue
+		}
+		if layout.CheckValid(resp.Code) {
+			continue
+		}
+		resp.Reason = coupon.
+			CouponInvalidReason_COUPON_INVALID_REASON_PROBABLE_TYPO
+		corrections = append(corrections, layout.Corrections(resp.Code)...)
+	}
+	if len(corrections) == 0 || userID == "" {
+		return nil
+	}
+
+	rows, err = s.pool.Query(ctx,
+		`SELECT code FROM coupons
+		WHERE code = ANY($1) AND user_id = $2
+		ORDER BY code`,
+		corrections,
+		userID,
+	)
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get coupons: %v", err),
+		)
+	}
+	resp.Suggestions, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to scan coupon codes: %v", err),
+		)
+	}
+
+	// Codes issued since the last flush are only known in memory
+	for _, code := range corrections {
+		pending, ok := s.codeGen.pendingCoupon(code)
+		if ok && pending.userID == userID {
+			resp.Suggestions = append(resp.Suggestions, code)
+		}
+	}
+	return nil
+}
+
+func setValidationState(
+	resp *coupon.ValidateCouponResponse,
+	state couponState,
+	expiresAt *time.Time,
+) {
+	if state == couponIssued && isExpired(expiresAt) {
+		state = couponExpired
+	}
+	resp.State = string(state)
+	resp.ExpiresAt = formatOptionalTime(expiresAt)
+	resp.Valid = state == couponIssued
+	resp.Reason = invalidReasons[state]
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponService_ValidateCoupon(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	campaignID, code := issueTestCoupon(t, service, "user-1")
+
+	t.Run("unflushed coupon is issued", func(t *testing.T) {
+		require.True(t, service.codeGen.isPending(code))
+
+		resp, err := service.ValidateCoupon(
+			ctx,
+			connect.NewRequest(&coupon.ValidateCouponRequest{Code: code}),
+		)
+		require.NoError(t, err)
+		assert.True(t, resp.Msg.Valid)
+		assert.Equal(t, campaignID, resp.Msg.CampaignId)
+		assert.Equal(t, "Test Campaign", resp.Msg.CampaignName)
+		assert.Equal(t, "user-1", resp.Msg.OwnerUserId)
+		assert.Equal(t, "issued", resp.Msg.State)
+	})
+
+	t.Run("redeemed coupon is invalid", func(t *testing.T) {
+		_, err := service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     code,
+				UserId:   "user-1",
+				OrderRef: "order-1",
+			}),
+		)
+		require.NoError(t, err)
+
+		resp, err := service.ValidateCoupon(
+			ctx,
+			connect.NewRequest(&coupon.ValidateCouponRequest{Code: code}),
+		)
+		require.NoError(t, err)
+		assert.False(t, resp.Msg.Valid)
+		assert.Equal(t, "redeemed", resp.Msg.State)
+		assert.Equal(t,
+			coupon.CouponInvalidReason_COUPON_INVALID_REASON_REDEEMED,
+			resp.Msg.Reason,
+		)
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		resp, err := service.ValidateCoupon(
+			ctx,
+			connect.NewRequest(&coupon.ValidateCouponRequest{
+				Code: "does-not-exist",
+			}),
+		)
+		require.NoError(t, err)
+		assert.False(t, resp.Msg.Valid)
+		assert.Equal(t,
+			coupon.CouponInvalidReason_COUPON_INVALID_REASON_NOT_FOUND,
+			resp.Msg.Reason,
+		)
+	})
+
+	t.Run("mistyped code", func(t *testing.T) {
+		created, err := service.CreateCampaign(
+			ctx,
+			connect.NewRequest(&coupon.CreateCampaignRequest{
+				Name:        "Call Center Campaign",
+				StartTime:   time.Now().Format(time.RFC3339),
+				CouponLimit: 10,
+				CodeFormat: &coupon.CodeFormat{
+					Alphabet:       coupon.CodeAlphabet_CODE_ALPHABET_DIGITS,
+					Length:         8,
+					CheckCharacter: coupon.CheckCharacter_CHECK_CHARACTER_DAMM,
+				},
+			}),
+		)
+		require.NoError(t, err)
+		_, err = service.pool.Exec(ctx,
+			`UPDATE campaigns SET status = 'active' WHERE id = $1`,
+			created.Msg.CampaignId,
+		)
+		require.NoError(t, err)
+
+		issued, err := service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: created.Msg.CampaignId,
+				UserId:     "user-2",
+			}),
+		)
+		require.NoError(t, err)
+		code := issued.Msg.CouponCode
+
+		typo := []byte(code)
+		typo[3] = '0' + (typo[3]-'0'+1)%10
+
+		validate := func(userID string) *coupon.ValidateCouponResponse {
+			resp, err := service.ValidateCoupon(
+				ctx,
+				connect.NewRequest(&coupon.ValidateCouponRequest{
+					Code:   string(typo),
+					UserId: userID,
+				}),
+			)
+			require.NoError(t, err)
+			assert.False(t, resp.Msg.Valid)
+			assert.Equal(t,
+				coupon.CouponInvalidReason_COUPON_INVALID_REASON_PROBABLE_TYPO,
+				resp.Msg.Reason,
+			)
+			return resp.Msg
+		}
+
+		assert.Equal(t, []string{code}, validate("user-2").Suggestions)
+
+		// Other users' codes are never suggested
+		require.NoError(t, service.codeGen.writeIssuedCodes(ctx, service.pool))
+		assert.Empty(t, validate("user-3").Suggestions)
+		assert.Empty(t, validate("").Suggestions)
+		assert.Equal(t, []string{code}, validate("user-2").Suggestions)
+	})
+
+	t.Run("empty code", func(t *testing.T) {
+		_, err := service.ValidateCoupon(
+			ctx,
+			connect.NewRequest(&coupon.ValidateCouponRequest{}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
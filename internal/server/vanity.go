@@ -0,0 +1,401 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// createVanityCode reserves a campaign's vanity code with an issued coupon
+// without an owner. It fails if the code exists already, whether generated,
+// imported or another campaign's vanity code.
+func createVanityCode(
+	ctx context.Context,
+	tx pgx.Tx,
+	campaignID pgtype.UUID,
+	code string,
+) error {
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO coupons (code, campaign_id, state, issued_at, expires_at)
+		SELECT $1, id, 'issued', now(), valid_until
+		FROM campaigns WHERE id = $2
+		ON CONFLICT (code) DO NOTHING`,
+		code,
+		campaignID,
+	)
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to reserve vanity code: %v", err),
+		)
+	}
+	if tag.RowsAffected() == 0 {
+		return connect.NewError(
+			connect.CodeAlreadyExists,
+			fmt.Errorf("code %q already exists", code),
+		)
+	}
+	if err := recordIssuedEvents(ctx, tx, []string{code}); err != nil {
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	return nil
+}
+
+// claimVanityCode records a user's claim of a vanity code. The caller has
+// already taken a slot from the campaign counter, and returns it if the
+// claim was not new because the user had claimed the code before.
+func (s *CouponService) claimVanityCode(
+	ctx context.Context,
+	campaignID string,
+	userID string,
+) (bool, error) {
+	_, err := s.pool.Exec(ctx,
+		`INSERT INTO vanity_claims (campaign_id, user_id, expires_at)
+		SELECT id, $2, LEAST(
+			valid_until,
+			now() + make_interval(secs => validity_seconds)
+		)
+		FROM campaigns WHERE id = $1`,
+		campaignID,
+		userID,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim vanity code: %w", err)
+	}
+	return true, nil
+}
+
+// hasVanityClaim reports whether a user has claimed a campaign's vanity
+// code.
+func (s *CouponService) hasVanityClaim(
+	ctx context.Context,
+	campaignID string,
+	userID string,
+) (bool, error) {
+	var claimed bool
+	err := s.pool.QueryRow(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM vanity_claims
+			WHERE campaign_id = $1 AND user_id = $2
+		)`,
+		campaignID,
+		userID,
+	).Scan(&claimed)
+	if err != nil {
+		return false, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get vanity claim: %v", err),
+		)
+	}
+	return claimed, nil
+}
+
+// vanityCampaign returns the campaign a code is the vanity code of, or
+// false if it is not one.
+func (s *CouponService) vanityCampaign(
+	ctx context.Context,
+	code string,
+) (pgtype.UUID, bool, error) {
+	var campaignID pgtype.UUID
+	err := s.pool.QueryRow(ctx,
+		`SELECT id FROM campaigns WHERE vanity_code = $1`,
+		code,
+	).Scan(&campaignID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return campaignID, false, nil
+	}
+	if err != nil {
+		return campaignID, false, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get campaign: %v", err),
+		)
+	}
+	return campaignID, true, nil
+}
+
+// rejectVanityCode fails with the given message if code is a vanity code,
+// for operations that only make sense on a coupon of a single user.
+func (s *CouponService) rejectVanityCode(
+	ctx context.Context,
+	code string,
+	message string,
+) error {
+	_, vanity, err := s.vanityCampaign(ctx, code)
+	if err != nil {
+		return err
+	}
+	if vanity {
+		return connect.NewError(
+			connect.CodeFailedPrecondition,
+			errors.New(message),
+		)
+	}
+	return nil
+}
+
+// vanityClaimState returns the state of a user's claim of a vanity code and
+// when it expires, given the state and expiry of the shared coupon. claimed
+// is false if the user has not claimed the code.
+func (s *CouponService) vanityClaimState(
+	ctx context.Context,
+	campaignID pgtype.UUID,
+	userID string,
+	state couponState,
+	expiresAt *time.Time,
+) (couponState, *time.Time, bool, error) {
+	if userID == "" {
+		return state, expiresAt, false, nil
+	}
+
+	var (
+		claimExpiresAt *time.Time
+		redeemed       bool
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT expires_at, redeemed_at IS NOT NULL FROM vanity_claims
+		WHERE campaign_id = $1 AND user_id = $2`,
+		campaignID,
+		userID,
+	).Scan(&claimExpiresAt, &redeemed)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return state, expiresAt, false, nil
+	}
+	if err != nil {
+		return state, expiresAt, false, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get vanity claim: %v", err),
+		)
+	}
+
+	if claimExpiresAt != nil &&
+		(expiresAt == nil || claimExpiresAt.Before(*expiresAt)) {
+		expiresAt = claimExpiresAt
+	}
+	// Voiding the shared coupon ends every claim
+	if state == couponIssued && redeemed {
+		state = couponRedeemed
+	}
+	return state, expiresAt, true, nil
+}
+
+func vanityNotClaimedError() error {
+	return connect.NewError(
+		connect.CodeFailedPrecondition,
+		fmt.Errorf("user has not claimed this code"),
+	)
+}
+
+// redeemVanityCode redeems a user's claim of a vanity code. The shared
+// coupon only records the redemption in its ledger; it stays issued until
+// it is voided or expires, which ends the code for every user.
+func (s *CouponService) redeemVanityCode(
+	ctx context.Context,
+	campaignID pgtype.UUID,
+	code string,
+	userID string,
+	orderRef string,
+) (*coupon.RedeemCouponResponse, error) {
+	if userID == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("user_id is required to redeem a vanity code"),
+		)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to begin transaction: %v", err),
+		)
+	}
+	defer func() {
+		if tx != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				log.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	var (
+		couponID   pgtype.UUID
+		redeemedAt time.Time
+	)
+	err = tx.QueryRow(ctx,
+		`UPDATE vanity_claims v
+		SET redeemed_at = now(), order_ref = $3
+		FROM coupons c
+		WHERE c.code = $4
+		AND c.state = 'issued'
+		AND (c.expires_at IS NULL OR c.expires_at > now())
+		AND v.campaign_id = $1
+		AND v.user_id = $2
+		AND v.redeemed_at IS NULL
+		AND (v.expires_at IS NULL OR v.expires_at > now())
+		RETURNING c.id, v.redeemed_at`,
+		campaignID,
+		userID,
+		orderRef,
+		code,
+	).Scan(&couponID, &redeemedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.explainVanityRedemptionFailure(
+			ctx, campaignID, code, userID, orderRef,
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to redeem vanity code: %v", err),
+		)
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO coupon_redemptions
+			(coupon_id, order_ref, user_id, redeemed_at)
+		VALUES ($1, $2, $3, $4)`,
+		couponID,
+		orderRef,
+		userID,
+		redeemedAt,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		// Another user's redemption was recorded for the same order
+		return nil, connect.NewError(
+			connect.CodeAlreadyExists,
+			fmt.Errorf("order %s has already redeemed this code", orderRef),
+		)
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to record redemption: %v", err),
+		)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to commit transaction: %v", err),
+		)
+	}
+	tx = nil // Set tx to nil after successful commit
+
+	return &coupon.RedeemCouponResponse{
+		Code:            code,
+		CampaignId:      campaignID.String(),
+		State:           string(couponRedeemed),
+		RedeemedAt:      redeemedAt.Format(time.RFC3339),
+		OrderRef:        orderRef,
+		RedemptionCount: 1,
+		MaxRedemptions:  1,
+	}, nil
+}
+
+func (s *CouponService) explainVanityRedemptionFailure(
+	ctx context.Context,
+	campaignID pgtype.UUID,
+	code string,
+	userID string,
+	orderRef string,
+) (*coupon.RedeemCouponResponse, error) {
+	var (
+		state           couponState
+		expiresAt       *time.Time
+		claimExpiresAt  *time.Time
+		redeemedAt      *time.Time
+		redeemedOrderID *string
+	)
+	err := s.pool.QueryRow(ctx,
+		`SELECT c.state, c.expires_at, v.expires_at, v.redeemed_at, v.order_ref
+		FROM coupons c
+		JOIN vanity_claims v ON v.campaign_id = c.campaign_id
+		WHERE c.code = $1 AND v.user_id = $2`,
+		code,
+		userID,
+	).Scan(&state, &expiresAt, &claimExpiresAt, &redeemedAt, &redeemedOrderID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, vanityNotClaimedError()
+	}
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get vanity claim: %v", err),
+		)
+	}
+
+	// Retried redemption of the same order
+	if redeemedAt != nil && derefString(redeemedOrderID) == orderRef {
+		return &coupon.RedeemCouponResponse{
+			Code:            code,
+			CampaignId:      campaignID.String(),
+			State:           string(couponRedeemed),
+			RedeemedAt:      redeemedAt.Format(time.RFC3339),
+			OrderRef:        orderRef,
+			RedemptionCount: 1,
+			MaxRedemptions:  1,
+		}, nil
+	}
+
+	switch {
+	case state != couponIssued:
+	case isExpired(expiresAt) || isExpired(claimExpiresAt):
+		state = couponExpired
+	case redeemedAt != nil:
+		state = couponRedeemed
+	}
+	return nil, transitionError(state, couponRedeemed)
+}
+
+// reverseVanityRedemption makes a user's claim of a vanity code redeemable
+// again after the ledger entry for the order has been reversed.
+func reverseVanityRedemption(
+	ctx context.Context,
+	tx pgx.Tx,
+	couponID pgtype.UUID,
+	orderRef string,
+) error {
+	tag, err := tx.Exec(ctx,
+		`UPDATE vanity_claims v
+		SET redeemed_at = NULL, order_ref = NULL
+		FROM coupons c
+		WHERE c.id = $1
+		AND v.campaign_id = c.campaign_id
+		AND v.order_ref = $2
+		AND c.state = 'issued'
+		AND (c.expires_at IS NULL OR c.expires_at > now())
+		AND (v.expires_at IS NULL OR v.expires_at > now())`,
+		couponID,
+		orderRef,
+	)
+	if err != nil {
+		return connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to restore vanity claim: %v", err),
+		)
+	}
+	if tag.RowsAffected() == 0 {
+		return connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("vanity code can no longer be redeemed"),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCouponService_VanityCode(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	createCampaign := func(code string) (string, error) {
+		created, err := service.CreateCampaign(
+			ctx,
+			connect.NewRequest(&coupon.CreateCampaignRequest{
+				Name:        "Summer Campaign",
+				StartTime:   time.Now().Format(time.RFC3339),
+				CouponLimit: 2,
+				VanityCode:  code,
+			}),
+		)
+		if err != nil {
+			return "", err
+		}
+		_, err = service.pool.Exec(ctx,
+			`UPDATE campaigns SET status = 'active' WHERE id = $1`,
+			created.Msg.CampaignId,
+		)
+		require.NoError(t, err)
+		return created.Msg.CampaignId, nil
+	}
+	campaignID, err := createCampaign("SUMMER24")
+	require.NoError(t, err)
+
+	claim := func(userID string) (string, error) {
+		resp, err := service.IssueCoupon(
+			ctx,
+			connect.NewRequest(&coupon.IssueCouponRequest{
+				CampaignId: campaignID,
+				UserId:     userID,
+			}),
+		)
+		if err != nil {
+			return "", err
+		}
+		return resp.Msg.CouponCode, nil
+	}
+	redeem := func(userID, orderRef string) error {
+		_, err := service.RedeemCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RedeemCouponRequest{
+				Code:     "SUMMER24",
+				UserId:   userID,
+				OrderRef: orderRef,
+			}),
+		)
+		return err
+	}
+
+	t.Run("codes cannot collide", func(t *testing.T) {
+		_, err := createCampaign("SUMMER24")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeAlreadyExists, connect.CodeOf(err))
+
+		_, code := issueTestCoupon(t, service, "user-1")
+		_, err = createCampaign(code)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeAlreadyExists, connect.CodeOf(err))
+	})
+
+	t.Run("each user claims the code once", func(t *testing.T) {
+		for _, userID := range []string{"user-1", "user-1", "user-2"} {
+			code, err := claim(userID)
+			require.NoError(t, err)
+			assert.Equal(t, "SUMMER24", code)
+		}
+
+		_, err := claim("user-3")
+		require.Error(t, err)
+	})
+
+	t.Run("each claim is redeemed once", func(t *testing.T) {
+		require.NoError(t, redeem("user-1", "order-1"))
+		require.NoError(t, redeem("user-1", "order-1"))
+		require.NoError(t, redeem("user-2", "order-2"))
+
+		err := redeem("user-1", "order-3")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		err = redeem("user-3", "order-4")
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	t.Run("reversal frees the claim", func(t *testing.T) {
+		_, err := service.ReverseRedemption(
+			ctx,
+			connect.NewRequest(&coupon.ReverseRedemptionRequest{
+				Code:     "SUMMER24",
+				OrderRef: "order-1",
+			}),
+		)
+		require.NoError(t, err)
+		assert.NoError(t, redeem("user-1", "order-5"))
+	})
+
+	t.Run("codes are seen through the user's claim", func(t *testing.T) {
+		_, err := service.pool.Exec(ctx,
+			`DELETE FROM vanity_claims WHERE campaign_id = $1 AND user_id = $2`,
+			campaignID,
+			"user-2",
+		)
+		require.NoError(t, err)
+		_, err = service.pool.Exec(ctx,
+			`INSERT INTO vanity_claims (campaign_id, user_id) VALUES ($1, $2)`,
+			campaignID,
+			"user-2",
+		)
+		require.NoError(t, err)
+
+		validate := func(userID string) *coupon.ValidateCouponResponse {
+			resp, err := service.ValidateCoupon(
+				ctx,
+				connect.NewRequest(&coupon.ValidateCouponRequest{
+					Code:   "SUMMER24",
+					UserId: userID,
+				}),
+			)
+			require.NoError(t, err)
+			return resp.Msg
+		}
+		assert.True(t, validate("user-2").Valid)
+		assert.Equal(t,
+			coupon.CouponInvalidReason_COUPON_INVALID_REASON_REDEEMED,
+			validate("user-1").Reason,
+		)
+		assert.Equal(t,
+			coupon.CouponInvalidReason_COUPON_INVALID_REASON_NOT_ISSUED,
+			validate("user-3").Reason,
+		)
+
+		_, err = service.ApplyCoupon(
+			ctx,
+			connect.NewRequest(&coupon.ApplyCouponRequest{
+				Code:   "SUMMER24",
+				UserId: "user-3",
+				Cart: &coupon.Cart{
+					Currency: "KRW",
+					Items: []*coupon.CartItem{
+						{Sku: "sku-1", UnitPrice: "1000", Quantity: 1},
+					},
+				},
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+		assert.Contains(t, err.Error(), "not claimed")
+
+		wallet, err := service.ListUserCoupons(
+			ctx,
+			connect.NewRequest(&coupon.ListUserCouponsRequest{
+				UserId: "user-2",
+			}),
+		)
+		require.NoError(t, err)
+		require.Len(t, wallet.Msg.Coupons, 1)
+		assert.Equal(t, "SUMMER24", wallet.Msg.Coupons[0].Code)
+		assert.Equal(t, "issued", wallet.Msg.Coupons[0].State)
+	})
+
+	t.Run("vanity codes cannot be revoked or extended", func(t *testing.T) {
+		_, err := service.RevokeCoupon(
+			ctx,
+			connect.NewRequest(&coupon.RevokeCouponRequest{
+				Code:         "SUMMER24",
+				Reason:       "leaked",
+				ReturnToPool: true,
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+
+		_, err = service.SetCouponRedemptionLimit(
+			ctx,
+			connect.NewRequest(&coupon.SetCouponRedemptionLimitRequest{
+				Code:           "SUMMER24",
+				MaxRedemptions: 5,
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+
+	t.Run("vanity codes cannot be locked", func(t *testing.T) {
+		_, err := service.LockCoupon(
+			ctx,
+			connect.NewRequest(&coupon.LockCouponRequest{
+				Code:       "SUMMER24",
+				CheckoutId: "checkout-1",
+				UserId:     "user-1",
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+	})
+}
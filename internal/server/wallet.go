@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+	"coupon-issuance/internal/benefit"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type (
+	ListUserCouponsReq  = connect.Request[coupon.ListUserCouponsRequest]
+	ListUserCouponsResp = connect.Response[coupon.ListUserCouponsResponse]
+)
+
+const (
+	defaultWalletPageSize = 20
+	maxWalletPageSize     = 100
+)
+
+// walletCursor is the position after the last coupon of a page. Pages are
+// ordered by issued_at and id, both descending.
+type walletCursor struct {
+	issuedAt time.Time
+	id       pgtype.UUID
+}
+
+func (c walletCursor) encode() string {
+	token := fmt.Sprintf("%d:%s", c.issuedAt.UnixMicro(), c.id.String())
+	return base64.RawURLEncoding.EncodeToString([]byte(token))
+}
+
+func decodeWalletCursor(token string) (walletCursor, error) {
+	var c walletCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, fmt.Errorf("invalid page token")
+	}
+	micros, id, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return c, fmt.Errorf("invalid page token")
+	}
+	n, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return c, fmt.Errorf("invalid page token")
+	}
+	if err := c.id.Scan(id); err != nil {
+		return c, fmt.Errorf("invalid page token")
+	}
+	c.issuedAt = time.UnixMicro(n)
+	return c, nil
+}
+
+// ListUserCoupons returns the coupons a user holds, including the vanity
+// codes they claimed, most recently issued first, with what the wallet
+// screen needs to display them.
+func (s *CouponService) ListUserCoupons(
+	ctx context.Context,
+	req *ListUserCouponsReq,
+) (*ListUserCouponsResp, error) {
+	userID := strings.TrimSpace(req.Msg.UserId)
+	if userID == "" {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("user_id cannot be empty"),
+		)
+	}
+
+	states := make([]string, 0, len(req.Msg.States))
+	for _, state := range req.Msg.States {
+		if !couponState(state).valid() {
+			return nil, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("unknown coupon state %q", state),
+			)
+		}
+		states = append(states, state)
+	}
+
+	pageSize := req.Msg.PageSize
+	if pageSize < 0 {
+		return nil, connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("page_size cannot be negative"),
+		)
+	}
+	if pageSize == 0 {
+		pageSize = defaultWalletPageSize
+	}
+	pageSize = min(pageSize, maxWalletPageSize)
+
+	var cursor *walletCursor
+	if req.Msg.PageToken != "" {
+		c, err := decodeWalletCursor(req.Msg.PageToken)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		cursor = &c
+	}
+
+	// The user's coupons issued by this server but not yet written would be
+	// missing
+	if err := s.flushUserCoupons(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	var (
+		afterIssuedAt *time.Time
+		afterID       *pgtype.UUID
+	)
+	if cursor != nil {
+		afterIssuedAt = &cursor.issuedAt
+		afterID = &cursor.id
+	}
+
+	// Claims of vanity codes are listed as coupons of their own, with the
+	// claim's state and expiry. One extra row tells whether there is a
+	// next page.
+	rows, err := s.pool.Query(ctx,
+		`WITH wallet AS (
+			SELECT c.id, c.code, c.campaign_id, cp.name, c.state, c.issued_at,
+				c.expires_at, cp.benefit, c.redemption_count,
+				COALESCE(c.max_redemptions, cp.max_redemptions)
+					AS max_redemptions
+			FROM coupons c
+			JOIN campaigns cp ON cp.id = c.campaign_id
+			WHERE c.user_id = $1
+			UNION ALL
+			SELECT c.id, c.code, c.campaign_id, cp.name,
+				CASE
+					WHEN c.state = 'issued' AND v.redeemed_at IS NOT NULL
+					THEN 'redeemed'
+					ELSE c.state
+				END,
+				v.claimed_at,
+				LEAST(c.expires_at, v.expires_at), cp.benefit,
+				(v.redeemed_at IS NOT NULL)::int, 1
+			FROM vanity_claims v
+			JOIN campaigns cp ON cp.id = v.campaign_id
+			JOIN coupons c ON c.code = cp.vanity_code
+			WHERE v.user_id = $1
+		)
+		SELECT id, code, campaign_id, name, state, issued_at, expires_at,
+			benefit, redemption_count, max_redemptions
+		FROM wallet
+		WHERE (
+			cardinality($2::text[]) = 0 OR
+			(CASE
+				WHEN state = 'issued' AND expires_at <= now()
+				THEN 'expired'
+				ELSE state::text
+			END) = ANY($2::text[])
+		)
+		AND ($3::timestamptz IS NULL OR (issued_at, id) < ($3, $4))
+		ORDER BY issued_at DESC, id DESC
+		LIMIT $5`,
+		userID,
+		states,
+		afterIssuedAt,
+		afterID,
+		pageSize+1,
+	)
+	if err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("failed to get user coupons: %v", err),
+		)
+	}
+	defer rows.Close()
+
+	resp := &coupon.ListUserCouponsResponse{}
+	var last walletCursor
+	for rows.Next() {
+		var (
+			id             pgtype.UUID
+			campaignID     pgtype.UUID
+			c              = &coupon.UserCoupon{}
+			state          couponState
+			issuedAt       time.Time
+			expiresAt      *time.Time
+			couponBenefit  *benefit.Benefit
+			maxRedemptions int32
+		)
+		err := rows.Scan(
+			&id,
+			&c.Code,
+			&campaignID,
+			&c.CampaignName,
+			&state,
+			&issuedAt,
+			&expiresAt,
+			&couponBenefit,
+			&c.RedemptionCount,
+			&maxRedemptions,
+		)
+		if err != nil {
+			return nil, connect.NewError(
+				connect.CodeInternal,
+				fmt.Errorf("failed to scan user coupon: %v", err),
+			)
+		}
+
+		if len(resp.Coupons) == int(pageSize) {
+			resp.NextPageToken = last.encode()
+			break
+		}
+		last = walletCursor{issuedAt: issuedAt, id: id}
+
+		if state == couponIssued && isExpired(expiresAt) {
+			state = couponExpired
+		}
+		c.CampaignId = campaignID.String()
+		c.State = string(state)
+		c.IssuedAt = issuedAt.Format(time.RFC3339)
+		c.ExpiresAt = formatOptionalTime(expiresAt)
+		c.MaxRedemptions = maxRedemptions
+		if couponBenefit != nil {
+			c.Benefit = benefitToProto(couponBenefit)
+			c.BenefitSummary = couponBenefit.Summary()
+		}
+		resp.Coupons = append(resp.Coupons, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, connect.NewError(
+			connect.CodeInternal,
+			fmt.Errorf("error iterating user coupons: %v", err),
+		)
+	}
+
+	return connect.NewResponse(resp), nil
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coupon "coupon-issuance/gen/coupon/v1"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletCursor(t *testing.T) {
+	var c walletCursor
+	c.issuedAt = time.Date(2026, 3, 1, 9, 30, 0, 123456000, time.UTC)
+	require.NoError(t, c.id.Scan("0b8d1f3e-8a5c-4c1e-9d3a-2f6b7c8d9e0f"))
+
+	decoded, err := decodeWalletCursor(c.encode())
+	require.NoError(t, err)
+	assert.True(t, c.issuedAt.Equal(decoded.issuedAt))
+	assert.Equal(t, c.id, decoded.id)
+
+	for _, token := range []string{"!!", "bm8tY29sb24", "eDp5"} {
+		_, err := decodeWalletCursor(token)
+		assert.Error(t, err, token)
+	}
+}
+
+func TestCouponService_ListUserCoupons(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	campaignID, _ := issueTestCoupon(t, service, "user-1")
+	_, err := service.pool.Exec(ctx,
+		`UPDATE campaigns SET benefit = $2 WHERE id = $1`,
+		campaignID,
+		`{"kind": "free_item", "sku": "LATTE"}`,
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		issueTestCoupon(t, service, "user-1")
+	}
+	_, other := issueTestCoupon(t, service, "user-2")
+
+	_, redeemed := issueTestCoupon(t, service, "user-1")
+	_, err = service.RedeemCoupon(
+		ctx,
+		connect.NewRequest(&coupon.RedeemCouponRequest{
+			Code:     redeemed,
+			UserId:   "user-1",
+			OrderRef: "order-1",
+		}),
+	)
+	require.NoError(t, err)
+
+	t.Run("pages through a user's coupons", func(t *testing.T) {
+		seen := map[string]bool{}
+		token := ""
+		pages := 0
+		for {
+			resp, err := service.ListUserCoupons(
+				ctx,
+				connect.NewRequest(&coupon.ListUserCouponsRequest{
+					UserId:    "user-1",
+					PageSize:  2,
+					PageToken: token,
+				}),
+			)
+			require.NoError(t, err)
+			pages++
+			for _, c := range resp.Msg.Coupons {
+				assert.False(t, seen[c.Code], "duplicate %s", c.Code)
+				seen[c.Code] = true
+				assert.Equal(t, "Test Campaign", c.CampaignName)
+				assert.Equal(t, "Free LATTE", c.BenefitSummary)
+			}
+			token = resp.Msg.NextPageToken
+			if token == "" {
+				break
+			}
+		}
+		assert.Equal(t, 3, pages)
+		assert.Len(t, seen, 6)
+		assert.False(t, seen[other])
+	})
+
+	t.Run("filters by state", func(t *testing.T) {
+		resp, err := service.ListUserCoupons(
+			ctx,
+			connect.NewRequest(&coupon.ListUserCouponsRequest{
+				UserId: "user-1",
+				States: []string{"redeemed"},
+			}),
+		)
+		require.NoError(t, err)
+		require.Len(t, resp.Msg.Coupons, 1)
+		assert.Equal(t, redeemed, resp.Msg.Coupons[0].Code)
+		assert.Empty(t, resp.Msg.NextPageToken)
+
+		_, err = service.ListUserCoupons(
+			ctx,
+			connect.NewRequest(&coupon.ListUserCouponsRequest{
+				UserId: "user-1",
+				States: []string{"used"},
+			}),
+		)
+		require.Error(t, err)
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	})
+}
@@ -0,0 +1,205 @@
+// Package signedcode creates and verifies coupon codes that carry their own
+// signature, so that devices without a network connection, such as
+// point-of-sale terminals, can tell a genuine code from a made-up one.
+//
+// A signed code encodes a key ID, a serial number and an HMAC-SHA256 of both
+// truncated to MACSize bytes, written in a campaign's alphabet. Verifying a
+// code only proves it was issued by the holder of the key; whether it has
+// already been redeemed is still only known to the coupon service.
+package signedcode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"unicode/utf8"
+)
+
+const (
+	keyIDSize  = 4
+	serialSize = 6
+	// MACSize is the number of bytes of the HMAC kept in a code.
+	MACSize = 8
+
+	payloadSize = keyIDSize + serialSize + MACSize
+
+	// MaxSerial is the largest serial number a code can carry.
+	MaxSerial = 1<<(8*serialSize) - 1
+)
+
+var (
+	// ErrMalformed is returned for codes that are not signed codes in the
+	// codec's alphabet.
+	ErrMalformed = errors.New("signedcode: malformed code")
+	// ErrUnknownKey is returned for codes signed with a key the verifier
+	// does not have.
+	ErrUnknownKey = errors.New("signedcode: unknown key")
+	// ErrBadSignature is returned for codes whose MAC does not match.
+	ErrBadSignature = errors.New("signedcode: bad signature")
+)
+
+// Key is a signing key. Verifiers need the same secret as the signer.
+type Key struct {
+	ID     uint32
+	Secret []byte
+}
+
+// Payload is what a signed code carries besides its MAC.
+type Payload struct {
+	KeyID  uint32
+	Serial uint64
+}
+
+// Codec writes payloads as fixed-length codes in an alphabet.
+type Codec struct {
+	alphabet []rune
+	index    map[rune]int
+	length   int
+}
+
+// NewCodec returns a codec for an alphabet of at least two distinct
+// characters.
+func NewCodec(alphabet string) (*Codec, error) {
+	if !utf8.ValidString(alphabet) {
+		return nil, errors.New("signedcode: alphabet is not valid UTF-8")
+	}
+	c := &Codec{index: make(map[rune]int)}
+	for _, r := range alphabet {
+		if _, ok := c.index[r]; ok {
+			return nil, fmt.Errorf("signedcode: duplicate character %q", r)
+		}
+		c.index[r] = len(c.alphabet)
+		c.alphabet = append(c.alphabet, r)
+	}
+	if len(c.alphabet) < 2 {
+		return nil, errors.New("signedcode: alphabet needs two characters")
+	}
+
+	// The shortest length whose codes can hold every payload
+	limit := new(big.Int).Lsh(big.NewInt(1), 8*payloadSize)
+	base := big.NewInt(int64(len(c.alphabet)))
+	for n := big.NewInt(1); n.Cmp(limit) < 0; n.Mul(n, base) {
+		c.length++
+	}
+	return c, nil
+}
+
+// Alphabet returns the codec's characters in digit order.
+func (c *Codec) Alphabet() string {
+	return string(c.alphabet)
+}
+
+// Length is the number of characters in every code.
+func (c *Codec) Length() int {
+	return c.length
+}
+
+// Sign returns the code for a serial signed with key.
+func (c *Codec) Sign(key Key, serial uint64) (string, error) {
+	if serial > MaxSerial {
+		return "", fmt.Errorf("signedcode: serial %d out of range", serial)
+	}
+	var b [payloadSize]byte
+	putPayload(b[:], Payload{KeyID: key.ID, Serial: serial})
+	copy(b[keyIDSize+serialSize:], mac(key.Secret, b[:keyIDSize+serialSize]))
+	return c.encode(b[:]), nil
+}
+
+// Parse decodes a code without checking its MAC.
+func (c *Codec) Parse(code string) (Payload, error) {
+	b, err := c.decode(code)
+	if err != nil {
+		return Payload{}, err
+	}
+	return payload(b), nil
+}
+
+func (c *Codec) encode(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	base := big.NewInt(int64(len(c.alphabet)))
+	digit := new(big.Int)
+	code := make([]rune, c.length)
+	for i := c.length - 1; i >= 0; i-- {
+		n.DivMod(n, base, digit)
+		code[i] = c.alphabet[digit.Int64()]
+	}
+	return string(code)
+}
+
+func (c *Codec) decode(code string) ([]byte, error) {
+	if utf8.RuneCountInString(code) != c.length {
+		return nil, ErrMalformed
+	}
+	n := new(big.Int)
+	base := big.NewInt(int64(len(c.alphabet)))
+	for _, r := range code {
+		digit, ok := c.index[r]
+		if !ok {
+			return nil, ErrMalformed
+		}
+		n.Mul(n, base).Add(n, big.NewInt(int64(digit)))
+	}
+	if n.BitLen() > 8*payloadSize {
+		return nil, ErrMalformed
+	}
+	return n.FillBytes(make([]byte, payloadSize)), nil
+}
+
+// Verifier checks codes against a set of keys, typically every key of the
+// campaigns a device accepts.
+type Verifier struct {
+	codec *Codec
+	keys  map[uint32][]byte
+}
+
+// NewVerifier returns a verifier for codes written by codec.
+func NewVerifier(codec *Codec, keys ...Key) *Verifier {
+	v := &Verifier{codec: codec, keys: make(map[uint32][]byte, len(keys))}
+	for _, k := range keys {
+		v.keys[k.ID] = k.Secret
+	}
+	return v
+}
+
+// Verify returns the payload of a genuine code.
+func (v *Verifier) Verify(code string) (Payload, error) {
+	b, err := v.codec.decode(code)
+	if err != nil {
+		return Payload{}, err
+	}
+	p := payload(b)
+	secret, ok := v.keys[p.KeyID]
+	if !ok {
+		return Payload{}, ErrUnknownKey
+	}
+	want := mac(secret, b[:keyIDSize+serialSize])
+	if !hmac.Equal(want, b[keyIDSize+serialSize:]) {
+		return Payload{}, ErrBadSignature
+	}
+	return p, nil
+}
+
+func putPayload(b []byte, p Payload) {
+	binary.BigEndian.PutUint32(b, p.KeyID)
+	var serial [8]byte
+	binary.BigEndian.PutUint64(serial[:], p.Serial)
+	copy(b[keyIDSize:], serial[8-serialSize:])
+}
+
+func payload(b []byte) Payload {
+	var serial [8]byte
+	copy(serial[8-serialSize:], b[keyIDSize:keyIDSize+serialSize])
+	return Payload{
+		KeyID:  binary.BigEndian.Uint32(b),
+		Serial: binary.BigEndian.Uint64(serial[:]),
+	}
+}
+
+func mac(secret, message []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write(message)
+	return h.Sum(nil)[:MACSize]
+}
@@ -0,0 +1,83 @@
+package signedcode
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec(t *testing.T) {
+	_, err := NewCodec("A")
+	assert.Error(t, err)
+	_, err = NewCodec("ABCA")
+	assert.Error(t, err)
+
+	base32, err := NewCodec("ABCDEFGHJKLMNPQRSTUVWXYZ23456789")
+	require.NoError(t, err)
+	assert.Equal(t, 29, base32.Length())
+
+	key := Key{ID: 7, Secret: []byte("secret")}
+	code, err := base32.Sign(key, 12345)
+	require.NoError(t, err)
+	assert.Equal(t, 29, utf8.RuneCountInString(code))
+
+	p, err := base32.Parse(code)
+	require.NoError(t, err)
+	assert.Equal(t, Payload{KeyID: 7, Serial: 12345}, p)
+
+	_, err = base32.Sign(key, MaxSerial+1)
+	assert.Error(t, err)
+
+	_, err = base32.Parse(code[1:])
+	assert.ErrorIs(t, err, ErrMalformed)
+	_, err = base32.Parse("0" + code[1:])
+	assert.ErrorIs(t, err, ErrMalformed)
+	// The largest code of this length holds more than a payload
+	_, err = base32.Parse("99999999999999999999999999999")
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestVerifier(t *testing.T) {
+	codec, err := NewCodec("0123456789가나다라마바사아자차카타파하")
+	require.NoError(t, err)
+
+	oldKey := Key{ID: 1, Secret: []byte("old secret")}
+	newKey := Key{ID: 2, Secret: []byte("new secret")}
+	verifier := NewVerifier(codec, oldKey, newKey)
+
+	for _, key := range []Key{oldKey, newKey} {
+		code, err := codec.Sign(key, MaxSerial)
+		require.NoError(t, err)
+		p, err := verifier.Verify(code)
+		require.NoError(t, err)
+		assert.Equal(t, Payload{KeyID: key.ID, Serial: MaxSerial}, p)
+	}
+
+	code, err := codec.Sign(Key{ID: 3, Secret: []byte("other")}, 1)
+	require.NoError(t, err)
+	_, err = verifier.Verify(code)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// Signed with a different secret under a known key ID
+	code, err = codec.Sign(Key{ID: 1, Secret: []byte("forged")}, 1)
+	require.NoError(t, err)
+	_, err = verifier.Verify(code)
+	assert.ErrorIs(t, err, ErrBadSignature)
+
+	// Changing any character breaks the signature
+	code, err = codec.Sign(oldKey, 42)
+	require.NoError(t, err)
+	runes := []rune(code)
+	for i := range runes {
+		tampered := append([]rune(nil), runes...)
+		if tampered[i] == '0' {
+			tampered[i] = '1'
+		} else {
+			tampered[i] = '0'
+		}
+		_, err := verifier.Verify(string(tampered))
+		assert.Error(t, err, "position %d", i)
+	}
+}